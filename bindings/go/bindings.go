@@ -1,21 +1,184 @@
 package turso
 
 import (
+	"context"
+	"database/sql/driver"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	turso_libs "github.com/tursodatabase/turso-go-platform-libs"
 )
 
-var initLibrary sync.Once
+var (
+	// libraryMu guards loading and unloading of the native library:
+	// users of the library hold it for reading while they register themselves in libraryUsers
+	libraryMu     sync.RWMutex
+	libraryHandle uintptr
+	libraryLoaded atomic.Bool
+	// libraryUsers counts live handles (databases and sync databases) which keep the library in use
+	libraryUsers atomic.Int64
+)
 
+// InitLibrary loads the native library with the given strategy and registers its functions.
+// If the library is already loaded the call is a no-op: use ShutdownLibrary to switch to another strategy.
 func InitLibrary(strategy turso_libs.LoadTursoLibraryConfig) {
-	initLibrary.Do(func() {
-		library, err := turso_libs.LoadTursoLibrary(strategy)
+	if libraryLoaded.Load() {
+		return
+	}
+	libraryMu.Lock()
+	defer libraryMu.Unlock()
+	if libraryLoaded.Load() {
+		return
+	}
+	library, err := turso_libs.LoadTursoLibrary(strategy)
+	if err != nil {
+		panic(fmt.Errorf("unable to load turso library: %w", err))
+	}
+	registerTursoDb(library)
+	registerTursoSync(library)
+	libraryHandle = library
+	libraryLoaded.Store(true)
+}
+
+// ShutdownLibrary releases the native library loaded by InitLibrary, so the next InitLibrary loads it again
+// (possibly with a different strategy). It is a no-op if the library is not loaded.
+// ShutdownLibrary returns ErrTursoLibraryInUse while any database opened through the library is alive:
+// close every *sql.DB (or connection) first. A TursoSyncDb keeps the library in use until it's closed.
+// The "turso" driver stays registered in database/sql (which lacks unregistration): opening a connection
+// after shutdown loads the library again with the default strategy.
+func ShutdownLibrary() error {
+	libraryMu.Lock()
+	defer libraryMu.Unlock()
+	if !libraryLoaded.Load() {
+		return nil
+	}
+	if n := libraryUsers.Load(); n > 0 {
+		return fmt.Errorf("%w: %d database(s) still open", ErrTursoLibraryInUse, n)
+	}
+	if err := closeLibrary(libraryHandle); err != nil {
+		return fmt.Errorf("turso: unable to unload turso library: %w", err)
+	}
+	libraryHandle = 0
+	libraryLoaded.Store(false)
+	return nil
+}
+
+// acquireLibrary registers a new user of the library; the caller must call releaseLibrary once done.
+func acquireLibrary() error {
+	libraryMu.RLock()
+	defer libraryMu.RUnlock()
+	if !libraryLoaded.Load() {
+		return ErrTursoLibraryNotLoaded
+	}
+	libraryUsers.Add(1)
+	return nil
+}
+
+func releaseLibrary() {
+	libraryUsers.Add(-1)
+}
+
+// requireLibrary acquires the library for a short call of the package-level function name
+// and panics if InitLibrary has not loaded the library yet.
+func requireLibrary(name string) {
+	if err := acquireLibrary(); err != nil {
+		panic(fmt.Sprintf("turso: %s called before InitLibrary: the library is not loaded", name))
+	}
+}
+
+// Version returns the version of the loaded library, e.g. "0.4.0" or "0.4.0-pre.1".
+// It panics if called before InitLibrary.
+func Version() string {
+	requireLibrary("Version")
+	defer releaseLibrary()
+	return turso_version()
+}
+
+// VersionNumber returns the version of the loaded library encoded as major*1000000 + minor*1000 + patch,
+// the same scheme as sqlite3_libversion_number; pre-release and build suffixes are ignored.
+// It panics if called before InitLibrary.
+func VersionNumber() int {
+	requireLibrary("VersionNumber")
+	defer releaseLibrary()
+	n, err := parseVersionNumber(turso_version())
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func parseVersionNumber(version string) (int, error) {
+	core, _, _ := strings.Cut(version, "-")
+	core, _, _ = strings.Cut(core, "+")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("turso: malformed library version %q", version)
+	}
+	n := 0
+	for _, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 || v >= 1000 {
+			return 0, fmt.Errorf("turso: malformed library version %q", version)
+		}
+		n = n*1000 + v
+	}
+	return n, nil
+}
+
+// SourceID returns the source identifier of the loaded library as reported by sqlite_source_id():
+// the build time followed by the git hash of the sources.
+// It panics if called before InitLibrary.
+func SourceID() string {
+	requireLibrary("SourceID")
+	defer releaseLibrary()
+	var id string
+	err := withMemoryConnection(func(conn *tursoDbConnection) error {
+		row, err := conn.queryRow(context.Background(), "SELECT sqlite_source_id()", nil)
+		if err != nil || row == nil {
+			return err
+		}
+		id, _ = row[0].(string)
+		return nil
+	})
+	if err != nil {
+		panic(fmt.Errorf("turso: read source id: %w", err))
+	}
+	return id
+}
+
+// CompileOptions returns the compile-time options reported by PRAGMA compile_options.
+// The library does not implement the pragma in every build, in which case the result is empty.
+// It panics if called before InitLibrary.
+func CompileOptions() []string {
+	requireLibrary("CompileOptions")
+	defer releaseLibrary()
+	var options []string
+	_ = withMemoryConnection(func(conn *tursoDbConnection) error {
+		rows, err := conn.query(context.Background(), "PRAGMA compile_options", nil)
 		if err != nil {
-			panic(fmt.Errorf("unable to load turso library: %w", err))
+			return err
 		}
-		registerTursoDb(library)
-		registerTursoSync(library)
+		defer rows.Close()
+		row := make([]driver.Value, len(rows.Columns()))
+		for rows.Next(row) == nil {
+			if option, ok := row[0].(string); ok {
+				options = append(options, option)
+			}
+		}
+		return nil
 	})
+	return options
+}
+
+// withMemoryConnection runs fn on a private in-memory connection that is closed afterwards.
+func withMemoryConnection(fn func(conn *tursoDbConnection) error) error {
+	conn, err := openConnection(TursoDatabaseConfig{Path: ":memory:"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return fn(conn)
 }
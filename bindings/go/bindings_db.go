@@ -1,9 +1,14 @@
 package turso // import "github.com/tursodatabase/turso/go"
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
+	"math"
 	"runtime"
+	"strings"
+	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/ebitengine/purego"
@@ -20,8 +25,104 @@ var (
 	ErrTursoDatabaseFull = errors.New("turso: database is full")
 	ErrTursoNotADb       = errors.New("turso: not a database")
 	ErrTursoCorrupt      = errors.New("turso: database is corrupt")
+	ErrTursoIoErr        = errors.New("turso: disk I/O error")
 )
 
+// SQLite result codes reported by Error.Code and Error.ExtendedCode
+const (
+	SQLITE_ERROR      = 1
+	SQLITE_INTERNAL   = 2
+	SQLITE_BUSY       = 5
+	SQLITE_READONLY   = 8
+	SQLITE_INTERRUPT  = 9
+	SQLITE_IOERR      = 10
+	SQLITE_CORRUPT    = 11
+	SQLITE_NOTFOUND   = 12
+	SQLITE_FULL       = 13
+	SQLITE_CONSTRAINT = 19
+	SQLITE_MISUSE     = 21
+	SQLITE_NOTADB     = 26
+
+	SQLITE_BUSY_SNAPSHOT         = SQLITE_BUSY | (2 << 8)
+	SQLITE_CONSTRAINT_CHECK      = SQLITE_CONSTRAINT | (1 << 8)
+	SQLITE_CONSTRAINT_FOREIGNKEY = SQLITE_CONSTRAINT | (3 << 8)
+	SQLITE_CONSTRAINT_NOTNULL    = SQLITE_CONSTRAINT | (5 << 8)
+	SQLITE_CONSTRAINT_PRIMARYKEY = SQLITE_CONSTRAINT | (6 << 8)
+	SQLITE_CONSTRAINT_TRIGGER    = SQLITE_CONSTRAINT | (7 << 8)
+	SQLITE_CONSTRAINT_UNIQUE     = SQLITE_CONSTRAINT | (8 << 8)
+)
+
+// Error is returned for every failure reported by the library.
+// It unwraps to the matching package-level error (e.g. ErrTursoConstraint), so errors.Is keeps working;
+// use errors.As to inspect the result codes.
+type Error struct {
+	// Code is the primary SQLite result code (e.g. SQLITE_CONSTRAINT)
+	Code int
+	// ExtendedCode is the extended SQLite result code (e.g. SQLITE_CONSTRAINT_UNIQUE)
+	// It equals Code when the library reports no details
+	ExtendedCode int
+	// Message is the error message reported by the library
+	Message string
+}
+
+func (e Error) Error() string {
+	if e.Message != "" {
+		return e.Unwrap().Error() + ": " + e.Message
+	}
+	return e.Unwrap().Error()
+}
+
+// Is reports whether the error matches target beyond its primary result code:
+// writes of a vector with the number of dimensions other than declared by the column match ErrVectorDimension.
+func (e Error) Is(target error) bool {
+	return target == ErrVectorDimension && e.Code == SQLITE_CONSTRAINT && strings.HasPrefix(e.Message, "vector dimension mismatch")
+}
+
+// Unwrap returns the package-level error for the primary result code.
+func (e Error) Unwrap() error {
+	switch e.Code {
+	case SQLITE_BUSY:
+		return ErrTursoBusy
+	case SQLITE_INTERRUPT:
+		return ErrTursoInterrupt
+	case SQLITE_MISUSE:
+		return ErrTursoMisuse
+	case SQLITE_CONSTRAINT:
+		return ErrTursoConstraint
+	case SQLITE_READONLY:
+		return ErrTursoReadOnly
+	case SQLITE_FULL:
+		return ErrTursoDatabaseFull
+	case SQLITE_NOTADB:
+		return ErrTursoNotADb
+	case SQLITE_CORRUPT:
+		return ErrTursoCorrupt
+	case SQLITE_IOERR:
+		return ErrTursoIoErr
+	default:
+		return ErrTursoGeneric
+	}
+}
+
+// IsBusy reports whether err is caused by the database being locked by another connection.
+func IsBusy(err error) bool {
+	return errors.Is(err, ErrTursoBusy)
+}
+
+// IsConstraint reports whether err is caused by a constraint violation.
+func IsConstraint(err error) bool {
+	return errors.Is(err, ErrTursoConstraint)
+}
+
+// IsNotFound reports whether err means that the requested row or object doesn't exist.
+func IsNotFound(err error) bool {
+	if errors.Is(err, sql.ErrNoRows) {
+		return true
+	}
+	var e Error
+	return errors.As(err, &e) && e.Code == SQLITE_NOTFOUND
+}
+
 // DefaultBusyTimeout is the default busy timeout in milliseconds (5 seconds).
 // This matches common SQLite production recommendations. Set _busy_timeout=-1
 // in the DSN to disable the busy handler completely.
@@ -37,6 +138,7 @@ const (
 	TURSO_IO            TursoStatusCode = 3
 	TURSO_BUSY          TursoStatusCode = 4
 	TURSO_INTERRUPT     TursoStatusCode = 5
+	TURSO_BUSY_SNAPSHOT TursoStatusCode = 6
 	TURSO_ERROR         TursoStatusCode = 127
 	TURSO_MISUSE        TursoStatusCode = 128
 	TURSO_CONSTRAINT    TursoStatusCode = 129
@@ -44,6 +146,7 @@ const (
 	TURSO_DATABASE_FULL TursoStatusCode = 131
 	TURSO_NOTADB        TursoStatusCode = 132
 	TURSO_CORRUPT       TursoStatusCode = 133
+	TURSO_IOERR         TursoStatusCode = 134
 )
 
 type TursoType int32
@@ -67,14 +170,30 @@ const (
 	TURSO_TRACING_LEVEL_TRACE TursoTracingLevel = 5
 )
 
+// value types of turso_value_t passed to and returned from user-defined functions
+const (
+	turso_extension_value_null    uint32 = 0
+	turso_extension_value_integer uint32 = 1
+	turso_extension_value_float   uint32 = 2
+	turso_extension_value_text    uint32 = 3
+	turso_extension_value_blob    uint32 = 4
+	turso_extension_value_error   uint32 = 5
+)
+
+const turso_extension_result_error uint32 = 1
+
 // define opaque pointers as-is and accept them as exact arguments
 type turso_database_t struct{}
 type turso_connection_t struct{}
 type turso_statement_t struct{}
+type turso_blob_t struct{}
+type turso_backup_t struct{}
 
 type TursoDatabase *turso_database_t
 type TursoConnection *turso_connection_t
 type TursoStatement *turso_statement_t
+type TursoBlob *turso_blob_t
+type TursoBackup *turso_backup_t
 
 // define all public binding types
 type TursoLog struct {
@@ -116,6 +235,39 @@ type TursoDatabaseConfig struct {
 	Encryption TursoDatabaseEncryptionOpts
 	// BusyTimeout in milliseconds (0 = no timeout, immediate SQLITE_BUSY)
 	BusyTimeout int
+	// ReadYourWrites makes reads on any connection of the pool observe writes already committed through the pool
+	ReadYourWrites bool
+	// ReadOnly opens the database file read-only and fails if it doesn't exist (mode=ro).
+	// The process shares one database per file, so opening read-write a file which is already open read-only fails.
+	ReadOnly bool
+	// NoCreate fails to open the database file if it doesn't exist instead of creating it (mode=rw)
+	NoCreate bool
+	// Immutable opens the database file read-only without locking: nobody may modify the file meanwhile (immutable=1)
+	Immutable bool
+	// AllowLoadExtension permits loading extensions with LoadExtension and SQL load_extension() function
+	AllowLoadExtension bool
+	// TimeFormat defines how time.Time arguments are stored and how integers of time columns are read back
+	TimeFormat TimeFormat
+	// StmtCacheSize is the number of idle prepared statements cached by each connection (0 disables the cache)
+	StmtCacheSize int
+	// SharedMemory names the in-memory database shared by all connections of the process opened with the same name
+	// (empty if the in-memory database is private)
+	SharedMemory string
+	// BusyRetries is the number of times a statement failed with SQLITE_BUSY is retried when it's safe
+	// (0 falls back to the policy set with SetBusyRetryPolicy)
+	BusyRetries int
+	// BusyRetryBackoff is the delay before the first retry, doubled for every next one (default is DefaultBusyRetryBackoff)
+	BusyRetryBackoff time.Duration
+	// PageSize is the page size of a newly created database in bytes (0 keeps the default)
+	PageSize int
+	// AutoVacuum is the auto_vacuum mode of a newly created database: "none", "full" or "incremental"
+	// (empty keeps the default); as auto_vacuum is experimental - ExperimentalFeatures must have "autovacuum" in the list
+	AutoVacuum string
+	// Profile measures every statement of the connections for ConnStats even if no hook is set with SetProfileHook
+	Profile bool
+	// NullAsZero makes Scan store the zero value when NULL is scanned into a non-nullable destination
+	// (e.g. *int, *string, *[]byte or *time.Time) instead of failing; requires Go 1.27 or later
+	NullAsZero bool
 }
 
 // define all necessary private C structs
@@ -147,18 +299,52 @@ type turso_database_config_t struct {
 	encryption_hexkey     uintptr // const char* or null
 }
 
+type turso_extension_text_t struct {
+	subtype uint32  // turso_extension_text_subtype_t
+	text    uintptr // const uint8_t*
+	len     uint32
+}
+
+type turso_extension_blob_t struct {
+	data uintptr // const uint8_t*
+	size uint64
+}
+
+type turso_extension_error_t struct {
+	code    uint32  // turso_extension_result_code_t
+	message uintptr // turso_extension_text_t*
+}
+
+type turso_value_t struct {
+	value_type uint32 // turso_extension_value_type_t
+	value      uint64 // turso_extension_value_data_t union: int64, double bits or pointer
+}
+
 // C extern method types
 type turso_status_code_t = int32
 
 // then, define C extern methods
 var (
+	c_turso_version                          func() uintptr
 	c_turso_setup                            func(config *turso_config_t, error_opt_out **byte) turso_status_code_t
 	c_turso_database_new                     func(config *turso_database_config_t, database **turso_database_t, error_opt_out **byte) turso_status_code_t
+	c_turso_database_set_open_flags          func(database TursoDatabase, read_only bool, create bool, no_lock bool, error_opt_out **byte) turso_status_code_t
 	c_turso_database_open                    func(database TursoDatabase, error_opt_out **byte) turso_status_code_t
 	c_turso_database_connect                 func(self TursoDatabase, connection **turso_connection_t, error_opt_out **byte) turso_status_code_t
 	c_turso_connection_get_autocommit        func(self TursoConnection) bool
 	c_turso_connection_set_busy_timeout_ms   func(self TursoConnection, timeout_ms int64)
 	c_turso_connection_last_insert_rowid     func(self TursoConnection) int64
+	c_turso_connection_changes               func(self TursoConnection) int64
+	c_turso_connection_total_changes         func(self TursoConnection) int64
+	c_turso_connection_metrics               func(self TursoConnection, statements, vm_steps, fullscan_steps, sort_operations, rows_read, rows_written *int64)
+	c_turso_connection_wal_position          func(self TursoConnection, checkpoint_seq *uint32, max_frame *uint64)
+	c_turso_connection_wal_commit_position   func(self TursoConnection, checkpoint_seq *uint32, max_frame *uint64, error_opt_out **byte) turso_status_code_t
+	c_turso_connection_interrupt             func(self TursoConnection)
+	c_turso_connection_enable_load_extension func(self TursoConnection, enabled bool, error_opt_out **byte) turso_status_code_t
+	c_turso_connection_load_extension        func(self TursoConnection, path string, error_opt_out **byte) turso_status_code_t
+	c_turso_connection_rekey                 func(self TursoConnection, hexkey string, error_opt_out **byte) turso_status_code_t
+	c_turso_connection_deserialize           func(self TursoConnection, schema string, ptr *byte, len uintptr, error_opt_out **byte) turso_status_code_t
+	c_turso_connection_serialize             func(self TursoConnection, schema string, ptr *uintptr, len *uintptr, error_opt_out **byte) turso_status_code_t
 	c_turso_connection_prepare_single        func(self TursoConnection, sql string, statement **turso_statement_t, error_opt_out **byte) turso_status_code_t
 	c_turso_connection_prepare_first         func(self TursoConnection, sql string, statement **turso_statement_t, tail_idx *uintptr, error_opt_out **byte) turso_status_code_t
 	c_turso_connection_close                 func(self TursoConnection, error_opt_out **byte) turso_status_code_t
@@ -168,9 +354,14 @@ var (
 	c_turso_statement_reset                  func(self TursoStatement, error_opt_out **byte) turso_status_code_t
 	c_turso_statement_finalize               func(self TursoStatement, error_opt_out **byte) turso_status_code_t
 	c_turso_statement_n_change               func(self TursoStatement) int64
+	c_turso_statement_inserted_rowid         func(self TursoStatement) bool
+	c_turso_statement_vm_steps               func(self TursoStatement) int64
+	c_turso_statement_readonly               func(self TursoStatement) bool
+	c_turso_statement_extended_error_code    func(self TursoStatement) int32
 	c_turso_statement_column_count           func(self TursoStatement) int64
 	c_turso_statement_column_name            func(self TursoStatement, index uintptr) uintptr
 	c_turso_statement_column_decltype        func(self TursoStatement, index uintptr) uintptr
+	c_turso_statement_column_nullable        func(self TursoStatement, index uintptr) int32
 	c_turso_statement_row_value_kind         func(self TursoStatement, index uintptr) int32
 	c_turso_statement_row_value_bytes_count  func(self TursoStatement, index uintptr) int64
 	c_turso_statement_row_value_bytes_ptr    func(self TursoStatement, index uintptr) uintptr
@@ -184,22 +375,65 @@ var (
 	c_turso_statement_bind_positional_double func(self TursoStatement, position uintptr, value float64) turso_status_code_t
 	c_turso_statement_bind_positional_blob   func(self TursoStatement, position uintptr, ptr *byte, len uintptr) turso_status_code_t
 	c_turso_statement_bind_positional_text   func(self TursoStatement, position uintptr, ptr *byte, len uintptr) turso_status_code_t
+	c_turso_connection_blob_open             func(self TursoConnection, table string, column string, rowid int64, writable bool, blob **turso_blob_t, error_opt_out **byte) turso_status_code_t
+	c_turso_blob_bytes                       func(self TursoBlob) int64
+	c_turso_blob_read                        func(self TursoBlob, offset uintptr, ptr *byte, len uintptr, error_opt_out **byte) turso_status_code_t
+	c_turso_blob_write                       func(self TursoBlob, offset uintptr, ptr *byte, len uintptr, error_opt_out **byte) turso_status_code_t
+	c_turso_blob_close                       func(self TursoBlob, error_opt_out **byte) turso_status_code_t
+	c_turso_backup_init                      func(destination TursoConnection, destination_name string, source TursoConnection, source_name string, backup **turso_backup_t, error_opt_out **byte) turso_status_code_t
+	c_turso_backup_step                      func(self TursoBackup, pages int32, remaining *int64, total *int64, error_opt_out **byte) turso_status_code_t
 	c_turso_str_deinit                       func(self uintptr)
+	c_turso_bytes_deinit                     func(ptr uintptr, len uintptr)
 	c_turso_database_deinit                  func(self TursoDatabase)
 	c_turso_connection_deinit                func(self TursoConnection)
 	c_turso_statement_deinit                 func(self TursoStatement)
+	c_turso_blob_deinit                      func(self TursoBlob)
+	c_turso_backup_deinit                    func(self TursoBackup)
+)
+
+// extern methods used by user-defined functions
+var (
+	c_turso_connection_register_scalar_function_out func(self TursoConnection, name string, argc int32, deterministic bool, context uintptr, callback uintptr, context_destructor uintptr, value_destructor uintptr, error_opt_out **byte) turso_status_code_t
+	c_turso_connection_unregister_function          func(self TursoConnection, name string, error_opt_out **byte) turso_status_code_t
+	c_turso_connection_register_collation           func(self TursoConnection, name string, context uintptr, callback uintptr, context_destructor uintptr, error_opt_out **byte) turso_status_code_t
+	c_turso_connection_unregister_collation         func(self TursoConnection, name string, error_opt_out **byte) turso_status_code_t
+	c_turso_connection_set_update_hook              func(self TursoConnection, context uintptr, callback uintptr, context_destructor uintptr, error_opt_out **byte) turso_status_code_t
+	c_turso_connection_set_commit_hook              func(self TursoConnection, context uintptr, callback uintptr, context_destructor uintptr, error_opt_out **byte) turso_status_code_t
+	c_turso_connection_set_rollback_hook            func(self TursoConnection, context uintptr, callback uintptr, context_destructor uintptr, error_opt_out **byte) turso_status_code_t
+	c_turso_connection_set_authorizer               func(self TursoConnection, context uintptr, callback uintptr, context_destructor uintptr, error_opt_out **byte) turso_status_code_t
 )
 
 // implement a function to register extern methods from loaded lib
 // DO NOT load lib - as it will be done externally
 func registerTursoDb(handle uintptr) error {
+	purego.RegisterLibFunc(&c_turso_version, handle, "turso_version")
 	purego.RegisterLibFunc(&c_turso_setup, handle, "turso_setup")
 	purego.RegisterLibFunc(&c_turso_database_new, handle, "turso_database_new")
+	purego.RegisterLibFunc(&c_turso_database_set_open_flags, handle, "turso_database_set_open_flags")
 	purego.RegisterLibFunc(&c_turso_database_open, handle, "turso_database_open")
 	purego.RegisterLibFunc(&c_turso_database_connect, handle, "turso_database_connect")
 	purego.RegisterLibFunc(&c_turso_connection_get_autocommit, handle, "turso_connection_get_autocommit")
 	purego.RegisterLibFunc(&c_turso_connection_set_busy_timeout_ms, handle, "turso_connection_set_busy_timeout_ms")
 	purego.RegisterLibFunc(&c_turso_connection_last_insert_rowid, handle, "turso_connection_last_insert_rowid")
+	purego.RegisterLibFunc(&c_turso_connection_changes, handle, "turso_connection_changes")
+	purego.RegisterLibFunc(&c_turso_connection_total_changes, handle, "turso_connection_total_changes")
+	purego.RegisterLibFunc(&c_turso_connection_metrics, handle, "turso_connection_metrics")
+	purego.RegisterLibFunc(&c_turso_connection_wal_position, handle, "turso_connection_wal_position")
+	purego.RegisterLibFunc(&c_turso_connection_wal_commit_position, handle, "turso_connection_wal_commit_position")
+	purego.RegisterLibFunc(&c_turso_connection_interrupt, handle, "turso_connection_interrupt")
+	purego.RegisterLibFunc(&c_turso_connection_enable_load_extension, handle, "turso_connection_enable_load_extension")
+	purego.RegisterLibFunc(&c_turso_connection_load_extension, handle, "turso_connection_load_extension")
+	purego.RegisterLibFunc(&c_turso_connection_rekey, handle, "turso_connection_rekey")
+	purego.RegisterLibFunc(&c_turso_connection_deserialize, handle, "turso_connection_deserialize")
+	purego.RegisterLibFunc(&c_turso_connection_serialize, handle, "turso_connection_serialize")
+	purego.RegisterLibFunc(&c_turso_connection_register_scalar_function_out, handle, "turso_connection_register_scalar_function_out")
+	purego.RegisterLibFunc(&c_turso_connection_unregister_function, handle, "turso_connection_unregister_function")
+	purego.RegisterLibFunc(&c_turso_connection_register_collation, handle, "turso_connection_register_collation")
+	purego.RegisterLibFunc(&c_turso_connection_unregister_collation, handle, "turso_connection_unregister_collation")
+	purego.RegisterLibFunc(&c_turso_connection_set_update_hook, handle, "turso_connection_set_update_hook")
+	purego.RegisterLibFunc(&c_turso_connection_set_commit_hook, handle, "turso_connection_set_commit_hook")
+	purego.RegisterLibFunc(&c_turso_connection_set_rollback_hook, handle, "turso_connection_set_rollback_hook")
+	purego.RegisterLibFunc(&c_turso_connection_set_authorizer, handle, "turso_connection_set_authorizer")
 	purego.RegisterLibFunc(&c_turso_connection_prepare_single, handle, "turso_connection_prepare_single")
 	purego.RegisterLibFunc(&c_turso_connection_prepare_first, handle, "turso_connection_prepare_first")
 	purego.RegisterLibFunc(&c_turso_connection_close, handle, "turso_connection_close")
@@ -209,9 +443,14 @@ func registerTursoDb(handle uintptr) error {
 	purego.RegisterLibFunc(&c_turso_statement_reset, handle, "turso_statement_reset")
 	purego.RegisterLibFunc(&c_turso_statement_finalize, handle, "turso_statement_finalize")
 	purego.RegisterLibFunc(&c_turso_statement_n_change, handle, "turso_statement_n_change")
+	purego.RegisterLibFunc(&c_turso_statement_inserted_rowid, handle, "turso_statement_inserted_rowid")
+	purego.RegisterLibFunc(&c_turso_statement_vm_steps, handle, "turso_statement_vm_steps")
+	purego.RegisterLibFunc(&c_turso_statement_readonly, handle, "turso_statement_readonly")
+	purego.RegisterLibFunc(&c_turso_statement_extended_error_code, handle, "turso_statement_extended_error_code")
 	purego.RegisterLibFunc(&c_turso_statement_column_count, handle, "turso_statement_column_count")
 	purego.RegisterLibFunc(&c_turso_statement_column_name, handle, "turso_statement_column_name")
 	purego.RegisterLibFunc(&c_turso_statement_column_decltype, handle, "turso_statement_column_decltype")
+	purego.RegisterLibFunc(&c_turso_statement_column_nullable, handle, "turso_statement_column_nullable")
 	purego.RegisterLibFunc(&c_turso_statement_row_value_kind, handle, "turso_statement_row_value_kind")
 	purego.RegisterLibFunc(&c_turso_statement_row_value_bytes_count, handle, "turso_statement_row_value_bytes_count")
 	purego.RegisterLibFunc(&c_turso_statement_row_value_bytes_ptr, handle, "turso_statement_row_value_bytes_ptr")
@@ -225,43 +464,71 @@ func registerTursoDb(handle uintptr) error {
 	purego.RegisterLibFunc(&c_turso_statement_bind_positional_double, handle, "turso_statement_bind_positional_double")
 	purego.RegisterLibFunc(&c_turso_statement_bind_positional_blob, handle, "turso_statement_bind_positional_blob")
 	purego.RegisterLibFunc(&c_turso_statement_bind_positional_text, handle, "turso_statement_bind_positional_text")
+	purego.RegisterLibFunc(&c_turso_connection_blob_open, handle, "turso_connection_blob_open")
+	purego.RegisterLibFunc(&c_turso_blob_bytes, handle, "turso_blob_bytes")
+	purego.RegisterLibFunc(&c_turso_blob_read, handle, "turso_blob_read")
+	purego.RegisterLibFunc(&c_turso_blob_write, handle, "turso_blob_write")
+	purego.RegisterLibFunc(&c_turso_blob_close, handle, "turso_blob_close")
+	purego.RegisterLibFunc(&c_turso_backup_init, handle, "turso_backup_init")
+	purego.RegisterLibFunc(&c_turso_backup_step, handle, "turso_backup_step")
 	purego.RegisterLibFunc(&c_turso_str_deinit, handle, "turso_str_deinit")
+	purego.RegisterLibFunc(&c_turso_bytes_deinit, handle, "turso_bytes_deinit")
 	purego.RegisterLibFunc(&c_turso_database_deinit, handle, "turso_database_deinit")
 	purego.RegisterLibFunc(&c_turso_connection_deinit, handle, "turso_connection_deinit")
 	purego.RegisterLibFunc(&c_turso_statement_deinit, handle, "turso_statement_deinit")
+	purego.RegisterLibFunc(&c_turso_blob_deinit, handle, "turso_blob_deinit")
+	purego.RegisterLibFunc(&c_turso_backup_deinit, handle, "turso_backup_deinit")
 	return nil
 }
 
-// Helper: map status code to default error kind
+// Helper: map status code to the Error with corresponding SQLite result code
 func statusToError(status TursoStatusCode, msg string) error {
-	var base error
+	return extendedStatusToError(status, 0, msg)
+}
+
+// extendedStatusToError maps status code and extended result code reported by the library (0 if unknown) to the Error
+func extendedStatusToError(status TursoStatusCode, extendedCode int, msg string) error {
+	var code int
 	switch status {
 	case TURSO_BUSY:
-		base = ErrTursoBusy
+		code = SQLITE_BUSY
+	case TURSO_BUSY_SNAPSHOT:
+		code = SQLITE_BUSY
+		if extendedCode == 0 {
+			extendedCode = SQLITE_BUSY_SNAPSHOT
+		}
 	case TURSO_INTERRUPT:
-		base = ErrTursoInterrupt
+		code = SQLITE_INTERRUPT
 	case TURSO_ERROR:
-		base = ErrTursoGeneric
+		code = SQLITE_ERROR
 	case TURSO_MISUSE:
-		base = ErrTursoMisuse
+		code = SQLITE_MISUSE
 	case TURSO_CONSTRAINT:
-		base = ErrTursoConstraint
+		code = SQLITE_CONSTRAINT
 	case TURSO_READONLY:
-		base = ErrTursoReadOnly
+		code = SQLITE_READONLY
 	case TURSO_DATABASE_FULL:
-		base = ErrTursoDatabaseFull
+		code = SQLITE_FULL
 	case TURSO_NOTADB:
-		base = ErrTursoNotADb
+		code = SQLITE_NOTADB
 	case TURSO_CORRUPT:
-		base = ErrTursoCorrupt
+		code = SQLITE_CORRUPT
+	case TURSO_IOERR:
+		code = SQLITE_IOERR
 	default:
 		// for unknown error codes, fallback to generic
-		base = ErrTursoGeneric
+		code = SQLITE_ERROR
 	}
-	if msg != "" {
-		return fmt.Errorf("%w: %s", base, msg)
+	// extended code must belong to the primary code family
+	if extendedCode&0xff != code {
+		extendedCode = code
 	}
-	return base
+	return Error{Code: code, ExtendedCode: extendedCode, Message: msg}
+}
+
+// statementStatusToError maps failed status of the statement step/execute to the Error with extended result code
+func statementStatusToError(self TursoStatement, status TursoStatusCode, msg string) error {
+	return extendedStatusToError(status, int(c_turso_statement_extended_error_code(self)), msg)
 }
 
 func decodeAndFreeCString(p *byte) string {
@@ -340,8 +607,280 @@ func init() {
 	})
 }
 
+// ------- Scalar function callback plumbing --------
+
+// TursoScalarFunction implements a user-defined scalar function.
+// Arguments are nil, int64, float64, string or []byte; the result must be one of these types too.
+type TursoScalarFunction func(args []any) (any, error)
+
+// purego can't free callbacks, so the library always calls the same trampolines
+// and the context argument selects the registered Go function
+var (
+	scalarFunctionCallback        uintptr
+	scalarFunctionDestroyCallback uintptr
+	scalarValueDestroyCallback    uintptr
+
+	scalarFunctionsMu     sync.Mutex
+	scalarFunctionsNextId uintptr
+	// scalarFunctions pins Go callbacks until the library releases their context
+	scalarFunctions = map[uintptr]TursoScalarFunction{}
+	// scalarValues keeps memory referenced by returned TEXT/BLOB/error values alive until the library copies them
+	scalarValues = map[uintptr]any{}
+
+	collationCallback        uintptr
+	collationDestroyCallback uintptr
+	// collations pins Go comparators until the library releases their context (ids are shared with scalarFunctions)
+	collations = map[uintptr]TursoCollation{}
+
+	updateHookCallback      uintptr
+	transactionHookCallback uintptr
+	hookDestroyCallback     uintptr
+	authorizerCallback      uintptr
+	// hooks pins TursoUpdateHook, TursoTransactionHook and TursoAuthorizer callbacks until the library releases their context
+	// (ids are shared with scalarFunctions)
+	hooks = map[uintptr]any{}
+)
+
+// TursoUpdateHook receives the SQLite action code (18 for INSERT, 23 for UPDATE, 9 for DELETE),
+// database and table names and rowid of the changed row.
+// It runs while the statement executes, so it must not panic or use the connection.
+type TursoUpdateHook func(op int32, database, table string, rowid int64)
+
+// TursoTransactionHook runs after a write transaction commits or rolls back.
+// It must not panic or use the connection.
+type TursoTransactionHook func()
+
+// TursoAuthorizer receives the SQLite action code, two action arguments, database and trigger names
+// (missing values are empty) and returns 0 to allow the access, 1 to deny it or 2 to ignore it.
+// It runs while the statement is prepared, so it must not panic or use the connection.
+type TursoAuthorizer func(action int32, arg1, arg2, database, trigger string) int32
+
+// TursoCollation compares two TEXT values and returns a negative number, zero or a positive number
+// when a sorts before, equal to or after b.
+// The library can't report errors from a collation, so the comparator must not panic.
+type TursoCollation func(a, b string) int
+
+func init() {
+	scalarFunctionCallback = purego.NewCallback(func(context uintptr, argc uintptr, argv uintptr, result uintptr) uintptr {
+		scalarFunctionsMu.Lock()
+		fn := scalarFunctions[context]
+		scalarFunctionsMu.Unlock()
+		out := cPointer[turso_value_t](result)
+		if fn == nil {
+			encodeTursoError(out, "turso: scalar function is not registered")
+			return 0
+		}
+		args := make([]any, int32(argc))
+		argvs := unsafe.Slice(cPointer[turso_value_t](argv), len(args))
+		for i := range args {
+			args[i] = decodeTursoValue(&argvs[i])
+		}
+		value, err := callScalarFunction(fn, args)
+		if err == nil {
+			err = encodeTursoValue(out, value)
+		}
+		if err != nil {
+			encodeTursoError(out, err.Error())
+		}
+		return 0
+	})
+	scalarFunctionDestroyCallback = purego.NewCallback(func(context uintptr) uintptr {
+		scalarFunctionsMu.Lock()
+		delete(scalarFunctions, context)
+		scalarFunctionsMu.Unlock()
+		return 0
+	})
+	collationCallback = purego.NewCallback(func(context uintptr, left uintptr, leftLen uintptr, right uintptr, rightLen uintptr) uintptr {
+		scalarFunctionsMu.Lock()
+		fn := collations[context]
+		scalarFunctionsMu.Unlock()
+		if fn == nil {
+			return 0
+		}
+		result := fn(decodeCollationText(left, leftLen), decodeCollationText(right, rightLen))
+		// the comparator result is int32 on the library side
+		switch {
+		case result < 0:
+			return uintptr(uint32(0xffffffff))
+		case result > 0:
+			return 1
+		default:
+			return 0
+		}
+	})
+	collationDestroyCallback = purego.NewCallback(func(context uintptr) uintptr {
+		scalarFunctionsMu.Lock()
+		delete(collations, context)
+		scalarFunctionsMu.Unlock()
+		return 0
+	})
+	updateHookCallback = purego.NewCallback(func(context uintptr, op uintptr, database uintptr, databaseLen uintptr, table uintptr, tableLen uintptr, rowid uintptr) uintptr {
+		scalarFunctionsMu.Lock()
+		fn, _ := hooks[context].(TursoUpdateHook)
+		scalarFunctionsMu.Unlock()
+		if fn != nil {
+			fn(int32(op), decodeCollationText(database, databaseLen), decodeCollationText(table, tableLen), int64(rowid))
+		}
+		return 0
+	})
+	transactionHookCallback = purego.NewCallback(func(context uintptr) uintptr {
+		scalarFunctionsMu.Lock()
+		fn, _ := hooks[context].(TursoTransactionHook)
+		scalarFunctionsMu.Unlock()
+		if fn != nil {
+			fn()
+		}
+		return 0
+	})
+	authorizerCallback = purego.NewCallback(func(context uintptr, action uintptr, arg1 uintptr, arg1Len uintptr, arg2 uintptr, arg2Len uintptr, database uintptr, databaseLen uintptr, trigger uintptr, triggerLen uintptr) uintptr {
+		scalarFunctionsMu.Lock()
+		fn, _ := hooks[context].(TursoAuthorizer)
+		scalarFunctionsMu.Unlock()
+		if fn == nil {
+			return 0
+		}
+		result := fn(
+			int32(action),
+			decodeCollationText(arg1, arg1Len),
+			decodeCollationText(arg2, arg2Len),
+			decodeCollationText(database, databaseLen),
+			decodeCollationText(trigger, triggerLen),
+		)
+		return uintptr(uint32(result))
+	})
+	hookDestroyCallback = purego.NewCallback(func(context uintptr) uintptr {
+		scalarFunctionsMu.Lock()
+		delete(hooks, context)
+		scalarFunctionsMu.Unlock()
+		return 0
+	})
+	scalarValueDestroyCallback = purego.NewCallback(func(p uintptr) uintptr {
+		value := cPointer[turso_value_t](p)
+		switch value.value_type {
+		case turso_extension_value_text, turso_extension_value_blob, turso_extension_value_error:
+			scalarFunctionsMu.Lock()
+			delete(scalarValues, uintptr(value.value))
+			scalarFunctionsMu.Unlock()
+		}
+		return 0
+	})
+}
+
+// callScalarFunction invokes fn and converts panics into errors as they can't unwind through the library
+func callScalarFunction(fn TursoScalarFunction, args []any) (value any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			value, err = nil, fmt.Errorf("turso: scalar function panicked: %v", r)
+		}
+	}()
+	return fn(args)
+}
+
+// decodeCollationText copies TEXT value passed to the collation into Go string
+func decodeCollationText(ptr uintptr, len uintptr) string {
+	if ptr == 0 || len == 0 {
+		return ""
+	}
+	return string(unsafe.Slice(cPointer[byte](ptr), len))
+}
+
+// cPointer converts the address of memory owned by the library, as passed to callbacks, into a pointer.
+// vet can't tell such addresses from Go pointers kept in uintptr, so callbacks convert them only here.
+func cPointer[T any](p uintptr) *T {
+	return (*T)(*(*unsafe.Pointer)(unsafe.Pointer(&p)))
+}
+
+// decodeTursoValue copies value into Go memory
+func decodeTursoValue(value *turso_value_t) any {
+	switch value.value_type {
+	case turso_extension_value_integer:
+		return int64(value.value)
+	case turso_extension_value_float:
+		return math.Float64frombits(value.value)
+	case turso_extension_value_text:
+		if value.value == 0 {
+			return nil
+		}
+		text := cPointer[turso_extension_text_t](uintptr(value.value))
+		if text.len == 0 {
+			return ""
+		}
+		return string(unsafe.Slice(cPointer[byte](text.text), text.len))
+	case turso_extension_value_blob:
+		if value.value == 0 {
+			return nil
+		}
+		blob := cPointer[turso_extension_blob_t](uintptr(value.value))
+		data := make([]byte, blob.size)
+		if blob.size > 0 {
+			copy(data, unsafe.Slice(cPointer[byte](blob.data), blob.size))
+		}
+		return data
+	default:
+		return nil
+	}
+}
+
+// encodeTursoValue fills out with v; memory referenced by out stays in scalarValues until scalarValueDestroyCallback
+func encodeTursoValue(out *turso_value_t, v any) error {
+	switch x := v.(type) {
+	case nil:
+		out.value_type = turso_extension_value_null
+	case int64:
+		out.value_type = turso_extension_value_integer
+		out.value = uint64(x)
+	case float64:
+		out.value_type = turso_extension_value_float
+		out.value = math.Float64bits(x)
+	case string:
+		if uint64(len(x)) > math.MaxUint32 {
+			return fmt.Errorf("turso: scalar function result text is too large")
+		}
+		text, data := newTursoText(x)
+		out.value_type = turso_extension_value_text
+		out.value = uint64(pinScalarValue(unsafe.Pointer(text), data))
+	case []byte:
+		// the library rejects null data pointer, so always allocate at least one byte
+		data := make([]byte, len(x)+1)
+		copy(data, x)
+		blob := &turso_extension_blob_t{data: uintptr(unsafe.Pointer(&data[0])), size: uint64(len(x))}
+		out.value_type = turso_extension_value_blob
+		out.value = uint64(pinScalarValue(unsafe.Pointer(blob), data))
+	default:
+		return fmt.Errorf("turso: unsupported scalar function result type %T", v)
+	}
+	return nil
+}
+
+// encodeTursoError fills out with an error value carrying msg
+func encodeTursoError(out *turso_value_t, msg string) {
+	text, data := newTursoText(msg)
+	e := &turso_extension_error_t{code: turso_extension_result_error, message: uintptr(unsafe.Pointer(text))}
+	out.value_type = turso_extension_value_error
+	out.value = uint64(pinScalarValue(unsafe.Pointer(e), []any{text, data}))
+}
+
+func newTursoText(s string) (*turso_extension_text_t, []byte) {
+	data := make([]byte, len(s)+1)
+	copy(data, s)
+	return &turso_extension_text_t{text: uintptr(unsafe.Pointer(&data[0])), len: uint32(len(s))}, data
+}
+
+// pinScalarValue keeps p and everything it references reachable until the library releases the value
+func pinScalarValue(p unsafe.Pointer, refs any) uintptr {
+	scalarFunctionsMu.Lock()
+	defer scalarFunctionsMu.Unlock()
+	scalarValues[uintptr(p)] = []any{p, refs}
+	return uintptr(p)
+}
+
 // Go wrappers over imported C bindings
 
+// turso_version returns the version of the loaded library; the string is static and must not be freed.
+func turso_version() string {
+	return decodeCStringNoFree(c_turso_version())
+}
+
 // turso_setup sets up global database info.
 func turso_setup(config TursoConfig) error {
 	var cconf turso_config_t
@@ -405,6 +944,17 @@ func turso_database_new(config TursoDatabaseConfig) (TursoDatabase, error) {
 	return nil, statusToError(TursoStatusCode(status), msg)
 }
 
+// turso_database_set_open_flags sets flags used by turso_database_open to open the database file.
+func turso_database_set_open_flags(database TursoDatabase, readOnly, create, noLock bool) error {
+	var errPtr *byte
+	status := c_turso_database_set_open_flags(database, readOnly, create, noLock, &errPtr)
+	if status == int32(TURSO_OK) {
+		return nil
+	}
+	msg := decodeAndFreeCString(errPtr)
+	return statusToError(TursoStatusCode(status), msg)
+}
+
 // turso_database_open opens the database.
 func turso_database_open(database TursoDatabase) error {
 	var errPtr *byte
@@ -443,6 +993,256 @@ func turso_connection_last_insert_rowid(self TursoConnection) int64 {
 	return c_turso_connection_last_insert_rowid(self)
 }
 
+// turso_connection_changes returns number of rows changed by the most recently completed statement of the connection.
+func turso_connection_changes(self TursoConnection) int64 {
+	return c_turso_connection_changes(self)
+}
+
+// turso_connection_total_changes returns number of rows changed by the connection since it was opened.
+func turso_connection_total_changes(self TursoConnection) int64 {
+	return c_turso_connection_total_changes(self)
+}
+
+// tursoConnectionMetrics holds cumulative metrics of the statements completed by a connection.
+type tursoConnectionMetrics struct {
+	statements     int64
+	vmSteps        int64
+	fullscanSteps  int64
+	sortOperations int64
+	rowsRead       int64
+	rowsWritten    int64
+}
+
+// turso_connection_metrics returns cumulative metrics of the statements completed by the connection since it was opened.
+func turso_connection_metrics(self TursoConnection) tursoConnectionMetrics {
+	var m tursoConnectionMetrics
+	c_turso_connection_metrics(self, &m.statements, &m.vmSteps, &m.fullscanSteps, &m.sortOperations, &m.rowsRead, &m.rowsWritten)
+	return m
+}
+
+// turso_connection_wal_position returns WAL position (checkpoint_seq, max_frame) of the connection:
+// the read mark of its last read transaction or the position after its last commit.
+func turso_connection_wal_position(self TursoConnection) (uint32, uint64) {
+	var checkpointSeq uint32
+	var maxFrame uint64
+	c_turso_connection_wal_position(self, &checkpointSeq, &maxFrame)
+	return checkpointSeq, maxFrame
+}
+
+// turso_connection_wal_commit_position returns WAL position (checkpoint_seq, max_frame) of the latest transaction
+// committed to the main database by any connection; it must not be called inside a transaction.
+func turso_connection_wal_commit_position(self TursoConnection) (uint32, uint64, error) {
+	var checkpointSeq uint32
+	var maxFrame uint64
+	var errPtr *byte
+	status := c_turso_connection_wal_commit_position(self, &checkpointSeq, &maxFrame, &errPtr)
+	if status == int32(TURSO_OK) {
+		return checkpointSeq, maxFrame, nil
+	}
+	msg := decodeAndFreeCString(errPtr)
+	return 0, 0, statusToError(TursoStatusCode(status), msg)
+}
+
+// turso_connection_interrupt interrupts the statement currently running on the connection.
+// Unlike other connection methods, it is safe to call concurrently from another goroutine.
+func turso_connection_interrupt(self TursoConnection) {
+	c_turso_connection_interrupt(self)
+}
+
+// turso_connection_enable_load_extension enables or disables SQL load_extension() function for the connection.
+func turso_connection_enable_load_extension(self TursoConnection, enabled bool) error {
+	var errPtr *byte
+	status := c_turso_connection_enable_load_extension(self, enabled, &errPtr)
+	if status == int32(TURSO_OK) {
+		return nil
+	}
+	msg := decodeAndFreeCString(errPtr)
+	return statusToError(TursoStatusCode(status), msg)
+}
+
+// turso_connection_load_extension loads an extension library on the connection using the native extension loader.
+func turso_connection_load_extension(self TursoConnection, path string) error {
+	var errPtr *byte
+	status := c_turso_connection_load_extension(self, path, &errPtr)
+	if status == int32(TURSO_OK) {
+		return nil
+	}
+	msg := decodeAndFreeCString(errPtr)
+	return statusToError(TursoStatusCode(status), msg)
+}
+
+// turso_connection_rekey re-encrypts the main database of the connection with the hex-encoded key in place.
+func turso_connection_rekey(self TursoConnection, hexkey string) error {
+	var errPtr *byte
+	status := c_turso_connection_rekey(self, hexkey, &errPtr)
+	if status == int32(TURSO_OK) {
+		return nil
+	}
+	msg := decodeAndFreeCString(errPtr)
+	return statusToError(TursoStatusCode(status), msg)
+}
+
+// turso_connection_deserialize replaces the content of the schema database of the connection with the database file image.
+func turso_connection_deserialize(self TursoConnection, schema string, image []byte) error {
+	var ptr *byte
+	if len(image) > 0 {
+		ptr = &image[0]
+	}
+	var errPtr *byte
+	status := c_turso_connection_deserialize(self, schema, ptr, uintptr(len(image)), &errPtr)
+	runtime.KeepAlive(image)
+	if status == int32(TURSO_OK) {
+		return nil
+	}
+	msg := decodeAndFreeCString(errPtr)
+	return statusToError(TursoStatusCode(status), msg)
+}
+
+// turso_connection_serialize returns the content of the schema database of the connection as a database file image
+// copied into Go memory.
+func turso_connection_serialize(self TursoConnection, schema string) ([]byte, error) {
+	var ptr, n uintptr
+	var errPtr *byte
+	status := c_turso_connection_serialize(self, schema, &ptr, &n, &errPtr)
+	if status != int32(TURSO_OK) {
+		msg := decodeAndFreeCString(errPtr)
+		return nil, statusToError(TursoStatusCode(status), msg)
+	}
+	if ptr == 0 {
+		return []byte{}, nil
+	}
+	image := make([]byte, n)
+	copy(image, unsafe.Slice(cPointer[byte](ptr), n))
+	// free Turso-allocated image
+	c_turso_bytes_deinit(ptr, n)
+	return image, nil
+}
+
+// turso_connection_register_scalar_function_out registers or replaces a scalar function on the connection.
+// fn stays referenced until the library replaces or unregisters the function or closes the connection.
+func turso_connection_register_scalar_function_out(self TursoConnection, name string, argc int32, deterministic bool, fn TursoScalarFunction) error {
+	scalarFunctionsMu.Lock()
+	scalarFunctionsNextId++
+	context := scalarFunctionsNextId
+	scalarFunctions[context] = fn
+	scalarFunctionsMu.Unlock()
+	var errPtr *byte
+	status := c_turso_connection_register_scalar_function_out(self, name, argc, deterministic, context, scalarFunctionCallback, scalarFunctionDestroyCallback, scalarValueDestroyCallback, &errPtr)
+	if status == int32(TURSO_OK) {
+		return nil
+	}
+	// the library doesn't take ownership of context on failure
+	scalarFunctionsMu.Lock()
+	delete(scalarFunctions, context)
+	scalarFunctionsMu.Unlock()
+	msg := decodeAndFreeCString(errPtr)
+	return statusToError(TursoStatusCode(status), msg)
+}
+
+// turso_connection_unregister_function unregisters a scalar or aggregate function from the connection.
+func turso_connection_unregister_function(self TursoConnection, name string) error {
+	var errPtr *byte
+	status := c_turso_connection_unregister_function(self, name, &errPtr)
+	if status == int32(TURSO_OK) {
+		return nil
+	}
+	msg := decodeAndFreeCString(errPtr)
+	return statusToError(TursoStatusCode(status), msg)
+}
+
+// turso_connection_register_collation registers or replaces a collation on the connection.
+// fn stays referenced until the library replaces or unregisters the collation or closes the connection.
+func turso_connection_register_collation(self TursoConnection, name string, fn TursoCollation) error {
+	scalarFunctionsMu.Lock()
+	scalarFunctionsNextId++
+	context := scalarFunctionsNextId
+	collations[context] = fn
+	scalarFunctionsMu.Unlock()
+	var errPtr *byte
+	status := c_turso_connection_register_collation(self, name, context, collationCallback, collationDestroyCallback, &errPtr)
+	if status == int32(TURSO_OK) {
+		return nil
+	}
+	// the library doesn't take ownership of context on failure
+	scalarFunctionsMu.Lock()
+	delete(collations, context)
+	scalarFunctionsMu.Unlock()
+	msg := decodeAndFreeCString(errPtr)
+	return statusToError(TursoStatusCode(status), msg)
+}
+
+// turso_connection_unregister_collation unregisters a collation from the connection.
+func turso_connection_unregister_collation(self TursoConnection, name string) error {
+	var errPtr *byte
+	status := c_turso_connection_unregister_collation(self, name, &errPtr)
+	if status == int32(TURSO_OK) {
+		return nil
+	}
+	msg := decodeAndFreeCString(errPtr)
+	return statusToError(TursoStatusCode(status), msg)
+}
+
+// turso_connection_set_update_hook sets or clears (with nil fn) the update hook of the connection.
+func turso_connection_set_update_hook(self TursoConnection, fn TursoUpdateHook) error {
+	if fn == nil {
+		return setConnectionHook(c_turso_connection_set_update_hook, self, nil, 0)
+	}
+	return setConnectionHook(c_turso_connection_set_update_hook, self, fn, updateHookCallback)
+}
+
+// turso_connection_set_commit_hook sets or clears (with nil fn) the commit hook of the connection.
+func turso_connection_set_commit_hook(self TursoConnection, fn TursoTransactionHook) error {
+	if fn == nil {
+		return setConnectionHook(c_turso_connection_set_commit_hook, self, nil, 0)
+	}
+	return setConnectionHook(c_turso_connection_set_commit_hook, self, fn, transactionHookCallback)
+}
+
+// turso_connection_set_rollback_hook sets or clears (with nil fn) the rollback hook of the connection.
+func turso_connection_set_rollback_hook(self TursoConnection, fn TursoTransactionHook) error {
+	if fn == nil {
+		return setConnectionHook(c_turso_connection_set_rollback_hook, self, nil, 0)
+	}
+	return setConnectionHook(c_turso_connection_set_rollback_hook, self, fn, transactionHookCallback)
+}
+
+// turso_connection_set_authorizer sets or clears (with nil fn) the authorizer of the connection.
+func turso_connection_set_authorizer(self TursoConnection, fn TursoAuthorizer) error {
+	if fn == nil {
+		return setConnectionHook(c_turso_connection_set_authorizer, self, nil, 0)
+	}
+	return setConnectionHook(c_turso_connection_set_authorizer, self, fn, authorizerCallback)
+}
+
+// setConnectionHook pins fn (unless it is nil) and passes it to the setter as the context of callback.
+// fn stays referenced until the library replaces or clears the hook or closes the connection.
+func setConnectionHook(
+	setter func(self TursoConnection, context uintptr, callback uintptr, context_destructor uintptr, error_opt_out **byte) turso_status_code_t,
+	self TursoConnection,
+	fn any,
+	callback uintptr,
+) error {
+	var context uintptr
+	if fn != nil {
+		scalarFunctionsMu.Lock()
+		scalarFunctionsNextId++
+		context = scalarFunctionsNextId
+		hooks[context] = fn
+		scalarFunctionsMu.Unlock()
+	}
+	var errPtr *byte
+	status := setter(self, context, callback, hookDestroyCallback, &errPtr)
+	if status == int32(TURSO_OK) {
+		return nil
+	}
+	// the library doesn't take ownership of context on failure
+	scalarFunctionsMu.Lock()
+	delete(hooks, context)
+	scalarFunctionsMu.Unlock()
+	msg := decodeAndFreeCString(errPtr)
+	return statusToError(TursoStatusCode(status), msg)
+}
+
 // turso_connection_prepare_single prepares a single statement in a connection.
 func turso_connection_prepare_single(self TursoConnection, sql string) (TursoStatement, error) {
 	var stmt *turso_statement_t
@@ -491,7 +1291,7 @@ func turso_statement_execute(self TursoStatement) (TursoStatusCode, uint64, erro
 		return TursoStatusCode(status), changes, nil
 	default:
 		msg := decodeAndFreeCString(errPtr)
-		return TursoStatusCode(status), 0, statusToError(TursoStatusCode(status), msg)
+		return TursoStatusCode(status), 0, statementStatusToError(self, TursoStatusCode(status), msg)
 	}
 }
 
@@ -504,7 +1304,7 @@ func turso_statement_step(self TursoStatement) (TursoStatusCode, error) {
 		return TursoStatusCode(status), nil
 	default:
 		msg := decodeAndFreeCString(errPtr)
-		return TursoStatusCode(status), statusToError(TursoStatusCode(status), msg)
+		return TursoStatusCode(status), statementStatusToError(self, TursoStatusCode(status), msg)
 	}
 }
 
@@ -534,13 +1334,23 @@ func turso_statement_reset(self TursoStatement) error {
 
 // turso_statement_finalize finalizes a statement.
 func turso_statement_finalize(self TursoStatement) error {
+	_, err := turso_statement_finalize_status(self)
+	return err
+}
+
+// turso_statement_finalize_status finalizes a statement and returns the finalization status.
+// * finalize runs pending execution to completion and returns TURSO_DONE if finalization completed
+// * finalize returns TURSO_IO if async_io was set and execution needs IO in order to make progress
+func turso_statement_finalize_status(self TursoStatement) (TursoStatusCode, error) {
 	var errPtr *byte
 	status := c_turso_statement_finalize(self, &errPtr)
-	if status == int32(TURSO_OK) {
-		return nil
+	switch TursoStatusCode(status) {
+	case TURSO_OK, TURSO_DONE, TURSO_IO:
+		return TursoStatusCode(status), nil
+	default:
+		msg := decodeAndFreeCString(errPtr)
+		return TursoStatusCode(status), statementStatusToError(self, TursoStatusCode(status), msg)
 	}
-	msg := decodeAndFreeCString(errPtr)
-	return statusToError(TursoStatusCode(status), msg)
 }
 
 // turso_statement_n_change returns amount of row modifications (insert/delete operations) made by the most recent executed statement.
@@ -548,6 +1358,23 @@ func turso_statement_n_change(self TursoStatement) int64 {
 	return c_turso_statement_n_change(self)
 }
 
+// turso_statement_inserted_rowid reports whether the most recent execution of the statement inserted a row
+// and set the last insert rowid of the connection (rows inserted by triggers are not counted).
+func turso_statement_inserted_rowid(self TursoStatement) bool {
+	return c_turso_statement_inserted_rowid(self)
+}
+
+// turso_statement_vm_steps returns number of VM instructions executed by the statement;
+// the counter accumulates over executions and is not cleared by reset.
+func turso_statement_vm_steps(self TursoStatement) int64 {
+	return c_turso_statement_vm_steps(self)
+}
+
+// turso_statement_readonly reports whether the statement makes no direct changes to the database.
+func turso_statement_readonly(self TursoStatement) bool {
+	return c_turso_statement_readonly(self)
+}
+
 // turso_statement_column_count returns the number of columns.
 func turso_statement_column_count(self TursoStatement) int64 {
 	return c_turso_statement_column_count(self)
@@ -571,6 +1398,19 @@ func turso_statement_column_decltype(self TursoStatement, index int) string {
 	return decodeAndFreeCStringRaw(ptr)
 }
 
+// turso_statement_column_nullable reports whether the column at the index can hold NULL;
+// ok is false if nullability is unknown (e.g. the column is an expression).
+func turso_statement_column_nullable(self TursoStatement, index int) (nullable, ok bool) {
+	switch c_turso_statement_column_nullable(self, uintptr(index)) {
+	case 1:
+		return true, true
+	case 0:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
 // turso_statement_row_value_kind returns the row value kind at index.
 func turso_statement_row_value_kind(self TursoStatement, index int) TursoType {
 	return TursoType(c_turso_statement_row_value_kind(self, uintptr(index)))
@@ -676,6 +1516,93 @@ func turso_statement_bind_positional_text(self TursoStatement, position int, val
 	return statusToError(TursoStatusCode(status), "")
 }
 
+// turso_connection_blob_open opens incremental I/O handle to the BLOB in the column of the row with given rowid.
+func turso_connection_blob_open(self TursoConnection, table string, column string, rowid int64, writable bool) (TursoBlob, error) {
+	var blob *turso_blob_t
+	var errPtr *byte
+	status := c_turso_connection_blob_open(self, table, column, rowid, writable, &blob, &errPtr)
+	if status == int32(TURSO_OK) {
+		return TursoBlob(blob), nil
+	}
+	msg := decodeAndFreeCString(errPtr)
+	return nil, statusToError(TursoStatusCode(status), msg)
+}
+
+// turso_blob_bytes returns size of the BLOB in bytes.
+func turso_blob_bytes(self TursoBlob) int64 {
+	return c_turso_blob_bytes(self)
+}
+
+// turso_blob_read fills buf with bytes of the BLOB starting at offset.
+func turso_blob_read(self TursoBlob, offset int64, buf []byte) error {
+	var ptr *byte
+	if len(buf) > 0 {
+		ptr = &buf[0]
+	}
+	var errPtr *byte
+	status := c_turso_blob_read(self, uintptr(offset), ptr, uintptr(len(buf)), &errPtr)
+	runtime.KeepAlive(buf)
+	if status == int32(TURSO_OK) {
+		return nil
+	}
+	msg := decodeAndFreeCString(errPtr)
+	return statusToError(TursoStatusCode(status), msg)
+}
+
+// turso_blob_write overwrites bytes of the BLOB starting at offset with data.
+func turso_blob_write(self TursoBlob, offset int64, data []byte) error {
+	var ptr *byte
+	if len(data) > 0 {
+		ptr = &data[0]
+	}
+	var errPtr *byte
+	status := c_turso_blob_write(self, uintptr(offset), ptr, uintptr(len(data)), &errPtr)
+	runtime.KeepAlive(data)
+	if status == int32(TURSO_OK) {
+		return nil
+	}
+	msg := decodeAndFreeCString(errPtr)
+	return statusToError(TursoStatusCode(status), msg)
+}
+
+// turso_blob_close closes the blob handle and commits its writes.
+func turso_blob_close(self TursoBlob) error {
+	var errPtr *byte
+	status := c_turso_blob_close(self, &errPtr)
+	if status == int32(TURSO_OK) {
+		return nil
+	}
+	msg := decodeAndFreeCString(errPtr)
+	return statusToError(TursoStatusCode(status), msg)
+}
+
+// turso_backup_init starts backup of the sourceName database of source into the destinationName database of destination.
+func turso_backup_init(destination TursoConnection, destinationName string, source TursoConnection, sourceName string) (TursoBackup, error) {
+	var backup *turso_backup_t
+	var errPtr *byte
+	status := c_turso_backup_init(destination, destinationName, source, sourceName, &backup, &errPtr)
+	if status == int32(TURSO_OK) {
+		return TursoBackup(backup), nil
+	}
+	msg := decodeAndFreeCString(errPtr)
+	return nil, statusToError(TursoStatusCode(status), msg)
+}
+
+// turso_backup_step copies up to pages pages (all pages if pages is negative) and reports
+// the number of pages left to copy and the number of pages in the source.
+// It returns TURSO_DONE once the copy is complete and TURSO_OK otherwise.
+func turso_backup_step(self TursoBackup, pages int32) (TursoStatusCode, int64, int64, error) {
+	var remaining, total int64
+	var errPtr *byte
+	status := c_turso_backup_step(self, pages, &remaining, &total, &errPtr)
+	switch status {
+	case int32(TURSO_OK), int32(TURSO_DONE):
+		return TursoStatusCode(status), remaining, total, nil
+	}
+	msg := decodeAndFreeCString(errPtr)
+	return TursoStatusCode(status), 0, 0, statusToError(TursoStatusCode(status), msg)
+}
+
 // turso_database_deinit deallocates and closes a database.
 // SAFETY: caller must ensure that no other code can concurrently or later call methods over deinited database.
 func turso_database_deinit(self TursoDatabase) {
@@ -694,6 +1621,18 @@ func turso_statement_deinit(self TursoStatement) {
 	c_turso_statement_deinit(self)
 }
 
+// turso_blob_deinit deallocates and closes a blob handle.
+// SAFETY: caller must ensure that no other code can concurrently or later call methods over deinited blob.
+func turso_blob_deinit(self TursoBlob) {
+	c_turso_blob_deinit(self)
+}
+
+// turso_backup_deinit deallocates a backup handle, rolling back the destination if the backup isn't complete.
+// SAFETY: caller must ensure that no other code can concurrently or later call methods over deinited backup.
+func turso_backup_deinit(self TursoBackup) {
+	c_turso_backup_deinit(self)
+}
+
 // Additional ergonomic helpers (the only non-direct translations):
 // turso_statement_row_value_bytes returns a copy of bytes for BLOB or TEXT values, nil otherwise.
 func turso_statement_row_value_bytes(self TursoStatement, index int) []byte {
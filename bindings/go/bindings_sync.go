@@ -28,6 +28,7 @@ const (
 	TURSO_SYNC_IO_HTTP       TursoSyncIoRequestType = 1
 	TURSO_SYNC_IO_FULL_READ  TursoSyncIoRequestType = 2
 	TURSO_SYNC_IO_FULL_WRITE TursoSyncIoRequestType = 3
+	TURSO_SYNC_IO_TRANSFORM  TursoSyncIoRequestType = 4
 )
 
 type TursoSyncOperationResultType int32
@@ -39,6 +40,14 @@ const (
 	TURSO_ASYNC_RESULT_STATS      TursoSyncOperationResultType = 3
 )
 
+type TursoSyncRevisionType int32
+
+const (
+	TURSO_SYNC_REVISION_NONE   TursoSyncRevisionType = 0
+	TURSO_SYNC_REVISION_LEGACY TursoSyncRevisionType = 1
+	TURSO_SYNC_REVISION_V1     TursoSyncRevisionType = 2
+)
+
 // ------------- Public binding types -------------
 
 // TursoSyncDatabaseConfig describes database sync configuration.
@@ -82,6 +91,8 @@ type TursoSyncDatabaseConfig struct {
 	// in chunks. 0 (default) bootstraps in a single round-trip. no-op when partial-sync uses the
 	// query bootstrap strategy.
 	PullBytesThreshold int
+	// pass local row changes replayed on top of pulled remote changes and sent by push to TURSO_SYNC_IO_TRANSFORM requests
+	UseTransform bool
 }
 
 // TursoSyncStats holds sync engine stats.
@@ -94,6 +105,17 @@ type TursoSyncStats struct {
 	NetworkSentBytes     int64
 	NetworkReceivedBytes int64
 	Revision             string
+	// kind of the revision pulled last; SyncedGeneration and SyncedFrameNo are set (-1 if unknown) for TURSO_SYNC_REVISION_LEGACY only
+	SyncedRevisionType TursoSyncRevisionType
+	SyncedGeneration   int64
+	SyncedFrameNo      int64
+}
+
+// TursoSyncProgress holds progress counters of sync operations, cumulative over the lifetime of the synced database.
+type TursoSyncProgress struct {
+	PulledPages   int64
+	RemoteDbPages int64
+	PushedChanges int64
 }
 
 // HTTP request description used by IO layer.
@@ -122,6 +144,12 @@ type TursoSyncIoFullWriteRequest struct {
 	Content []byte
 }
 
+// Transform request description.
+type TursoSyncIoTransformRequest struct {
+	// JSON array of row mutations (see turso_sync_io_transform_request_t)
+	Mutations []byte
+}
+
 // ------------- Private C-compatible structs -------------
 
 type turso_sync_database_config_t struct {
@@ -139,6 +167,8 @@ type turso_sync_database_config_t struct {
 	remote_encryption_cipher          uintptr // const char*
 	push_operations_threshold         uintptr // size_t
 	pull_bytes_threshold              uintptr // size_t
+	logical_mvcc_pull                 bool
+	use_transform                     bool
 }
 
 type turso_sync_io_http_request_t struct {
@@ -163,6 +193,10 @@ type turso_sync_io_full_write_request_t struct {
 	content turso_slice_ref_t
 }
 
+type turso_sync_io_transform_request_t struct {
+	mutations turso_slice_ref_t
+}
+
 type turso_sync_stats_t struct {
 	cdc_operations         int64
 	main_wal_size          int64
@@ -172,6 +206,15 @@ type turso_sync_stats_t struct {
 	network_sent_bytes     int64
 	network_received_bytes int64
 	revision               turso_slice_ref_t
+	synced_revision_type   int32
+	synced_generation      int64
+	synced_frame_no        int64
+}
+
+type turso_sync_progress_t struct {
+	pulled_pages    int64
+	remote_db_pages int64
+	pushed_changes  int64
 }
 
 // ------------- C extern function vars -------------
@@ -268,6 +311,12 @@ var (
 		errorOptOut **byte,
 	) int32
 
+	c_turso_sync_database_progress func(
+		self TursoSyncDatabase,
+		progress *turso_sync_progress_t,
+		errorOptOut **byte,
+	) int32
+
 	c_turso_sync_database_io_request_kind func(
 		self TursoSyncIoItem,
 	) int32
@@ -293,6 +342,11 @@ var (
 		request *turso_sync_io_full_write_request_t,
 	) int32
 
+	c_turso_sync_database_io_request_transform func(
+		self TursoSyncIoItem,
+		request *turso_sync_io_transform_request_t,
+	) int32
+
 	c_turso_sync_database_io_poison func(
 		self TursoSyncIoItem,
 		err *turso_slice_ref_t,
@@ -350,11 +404,13 @@ func registerTursoSync(handle uintptr) error {
 	purego.RegisterLibFunc(&c_turso_sync_operation_result_extract_stats, handle, "turso_sync_operation_result_extract_stats")
 	purego.RegisterLibFunc(&c_turso_sync_database_io_take_item, handle, "turso_sync_database_io_take_item")
 	purego.RegisterLibFunc(&c_turso_sync_database_io_step_callbacks, handle, "turso_sync_database_io_step_callbacks")
+	purego.RegisterLibFunc(&c_turso_sync_database_progress, handle, "turso_sync_database_progress")
 	purego.RegisterLibFunc(&c_turso_sync_database_io_request_kind, handle, "turso_sync_database_io_request_kind")
 	purego.RegisterLibFunc(&c_turso_sync_database_io_request_http, handle, "turso_sync_database_io_request_http")
 	purego.RegisterLibFunc(&c_turso_sync_database_io_request_http_header, handle, "turso_sync_database_io_request_http_header")
 	purego.RegisterLibFunc(&c_turso_sync_database_io_request_full_read, handle, "turso_sync_database_io_request_full_read")
 	purego.RegisterLibFunc(&c_turso_sync_database_io_request_full_write, handle, "turso_sync_database_io_request_full_write")
+	purego.RegisterLibFunc(&c_turso_sync_database_io_request_transform, handle, "turso_sync_database_io_request_transform")
 	purego.RegisterLibFunc(&c_turso_sync_database_io_poison, handle, "turso_sync_database_io_poison")
 	purego.RegisterLibFunc(&c_turso_sync_database_io_status, handle, "turso_sync_database_io_status")
 	purego.RegisterLibFunc(&c_turso_sync_database_io_push_buffer, handle, "turso_sync_database_io_push_buffer")
@@ -428,6 +484,7 @@ func turso_sync_database_new(dbConfig TursoDatabaseConfig, syncConfig TursoSyncD
 	}
 	csync.push_operations_threshold = uintptr(syncConfig.PushOperationsThreshold)
 	csync.pull_bytes_threshold = uintptr(syncConfig.PullBytesThreshold)
+	csync.use_transform = syncConfig.UseTransform
 
 	var db *turso_sync_database_t
 	var errPtr *byte
@@ -616,6 +673,9 @@ func turso_sync_operation_result_extract_stats(self TursoSyncOperation) (TursoSy
 		NetworkSentBytes:     cstats.network_sent_bytes,
 		NetworkReceivedBytes: cstats.network_received_bytes,
 		Revision:             sliceRefToStringCopy(cstats.revision),
+		SyncedRevisionType:   TursoSyncRevisionType(cstats.synced_revision_type),
+		SyncedGeneration:     cstats.synced_generation,
+		SyncedFrameNo:        cstats.synced_frame_no,
 	}, nil
 }
 
@@ -643,6 +703,23 @@ func turso_sync_database_io_step_callbacks(self TursoSyncDatabase) error {
 	return statusToError(TursoStatusCode(status), msg)
 }
 
+// turso_sync_database_progress reads progress counters of the synced database.
+// counters are updated while operations run, so they can be polled between IO items
+func turso_sync_database_progress(self TursoSyncDatabase) (TursoSyncProgress, error) {
+	var cprogress turso_sync_progress_t
+	var errPtr *byte
+	status := c_turso_sync_database_progress(self, &cprogress, &errPtr)
+	if status != int32(TURSO_OK) {
+		msg := decodeAndFreeCString(errPtr)
+		return TursoSyncProgress{}, statusToError(TursoStatusCode(status), msg)
+	}
+	return TursoSyncProgress{
+		PulledPages:   cprogress.pulled_pages,
+		RemoteDbPages: cprogress.remote_db_pages,
+		PushedChanges: cprogress.pushed_changes,
+	}, nil
+}
+
 // turso_sync_database_io_request_kind returns the IO request kind.
 func turso_sync_database_io_request_kind(self TursoSyncIoItem) TursoSyncIoRequestType {
 	return TursoSyncIoRequestType(c_turso_sync_database_io_request_kind(self))
@@ -700,6 +777,18 @@ func turso_sync_database_io_request_full_write(self TursoSyncIoItem) (TursoSyncI
 	}, nil
 }
 
+// turso_sync_database_io_request_transform returns transform request fields.
+func turso_sync_database_io_request_transform(self TursoSyncIoItem) (TursoSyncIoTransformRequest, error) {
+	var r turso_sync_io_transform_request_t
+	status := c_turso_sync_database_io_request_transform(self, &r)
+	if status != int32(TURSO_OK) {
+		return TursoSyncIoTransformRequest{}, statusToError(TursoStatusCode(status), "")
+	}
+	return TursoSyncIoTransformRequest{
+		Mutations: sliceRefToBytesCopy(r.mutations),
+	}, nil
+}
+
 // turso_sync_database_io_poison marks IO request completion with error.
 func turso_sync_database_io_poison(self TursoSyncIoItem, errMsg string) error {
 	var ref turso_slice_ref_t
@@ -1,16 +1,25 @@
 package turso
 
 import (
+	"container/list"
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"math"
+	"math/big"
 	"net/url"
+	"os"
+	"reflect"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	turso_libs "github.com/tursodatabase/turso-go-platform-libs"
@@ -22,8 +31,215 @@ var (
 	ErrTursoConnClosed = errors.New("turso: connection closed")
 	ErrTursoRowsClosed = errors.New("turso: rows closed")
 	ErrTursoTxDone     = errors.New("turso: transaction done")
+	ErrTursoBlobClosed = errors.New("turso: blob closed")
+	// ErrTursoBlobOutOfRange is returned when write would extend the BLOB beyond its size
+	ErrTursoBlobOutOfRange = errors.New("turso: blob access out of range")
+	// ErrTursoStaleRead is returned when read can't observe a write committed through the pool with _read_your_writes enabled
+	ErrTursoStaleRead = errors.New("turso: read snapshot is behind the last committed write")
+	// ErrTursoNoSavepoint is returned when savepoint is released or rolled back while no transaction is active
+	ErrTursoNoSavepoint = errors.New("turso: no such savepoint")
+	// ErrConcurrentConflict is returned when BEGIN CONCURRENT transaction conflicts with another concurrent writer;
+	// the transaction must be rolled back and retried
+	ErrConcurrentConflict = errors.New("turso: concurrent transaction conflict")
+	// ErrTursoLoadExtensionDisabled is returned by LoadExtension unless the database is opened with _allow_load_extension=true
+	ErrTursoLoadExtensionDisabled = errors.New("turso: loading extensions is not allowed")
+	// ErrVectorDimension is matched by errors of writes and KNN queries of a vector which doesn't have the number
+	// of dimensions declared by F32_BLOB(N) column
+	ErrVectorDimension = errors.New("turso: vector dimension mismatch")
+	// ErrTursoAlreadyAttached is returned when Attach uses an alias which already names a database of the connection
+	ErrTursoAlreadyAttached = errors.New("turso: database alias already in use")
+	// ErrTursoNotAttached is returned when Detach uses an alias which doesn't name an attached database
+	ErrTursoNotAttached = errors.New("turso: no such attached database")
+	// ErrTursoLibraryNotLoaded is returned when a database is opened after ShutdownLibrary released the library
+	ErrTursoLibraryNotLoaded = errors.New("turso: library is not loaded, call InitLibrary first")
+	// ErrTursoLibraryInUse is returned by ShutdownLibrary while databases opened through the library are still alive
+	ErrTursoLibraryInUse = errors.New("turso: library is in use")
+	// ErrTursoRemoteUnreachable is returned by Ping of an embedded replica opened with _ping_remote=true
+	// when the sync endpoint doesn't answer
+	ErrTursoRemoteUnreachable = errors.New("turso: sync endpoint is unreachable")
+	// ErrTursoMaxRows is returned by Next of rows which exceed the limit set with WithMaxRows
+	ErrTursoMaxRows = errors.New("turso: query returned more rows than allowed")
+	// ErrTursoScanTimeout is returned by Next of rows which are scanned longer than allowed by WithScanTimeout
+	ErrTursoScanTimeout = errors.New("turso: query scan timed out")
+	// ErrTursoInvalidJSON is returned when a value bound with JSON or scanned with JSONInto is not valid JSON text
+	ErrTursoInvalidJSON = errors.New("turso: value is not valid JSON")
+	// ErrTursoPrimary is returned when a statement routed to the remote primary by RouteWrites fails there
+	ErrTursoPrimary = errors.New("turso: statement failed on the remote primary")
+	// ErrTursoSyncDbClosed is returned by methods of TursoSyncDb and its connections after Close
+	ErrTursoSyncDbClosed = errors.New("turso: sync database is closed")
+	// ErrTursoNotReplica is returned by CurrentFrame on a connection which doesn't belong to an embedded replica
+	ErrTursoNotReplica = errors.New("turso: database is not an embedded replica")
 )
 
+// LevelConcurrent is a custom sql.TxOptions isolation level which starts transaction with BEGIN CONCURRENT.
+// Concurrent transactions are optimistic: multiple writers run in parallel and conflicts are detected
+// on write or commit, in which case ErrConcurrentConflict is returned and the transaction must be retried.
+// It requires the database in MVCC journal mode (PRAGMA journal_mode = 'mvcc').
+// Inside concurrent transaction use queries and DML (SELECT, INSERT, UPDATE, DELETE);
+// run schema changes, ATTACH/DETACH and PRAGMAs which change database state outside of it.
+const LevelConcurrent = sql.IsolationLevel(16)
+
+// QueryEventKind is the operation of the driver reported to the logger set with SetLogger.
+type QueryEventKind int
+
+const (
+	// statements run with Exec (including Exec of prepared statements)
+	QueryEventExec QueryEventKind = iota
+	// statements run with Query: the event is reported once the rows are closed
+	QueryEventQuery
+	// statements prepared with Prepare
+	QueryEventPrepare
+	// start of transaction
+	QueryEventBegin
+	// transaction commit
+	QueryEventCommit
+	// transaction rollback
+	QueryEventRollback
+	// warning of the driver (e.g. a DSN setting which doesn't apply to the database) described by Err
+	QueryEventWarning
+)
+
+func (k QueryEventKind) String() string {
+	switch k {
+	case QueryEventExec:
+		return "Exec"
+	case QueryEventQuery:
+		return "Query"
+	case QueryEventPrepare:
+		return "Prepare"
+	case QueryEventBegin:
+		return "Begin"
+	case QueryEventCommit:
+		return "Commit"
+	case QueryEventRollback:
+		return "Rollback"
+	case QueryEventWarning:
+		return "Warning"
+	default:
+		return fmt.Sprintf("QueryEventKind(%d)", int(k))
+	}
+}
+
+// QueryEvent describes a statement run by the driver.
+type QueryEvent struct {
+	Kind QueryEventKind
+	// SQL text of the statement
+	SQL string
+	// arguments bound to the statement (only names and ordinals are kept if the logger redacts arguments);
+	// the slice must not be retained after the logger returns
+	Args []driver.NamedValue
+	// time spent running the statement: for queries it lasts until the rows are closed
+	Duration time.Duration
+	// rows changed by Exec
+	RowsAffected int64
+	// rows returned by Query
+	Rows int64
+	// failure of the statement (nil if it succeeded)
+	Err error
+}
+
+// LoggerOption configures the logger set with SetLogger or SetContextLogger.
+type LoggerOption func(*queryLogger)
+
+// WithRedactedArgs hides values of bound arguments from the logger: QueryEvent.Args keep only names and ordinals.
+func WithRedactedArgs() LoggerOption {
+	return func(l *queryLogger) { l.redactArgs = true }
+}
+
+type queryLogger struct {
+	fn         func(ctx context.Context, event QueryEvent)
+	redactArgs bool
+}
+
+// currentLogger is the logger set with SetLogger or SetContextLogger (nil if logging is disabled)
+var currentLogger atomic.Pointer[queryLogger]
+
+// SetLogger sets the function which receives an event for every Exec, Query, Prepare, Begin, Commit and Rollback
+// run by connections of the driver; nil disables logging. The function is called synchronously from the goroutine
+// which runs the statement, possibly concurrently for statements of different connections.
+// When no logger is set, statements are not timed at all.
+func SetLogger(fn func(event QueryEvent), opts ...LoggerOption) {
+	if fn == nil {
+		SetContextLogger(nil)
+		return
+	}
+	SetContextLogger(func(_ context.Context, event QueryEvent) { fn(event) }, opts...)
+}
+
+// SetContextLogger is SetLogger with the function which also receives the context of the statement,
+// e.g. to attach tracing spans; Commit and Rollback are reported with context.Background as driver.Tx has no context.
+func SetContextLogger(fn func(ctx context.Context, event QueryEvent), opts ...LoggerOption) {
+	if fn == nil {
+		currentLogger.Store(nil)
+		return
+	}
+	l := &queryLogger{fn: fn}
+	for _, opt := range opts {
+		opt(l)
+	}
+	currentLogger.Store(l)
+}
+
+// log reports the event of the statement started at start.
+func (l *queryLogger) log(ctx context.Context, event QueryEvent, start time.Time) {
+	event.Duration = time.Since(start)
+	if l.redactArgs && len(event.Args) > 0 {
+		redacted := make([]driver.NamedValue, len(event.Args))
+		for i, arg := range event.Args {
+			redacted[i] = driver.NamedValue{Name: arg.Name, Ordinal: arg.Ordinal}
+		}
+		event.Args = redacted
+	}
+	l.fn(ctx, event)
+}
+
+// currentProfileHook is the hook set with SetProfileHook (nil if no hook is set)
+var currentProfileHook atomic.Pointer[func(sql string, nanoseconds int64, vmSteps int64)]
+
+// SetProfileHook sets the function which receives the cost of every statement run by connections of the driver
+// once the statement completes (like sqlite3_profile): its SQL text, the time it ran in nanoseconds and the number
+// of VM instructions it executed; nil removes the hook. Statements of a multi-statement Exec or Query are reported
+// one by one, and time of a query lasts until its rows are closed or move to the next result set.
+// The function is called synchronously from the goroutine which runs the statement while the connection is busy,
+// so it must not use the connection. When no hook is set and _profile is disabled, statements are not measured at all.
+func SetProfileHook(fn func(sql string, nanoseconds int64, vmSteps int64)) {
+	if fn == nil {
+		currentProfileHook.Store(nil)
+		return
+	}
+	currentProfileHook.Store(&fn)
+}
+
+// statementProfile is the cost of a statement measured since it started
+type statementProfile struct {
+	sql   string
+	start time.Time
+	steps int64
+}
+
+// startProfile starts measuring stmt with SQL text sql; it returns the zero profile if profiling is disabled
+func (c *tursoDbConnection) startProfile(stmt TursoStatement, sql string) statementProfile {
+	if !c.profile && currentProfileHook.Load() == nil {
+		return statementProfile{}
+	}
+	return statementProfile{sql: strings.TrimSpace(sql), start: time.Now(), steps: turso_statement_vm_steps(stmt)}
+}
+
+// finishProfile reports the cost of stmt measured by p to ConnStats and the profile hook;
+// it must be called before stmt is finalized or returned to the statement cache
+func (c *tursoDbConnection) finishProfile(p statementProfile, stmt TursoStatement) {
+	if p.start.IsZero() {
+		return
+	}
+	elapsed := time.Since(p.start)
+	steps := turso_statement_vm_steps(stmt) - p.steps
+	c.profiledStatements.Add(1)
+	c.profiledNanos.Add(elapsed.Nanoseconds())
+	if hook := currentProfileHook.Load(); hook != nil {
+		(*hook)(p.sql, elapsed.Nanoseconds(), steps)
+	}
+}
+
 // define all package level structs here
 
 type tursoDbDriver struct{}
@@ -32,12 +248,46 @@ type tursoDbConnection struct {
 	db      TursoDatabase
 	conn    TursoConnection
 	extraIo func() error
+	// in-memory database shared with other connections (db is nil in this case)
+	memory *memoryDatabase
 
-	mu          sync.Mutex
-	closed      bool
+	mu     sync.Mutex
+	closed bool
+	// guards release of conn against Interrupt, which runs without mu while a statement holds it
+	interruptMu sync.Mutex
 	busyTimeout int // current busy timeout in milliseconds
+	timeFormat  TimeFormat
+	// set if the DSN allows loading extensions
+	allowLoadExtension bool
 	// keep flags for configuration if needed
 	async bool
+	// open blob handles which must be released before the connection
+	blobs map[*Blob]struct{}
+	// position of the latest write shared by all connections of the pool (nil if _read_your_writes is disabled)
+	ryw *readYourWrites
+	// set while transaction started with LevelConcurrent is active
+	concurrentTx bool
+	// error of the collation which panicked during the current statement
+	collationPanic atomic.Pointer[error]
+	// idle prepared statements reused by QueryContext and ExecContext (nil if _stmt_cache_size is 0)
+	stmts *stmtCache
+	// probes the sync endpoint on Ping (set for embedded replica connections opened with _ping_remote=true)
+	pingRemote func(ctx context.Context) error
+	// retry policy set with _busy_retries (nil falls back to the policy set with SetBusyRetryPolicy)
+	busyRetry *busyRetryPolicy
+	// runs writes on the remote primary (set for embedded replica connections opened with RouteWrites)
+	primary *primaryRoute
+	// cipher set with _cipher (SetKey falls back to DefaultCipher if empty)
+	cipher string
+	// set with _profile: statements are measured for ConnStats even if no profile hook is set
+	profile bool
+	// number and total time in nanoseconds of the statements measured while profiling was enabled
+	profiledStatements atomic.Int64
+	profiledNanos      atomic.Int64
+	// set with _null_as_zero: NULL scanned into a non-nullable destination stores its zero value
+	nullAsZero bool
+	// set for connections of an embedded replica opened with TursoSyncDb
+	replica bool
 }
 
 type tursoDbStatement struct {
@@ -52,9 +302,44 @@ type tursoDbRows struct {
 	stmt      TursoStatement
 	columns   []string
 	decltypes []string
+	// ctx of the query and the function which stops interrupting the statement once ctx is done
+	ctx  context.Context
+	stop func()
+	// releases ctx derived for the scan timeout set with WithScanTimeout (nil if not set)
+	cancel context.CancelFunc
+	// limit set with WithMaxRows (0 if not set) and the number of rows returned so far
+	maxRows  int64
+	returned int64
+	// logger which receives the query event on Close (nil if no logger was set when the query started)
+	logger   *queryLogger
+	logStart time.Time
+	logSQL   string
+	// retries of the statement which failed with SQLITE_BUSY before returning any row
+	busyRetries int
 
 	closed bool
 	err    error
+	// done is set once the statement ran to completion
+	done bool
+	// SQL text of the statement which is returned to the statement cache on Close (empty if not cacheable)
+	cached string
+	// SQL text of a multi-statement query after the current statement and the next statement prepared from it
+	// by HasNextResultSet along with its SQL text (or the error of its preparation, reported by NextResultSet)
+	rest    string
+	next    TursoStatement
+	nextSQL string
+	nextErr error
+	// cost of the current statement measured for the profile hook (zero if profiling is disabled)
+	profile statementProfile
+
+	// arguments bound to the statement again when it's restarted
+	args []driver.NamedValue
+	// with _read_your_writes statement is restarted until its snapshot reaches required position
+	catchUp  bool
+	required walPosition
+	restarts int
+	// current row read by NextRow for ScanColumn
+	scanned []driver.Value
 }
 
 type tursoDbResult struct {
@@ -84,6 +369,11 @@ func NewConnection(conn TursoConnection, extraIo func() error) *tursoDbConnectio
 // Optional helper to run global setup (logger and log level).
 func Setup(config TursoConfig) error {
 	InitLibrary(turso_libs.LoadTursoLibraryConfig{})
+	// keeps ShutdownLibrary from unloading the library during the call
+	if err := acquireLibrary(); err != nil {
+		return err
+	}
+	defer releaseLibrary()
 	return turso_setup(config)
 }
 
@@ -94,17 +384,79 @@ func (d *tursoDbDriver) Open(dsn string) (driver.Conn, error) {
 	if err != nil {
 		return nil, err
 	}
+	return openConnection(config)
+}
+
+// OpenConnector implements driver.DriverContext so sql.Open validates the DSN upfront
+// and every connection of the pool is opened with the same parsed configuration.
+func (d *tursoDbDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	return NewConnector(dsn)
+}
+
+var _ driver.DriverContext = (*tursoDbDriver)(nil)
+
+// openConnection opens the database described by config and connects to it.
+// The busy timeout is applied to every new connection: 0 means DefaultBusyTimeout, -1 disables the busy handler.
+func openConnection(config TursoDatabaseConfig) (*tursoDbConnection, error) {
+	if config.SharedMemory != "" {
+		memory, err := acquireSharedMemory(config)
+		if err != nil {
+			return nil, err
+		}
+		defer memory.release()
+		return memory.connect(config)
+	}
+	// checked before the open, which creates the file
+	empty := databaseFileIsEmpty(config.Path)
+	db, err := openDatabase(config)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := connectDatabase(db, config, empty)
+	if err != nil {
+		closeDatabase(db)
+		return nil, err
+	}
+	conn.db = db
+	return conn, nil
+}
+
+// openDatabase creates and opens the database described by config.
+// The database keeps the library in use until it's released with closeDatabase.
+func openDatabase(config TursoDatabaseConfig) (TursoDatabase, error) {
+	if err := acquireLibrary(); err != nil {
+		return nil, err
+	}
 	db, err := turso_database_new(config)
 	if err != nil {
+		releaseLibrary()
 		return nil, err
 	}
+	if config.ReadOnly || config.NoCreate || config.Immutable {
+		readOnly := config.ReadOnly || config.Immutable
+		if err := turso_database_set_open_flags(db, readOnly, !config.NoCreate, config.Immutable); err != nil {
+			closeDatabase(db)
+			return nil, err
+		}
+	}
 	if err := turso_database_open(db); err != nil {
-		turso_database_deinit(db)
+		closeDatabase(db)
 		return nil, err
 	}
+	return db, nil
+}
+
+// closeDatabase deinits database opened with openDatabase.
+func closeDatabase(db TursoDatabase) {
+	turso_database_deinit(db)
+	releaseLibrary()
+}
+
+// connectDatabase opens a new connection to db; the caller keeps ownership of db.
+// empty tells whether db had no content when it was opened, see applyCreationSettings.
+func connectDatabase(db TursoDatabase, config TursoDatabaseConfig, empty bool) (*tursoDbConnection, error) {
 	c, err := turso_database_connect(db)
 	if err != nil {
-		turso_database_deinit(db)
 		return nil, err
 	}
 	// Apply busy timeout - use default if not explicitly set
@@ -120,12 +472,165 @@ func (d *tursoDbDriver) Open(dsn string) (driver.Conn, error) {
 	if timeout > 0 {
 		turso_connection_set_busy_timeout_ms(c, int64(timeout))
 	}
-	return &tursoDbConnection{
-		db:          db,
-		conn:        c,
-		busyTimeout: timeout,
-		async:       config.AsyncIO,
-	}, nil
+	if config.AllowLoadExtension {
+		if err := turso_connection_enable_load_extension(c, true); err != nil {
+			_ = turso_connection_close(c)
+			turso_connection_deinit(c)
+			return nil, err
+		}
+	}
+	conn := &tursoDbConnection{
+		conn:               c,
+		busyTimeout:        timeout,
+		timeFormat:         config.TimeFormat,
+		allowLoadExtension: config.AllowLoadExtension,
+		async:              config.AsyncIO,
+		busyRetry:          newBusyRetryPolicy(config.BusyRetries, config.BusyRetryBackoff),
+		cipher:             config.Encryption.Cipher,
+		profile:            config.Profile,
+		nullAsZero:         config.NullAsZero,
+	}
+	if config.ReadYourWrites {
+		conn.ryw = &readYourWrites{}
+	}
+	if config.StmtCacheSize > 0 {
+		conn.stmts = newStmtCache(config.StmtCacheSize)
+	}
+	if err := conn.applyCreationSettings(config, empty); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// autoVacuumModes maps names of auto_vacuum modes to the values reported by PRAGMA auto_vacuum
+var autoVacuumModes = map[string]int64{"none": 0, "full": 1, "incremental": 2}
+
+// applyCreationSettings applies _page_size and _auto_vacuum, which take effect only while the database is empty:
+// page_size must be set before the first write and auto_vacuum before the first table.
+// The settings are applied only if the database was empty when it was opened. A database with content keeps its own
+// values: a mismatch is reported to the logger as QueryEventWarning once per database path and setting.
+func (c *tursoDbConnection) applyCreationSettings(config TursoDatabaseConfig, empty bool) error {
+	if config.PageSize > 0 {
+		if err := c.applyCreationPragma(config.Path, empty, "page_size", strconv.Itoa(config.PageSize), int64(config.PageSize)); err != nil {
+			return err
+		}
+	}
+	if config.AutoVacuum != "" {
+		if err := c.applyCreationPragma(config.Path, empty, "auto_vacuum", config.AutoVacuum, autoVacuumModes[config.AutoVacuum]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// creationWarnings holds the database paths and settings whose mismatch was already reported
+var creationWarnings sync.Map
+
+func (c *tursoDbConnection) applyCreationPragma(path string, empty bool, name, value string, want int64) error {
+	warning := path + "\x00" + name
+	if !empty {
+		if _, warned := creationWarnings.Load(warning); warned {
+			return nil
+		}
+	}
+	current, err := c.GetPragmaInt(name)
+	if err != nil || current == want {
+		return err
+	}
+	query := "PRAGMA " + name + " = " + value
+	if empty {
+		_, _, err := c.exec(context.Background(), query, nil)
+		return err
+	}
+	if _, warned := creationWarnings.LoadOrStore(warning, struct{}{}); warned {
+		return nil
+	}
+	if l := currentLogger.Load(); l != nil {
+		err := fmt.Errorf("turso: %s is not applied: the database already has content with %s = %d", name, name, current)
+		l.log(context.Background(), QueryEvent{Kind: QueryEventWarning, SQL: query, Err: err}, time.Now())
+	}
+	return nil
+}
+
+// walHeaderSize is the size of the WAL file header: a WAL file of this size has no frames
+const walHeaderSize = 32
+
+// databaseFileIsEmpty reports whether the database file at path is missing or has no content, neither in the
+// file itself nor in its WAL
+func databaseFileIsEmpty(path string) bool {
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		return false
+	}
+	if info, err := os.Stat(path + "-wal"); err == nil && info.Size() > walHeaderSize {
+		return false
+	}
+	return true
+}
+
+// memoryDatabase is an in-memory database shared by several connections.
+// It's released when the last connection or connector referencing it is closed.
+type memoryDatabase struct {
+	db   TursoDatabase
+	name string // key in sharedMemory (empty for the database private to a connector)
+	refs int    // guarded by sharedMemoryMu
+	// connected is set once the first connection is opened (guarded by sharedMemoryMu)
+	connected bool
+}
+
+var (
+	sharedMemoryMu sync.Mutex
+	// sharedMemory holds in-memory databases opened with cache=shared by their name
+	sharedMemory = map[string]*memoryDatabase{}
+)
+
+// acquireSharedMemory returns the shared in-memory database named by config, opening it on first use.
+// The caller must release the returned database.
+func acquireSharedMemory(config TursoDatabaseConfig) (*memoryDatabase, error) {
+	sharedMemoryMu.Lock()
+	defer sharedMemoryMu.Unlock()
+	if memory, ok := sharedMemory[config.SharedMemory]; ok {
+		memory.refs++
+		return memory, nil
+	}
+	db, err := openDatabase(config)
+	if err != nil {
+		return nil, err
+	}
+	memory := &memoryDatabase{db: db, name: config.SharedMemory, refs: 1}
+	sharedMemory[memory.name] = memory
+	return memory, nil
+}
+
+// connect opens a new connection which keeps the database alive until the connection is closed.
+func (m *memoryDatabase) connect(config TursoDatabaseConfig) (*tursoDbConnection, error) {
+	sharedMemoryMu.Lock()
+	m.refs++
+	// only the first connection finds the database empty
+	empty := !m.connected
+	m.connected = true
+	sharedMemoryMu.Unlock()
+	conn, err := connectDatabase(m.db, config, empty)
+	if err != nil {
+		m.release()
+		return nil, err
+	}
+	conn.memory = m
+	return conn, nil
+}
+
+// release drops one reference and closes the database once nothing references it.
+func (m *memoryDatabase) release() {
+	sharedMemoryMu.Lock()
+	defer sharedMemoryMu.Unlock()
+	m.refs--
+	if m.refs > 0 {
+		return
+	}
+	if m.name != "" {
+		delete(sharedMemory, m.name)
+	}
+	closeDatabase(m.db)
 }
 
 // --- driver.Conn and friends ---
@@ -137,7 +642,9 @@ var (
 	_ driver.ExecerContext      = (*tursoDbConnection)(nil)
 	_ driver.QueryerContext     = (*tursoDbConnection)(nil)
 	_ driver.Pinger             = (*tursoDbConnection)(nil)
+	_ driver.Validator          = (*tursoDbConnection)(nil)
 	_ driver.ConnBeginTx        = (*tursoDbConnection)(nil)
+	_ driver.NamedValueChecker  = (*tursoDbConnection)(nil)
 )
 
 func (c *tursoDbConnection) Prepare(query string) (driver.Stmt, error) {
@@ -145,14 +652,32 @@ func (c *tursoDbConnection) Prepare(query string) (driver.Stmt, error) {
 }
 
 func (c *tursoDbConnection) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	l := currentLogger.Load()
+	if l == nil {
+		return c.prepare(ctx, query)
+	}
+	start := time.Now()
+	stmt, err := c.prepare(ctx, query)
+	l.log(ctx, QueryEvent{Kind: QueryEventPrepare, SQL: query, Err: err}, start)
+	if err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+func (c *tursoDbConnection) prepare(ctx context.Context, query string) (*tursoDbStatement, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err := c.checkOpen(); err != nil {
 		return nil, err
 	}
 	// PREPARE in Prepare - do not delay that
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if ctx.Err() != nil {
-		return nil, ctx.Err()
+	// the local schema may not know tables of the statement which runs on the primary yet
+	if c.routesToPrimary(ctx, query) {
+		return &tursoDbStatement{conn: c, sql: query, numInputs: -1}, nil
 	}
 	stmt, err := turso_connection_prepare_single(c.conn, query)
 	if err != nil {
@@ -160,6 +685,13 @@ func (c *tursoDbConnection) PrepareContext(ctx context.Context, query string) (d
 	}
 	// determine number of inputs and then finalize immediately to avoid keeping state
 	num := int(turso_statement_parameters_count(stmt))
+	for i := 1; i <= num; i++ {
+		// named parameters are validated by the driver in order to report missing names explicitly
+		if isNamedParameter(turso_statement_parameter_name(stmt, i)) {
+			num = -1
+			break
+		}
+	}
 	_ = turso_statement_finalize(stmt)
 	turso_statement_deinit(stmt)
 
@@ -170,22 +702,88 @@ func (c *tursoDbConnection) PrepareContext(ctx context.Context, query string) (d
 	}, nil
 }
 
+// StmtInfo describes a prepared statement: its parameters and the columns of its result.
+type StmtInfo struct {
+	// ParamCount is the number of parameters; a named parameter used several times counts once
+	ParamCount int
+	// ParamNames holds names of the parameters by position (index 0 is the parameter ?1) with their prefix,
+	// e.g. ":name", "@name" or "$name"; positional parameters (? and ?NNN) have empty names
+	ParamNames []string
+	// ColumnCount is the number of result columns, 0 for statements which return no rows (e.g. UPDATE)
+	ColumnCount int
+	// ColumnNames holds names of the result columns
+	ColumnNames []string
+}
+
+// Describe prepares the single statement query without executing it and returns its metadata.
+func (c *tursoDbConnection) Describe(query string) (StmtInfo, error) {
+	if err := c.checkOpen(); err != nil {
+		return StmtInfo{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stmt, err := turso_connection_prepare_single(c.conn, query)
+	if err != nil {
+		return StmtInfo{}, err
+	}
+	defer func() {
+		_ = turso_statement_finalize(stmt)
+		turso_statement_deinit(stmt)
+	}()
+	info := StmtInfo{
+		ParamCount:  int(turso_statement_parameters_count(stmt)),
+		ColumnCount: int(turso_statement_column_count(stmt)),
+	}
+	info.ParamNames = make([]string, info.ParamCount)
+	for i := range info.ParamNames {
+		if name := turso_statement_parameter_name(stmt, i+1); isNamedParameter(name) {
+			info.ParamNames[i] = name
+		}
+	}
+	info.ColumnNames = make([]string, info.ColumnCount)
+	for i := range info.ColumnNames {
+		info.ColumnNames[i] = turso_statement_column_name(stmt, i)
+	}
+	return info, nil
+}
+
 func (c *tursoDbConnection) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if c.closed {
 		return nil
 	}
+	// a transaction left open on the primary is rolled back instead of waiting for the stream to expire
+	if c.primary != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), primaryCloseTimeout)
+		_ = c.primary.closeStream(ctx)
+		cancel()
+	}
 	// Close connection and deinit resources
+	for blob := range c.blobs {
+		_ = blob.release()
+	}
+	c.blobs = nil
+	if c.stmts != nil {
+		for _, stmt := range c.stmts.drain() {
+			_ = c.finalize(stmt)
+		}
+	}
 	if c.conn != nil {
 		_ = turso_connection_close(c.conn)
+		c.interruptMu.Lock()
 		turso_connection_deinit(c.conn)
 		c.conn = nil
+		c.interruptMu.Unlock()
 	}
 	if c.db != nil {
-		turso_database_deinit(c.db)
+		closeDatabase(c.db)
 		c.db = nil
 	}
+	if c.memory != nil {
+		c.memory.release()
+		c.memory = nil
+	}
 	c.closed = true
 	return nil
 }
@@ -194,147 +792,2424 @@ func (c *tursoDbConnection) Begin() (driver.Tx, error) {
 	return c.BeginTx(context.Background(), driver.TxOptions{})
 }
 
-func (c *tursoDbConnection) BeginTx(ctx context.Context, _ driver.TxOptions) (driver.Tx, error) {
+func (c *tursoDbConnection) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
 	if err := c.checkOpen(); err != nil {
 		return nil, err
 	}
-	// Use BEGIN (snapshot isolation)
-	_, err := c.ExecContext(ctx, "BEGIN", nil)
+	// Use BEGIN (snapshot isolation) unless concurrent transaction is requested
+	begin := "BEGIN"
+	concurrent := sql.IsolationLevel(opts.Isolation) == LevelConcurrent
+	if concurrent {
+		begin = "BEGIN CONCURRENT"
+	}
+	// read-only transaction of a connection with RouteWrites stays on the local replica
+	if opts.ReadOnly && c.primary != nil {
+		ctx = WithRoute(ctx, RouteReplica)
+	}
+	_, err := c.execLogged(ctx, QueryEventBegin, begin, nil)
 	if err != nil {
 		return nil, err
 	}
+	c.concurrentTx = concurrent
 	return &tursoDbTx{conn: c}, nil
 }
 
-func (c *tursoDbConnection) Ping(ctx context.Context) error {
+// concurrentError marks conflicts of the active concurrent transaction with ErrConcurrentConflict
+func (c *tursoDbConnection) concurrentError(err error) error {
+	if !c.concurrentTx || !isConcurrentConflict(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrConcurrentConflict, err)
+}
+
+// isConcurrentConflict reports whether err is a conflict of the concurrent transaction, which the library reports
+// with SQLITE_BUSY_SNAPSHOT (write-write conflicts, aborted commit dependencies and stale snapshots)
+func isConcurrentConflict(err error) bool {
+	var e Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	return e.ExtendedCode == SQLITE_BUSY_SNAPSHOT
+}
+
+// Savepoint starts a new savepoint with the given name (any string - it is quoted as identifier).
+// If no transaction is active, the savepoint starts one which ends with release of this savepoint.
+// Use sql.Conn.Raw to reach savepoint methods from database/sql.
+func (c *tursoDbConnection) Savepoint(name string) error {
+	return c.savepoint("SAVEPOINT ", name, false)
+}
+
+// ReleaseSavepoint releases the savepoint with the given name and all savepoints started after it.
+func (c *tursoDbConnection) ReleaseSavepoint(name string) error {
+	return c.savepoint("RELEASE SAVEPOINT ", name, true)
+}
+
+// RollbackToSavepoint reverts changes made after the savepoint with the given name; the savepoint stays active.
+// It returns ErrTursoNoSavepoint if the transaction holding the savepoint already ended (e.g. was rolled back).
+func (c *tursoDbConnection) RollbackToSavepoint(name string) error {
+	return c.savepoint("ROLLBACK TO SAVEPOINT ", name, true)
+}
+
+func (c *tursoDbConnection) savepoint(command, name string, existing bool) error {
+	if name == "" || strings.IndexByte(name, 0) >= 0 {
+		return fmt.Errorf("turso: invalid savepoint name %q", name)
+	}
 	if err := c.checkOpen(); err != nil {
 		return err
 	}
-	// trivial ping: simple select constant
-	_, err := c.QueryContext(ctx, "SELECT 1", nil)
-	if err != nil {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// savepoints live only inside transaction: without it the library may treat the command as a no-op
+	if existing && turso_connection_get_autocommit(c.conn) {
+		return fmt.Errorf("%w: %s", ErrTursoNoSavepoint, name)
+	}
+	if _, _, err := c.exec(context.Background(), command+quoteIdentifier(name), nil); err != nil {
 		return err
 	}
+	c.observeWrite()
 	return nil
 }
 
-func (c *tursoDbConnection) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+// quoteIdentifier quotes name as SQL identifier, so it can contain quotes and reserved words.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Attach attaches the database file at path under alias, so queries can refer to its tables as alias.table.
+// The attachment belongs to the connection: use it through a dedicated sql.Conn, where it lasts until Detach
+// or until the connection is closed, as other connections of the pool don't see it.
+// Attaching an alias which is already in use fails with ErrTursoAlreadyAttached.
+// The main database itself can't be attached: the library shares an open file between all its users,
+// so the attached copy would compete for the locks of the main database.
+// ATTACH is experimental in the library: open the database with "experimental=attach" in the DSN.
+// Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) Attach(path, alias string) error {
+	if alias == "" || strings.IndexByte(alias, 0) >= 0 {
+		return fmt.Errorf("turso: invalid database alias %q", alias)
+	}
+	file, err := sqlLiteral(path)
+	if err != nil {
+		return fmt.Errorf("turso: invalid database path: %w", err)
+	}
+	if strings.EqualFold(alias, "main") || strings.EqualFold(alias, "temp") {
+		return fmt.Errorf("%w: %s is reserved", ErrTursoAlreadyAttached, alias)
+	}
 	if err := c.checkOpen(); err != nil {
-		return nil, err
+		return err
 	}
-	// Multi-statement support for Exec-family
-	var totalAffected int64
+	// the check and ATTACH run under one lock, so no other statement of the connection attaches the alias in between
 	c.mu.Lock()
 	defer c.mu.Unlock()
-
-	offset := 0
-	first := true
-	var lastInsert int64 = 0
-	for {
-		if ctx.Err() != nil {
-			return nil, ctx.Err()
-		}
-		rest := query[offset:]
-		if strings.TrimSpace(rest) == "" {
-			break
-		}
-		stmt, tail, err := turso_connection_prepare_first(c.conn, rest)
-		if err != nil {
-			return nil, err
-		}
-		// Calculate absolute offset advance
-		offset += tail
-
-		// Bind only for the first statement
-		if first {
-			if err := bindArgs(stmt, args); err != nil {
-				_ = turso_statement_finalize(stmt)
-				turso_statement_deinit(stmt)
-				return nil, err
-			}
-		}
-		// Execute statement fully
-		affected, err := c.executeFully(ctx, stmt)
-		// finalize and deinit regardless of status
-		_ = turso_statement_finalize(stmt)
-		turso_statement_deinit(stmt)
-		if err != nil {
-			return nil, err
+	databases, err := c.databaseListLocked()
+	if err != nil {
+		return err
+	}
+	for name, file := range databases {
+		if strings.EqualFold(name, alias) {
+			return fmt.Errorf("%w: %s", ErrTursoAlreadyAttached, alias)
 		}
-		// rows affected is capped at MaxInt64
-		if affected > uint64(math.MaxInt64-totalAffected) {
-			totalAffected = math.MaxInt64
-		} else {
-			totalAffected += int64(affected)
+		if name == "main" && sameFile(file, path) {
+			return fmt.Errorf("turso: cannot attach the main database %q as %s", path, alias)
 		}
-		lastInsert = turso_connection_last_insert_rowid(c.conn)
-		first = false
-		// continue with the rest of the query string
 	}
-	return &tursoDbResult{
-		lastInsertId: lastInsert,
-		rowsAffected: totalAffected,
-	}, nil
+	_, _, err = c.exec(context.Background(), "ATTACH DATABASE "+file+" AS "+quoteIdentifier(alias), nil)
+	return err
 }
 
-func (c *tursoDbConnection) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+// Detach detaches the database attached under alias with Attach or ATTACH DATABASE statement.
+// Detaching an alias which isn't attached fails with ErrTursoNotAttached.
+func (c *tursoDbConnection) Detach(alias string) error {
+	if strings.EqualFold(alias, "main") || strings.EqualFold(alias, "temp") {
+		return fmt.Errorf("turso: cannot detach %s database", alias)
+	}
 	if err := c.checkOpen(); err != nil {
-		return nil, err
+		return err
 	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if ctx.Err() != nil {
-		return nil, ctx.Err()
-	}
-	// Only single-statement queries supported here
-	stmt, err := turso_connection_prepare_single(c.conn, query)
+	databases, err := c.databaseListLocked()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	if err := bindArgs(stmt, args); err != nil {
-		_ = turso_statement_finalize(stmt)
-		turso_statement_deinit(stmt)
+	attached := false
+	for name := range databases {
+		attached = attached || strings.EqualFold(name, alias)
+	}
+	if !attached {
+		return fmt.Errorf("%w: %s", ErrTursoNotAttached, alias)
+	}
+	_, _, err = c.exec(context.Background(), "DETACH DATABASE "+quoteIdentifier(alias), nil)
+	return err
+}
+
+// databaseListLocked returns the files of the databases of the connection by their names; c.mu must be held
+func (c *tursoDbConnection) databaseListLocked() (map[string]string, error) {
+	stmt, err := turso_connection_prepare_single(c.conn, "PRAGMA database_list")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = turso_statement_finalize(stmt)
+		turso_statement_deinit(stmt)
+	}()
+	databases := map[string]string{}
+	for {
+		status, err := turso_statement_step(stmt)
+		if err != nil {
+			return nil, err
+		}
+		switch status {
+		case TURSO_ROW:
+			databases[turso_statement_row_value_text(stmt, 1)] = turso_statement_row_value_text(stmt, 2)
+		case TURSO_IO:
+			if c.extraIo != nil {
+				if err := c.extraIo(); err != nil {
+					return nil, err
+				}
+			}
+			if err := turso_statement_run_io(stmt); err != nil {
+				return nil, err
+			}
+		case TURSO_DONE:
+			return databases, nil
+		}
+	}
+}
+
+// sameFile reports whether both paths name the same existing file
+func sameFile(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(infoA, infoB)
+}
+
+// sqlLiteral formats bool, integer, float or string value as SQL literal. Strings are enclosed in single quotes
+// with embedded single quotes doubled; the library unescapes them in every statement which takes a string
+// literal, including ATTACH, VACUUM INTO and PRAGMA arguments. Strings with NUL bytes are rejected,
+// as the library cuts SQL off at the NUL.
+func sqlLiteral(value any) (string, error) {
+	switch x := value.(type) {
+	case bool:
+		if x {
+			return "1", nil
+		}
+		return "0", nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(x), nil
+	case string:
+		if strings.IndexByte(x, 0) >= 0 {
+			return "", fmt.Errorf("string %q contains NUL byte", x)
+		}
+		return "'" + strings.ReplaceAll(x, "'", "''") + "'", nil
+	default:
+		return "", fmt.Errorf("unsupported type %T", value)
+	}
+}
+
+// QuoteIdentifier quotes name as SQL identifier for dynamic SQL (e.g. table and column names):
+// name is enclosed in double quotes with embedded double quotes doubled, so any other character,
+// including backticks, brackets and reserved words, is taken literally.
+// QuoteIdentifier panics if name contains a NUL byte, as the library would cut it off at the NUL:
+// use QuoteIdentifierE for names which don't come from the program itself.
+func QuoteIdentifier(name string) string {
+	quoted, err := QuoteIdentifierE(name)
+	if err != nil {
+		panic(err)
+	}
+	return quoted
+}
+
+// QuoteIdentifierE is like QuoteIdentifier but returns an error for names containing NUL bytes.
+func QuoteIdentifierE(name string) (string, error) {
+	if strings.IndexByte(name, 0) >= 0 {
+		return "", fmt.Errorf("turso: identifier %q contains NUL byte", name)
+	}
+	return quoteIdentifier(name), nil
+}
+
+// QuoteString quotes s as SQL string literal: s is enclosed in single quotes with embedded single quotes doubled.
+// The literal is safe wherever the library expects a string literal, including ATTACH, VACUUM INTO and
+// PRAGMA arguments, but not in place of a name (use QuoteIdentifier) or a keyword.
+// QuoteString panics if s contains a NUL byte: use QuoteStringE for strings which don't come from the program itself.
+// Prefer bound arguments to literals wherever SQL permits them.
+func QuoteString(s string) string {
+	literal, err := QuoteStringE(s)
+	if err != nil {
+		panic(err)
+	}
+	return literal
+}
+
+// QuoteStringE is like QuoteString but returns an error for strings containing NUL bytes.
+func QuoteStringE(s string) (string, error) {
+	literal, err := sqlLiteral(s)
+	if err != nil {
+		return "", fmt.Errorf("turso: %w", err)
+	}
+	return literal, nil
+}
+
+// Ping checks that the connection is usable by running a trivial query.
+// It returns driver.ErrBadConn if the connection is closed or the query fails, so database/sql discards it from the pool.
+// Connections of an embedded replica opened with _ping_remote=true probe the sync endpoint as well
+// and return ErrTursoRemoteUnreachable if it doesn't answer: the connection itself stays valid in this case.
+func (c *tursoDbConnection) Ping(ctx context.Context) error {
+	if err := c.checkOpen(); err != nil {
+		return driver.ErrBadConn
+	}
+	// trivial ping: simple select constant
+	row, err := c.queryRow(ctx, "SELECT 1", nil)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: %w", driver.ErrBadConn, err)
+	}
+	if row == nil {
+		return driver.ErrBadConn
+	}
+	if c.pingRemote != nil {
+		return c.pingRemote(ctx)
+	}
+	return nil
+}
+
+// IsValid reports whether the connection can be reused by the pool of database/sql.
+func (c *tursoDbConnection) IsValid() bool {
+	return c.checkOpen() == nil
+}
+
+// ResetSession implements driver.SessionResetter: a transaction which a connection opened with RouteWrites left open
+// on the primary (e.g. BEGIN run with ExecContext) is rolled back before the pool hands the connection out again.
+// The connection is discarded if the primary can't be reached to roll it back.
+func (c *tursoDbConnection) ResetSession(ctx context.Context) error {
+	if c.primary == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.conn == nil {
+		return driver.ErrBadConn
+	}
+	if err := c.primary.closeStream(ctx); err != nil {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+// ExecContext runs all statements of query in order and stops at the first failed one.
+// Arguments are bound to the first statement only: parameters of the statements after it stay NULL.
+func (c *tursoDbConnection) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.execLogged(ctx, QueryEventExec, query, args)
+}
+
+// execLogged runs the statements with execContext and reports them to the logger set with SetLogger as event of the kind
+func (c *tursoDbConnection) execLogged(ctx context.Context, kind QueryEventKind, query string, args []driver.NamedValue) (driver.Result, error) {
+	l := currentLogger.Load()
+	if l == nil {
+		return c.execContext(ctx, query, args)
+	}
+	start := time.Now()
+	result, err := c.execContext(ctx, query, args)
+	event := QueryEvent{Kind: kind, SQL: query, Args: args, Err: err}
+	if result != nil {
+		event.RowsAffected, _ = result.RowsAffected()
+	}
+	l.log(ctx, event, start)
+	return result, err
+}
+
+func (c *tursoDbConnection) execContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err := c.checkOpen(); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.routesToPrimary(ctx, query) {
+		return c.primary.exec(ctx, query, args, c.timeFormat)
+	}
+	stop := c.interruptOnDone(ctx)
+	result, err := c.execRetryingBusy(ctx, query, args)
+	stop()
+	if err = c.collationError(err); err != nil {
+		return nil, c.concurrentError(ctxError(ctx, err))
+	}
+	c.observeWrite()
+	return result, nil
+}
+
+// execRetryingBusy runs exec and retries it with the busy retry policy while it fails with SQLITE_BUSY
+// on the first statement outside of a transaction: the failed statement was rolled back as a whole, so it can be replayed.
+// Statements of an open transaction are never retried.
+// caller must hold c.mu
+func (c *tursoDbConnection) execRetryingBusy(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	policy := c.busyRetryPolicy()
+	autocommit := policy != nil && turso_connection_get_autocommit(c.conn)
+	for attempt := 1; ; attempt++ {
+		result, index, err := c.exec(ctx, query, args)
+		if err == nil || !autocommit || attempt > policy.attempts || index != 0 || !IsBusy(err) ||
+			!turso_connection_get_autocommit(c.conn) {
+			return result, err
+		}
+		if err := policy.wait(ctx, attempt); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// ScriptError reports the statement which failed the script executed with ExecScript.
+type ScriptError struct {
+	// Index is the 0-based index of the failed statement in the script (empty statements are not counted)
+	Index int
+	// Err is the failure of the statement
+	Err error
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("turso: statement %d of the script failed: %v", e.Index, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// ExecScript executes all statements of the SQL script one after another and stops at the first failure,
+// which is reported as *ScriptError. Statements are split by the library parser,
+// so comments, empty statements and semicolons inside literals or trigger bodies are handled properly.
+// Statements executed before the failure are not rolled back unless the script runs them in a transaction.
+// Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) ExecScript(ctx context.Context, script string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := c.checkOpen(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stop := c.interruptOnDone(ctx)
+	_, index, err := c.exec(ctx, script, nil)
+	stop()
+	if err = c.collationError(err); err != nil {
+		return &ScriptError{Index: index, Err: ctxError(ctx, err)}
+	}
+	c.observeWrite()
+	return nil
+}
+
+// exec runs all statements from the query and returns index of the failed statement along with the error
+// caller must hold c.mu
+func (c *tursoDbConnection) exec(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, int, error) {
+	// Multi-statement support for Exec-family
+	var totalAffected int64
+
+	offset := 0
+	index := 0
+	// rowid inserted by the latest statement of the query which inserted a row (0 if none did)
+	var lastInsert int64 = 0
+	for {
+		if ctx.Err() != nil {
+			return nil, index, ctx.Err()
+		}
+		rest := query[offset:]
+		if strings.TrimSpace(rest) == "" {
+			break
+		}
+		// only the query consisting of a single statement is cached
+		var stmt TursoStatement
+		tail := len(rest)
+		cacheable := false
+		if index == 0 {
+			stmt = c.takeStatement(query)
+			cacheable = stmt != nil
+		}
+		if stmt == nil {
+			var err error
+			stmt, tail, err = turso_connection_prepare_first(c.conn, rest)
+			if err != nil {
+				return nil, index, err
+			}
+			// the rest contains only comments or empty statements
+			if stmt == nil {
+				break
+			}
+			cacheable = index == 0 && c.stmts != nil && strings.TrimSpace(rest[tail:]) == ""
+		}
+		// Calculate absolute offset advance
+		offset += tail
+
+		// Bind only for the first statement
+		if index == 0 {
+			if err := bindArgs(stmt, args, c.timeFormat); err != nil {
+				_ = turso_statement_finalize(stmt)
+				turso_statement_deinit(stmt)
+				return nil, index, err
+			}
+		}
+		profile := c.startProfile(stmt, rest[:tail])
+		// Execute statement fully (rows produced by RETURNING clause are consumed and dropped)
+		affected, err := c.executeFully(ctx, stmt)
+		c.finishProfile(profile, stmt)
+		// connection keeps last insert rowid of previous statements: read it only if this one inserted a row
+		if turso_statement_inserted_rowid(stmt) {
+			lastInsert = turso_connection_last_insert_rowid(c.conn)
+		}
+		// statement which ran to completion goes back to the cache, otherwise it's finalized regardless of status
+		if err == nil && cacheable {
+			err = c.releaseStatement(query, stmt)
+		} else if finalizeErr := c.finalize(stmt); err == nil {
+			err = finalizeErr
+		}
+		if err != nil {
+			return nil, index, err
+		}
+		// rows affected is capped at MaxInt64
+		if affected > uint64(math.MaxInt64-totalAffected) {
+			totalAffected = math.MaxInt64
+		} else {
+			totalAffected += int64(affected)
+		}
+		index++
+		// continue with the rest of the query string
+	}
+	return &tursoDbResult{
+		lastInsertId: lastInsert,
+		rowsAffected: totalAffected,
+	}, index, nil
+}
+
+// QueryContext returns the rows of the first statement of query which returns rows; the statements after it
+// run as the rows advance with NextResultSet, or when they are closed. Arguments are bound to the first
+// statement only: parameters of the statements after it stay NULL.
+func (c *tursoDbConnection) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	l := currentLogger.Load()
+	if c.primary != nil {
+		start := time.Now()
+		if rows, routed, err := c.queryPrimary(ctx, query, args); routed {
+			if l != nil {
+				event := QueryEvent{Kind: QueryEventQuery, SQL: query, Args: args, Err: err}
+				if rows != nil {
+					event.Rows = rows.rowCount()
+				}
+				l.log(ctx, event, start)
+			}
+			if err != nil {
+				return nil, err
+			}
+			return rows, nil
+		}
+	}
+	if l == nil {
+		return c.query(ctx, query, args)
+	}
+	start := time.Now()
+	rows, err := c.query(ctx, query, args)
+	if err != nil {
+		l.log(ctx, QueryEvent{Kind: QueryEventQuery, SQL: query, Args: args, Err: err}, start)
+		return nil, err
+	}
+	// query is reported once rows are closed, so the event covers the whole iteration
+	rows.logger = l
+	rows.logStart = start
+	rows.logSQL = query
+	return rows, nil
+}
+
+func (c *tursoDbConnection) query(ctx context.Context, query string, args []driver.NamedValue) (*tursoDbRows, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err := c.checkOpen(); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// statements of a multi-statement query after the first one are prepared lazily by NextResultSet,
+	// so they can refer to the schema changes made by the preceding statements
+	var stmt TursoStatement
+	var rest string
+	stmt = c.takeStatement(query)
+	if stmt == nil {
+		first, tail, err := turso_connection_prepare_first(c.conn, query)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			// nothing to run: the library reports the empty query
+			first, err = turso_connection_prepare_single(c.conn, query)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			rest = query[tail:]
+		}
+		stmt = first
+	}
+	if err := bindArgs(stmt, args, c.timeFormat); err != nil {
+		_ = turso_statement_finalize(stmt)
+		turso_statement_deinit(stmt)
+		return nil, err
+	}
+	// Return rows wrapper; do not step yet, leave cursor before first row
+	// rows keep watching ctx as the statement is stepped only in Next
+	limits, _ := ctx.Value(queryLimitsKey{}).(queryLimits)
+	var cancel context.CancelFunc
+	if limits.scanTimeout > 0 {
+		ctx, cancel = context.WithTimeoutCause(ctx, limits.scanTimeout, ErrTursoScanTimeout)
+	}
+	rows := &tursoDbRows{
+		conn:    c,
+		stmt:    stmt,
+		ctx:     ctx,
+		stop:    c.interruptOnDone(ctx),
+		cancel:  cancel,
+		maxRows: limits.maxRows,
+		args:    args,
+		rest:    rest,
+		profile: c.startProfile(stmt, query[:len(query)-len(rest)]),
+	}
+	if c.stmts != nil && strings.TrimSpace(rest) == "" {
+		rows.cached = query
+	}
+	// explicit transaction reads from its own snapshot - catch up only autocommit reads
+	if c.ryw != nil && turso_connection_get_autocommit(c.conn) {
+		rows.catchUp = true
+		rows.required = c.ryw.latestWrite()
+	}
+	// statements which return no rows ahead of the first query of a multi-statement query are executed and skipped
+	if strings.TrimSpace(rest) != "" && turso_statement_column_count(stmt) == 0 {
+		if err := rows.NextResultSet(); err != nil && err != io.EOF {
+			_ = rows.Close()
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+type queryLimitsKey struct{}
+
+// queryLimits are enforced by rows of queries which run with ctx returned by WithMaxRows or WithScanTimeout
+type queryLimits struct {
+	maxRows     int64
+	scanTimeout time.Duration
+}
+
+// WithMaxRows returns a copy of ctx which limits queries run with it to n rows:
+// Next of rows fails with ErrTursoMaxRows instead of returning the row n+1. Non-positive n removes the limit.
+// The limit composes with WithScanTimeout.
+func WithMaxRows(ctx context.Context, n int64) context.Context {
+	limits, _ := ctx.Value(queryLimitsKey{}).(queryLimits)
+	limits.maxRows = max(n, 0)
+	return context.WithValue(ctx, queryLimitsKey{}, limits)
+}
+
+// WithScanTimeout returns a copy of ctx which limits the time queries run with it are scanned:
+// once d elapsed since the query started, the statement is interrupted and Next fails with ErrTursoScanTimeout.
+// Unlike a deadline of ctx, the timeout starts anew for every query. Non-positive d removes the timeout.
+// The timeout composes with the deadline of ctx (whichever expires first stops the scan) and WithMaxRows.
+func WithScanTimeout(ctx context.Context, d time.Duration) context.Context {
+	limits, _ := ctx.Value(queryLimitsKey{}).(queryLimits)
+	limits.scanTimeout = max(d, 0)
+	return context.WithValue(ctx, queryLimitsKey{}, limits)
+}
+
+// DefaultBusyRetryBackoff is the delay before the first retry of a statement failed with SQLITE_BUSY
+// when _busy_retries is set without _busy_retry_backoff.
+const DefaultBusyRetryBackoff = 10 * time.Millisecond
+
+type busyRetryPolicy struct {
+	attempts int
+	backoff  func(attempt int) time.Duration
+}
+
+// defaultBusyRetryPolicy is the policy set with SetBusyRetryPolicy (nil if retries are disabled)
+var defaultBusyRetryPolicy atomic.Pointer[busyRetryPolicy]
+
+// SetBusyRetryPolicy sets the default policy of connections opened without _busy_retries: a statement which fails
+// with SQLITE_BUSY (after the busy timeout elapsed) is retried up to attempts times, waiting backoff(attempt) before
+// the attempt (1-based); nil backoff retries immediately. Non-positive attempts disable retries.
+// Retries happen only when they are safe: the statement runs outside of a transaction (or it's a read-only query)
+// and it hasn't returned any row yet. Writes inside an open transaction are never retried, and neither are multi-statement
+// Execs once their first statement completed. Waiting for the retry is interrupted when the context is done.
+func SetBusyRetryPolicy(attempts int, backoff func(attempt int) time.Duration) {
+	if attempts <= 0 {
+		defaultBusyRetryPolicy.Store(nil)
+		return
+	}
+	if backoff == nil {
+		backoff = func(int) time.Duration { return 0 }
+	}
+	defaultBusyRetryPolicy.Store(&busyRetryPolicy{attempts: attempts, backoff: backoff})
+}
+
+// newBusyRetryPolicy returns the policy of _busy_retries which doubles the delay before every next retry
+func newBusyRetryPolicy(attempts int, backoff time.Duration) *busyRetryPolicy {
+	if attempts <= 0 {
+		return nil
+	}
+	if backoff <= 0 {
+		backoff = DefaultBusyRetryBackoff
+	}
+	return &busyRetryPolicy{
+		attempts: attempts,
+		backoff: func(attempt int) time.Duration {
+			return backoff << min(attempt-1, 10)
+		},
+	}
+}
+
+func (c *tursoDbConnection) busyRetryPolicy() *busyRetryPolicy {
+	if c.busyRetry != nil {
+		return c.busyRetry
+	}
+	return defaultBusyRetryPolicy.Load()
+}
+
+// wait sleeps before the retry attempt and fails with the error of ctx if it's done meanwhile
+func (p *busyRetryPolicy) wait(ctx context.Context, attempt int) error {
+	delay := p.backoff(attempt)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// interruptOnDone interrupts the statement running on the connection once ctx is done.
+// The returned function stops watching ctx and must be called before the connection runs anything else:
+// it waits for an already started interrupt, so the interruption never hits an unrelated statement.
+func (c *tursoDbConnection) interruptOnDone(ctx context.Context) func() {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+	conn := c.conn
+	interrupted := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		turso_connection_interrupt(conn)
+		close(interrupted)
+	})
+	return func() {
+		if !stop() {
+			<-interrupted
+		}
+	}
+}
+
+// Interrupt aborts the statements currently running on the connection, including queries whose rows are still open.
+// It is safe to call from any goroutine (e.g. a signal handler) while a statement runs, and it is a no-op
+// if nothing runs or the connection is closed: the interruption never carries over to later statements.
+// Interrupted statements fail with an Error of Code SQLITE_INTERRUPT, which matches ErrTursoInterrupt with errors.Is.
+// Statements routed to the primary of an embedded replica are interrupted by cancelling their request, so they fail
+// with ErrTursoInterrupt as well, although the primary may still complete them.
+// Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) Interrupt() {
+	if c.primary != nil {
+		c.primary.interrupt()
+	}
+	c.interruptMu.Lock()
+	defer c.interruptMu.Unlock()
+	if c.conn != nil {
+		turso_connection_interrupt(c.conn)
+	}
+}
+
+// CheckNamedValue implements driver.NamedValueChecker. Arguments keep their storage class:
+// []byte is always bound as BLOB (an empty slice as an empty BLOB, not NULL) and string as TEXT;
+// named types based on them (e.g. json.RawMessage) are converted by database/sql first and bound the same way.
+// Scanning preserves the storage class as well: BLOB is returned as []byte and TEXT as string.
+// Scanning BLOB into *string is the explicit conversion of database/sql which copies the bytes as they are,
+// without checking them for valid UTF-8.
+func (c *tursoDbConnection) CheckNamedValue(nv *driver.NamedValue) error {
+	return checkNamedValue(nv)
+}
+
+func (c *tursoDbConnection) checkOpen() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.conn == nil {
+		return ErrTursoConnClosed
+	}
+	return nil
+}
+
+// SetBusyTimeout sets the busy timeout for this connection in milliseconds.
+// Pass 0 to disable the busy handler (immediate SQLITE_BUSY on contention).
+// This method is thread-safe.
+func (c *tursoDbConnection) SetBusyTimeout(timeoutMs int) error {
+	if err := c.checkOpen(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if timeoutMs < 0 {
+		timeoutMs = 0
+	}
+	turso_connection_set_busy_timeout_ms(c.conn, int64(timeoutMs))
+	c.busyTimeout = timeoutMs
+	return nil
+}
+
+// GetBusyTimeout returns the current busy timeout in milliseconds.
+// Returns 0 if the busy handler is disabled.
+func (c *tursoDbConnection) GetBusyTimeout() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.busyTimeout
+}
+
+// Changes returns the number of rows inserted, updated or deleted by the most recently completed
+// INSERT, UPDATE or DELETE statement of the connection (sqlite3_changes64). Other statements leave it unchanged,
+// and changes made by triggers or foreign key actions are not counted.
+// The counter belongs to the physical connection, so it's meaningful only on a dedicated sql.Conn (through Raw):
+// with *sql.DB the pool may run the statement and the call on different connections.
+func (c *tursoDbConnection) Changes() (int64, error) {
+	if err := c.checkOpen(); err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return turso_connection_changes(c.conn), nil
+}
+
+// TotalChanges returns the number of rows inserted, updated or deleted by all statements completed
+// on the connection since it was opened, including changes made by triggers (sqlite3_total_changes64).
+// Like Changes, the counter belongs to the physical connection.
+func (c *tursoDbConnection) TotalChanges() (int64, error) {
+	if err := c.checkOpen(); err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return turso_connection_total_changes(c.conn), nil
+}
+
+// ConnStats holds cumulative counters of a connection returned by ConnStats.
+type ConnStats struct {
+	// Statements is the number of statements which ran to completion
+	Statements int64
+	// VMSteps is the number of VM instructions executed by the statements
+	VMSteps int64
+	// FullscanSteps is the number of rows visited by full table scans: a large value hints at a missing index
+	FullscanSteps int64
+	// Sorts is the number of sort operations of the statements
+	Sorts int64
+	// RowsRead and RowsWritten are the numbers of rows read and written by the statements
+	RowsRead    int64
+	RowsWritten int64
+	// CacheSize is the page cache size reported by PRAGMA cache_size: number of pages if positive, KiB if negative
+	CacheSize int64
+	// ProfiledStatements and ProfiledTime are the number and the total time of the statements
+	// measured while profiling was enabled with _profile or SetProfileHook
+	ProfiledStatements int64
+	ProfiledTime       time.Duration
+}
+
+// ConnStats returns cumulative counters of the statements run on the connection since it was opened.
+// The library has no lookaside allocator and doesn't count page cache hits and misses (sqlite3_db_status),
+// so only the configured cache size is reported for the page cache.
+// Like Changes, the counters belong to the physical connection.
+func (c *tursoDbConnection) ConnStats() (ConnStats, error) {
+	if err := c.checkOpen(); err != nil {
+		return ConnStats{}, err
+	}
+	c.mu.Lock()
+	m := turso_connection_metrics(c.conn)
+	c.mu.Unlock()
+	stats := ConnStats{
+		Statements:         m.statements,
+		VMSteps:            m.vmSteps,
+		FullscanSteps:      m.fullscanSteps,
+		Sorts:              m.sortOperations,
+		RowsRead:           m.rowsRead,
+		RowsWritten:        m.rowsWritten,
+		ProfiledStatements: c.profiledStatements.Load(),
+		ProfiledTime:       time.Duration(c.profiledNanos.Load()),
+	}
+	// the pragma runs after the metrics are taken, so it isn't counted itself
+	cacheSize, err := c.GetPragmaInt("cache_size")
+	if err != nil {
+		return ConnStats{}, err
+	}
+	stats.CacheSize = cacheSize
+	return stats, nil
+}
+
+// walPosition is a WAL position of the connection; positions are ordered by checkpoint sequence first.
+type walPosition struct {
+	checkpointSeq uint32
+	maxFrame      uint64
+}
+
+func (p walPosition) before(other walPosition) bool {
+	if p.checkpointSeq != other.checkpointSeq {
+		return p.checkpointSeq < other.checkpointSeq
+	}
+	return p.maxFrame < other.maxFrame
+}
+
+// readYourWrites tracks position of the latest write committed by any connection of the pool,
+// so reads started later on any other connection of the pool observe it (see _read_your_writes DSN option).
+type readYourWrites struct {
+	mu     sync.Mutex
+	latest walPosition
+}
+
+func (r *readYourWrites) observe(pos walPosition) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.latest.before(pos) {
+		r.latest = pos
+	}
+}
+
+// reset forgets the latest write position: used when WAL is rewritten in a way which incorporates all previous writes
+func (r *readYourWrites) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latest = walPosition{}
+}
+
+func (r *readYourWrites) latestWrite() walPosition {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.latest
+}
+
+// amount of statement restarts before the read gives up with ErrTursoStaleRead
+const readYourWritesMaxRestarts = 3
+
+func (c *tursoDbConnection) walPosition() walPosition {
+	checkpointSeq, maxFrame := turso_connection_wal_position(c.conn)
+	return walPosition{checkpointSeq: checkpointSeq, maxFrame: maxFrame}
+}
+
+// CurrentFrame returns the position of the latest transaction committed to the local database of an embedded replica,
+// whether by a local write or by applying changes pulled by a sync: the WAL generation (incremented whenever
+// a checkpoint restarts the WAL) and the last committed frame within it. Positions are ordered by generation first,
+// so a follower caught up to a known position once its (generation, frame) is not behind it.
+// The position is read within a short read transaction of the library, so it's always the one of a committed
+// transaction; CurrentFrame fails inside a transaction and while a sync operation is in progress, and on connections
+// which aren't opened by TursoSyncDb it fails with ErrTursoNotReplica. The revision of the remote database pulled last
+// is reported by TursoSyncDbStats.SyncedRevision. Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) CurrentFrame() (generation uint64, frame uint64, err error) {
+	if !c.replica {
+		return 0, 0, ErrTursoNotReplica
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.conn == nil {
+		return 0, 0, ErrTursoConnClosed
+	}
+	if c.inTransaction() {
+		return 0, 0, errors.New("turso: can't read current frame inside a transaction")
+	}
+	checkpointSeq, maxFrame, err := turso_connection_wal_commit_position(c.conn)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint64(checkpointSeq), maxFrame, nil
+}
+
+// observeWrite publishes position of the connection to the pool once its changes are committed
+func (c *tursoDbConnection) observeWrite() {
+	if c.ryw == nil || !turso_connection_get_autocommit(c.conn) {
+		return
+	}
+	c.ryw.observe(c.walPosition())
+}
+
+// queryRow runs single-statement query and returns its first row (nil if query returned no rows)
+func (c *tursoDbConnection) queryRow(ctx context.Context, query string, args []driver.NamedValue) ([]driver.Value, error) {
+	rows, err := c.query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	row := make([]driver.Value, len(rows.Columns()))
+	if err := rows.Next(row); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return row, nil
+}
+
+// LoadExtension loads the extension library at path into the connection.
+// The library uses its native extension loader, which always calls the register_extension entry point:
+// pass empty entryPoint (or "register_extension"), other entry points are not supported.
+// Loading is permitted only if the database is opened with _allow_load_extension=true, otherwise ErrTursoLoadExtensionDisabled is returned.
+// Loaded library is never unloaded, so the extension stays available for the whole connection lifetime.
+// Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) LoadExtension(path, entryPoint string) error {
+	if entryPoint != "" && entryPoint != "register_extension" {
+		return fmt.Errorf("turso: unsupported extension entry point %q: the library calls register_extension", entryPoint)
+	}
+	if strings.IndexByte(path, 0) >= 0 {
+		return fmt.Errorf("turso: invalid extension path %q", path)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.conn == nil {
+		return ErrTursoConnClosed
+	}
+	if !c.allowLoadExtension {
+		return ErrTursoLoadExtensionDisabled
+	}
+	// the error of the library carries the reason reported by the dynamic loader
+	if err := turso_connection_load_extension(c.conn, path); err != nil {
+		return fmt.Errorf("turso: load extension %q: %w", path, err)
+	}
+	return nil
+}
+
+// BackupToFile writes a consistent snapshot of the main database to a new database file at path.
+// The snapshot is read in a single read transaction, so writers on other connections aren't blocked
+// and their commits made during the backup don't get into the copy.
+// It fails if the file already exists or if the connection is inside a transaction.
+// Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) BackupToFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("turso: invalid backup path %q", path)
+	}
+	if err := c.checkOpen(); err != nil {
+		return err
+	}
+	return c.vacuumInto("main", path)
+}
+
+// vacuumInto writes a snapshot of the database schema to a new database file at path with VACUUM INTO.
+// The output is never encrypted, even if the database is.
+func (c *tursoDbConnection) vacuumInto(schema, path string) error {
+	file, err := sqlLiteral(path)
+	if err != nil {
+		return fmt.Errorf("turso: invalid path: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, _, err = c.exec(context.Background(), "VACUUM "+quoteIdentifier(schema)+" INTO "+file, nil)
+	return err
+}
+
+// BackupHandle copies a database page by page, see Backup. It is not safe for concurrent use.
+type BackupHandle struct {
+	src          *tursoDbConnection
+	dst          *tursoDbConnection
+	dstConn      *sql.Conn
+	backup       TursoBackup
+	pagesPerStep int32
+}
+
+// Backup starts an online backup of the srcName database of the connection into the dstName database of dst
+// (mirrors sqlite3_backup_init); only "main" databases are supported and dst must be opened with this driver.
+// Every BackupHandle.Step copies up to pagesPerStep pages (all remaining pages if pagesPerStep <= 0) from a consistent
+// snapshot of the source, so writers aren't blocked between and during steps; if the source changed since the previous
+// step, the copy restarts from the first page. The destination is written in a single transaction which is committed
+// by the step copying the last page: its readers see either the old content or the complete copy, and its writers
+// wait until the backup completes or BackupHandle.Finish is called.
+// The source and destination page sizes must match. Steps fail if the source connection is inside a transaction.
+// Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) Backup(dst *sql.DB, dstName, srcName string, pagesPerStep int) (*BackupHandle, error) {
+	if pagesPerStep <= 0 || pagesPerStep > math.MaxInt32 {
+		pagesPerStep = -1
+	}
+	// the destination connection is reserved for the whole backup as its write transaction spans the steps
+	dstConn, err := dst.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	var dstDriver *tursoDbConnection
+	err = dstConn.Raw(func(driverConn any) error {
+		conn, ok := driverConn.(*tursoDbConnection)
+		if !ok {
+			return fmt.Errorf("turso: backup destination must be a turso database, got %T", driverConn)
+		}
+		dstDriver = conn
+		return nil
+	})
+	if err == nil {
+		var backup TursoBackup
+		backup, err = c.backupInit(dstDriver, dstName, srcName)
+		if err == nil {
+			return &BackupHandle{src: c, dst: dstDriver, dstConn: dstConn, backup: backup, pagesPerStep: int32(pagesPerStep)}, nil
+		}
+	}
+	_ = dstConn.Close()
+	return nil, err
+}
+
+func (c *tursoDbConnection) backupInit(dst *tursoDbConnection, dstName, srcName string) (TursoBackup, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dst.mu.Lock()
+	defer dst.mu.Unlock()
+	if c.closed || c.conn == nil || dst.closed || dst.conn == nil {
+		return nil, ErrTursoConnClosed
+	}
+	return turso_backup_init(dst.conn, dstName, c.conn, srcName)
+}
+
+// Step copies the next batch of pages and returns the number of pages left to copy and the number of pages
+// in the source; the backup is complete once remaining is 0 (further steps are no-op).
+// A failed step rolls back the pages copied so far and the next step starts the copy over.
+func (b *BackupHandle) Step() (remaining, total int, err error) {
+	if b.backup == nil {
+		return 0, 0, errors.New("turso: backup is finished")
+	}
+	b.src.mu.Lock()
+	defer b.src.mu.Unlock()
+	b.dst.mu.Lock()
+	defer b.dst.mu.Unlock()
+	if b.src.closed || b.src.conn == nil || b.dst.closed || b.dst.conn == nil {
+		return 0, 0, ErrTursoConnClosed
+	}
+	_, left, pages, err := turso_backup_step(b.backup, b.pagesPerStep)
+	return int(left), int(pages), err
+}
+
+// Finish releases the backup (mirrors sqlite3_backup_finish); if the backup isn't complete,
+// the destination is left as it was before the backup. Finish must be called even if Step failed.
+func (b *BackupHandle) Finish() error {
+	if b.backup == nil {
+		return nil
+	}
+	b.dst.mu.Lock()
+	turso_backup_deinit(b.backup)
+	b.backup = nil
+	b.dst.mu.Unlock()
+	return b.dstConn.Close()
+}
+
+// Serialize returns a snapshot of the main database as the bytes of a database file (mirrors sqlite3_serialize):
+// pages are read in memory from a single read snapshot, so in-memory databases are serialized as well and nothing
+// is written to disk. Only the "main" schema can be serialized and it fails if the connection is inside a transaction.
+// Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) Serialize(schema string) ([]byte, error) {
+	if !strings.EqualFold(schema, "main") {
+		return nil, fmt.Errorf("turso: can't serialize schema %q: only main is supported", schema)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.conn == nil {
+		return nil, ErrTursoConnClosed
+	}
+	return turso_connection_serialize(c.conn, schema)
+}
+
+// Deserialize replaces the content of the main database with the database file data (e.g. returned by Serialize)
+// (mirrors sqlite3_deserialize): data is checked first and its pages are committed in a single transaction,
+// so the pool sees either the old content or data, and invalid data leaves the database as it was.
+// Data which is not a database file fails with an error matching ErrTursoNotADb. Only the "main" schema can be
+// replaced and the page size of data must match the one of the database unless the database is still empty.
+// It fails if the connection is inside a transaction. Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) Deserialize(schema string, data []byte) error {
+	if !strings.EqualFold(schema, "main") {
+		return fmt.Errorf("turso: can't deserialize into schema %q: only main is supported", schema)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.conn == nil {
+		return ErrTursoConnClosed
+	}
+	return turso_connection_deserialize(c.conn, schema, data)
+}
+
+// bulkInsertMaxParams limits number of parameters bound to a single statement by BulkInsert (SQLite default SQLITE_MAX_VARIABLE_NUMBER)
+const bulkInsertMaxParams = 999
+
+// BulkInsert inserts rows into table with multi-row INSERT statements of up to batchSize rows each and returns number of inserted rows.
+// Batches are split further so a statement never binds more than 999 parameters; batchSize 0 picks the largest batch.
+// Table and column names are quoted as identifiers; every row must have a value for each column.
+// All rows are inserted atomically under a savepoint: outside of transaction it's a transaction of its own,
+// inside of transaction a failure reverts only the rows of this call.
+// Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) BulkInsert(ctx context.Context, table string, columns []string, rows [][]any, batchSize int) (int64, error) {
+	if table == "" || len(columns) == 0 {
+		return 0, errors.New("turso: bulk insert needs a table and at least one column")
+	}
+	if len(columns) > bulkInsertMaxParams {
+		return 0, fmt.Errorf("turso: bulk insert of %d columns exceeds the limit of %d parameters", len(columns), bulkInsertMaxParams)
+	}
+	if batchSize < 0 {
+		return 0, fmt.Errorf("turso: invalid bulk insert batch size %d", batchSize)
+	}
+	if limit := bulkInsertMaxParams / len(columns); batchSize == 0 || batchSize > limit {
+		batchSize = limit
+	}
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return 0, fmt.Errorf("turso: bulk insert row %d has %d values, expected %d (one per column)", i, len(row), len(columns))
+		}
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+	if err := c.checkOpen(); err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	inserted, err := c.bulkInsert(ctx, table, columns, rows, batchSize)
+	if err != nil {
+		return 0, c.concurrentError(err)
+	}
+	c.observeWrite()
+	return inserted, nil
+}
+
+// bulkInsert runs batches of BulkInsert under a savepoint which is rolled back on failure
+// caller must hold c.mu
+func (c *tursoDbConnection) bulkInsert(ctx context.Context, table string, columns []string, rows [][]any, batchSize int) (int64, error) {
+	const savepoint = `"turso_bulk_insert"`
+	if _, _, err := c.exec(ctx, "SAVEPOINT "+savepoint, nil); err != nil {
+		return 0, err
+	}
+	stop := c.interruptOnDone(ctx)
+	inserted, err := c.insertBatches(ctx, table, columns, rows, batchSize)
+	stop()
+	if err = c.collationError(err); err != nil {
+		// ctx is not watched anymore, so the rollback runs to completion even if ctx is done
+		_, _, _ = c.exec(context.Background(), "ROLLBACK TO SAVEPOINT "+savepoint, nil)
+		_, _, _ = c.exec(context.Background(), "RELEASE SAVEPOINT "+savepoint, nil)
+		return 0, ctxError(ctx, err)
+	}
+	if _, _, err := c.exec(ctx, "RELEASE SAVEPOINT "+savepoint, nil); err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+// insertBatches executes INSERT statement for each batch of rows; statement is prepared once per distinct batch width
+func (c *tursoDbConnection) insertBatches(ctx context.Context, table string, columns []string, rows [][]any, batchSize int) (int64, error) {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIdentifier(column)
+	}
+	prefix := "INSERT INTO " + quoteIdentifier(table) + " (" + strings.Join(quoted, ", ") + ") VALUES "
+	tuple := "(" + strings.Repeat("?, ", len(columns)-1) + "?)"
+
+	stmts := make(map[int]TursoStatement)
+	defer func() {
+		for _, stmt := range stmts {
+			_ = c.finalize(stmt)
+		}
+	}()
+	var inserted int64
+	for start := 0; start < len(rows); start += batchSize {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		batch := rows[start:min(start+batchSize, len(rows))]
+		stmt, ok := stmts[len(batch)]
+		if ok {
+			if err := turso_statement_reset(stmt); err != nil {
+				return 0, err
+			}
+		} else {
+			query := prefix + strings.Repeat(tuple+", ", len(batch)-1) + tuple
+			var err error
+			if stmt, err = turso_connection_prepare_single(c.conn, query); err != nil {
+				return 0, err
+			}
+			stmts[len(batch)] = stmt
+		}
+		position := 1
+		for i, row := range batch {
+			for j, value := range row {
+				v, err := bulkInsertValue(value)
+				if err != nil {
+					return 0, fmt.Errorf("turso: bulk insert row %d, column %s: %w", start+i, columns[j], err)
+				}
+				if err := bindOne(stmt, position, v, c.timeFormat); err != nil {
+					return 0, err
+				}
+				position++
+			}
+		}
+		affected, err := c.executeFully(ctx, stmt)
+		if err != nil {
+			return 0, err
+		}
+		inserted += int64(affected)
+	}
+	return inserted, nil
+}
+
+// bulkInsertValue converts value in the same way as database/sql converts arguments of the driver
+func bulkInsertValue(value any) (any, error) {
+	nv := driver.NamedValue{Value: value}
+	if err := checkNamedValue(&nv); err == nil {
+		// checkNamedValue may rewrite the value (e.g. *big.Int into int64 or Numeric)
+		return nv.Value, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(value)
+}
+
+// SetPragma sets PRAGMA name (optionally qualified with schema as "schema.name") to value on this connection.
+// value is bool, integer, float or string: strings of letters, digits and underscores are passed as keywords (e.g. WAL),
+// other strings as quoted literals.
+// The library handles "PRAGMA name = value" and "PRAGMA name(value)" in the same way.
+// PRAGMAs are connection-local, so use a dedicated sql.Conn to reach the method with sql.Conn.Raw.
+func (c *tursoDbConnection) SetPragma(name string, value any) error {
+	if err := validatePragmaName(name); err != nil {
+		return err
+	}
+	arg, ok := value.(string)
+	if !ok || !isKeyword(arg) {
+		var err error
+		if arg, err = sqlLiteral(value); err != nil {
+			return fmt.Errorf("turso: invalid value for pragma %s: %w", name, err)
+		}
+	}
+	if err := c.checkOpen(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// some PRAGMAs report the applied value as a row: exec consumes and drops it
+	_, _, err := c.exec(context.Background(), "PRAGMA "+name+" = "+arg, nil)
+	return err
+}
+
+// GetPragmaInt returns the value of PRAGMA name as integer.
+func (c *tursoDbConnection) GetPragmaInt(name string) (int64, error) {
+	value, err := c.pragma(name)
+	if err != nil {
+		return 0, err
+	}
+	switch x := value.(type) {
+	case int64:
+		return x, nil
+	case float64:
+		return int64(x), nil
+	case string:
+		i, err := strconv.ParseInt(x, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("turso: pragma %s returned non-integer value %q", name, x)
+		}
+		return i, nil
+	default:
+		return 0, fmt.Errorf("turso: pragma %s returned non-integer value %v", name, value)
+	}
+}
+
+// GetPragmaString returns the value of PRAGMA name as string.
+func (c *tursoDbConnection) GetPragmaString(name string) (string, error) {
+	value, err := c.pragma(name)
+	if err != nil {
+		return "", err
+	}
+	switch x := value.(type) {
+	case string:
+		return x, nil
+	case []byte:
+		return string(x), nil
+	default:
+		return fmt.Sprint(x), nil
+	}
+}
+
+// JournalMode returns the journal mode of the main database (e.g. "wal").
+func (c *tursoDbConnection) JournalMode() (string, error) {
+	return c.GetPragmaString("journal_mode")
+}
+
+// SetJournalMode switches the journal mode of the main database and fails if the library keeps another mode.
+func (c *tursoDbConnection) SetJournalMode(mode string) error {
+	if err := c.SetPragma("journal_mode", mode); err != nil {
+		return err
+	}
+	applied, err := c.JournalMode()
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(applied, mode) {
+		return fmt.Errorf("turso: journal mode %q is not applied, current mode is %q", mode, applied)
+	}
+	return nil
+}
+
+// Synchronous returns the synchronous setting: 0 (OFF), 1 (NORMAL), 2 (FULL) or 3 (EXTRA).
+func (c *tursoDbConnection) Synchronous() (int64, error) {
+	return c.GetPragmaInt("synchronous")
+}
+
+// ForeignKeys enables or disables enforcement of foreign key constraints on this connection.
+func (c *tursoDbConnection) ForeignKeys(on bool) error {
+	return c.SetPragma("foreign_keys", on)
+}
+
+// CacheSize sets the suggested number of pages in the page cache; negative value sets the size in KiB instead.
+func (c *tursoDbConnection) CacheSize(pages int) error {
+	return c.SetPragma("cache_size", pages)
+}
+
+// DefaultCipher is the cipher SetKey encrypts the database with if the DSN doesn't set _cipher.
+const DefaultCipher = "aegis256"
+
+// SetKey sets the encryption key of the database on this connection (PRAGMA cipher and PRAGMA hexkey);
+// the key size must match the cipher, e.g. 32 bytes for aegis256 and aes256gcm, 16 bytes for aes128gcm.
+// It must be called right after the connection is opened, before any other statement, and only once:
+// the key of an encrypted database is changed with Rekey instead. Encryption is experimental,
+// so the DSN must enable it with experimental=encryption.
+// The key belongs to the physical connection, so pools should rather set it with _key and _cipher parameters of the DSN.
+// Reading a database with a wrong key fails with an error which matches ErrTursoNotADb.
+func (c *tursoDbConnection) SetKey(key []byte) error {
+	if len(key) == 0 {
+		return fmt.Errorf("turso: encryption key is empty")
+	}
+	if err := c.checkOpen(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cipher := c.cipher
+	if cipher == "" {
+		cipher = DefaultCipher
+	}
+	literal, err := sqlLiteral(cipher)
+	if err != nil {
+		return fmt.Errorf("turso: invalid cipher: %w", err)
+	}
+	if _, _, err := c.exec(context.Background(), "PRAGMA cipher = "+literal, nil); err != nil {
+		return err
+	}
+	hexkey, _ := sqlLiteral(hex.EncodeToString(key))
+	_, _, err = c.exec(context.Background(), "PRAGMA hexkey = "+hexkey, nil)
+	return err
+}
+
+// Rekey re-encrypts the database of this connection with a new key in place; the key size must match the cipher
+// the database is encrypted with. The database is rewritten in a single transaction, so a failure leaves it
+// encrypted with the old key. The rewrite runs as in-place VACUUM, so the DSN must enable it along with
+// encryption: experimental=encryption,vacuum. Other connections to the database, including idle connections
+// of the pool, keep the old key and stop working: reopen the pool with the new key in the DSN after Rekey.
+func (c *tursoDbConnection) Rekey(newKey []byte) error {
+	if len(newKey) == 0 {
+		return fmt.Errorf("turso: encryption key is empty")
+	}
+	if err := c.checkOpen(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return turso_connection_rekey(c.conn, hex.EncodeToString(newKey))
+}
+
+// pragma returns the first column of the first row returned by PRAGMA name
+func (c *tursoDbConnection) pragma(name string) (driver.Value, error) {
+	if err := validatePragmaName(name); err != nil {
+		return nil, err
+	}
+	row, err := c.queryRow(context.Background(), "PRAGMA "+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(row) == 0 {
+		return nil, fmt.Errorf("turso: pragma %s returned no value", name)
+	}
+	return row[0], nil
+}
+
+// validatePragmaName accepts PRAGMA name optionally qualified with schema name
+func validatePragmaName(name string) error {
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return fmt.Errorf("turso: invalid pragma name %q", name)
+	}
+	for _, part := range parts {
+		if !isKeyword(part) {
+			return fmt.Errorf("turso: invalid pragma name %q", name)
+		}
+	}
+	return nil
+}
+
+// isKeyword reports whether s is a non-empty sequence of ASCII letters, digits and underscores
+func isKeyword(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if !(b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckpointMode selects how much work Checkpoint does (see PRAGMA wal_checkpoint).
+type CheckpointMode int
+
+const (
+	// CheckpointPassive checkpoints as many frames as possible without waiting for readers or writers.
+	CheckpointPassive CheckpointMode = iota
+	// CheckpointFull waits for the writer, then checkpoints all frames; it waits for readers using busy handler.
+	CheckpointFull
+	// CheckpointRestart works like CheckpointFull and also waits for readers so the next writer restarts the WAL.
+	CheckpointRestart
+	// CheckpointTruncate works like CheckpointRestart and also truncates the WAL file to zero bytes.
+	CheckpointTruncate
+)
+
+func (m CheckpointMode) String() string {
+	switch m {
+	case CheckpointPassive:
+		return "PASSIVE"
+	case CheckpointFull:
+		return "FULL"
+	case CheckpointRestart:
+		return "RESTART"
+	case CheckpointTruncate:
+		return "TRUNCATE"
+	default:
+		return fmt.Sprintf("CheckpointMode(%d)", int(m))
+	}
+}
+
+// Checkpoint runs WAL checkpoint of the given mode and returns the frame counts reported by the library:
+// busyFrames is non-zero if the checkpoint couldn't complete because other connections hold locks
+// and logFrames is the number of frames in the WAL (-1 if the checkpoint was not attempted).
+// FULL/RESTART/TRUNCATE don't block forever when another connection holds a read lock:
+// they wait at most for the connection busy timeout and then report busyFrames = 1 with nil error.
+// Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) Checkpoint(mode CheckpointMode) (busyFrames, logFrames int, err error) {
+	if mode < CheckpointPassive || mode > CheckpointTruncate {
+		return 0, 0, fmt.Errorf("turso: invalid checkpoint mode %d", int(mode))
+	}
+	row, err := c.queryRow(context.Background(), "PRAGMA wal_checkpoint("+mode.String()+")", nil)
+	if err != nil {
+		if IsBusy(err) {
+			return 1, -1, nil
+		}
+		return 0, 0, err
+	}
+	if len(row) < 2 {
+		return 0, 0, fmt.Errorf("turso: unexpected wal_checkpoint result %v", row)
+	}
+	busy, ok1 := row[0].(int64)
+	log, ok2 := row[1].(int64)
+	if !ok1 || !ok2 {
+		return 0, 0, fmt.Errorf("turso: unexpected wal_checkpoint result %v", row)
+	}
+	return int(busy), int(log), nil
+}
+
+// CreateScalarFunction registers fn as the SQL scalar function name taking nArgs arguments (-1 for any number).
+// Pass deterministic when fn always returns the same result for the same arguments so the planner can optimize calls.
+// fn receives nil, int64, float64, string or []byte arguments and can return any value accepted as a query argument.
+// Registering an existing name replaces the previous function; fn stays alive as long as the connection uses it.
+// Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) CreateScalarFunction(name string, nArgs int, deterministic bool, fn func(args []driver.Value) (driver.Value, error)) error {
+	if fn == nil {
+		return fmt.Errorf("turso: scalar function %q is nil", name)
+	}
+	if nArgs < -1 || nArgs > math.MaxInt32 {
+		return fmt.Errorf("turso: invalid number of arguments %d for scalar function %q", nArgs, name)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.conn == nil {
+		return ErrTursoConnClosed
+	}
+	return turso_connection_register_scalar_function_out(c.conn, name, int32(nArgs), deterministic, func(args []any) (any, error) {
+		values := make([]driver.Value, len(args))
+		for i, arg := range args {
+			values[i] = arg
+		}
+		result, err := fn(values)
+		if err != nil {
+			return nil, err
+		}
+		return scalarFunctionResult(result, c.timeFormat)
+	})
+}
+
+// RemoveFunction unregisters the function name previously registered on this connection.
+func (c *tursoDbConnection) RemoveFunction(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.conn == nil {
+		return ErrTursoConnClosed
+	}
+	return turso_connection_unregister_function(c.conn, name)
+}
+
+// CreateCollation registers cmp as the collation name usable in COLLATE clauses of queries, column definitions and indexes.
+// cmp receives TEXT values decoded as UTF-8 and returns a negative number, zero or a positive number when a sorts before, equal to or after b.
+// Registering an existing name replaces the previous collation; cmp stays alive as long as the connection uses it.
+// The collation exists only on this connection, so every connection which reads an index built with it must register it too.
+// A panic in cmp interrupts the running statement, which then fails with the recovered panic as the error.
+// Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) CreateCollation(name string, cmp func(a, b string) int) error {
+	if cmp == nil {
+		return fmt.Errorf("turso: collation %q is nil", name)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.conn == nil {
+		return ErrTursoConnClosed
+	}
+	conn := c.conn
+	return turso_connection_register_collation(conn, name, func(a, b string) (result int) {
+		defer func() {
+			if r := recover(); r != nil {
+				err := fmt.Errorf("turso: collation %q panicked: %v", name, r)
+				c.collationPanic.CompareAndSwap(nil, &err)
+				turso_connection_interrupt(conn)
+				result = 0
+			}
+		}()
+		return cmp(a, b)
+	})
+}
+
+// RemoveCollation unregisters the collation name previously registered on this connection.
+func (c *tursoDbConnection) RemoveCollation(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.conn == nil {
+		return ErrTursoConnClosed
+	}
+	return turso_connection_unregister_collation(c.conn, name)
+}
+
+// Op is the kind of row change reported to the update hook.
+type Op int32
+
+// Values match the SQLite action codes.
+const (
+	OpInsert Op = 18
+	OpUpdate Op = 23
+	OpDelete Op = 9
+)
+
+func (op Op) String() string {
+	switch op {
+	case OpInsert:
+		return "INSERT"
+	case OpUpdate:
+		return "UPDATE"
+	case OpDelete:
+		return "DELETE"
+	default:
+		return fmt.Sprintf("Op(%d)", int32(op))
+	}
+}
+
+// SetUpdateHook sets fn to run after a row of a rowid table is inserted, updated or deleted on this connection;
+// nil clears the hook. fn receives the database name ("main", "temp" or the attach alias), table name and rowid.
+// Changes of internal tables (e.g. sqlite_sequence) and of WITHOUT ROWID tables are not reported.
+// Hooks run synchronously while the statement executes and the connection is busy, so they must not use it;
+// a panic in a hook is recovered and ignored.
+// Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) SetUpdateHook(fn func(op Op, db, table string, rowid int64)) error {
+	var hook TursoUpdateHook
+	if fn != nil {
+		hook = func(op int32, database, table string, rowid int64) {
+			defer recoverHook()
+			fn(Op(op), database, table, rowid)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.conn == nil {
+		return ErrTursoConnClosed
+	}
+	return turso_connection_set_update_hook(c.conn, hook)
+}
+
+// SetCommitHook sets fn to run after a write transaction of this connection commits; nil clears the hook.
+// Unlike sqlite3_commit_hook, fn can't turn the commit into a rollback. See SetUpdateHook for the restrictions.
+func (c *tursoDbConnection) SetCommitHook(fn func()) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.conn == nil {
+		return ErrTursoConnClosed
+	}
+	return turso_connection_set_commit_hook(c.conn, transactionHook(fn))
+}
+
+// SetRollbackHook sets fn to run after a write transaction of this connection rolls back; nil clears the hook.
+// Read-only transactions report neither commit nor rollback. See SetUpdateHook for the restrictions.
+func (c *tursoDbConnection) SetRollbackHook(fn func()) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.conn == nil {
+		return ErrTursoConnClosed
+	}
+	return turso_connection_set_rollback_hook(c.conn, transactionHook(fn))
+}
+
+func transactionHook(fn func()) TursoTransactionHook {
+	if fn == nil {
+		return nil
+	}
+	return func() {
+		defer recoverHook()
+		fn()
+	}
+}
+
+// Action is the kind of access reported to the authorizer.
+type Action int32
+
+// Values match the SQLite action codes; see SetAuthorizer for the arguments of every action.
+const (
+	ActionCreateIndex       Action = 1
+	ActionCreateTable       Action = 2
+	ActionCreateTempIndex   Action = 3
+	ActionCreateTempTable   Action = 4
+	ActionCreateTempTrigger Action = 5
+	ActionCreateTempView    Action = 6
+	ActionCreateTrigger     Action = 7
+	ActionCreateView        Action = 8
+	ActionDelete            Action = 9
+	ActionDropIndex         Action = 10
+	ActionDropTable         Action = 11
+	ActionDropTempIndex     Action = 12
+	ActionDropTempTable     Action = 13
+	ActionDropTempTrigger   Action = 14
+	ActionDropTempView      Action = 15
+	ActionDropTrigger       Action = 16
+	ActionDropView          Action = 17
+	ActionInsert            Action = 18
+	ActionPragma            Action = 19
+	ActionRead              Action = 20
+	ActionSelect            Action = 21
+	ActionTransaction       Action = 22
+	ActionUpdate            Action = 23
+	ActionAttach            Action = 24
+	ActionDetach            Action = 25
+	ActionAlterTable        Action = 26
+	ActionReindex           Action = 27
+	ActionAnalyze           Action = 28
+	ActionCreateVtable      Action = 29
+	ActionFunction          Action = 31
+	ActionSavepoint         Action = 32
+)
+
+// AuthResult is the decision of the authorizer.
+type AuthResult int32
+
+// Values match SQLITE_OK, SQLITE_DENY and SQLITE_IGNORE.
+const (
+	// AuthAllow lets the statement make the access.
+	AuthAllow AuthResult = 0
+	// AuthDeny fails the statement preparation with "not authorized" error.
+	AuthDeny AuthResult = 1
+	// AuthIgnore prepares the statement as a no-op; for ActionRead it behaves as AuthDeny.
+	AuthIgnore AuthResult = 2
+)
+
+// SetAuthorizer sets fn to be consulted for every access a statement makes when it is prepared on this connection;
+// nil clears the authorizer. Arguments follow sqlite3_set_authorizer, with empty strings for missing values:
+//   - ActionCreate*Index, ActionDrop*Index: index name, table name
+//   - ActionCreate*Table, ActionDrop*Table, ActionInsert, ActionDelete, ActionAnalyze: table name
+//   - ActionCreate*Trigger, ActionDrop*Trigger: trigger name, table name
+//   - ActionCreate*View, ActionDrop*View: view name
+//   - ActionCreateVtable: table name, module name
+//   - ActionRead, ActionUpdate: table name, column name
+//   - ActionPragma: pragma name, argument
+//   - ActionTransaction: "BEGIN", "COMMIT" or "ROLLBACK"
+//   - ActionSavepoint: "BEGIN", "RELEASE" or "ROLLBACK", savepoint name
+//   - ActionAttach: file name; ActionDetach: database name
+//   - ActionAlterTable: database name, table name
+//   - ActionReindex: index name
+//   - ActionFunction: empty, function name
+//
+// Reads are reported once per table with an empty column name. Accesses made through views and triggers
+// of the main database are reported too, with the name of the inner-most view or trigger as trigger;
+// returning AuthIgnore for them denies the statement as a part of it can't be skipped.
+// A denied statement fails with an error naming the action and the object, e.g. `not authorized: Read "secret"`.
+// Statements are checked when they are prepared (including re-preparation after schema changes),
+// so setting the authorizer drops the idle statements of the statement cache; statements prepared earlier
+// keep running unchecked. fn must not use the connection; a panic in fn is recovered and denies the access.
+// Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) SetAuthorizer(fn func(action Action, arg1, arg2, dbName, trigger string) AuthResult) error {
+	var authorizer TursoAuthorizer
+	if fn != nil {
+		authorizer = func(action int32, arg1, arg2, database, trigger string) (result int32) {
+			result = int32(AuthDeny)
+			defer recoverHook()
+			return int32(fn(Action(action), arg1, arg2, database, trigger))
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.conn == nil {
+		return ErrTursoConnClosed
+	}
+	if err := turso_connection_set_authorizer(c.conn, authorizer); err != nil {
+		return err
+	}
+	if c.stmts != nil {
+		for _, stmt := range c.stmts.evictAll() {
+			_ = c.finalize(stmt)
+		}
+	}
+	return nil
+}
+
+// recoverHook drops the panic of a hook as it can't unwind through the library
+func recoverHook() {
+	_ = recover()
+}
+
+// collationError reports the panic of the collation instead of the interruption it caused.
+// The statement fails even if it completed before the interruption took effect, as its result can be wrong.
+func (c *tursoDbConnection) collationError(err error) error {
+	panicErr := c.collationPanic.Swap(nil)
+	if panicErr == nil {
+		return err
+	}
+	if err == nil || errors.Is(err, ErrTursoInterrupt) {
+		return *panicErr
+	}
+	return err
+}
+
+// scalarFunctionResult converts value returned from user-defined function to one of the types which library accepts
+func scalarFunctionResult(value driver.Value, timeFormat TimeFormat) (any, error) {
+	value, err := driver.DefaultParameterConverter.ConvertValue(value)
+	if err != nil {
+		return nil, err
+	}
+	switch x := value.(type) {
+	case bool:
+		if x {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case time.Time:
+		// encode in the same way as bindOne
+		return timeFormat.encode(x), nil
+	default:
+		return x, nil
+	}
+}
+
+// Numeric is an arbitrary-precision decimal number (e.g. "-1234.5678" or "1e-40") kept as its text:
+// it is bound as TEXT, so no float rounding ever happens. Store it in TEXT (or untyped) columns:
+// columns of NUMERIC affinity (e.g. DECIMAL(10,2)) convert such text into INTEGER or REAL.
+// As a scan destination it accepts TEXT holding a decimal number as well as INTEGER and REAL values.
+type Numeric string
+
+var (
+	_ driver.Valuer = Numeric("")
+	_ sql.Scanner   = (*Numeric)(nil)
+)
+
+// Value implements driver.Valuer; it fails if n is not a decimal number.
+func (n Numeric) Value() (driver.Value, error) {
+	if !isDecimal(string(n)) {
+		return nil, fmt.Errorf("turso: invalid Numeric %q: not a decimal number", string(n))
+	}
+	return string(n), nil
+}
+
+// Scan implements sql.Scanner.
+func (n *Numeric) Scan(src any) error {
+	switch x := src.(type) {
+	case int64:
+		*n = Numeric(strconv.FormatInt(x, 10))
+	case float64:
+		*n = Numeric(strconv.FormatFloat(x, 'g', -1, 64))
+	case string, []byte:
+		text := asString(x)
+		if !isDecimal(text) {
+			return fmt.Errorf("turso: can't scan %q into Numeric: not a decimal number", text)
+		}
+		*n = Numeric(text)
+	default:
+		return fmt.Errorf("turso: can't scan %T into Numeric", src)
+	}
+	return nil
+}
+
+// Rat returns the value of n as a rational number; ok is false if n is not a decimal number.
+func (n Numeric) Rat() (r *big.Rat, ok bool) {
+	if !isDecimal(string(n)) {
+		return nil, false
+	}
+	return new(big.Rat).SetString(string(n))
+}
+
+// BigInt binds and scans *big.Int values without loss: rows.Scan((*turso.BigInt)(n)) fills n of type *big.Int.
+// A *big.Int argument is bound as INTEGER if it fits into int64 and as decimal TEXT otherwise.
+// Scanning a value with a fractional part fails instead of truncating it, as does decimal text
+// with an exponent above 10000.
+type BigInt big.Int
+
+var (
+	_ driver.Valuer = (*BigInt)(nil)
+	_ sql.Scanner   = (*BigInt)(nil)
+)
+
+// Value implements driver.Valuer.
+func (b *BigInt) Value() (driver.Value, error) {
+	return bigIntValue((*big.Int)(b)), nil
+}
+
+// Scan implements sql.Scanner.
+func (b *BigInt) Scan(src any) error {
+	n := (*big.Int)(b)
+	switch x := src.(type) {
+	case int64:
+		n.SetInt64(x)
+	case float64:
+		if math.IsInf(x, 0) || math.IsNaN(x) || x != math.Trunc(x) {
+			return fmt.Errorf("turso: can't scan %v into big.Int: not an integer", x)
+		}
+		new(big.Float).SetFloat64(x).Int(n)
+	case string, []byte:
+		text := asString(x)
+		if _, ok := n.SetString(text, 10); ok {
+			return nil
+		}
+		if err := decimalToBigInt(n, text); err != nil {
+			return fmt.Errorf("turso: can't scan %q into big.Int: %w", text, err)
+		}
+	default:
+		return fmt.Errorf("turso: can't scan %T into big.Int", src)
+	}
+	return nil
+}
+
+// maxBigIntExponent limits the decimal exponent of the text scanned into BigInt,
+// so a short value like '1e999999999' can't make Scan allocate an enormous number
+const maxBigIntExponent = 10000
+
+// decimalToBigInt sets n to the integer value of the decimal text s with optional fraction and exponent;
+// it fails if s is not a decimal number, has a fractional part or its exponent exceeds maxBigIntExponent
+func decimalToBigInt(n *big.Int, s string) error {
+	if !isDecimal(s) {
+		return errors.New("not a number")
+	}
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa = s[:i]
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil && !errors.Is(err, strconv.ErrRange) || e > maxBigIntExponent {
+			return errors.New("exponent is out of range")
+		}
+		// trailing zeros of the mantissa can't cover a smaller exponent: clamp it to avoid overflow
+		exponent = max(e, -len(s))
+	}
+	sign := ""
+	if mantissa[0] == '+' || mantissa[0] == '-' {
+		sign, mantissa = mantissa[:1], mantissa[1:]
+	}
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		exponent -= len(mantissa) - i - 1
+		mantissa = mantissa[:i] + mantissa[i+1:]
+	}
+	// trailing zeros of the digits cover negative exponent without losing anything
+	digits := strings.TrimLeft(mantissa, "0")
+	for exponent < 0 && strings.HasSuffix(digits, "0") {
+		digits = digits[:len(digits)-1]
+		exponent++
+	}
+	if digits == "" {
+		n.SetInt64(0)
+		return nil
+	}
+	if exponent < 0 {
+		return errors.New("value has a fractional part")
+	}
+	if exponent > maxBigIntExponent {
+		return errors.New("exponent is out of range")
+	}
+	n.SetString(sign+digits, 10)
+	if exponent > 0 {
+		n.Mul(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil))
+	}
+	return nil
+}
+
+func bigIntValue(n *big.Int) driver.Value {
+	if n == nil {
+		return nil
+	}
+	if n.IsInt64() {
+		return n.Int64()
+	}
+	return n.String()
+}
+
+// isDecimal reports whether s is a decimal number: optional sign, digits with optional fraction and optional exponent
+func isDecimal(s string) bool {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	digits := 0
+	for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		digits++
+	}
+	if i < len(s) && s[i] == '.' {
+		for i++; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			digits++
+		}
+	}
+	if digits == 0 {
+		return false
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		exponent := 0
+		for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			exponent++
+		}
+		if exponent == 0 {
+			return false
+		}
+	}
+	return i == len(s)
+}
+
+func asString(v any) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v.(string)
+}
+
+// JSON returns an argument which binds the JSON encoding of v (json.Marshal) as TEXT,
+// for columns queried with the JSON functions: db.Exec("INSERT INTO docs VALUES (?)", turso.JSON(doc)).
+// Binding fails if v can't be encoded or its MarshalJSON method produces invalid JSON,
+// so malformed documents never reach the database.
+func JSON(v any) driver.Valuer {
+	return jsonValue{v: v}
+}
+
+type jsonValue struct {
+	v any
+}
+
+// Value implements driver.Valuer.
+func (j jsonValue) Value() (driver.Value, error) {
+	data, err := json.Marshal(j.v)
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("%w: can't bind %T: %w", ErrTursoInvalidJSON, j.v, err)
+		}
+		return nil, fmt.Errorf("turso: can't bind %T as JSON: %w", j.v, err)
+	}
+	return string(data), nil
+}
+
+// JSONInto returns a scan destination which decodes the JSON text of the column into v (json.Unmarshal):
+// rows.Scan(turso.JSONInto(&doc)). v must be a non-nil pointer. NULL leaves v unchanged.
+// INTEGER and REAL values decode as JSON numbers; JSONB blobs must be converted with json() in the query.
+// Scanning a value which is not valid JSON text fails with an error matching ErrTursoInvalidJSON.
+func JSONInto(v any) sql.Scanner {
+	return jsonScanner{v: v}
+}
+
+type jsonScanner struct {
+	v any
+}
+
+// Scan implements sql.Scanner.
+func (j jsonScanner) Scan(src any) error {
+	var data []byte
+	switch x := src.(type) {
+	case nil:
+		return nil
+	case int64:
+		data = strconv.AppendInt(nil, x, 10)
+	case float64:
+		data = strconv.AppendFloat(nil, x, 'g', -1, 64)
+	case string:
+		data = []byte(x)
+	case []byte:
+		data = x
+	default:
+		return fmt.Errorf("turso: can't scan %T as JSON", src)
+	}
+	if !json.Valid(data) {
+		return fmt.Errorf("%w: can't scan %q into %T", ErrTursoInvalidJSON, truncateText(string(data), 64), j.v)
+	}
+	if err := json.Unmarshal(data, j.v); err != nil {
+		return fmt.Errorf("turso: can't scan JSON into %T: %w", j.v, err)
+	}
+	return nil
+}
+
+// truncateText shortens text to at most n bytes for error messages
+func truncateText(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	return text[:n] + "..."
+}
+
+// Vector is a dense float32 vector bound and scanned in the BLOB format of vector32() function
+// (little-endian float32 values). Use it as an argument for F32_BLOB(N) columns and vector functions,
+// and as a scan destination: rows.Scan((*turso.Vector)(&floats)) fills a []float32.
+// Writing a vector with the number of dimensions other than declared by F32_BLOB(N) column fails with
+// a constraint error matching ErrVectorDimension.
+type Vector []float32
+
+var (
+	_ driver.Valuer = Vector(nil)
+	_ sql.Scanner   = (*Vector)(nil)
+)
+
+// Value implements driver.Valuer.
+func (v Vector) Value() (driver.Value, error) {
+	return encodeVector(v), nil
+}
+
+// Scan implements sql.Scanner; NULL scans into nil vector.
+func (v *Vector) Scan(src any) error {
+	switch x := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		vector, err := decodeVector(x)
+		if err != nil {
+			return err
+		}
+		*v = vector
+		return nil
+	default:
+		return fmt.Errorf("turso: can't scan %T into Vector", src)
+	}
+}
+
+// encodeVector serializes v as float32 vector BLOB
+func encodeVector(v []float32) []byte {
+	blob := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(blob[4*i:], math.Float32bits(f))
+	}
+	return blob
+}
+
+// decodeVector parses float32 vector BLOB; odd-sized BLOBs carry the vector type in the last byte
+func decodeVector(blob []byte) (Vector, error) {
+	if len(blob)%2 == 1 {
+		// 1 is the type of float32 vector
+		if kind := blob[len(blob)-1]; kind != 1 {
+			return nil, fmt.Errorf("turso: vector of type %d is not a float32 vector", kind)
+		}
+		blob = blob[:len(blob)-1]
+	}
+	if len(blob)%4 != 0 {
+		return nil, fmt.Errorf("turso: invalid float32 vector: %d bytes is not a multiple of 4", len(blob))
+	}
+	v := make(Vector, len(blob)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(blob[4*i:]))
+	}
+	return v, nil
+}
+
+// Queryer runs queries; it's implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Neighbor is a row found by KNN.
+type Neighbor struct {
+	RowID int64
+	// Distance is the cosine distance between the row vector and the query vector
+	Distance float64
+}
+
+// KNN returns up to k rows of table nearest to query by cosine distance of their vectors in column, closest first.
+// Rows with NULL vectors are skipped. If column is declared as F32_BLOB(N), query must have N dimensions,
+// otherwise ErrVectorDimension is returned. If column has a vector index (created with libsql_vector_idx),
+// candidates are looked up with vector_top_k through the index, otherwise the whole table is scanned.
+func KNN(ctx context.Context, q Queryer, table, column string, query []float32, k int) ([]Neighbor, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("turso: invalid number of neighbors %d", k)
+	}
+	if len(query) == 0 {
+		return nil, fmt.Errorf("%w: empty query vector", ErrVectorDimension)
+	}
+	dims, err := vectorDimensions(ctx, q, table, column)
+	if err != nil {
 		return nil, err
 	}
-	// Return rows wrapper; do not step yet, leave cursor before first row
-	return &tursoDbRows{
-		conn: c,
-		stmt: stmt,
-	}, nil
+	if dims > 0 && dims != len(query) {
+		return nil, fmt.Errorf("%w: %s.%s has %d dimensions, query has %d", ErrVectorDimension, table, column, dims, len(query))
+	}
+	index, err := vectorIndex(ctx, q, table, column)
+	if err != nil {
+		return nil, err
+	}
+	var rows *sql.Rows
+	if index != "" {
+		rows, err = q.QueryContext(ctx, fmt.Sprintf(
+			"SELECT %[2]s.rowid, vector_distance_cos(%[2]s.%[1]s, ?) AS distance FROM vector_top_k(?, ?, ?) "+
+				"JOIN %[2]s ON %[2]s.rowid = id ORDER BY distance",
+			quoteIdentifier(column), quoteIdentifier(table),
+		), Vector(query), index, Vector(query), k)
+	} else {
+		rows, err = q.QueryContext(ctx, fmt.Sprintf(
+			"SELECT rowid, vector_distance_cos(%[1]s, ?) AS distance FROM %[2]s WHERE %[1]s IS NOT NULL ORDER BY distance LIMIT ?",
+			quoteIdentifier(column), quoteIdentifier(table),
+		), Vector(query), k)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	neighbors := make([]Neighbor, 0, k)
+	for rows.Next() {
+		var n Neighbor
+		if err := rows.Scan(&n.RowID, &n.Distance); err != nil {
+			return nil, err
+		}
+		neighbors = append(neighbors, n)
+	}
+	return neighbors, rows.Err()
 }
 
-func (c *tursoDbConnection) checkOpen() error {
+// vectorIndex returns the name of the vector index on the column of the table or "" if there is none
+func vectorIndex(ctx context.Context, q Queryer, table, column string) (string, error) {
+	rows, err := q.QueryContext(ctx, "SELECT name, sql FROM sqlite_schema WHERE type = 'index' AND tbl_name = ? COLLATE NOCASE AND sql IS NOT NULL", table)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, definition string
+		if err := rows.Scan(&name, &definition); err != nil {
+			return "", err
+		}
+		if isVectorIndexOn(definition, column) {
+			return name, nil
+		}
+	}
+	return "", rows.Err()
+}
+
+// isVectorIndexOn reports whether the CREATE INDEX statement indexes the column with libsql_vector_idx
+func isVectorIndexOn(definition, column string) bool {
+	compact := strings.ToLower(strings.Join(strings.Fields(definition), ""))
+	column = strings.ToLower(column)
+	for _, quoted := range []string{column, `"` + column + `"`, "[" + column + "]", "`" + column + "`"} {
+		// the column is followed by the end of arguments or by index options
+		call := "libsql_vector_idx(" + strings.Join(strings.Fields(quoted), "")
+		if strings.Contains(compact, call+")") || strings.Contains(compact, call+",") {
+			return true
+		}
+	}
+	return false
+}
+
+// vectorDimensions returns N of the column declared as F32_BLOB(N) or 0 if the column has another type
+func vectorDimensions(ctx context.Context, q Queryer, table, column string) (int, error) {
+	rows, err := q.QueryContext(ctx, "SELECT type FROM pragma_table_info(?) WHERE name = ? COLLATE NOCASE", table, column)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("turso: no such column: %s.%s", table, column)
+	}
+	var decltype string
+	if err := rows.Scan(&decltype); err != nil {
+		return 0, err
+	}
+	upper := strings.ToUpper(strings.ReplaceAll(decltype, " ", ""))
+	if !strings.HasPrefix(upper, "F32_BLOB(") || !strings.HasSuffix(upper, ")") {
+		return 0, nil
+	}
+	dims, err := strconv.Atoi(upper[len("F32_BLOB(") : len(upper)-1])
+	if err != nil {
+		return 0, nil
+	}
+	return dims, nil
+}
+
+// Blob is an incremental I/O handle to a single BLOB value opened with OpenBlob.
+// It implements io.Reader, io.Writer, io.Seeker, io.ReaderAt, io.WriterAt and io.Closer.
+// The size of the BLOB is fixed: writes can't extend it (use zeroblob(N) to allocate space upfront).
+// Blob must not be used concurrently from multiple goroutines.
+type Blob struct {
+	conn   *tursoDbConnection
+	blob   TursoBlob
+	size   int64
+	offset int64
+	closed bool
+}
+
+var (
+	_ io.ReadWriteSeeker = (*Blob)(nil)
+	_ io.ReaderAt        = (*Blob)(nil)
+	_ io.WriterAt        = (*Blob)(nil)
+	_ io.Closer          = (*Blob)(nil)
+)
+
+// OpenBlob opens the BLOB stored in column of the row with rowid in table for incremental I/O.
+// db is the schema name; only "main" (or empty string) is supported.
+// Set writable to open the BLOB for writing. Use sql.Conn.Raw to reach the method from database/sql.
+func (c *tursoDbConnection) OpenBlob(db, table, column string, rowid int64, writable bool) (*Blob, error) {
+	if db != "" && !strings.EqualFold(db, "main") {
+		return nil, fmt.Errorf("turso: no such database: %s", db)
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if c.closed || c.conn == nil {
-		return ErrTursoConnClosed
+		return nil, ErrTursoConnClosed
 	}
-	return nil
+	handle, err := turso_connection_blob_open(c.conn, table, column, rowid, writable)
+	if err != nil {
+		return nil, err
+	}
+	b := &Blob{conn: c, blob: handle, size: turso_blob_bytes(handle)}
+	if c.blobs == nil {
+		c.blobs = make(map[*Blob]struct{})
+	}
+	c.blobs[b] = struct{}{}
+	return b, nil
 }
 
-// SetBusyTimeout sets the busy timeout for this connection in milliseconds.
-// Pass 0 to disable the busy handler (immediate SQLITE_BUSY on contention).
-// This method is thread-safe.
-func (c *tursoDbConnection) SetBusyTimeout(timeoutMs int) error {
-	if err := c.checkOpen(); err != nil {
-		return err
+// Len returns the size of the BLOB in bytes.
+func (b *Blob) Len() int64 {
+	return b.size
+}
+
+// Read reads from the current offset and advances it; it returns io.EOF at the end of the BLOB.
+func (b *Blob) Read(p []byte) (int, error) {
+	n, err := b.ReadAt(p, b.offset)
+	b.offset += int64(n)
+	return n, err
+}
+
+// ReadAt reads len(p) bytes starting at offset off; it returns io.EOF if fewer bytes are available.
+func (b *Blob) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("turso: negative blob offset %d", off)
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if timeoutMs < 0 {
-		timeoutMs = 0
+	if off >= b.size {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
 	}
-	turso_connection_set_busy_timeout_ms(c.conn, int64(timeoutMs))
-	c.busyTimeout = timeoutMs
-	return nil
+	n := len(p)
+	if remaining := b.size - off; int64(n) > remaining {
+		n = int(remaining)
+	}
+	b.conn.mu.Lock()
+	defer b.conn.mu.Unlock()
+	if b.closed {
+		return 0, ErrTursoBlobClosed
+	}
+	if err := turso_blob_read(b.blob, off, p[:n]); err != nil {
+		return 0, err
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
 }
 
-// GetBusyTimeout returns the current busy timeout in milliseconds.
-// Returns 0 if the busy handler is disabled.
-func (c *tursoDbConnection) GetBusyTimeout() int {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.busyTimeout
+// Write writes at the current offset and advances it.
+// Nothing is written and ErrTursoBlobOutOfRange is returned if p doesn't fit in the BLOB.
+func (b *Blob) Write(p []byte) (int, error) {
+	n, err := b.WriteAt(p, b.offset)
+	b.offset += int64(n)
+	return n, err
+}
+
+// WriteAt writes p starting at offset off.
+// Nothing is written and ErrTursoBlobOutOfRange is returned if p doesn't fit in the BLOB.
+func (b *Blob) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("turso: negative blob offset %d", off)
+	}
+	if off > b.size || int64(len(p)) > b.size-off {
+		return 0, fmt.Errorf("%w: writing %d bytes at offset %d into blob of %d bytes", ErrTursoBlobOutOfRange, len(p), off, b.size)
+	}
+	b.conn.mu.Lock()
+	defer b.conn.mu.Unlock()
+	if b.closed {
+		return 0, ErrTursoBlobClosed
+	}
+	if err := turso_blob_write(b.blob, off, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Seek sets the offset for the next Read or Write; seeking past the end is allowed.
+func (b *Blob) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = b.offset + offset
+	case io.SeekEnd:
+		abs = b.size + offset
+	default:
+		return 0, fmt.Errorf("turso: invalid blob seek whence %d", whence)
+	}
+	if abs < 0 {
+		return 0, fmt.Errorf("turso: negative blob offset %d", abs)
+	}
+	b.offset = abs
+	return abs, nil
+}
+
+// Close releases the handle and commits written data; closing already closed blob is no-op.
+func (b *Blob) Close() error {
+	b.conn.mu.Lock()
+	defer b.conn.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	delete(b.conn.blobs, b)
+	return b.release()
+}
+
+// release closes and deinits the handle; caller must hold b.conn.mu
+func (b *Blob) release() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	err := turso_blob_close(b.blob)
+	turso_blob_deinit(b.blob)
+	b.blob = nil
+	return err
 }
 
 // --- Connector Pattern ---
@@ -351,75 +3226,110 @@ func WithBusyTimeout(ms int) ConnectorOption {
 }
 
 // TursoConnector implements driver.Connector for programmatic configuration.
+// The DSN is parsed once and every connection opened by the connector gets the same settings.
 type TursoConnector struct {
 	dsn         string
+	config      TursoDatabaseConfig
 	busyTimeout int // -1 = use default, 0 = disabled, >0 = custom
+	ryw         *readYourWrites
+
+	mu sync.Mutex
+	// in-memory database shared by all connections of the connector (nil until the first connection)
+	memory *memoryDatabase
 }
 
 // NewConnector creates a new TursoConnector with the given DSN and options.
 // By default, uses the DefaultBusyTimeout (5000ms).
+// It fails if the DSN or the options are invalid.
 func NewConnector(dsn string, opts ...ConnectorOption) (*TursoConnector, error) {
+	config, err := parseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
 	c := &TursoConnector{
 		dsn:         dsn,
+		config:      config,
 		busyTimeout: -1, // -1 means use default
 	}
 	for _, opt := range opts {
 		opt(c)
 	}
+	if c.busyTimeout < -1 {
+		return nil, fmt.Errorf("turso: invalid busy timeout %d: expected non-negative number of milliseconds", c.busyTimeout)
+	}
+	if config.ReadYourWrites {
+		// connections of the connector form a single pool: they all share the write position
+		c.ryw = &readYourWrites{}
+	}
 	return c, nil
 }
 
 // Connect implements driver.Connector.
 func (c *TursoConnector) Connect(ctx context.Context) (driver.Conn, error) {
 	InitLibrary(turso_libs.LoadTursoLibraryConfig{})
-	config, err := parseDSN(c.dsn)
-	if err != nil {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
+	config := c.config
 	// Override busy timeout from connector if set
 	if c.busyTimeout >= 0 {
 		// If connector explicitly sets 0, that means disabled
-		// We use -1 internally to signal "disabled" to Open logic
+		// We use -1 internally to signal "disabled" to openConnection logic
 		if c.busyTimeout == 0 {
-			config.BusyTimeout = -1 // Will be converted to 0 in Open
+			config.BusyTimeout = -1 // Will be converted to 0 in openConnection
 		} else {
 			config.BusyTimeout = c.busyTimeout
 		}
 	}
 	// If busyTimeout is -1 (use default) and DSN didn't set one, leave it as 0
-	// which will trigger the default in Open()
-
-	db, err := turso_database_new(config)
-	if err != nil {
-		return nil, err
-	}
-	if err := turso_database_open(db); err != nil {
-		turso_database_deinit(db)
-		return nil, err
+	// which will trigger the default in openConnection()
+	var conn *tursoDbConnection
+	var err error
+	if config.Path == ":memory:" {
+		conn, err = c.connectMemory(config)
+	} else {
+		conn, err = openConnection(config)
 	}
-	conn, err := turso_database_connect(db)
 	if err != nil {
-		turso_database_deinit(db)
 		return nil, err
 	}
+	conn.ryw = c.ryw
+	return conn, nil
+}
 
-	// Apply busy timeout - same logic as Open()
-	timeout := config.BusyTimeout
-	if timeout == 0 {
-		timeout = DefaultBusyTimeout
-	} else if timeout < 0 {
-		timeout = 0
-	}
-	if timeout > 0 {
-		turso_connection_set_busy_timeout_ms(conn, int64(timeout))
+// connectMemory connects to the in-memory database of the connector.
+// sql.DB opens several connections through the same connector, so they all must see the same database
+// even without cache=shared; the connector keeps the database alive until it's closed.
+func (c *TursoConnector) connectMemory(config TursoDatabaseConfig) (*tursoDbConnection, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.memory == nil {
+		if config.SharedMemory != "" {
+			memory, err := acquireSharedMemory(config)
+			if err != nil {
+				return nil, err
+			}
+			c.memory = memory
+		} else {
+			db, err := openDatabase(config)
+			if err != nil {
+				return nil, err
+			}
+			c.memory = &memoryDatabase{db: db, refs: 1}
+		}
 	}
+	return c.memory.connect(config)
+}
 
-	return &tursoDbConnection{
-		db:          db,
-		conn:        conn,
-		busyTimeout: timeout,
-		async:       config.AsyncIO,
-	}, nil
+// Close releases the in-memory database of the connector; sql.DB.Close calls it after closing all connections.
+func (c *TursoConnector) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.memory != nil {
+		c.memory.release()
+		c.memory = nil
+	}
+	return nil
 }
 
 // Driver implements driver.Connector.
@@ -428,15 +3338,19 @@ func (c *TursoConnector) Driver() driver.Driver {
 }
 
 // Ensure TursoConnector implements driver.Connector
-var _ driver.Connector = (*TursoConnector)(nil)
+var (
+	_ driver.Connector = (*TursoConnector)(nil)
+	_ io.Closer        = (*TursoConnector)(nil)
+)
 
 // --- driver.Stmt and friends ---
 
 // Ensure tursoDbStatement implements required interfaces.
 var (
-	_ driver.Stmt             = (*tursoDbStatement)(nil)
-	_ driver.StmtExecContext  = (*tursoDbStatement)(nil)
-	_ driver.StmtQueryContext = (*tursoDbStatement)(nil)
+	_ driver.Stmt              = (*tursoDbStatement)(nil)
+	_ driver.StmtExecContext   = (*tursoDbStatement)(nil)
+	_ driver.StmtQueryContext  = (*tursoDbStatement)(nil)
+	_ driver.NamedValueChecker = (*tursoDbStatement)(nil)
 )
 
 func (s *tursoDbStatement) Close() error {
@@ -444,10 +3358,16 @@ func (s *tursoDbStatement) Close() error {
 	return nil
 }
 
+// NumInput returns -1 for statements with named parameters: database/sql can't match
+// named arguments with placeholders, so the driver validates them on its own.
 func (s *tursoDbStatement) NumInput() int {
 	return s.numInputs
 }
 
+func (s *tursoDbStatement) CheckNamedValue(nv *driver.NamedValue) error {
+	return checkNamedValue(nv)
+}
+
 func (s *tursoDbStatement) Exec(args []driver.Value) (driver.Result, error) {
 	named := make([]driver.NamedValue, len(args))
 	for i, v := range args {
@@ -480,13 +3400,23 @@ func (s *tursoDbStatement) QueryContext(ctx context.Context, args []driver.Named
 
 // --- driver.Rows ---
 
-// Ensure tursoDbRows implements the required interface.
-var _ driver.Rows = (*tursoDbRows)(nil)
+// Ensure tursoDbRows implements the required interfaces.
+var (
+	_ driver.Rows                           = (*tursoDbRows)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*tursoDbRows)(nil)
+	_ driver.RowsColumnTypeNullable         = (*tursoDbRows)(nil)
+	_ driver.RowsColumnTypeScanType         = (*tursoDbRows)(nil)
+	_ driver.RowsNextResultSet              = (*tursoDbRows)(nil)
+)
 
 func (r *tursoDbRows) Columns() []string {
 	if r.columns != nil {
 		return r.columns
 	}
+	if r.stmt == nil {
+		r.columns = []string{}
+		return r.columns
+	}
 	n := int(turso_statement_column_count(r.stmt))
 	names := make([]string, n)
 	decltypes := make([]string, n)
@@ -503,28 +3433,193 @@ func (r *tursoDbRows) Close() error {
 	if r.closed {
 		return nil
 	}
+	args := r.args
+	// the rest of a multi-statement query runs even if its result sets weren't read: their rows are dropped
+	// and their changes apply, like with Exec. Nothing more runs once the rows failed.
+	var err error
+	for err == nil && r.err == nil && (r.ctx == nil || r.ctx.Err() == nil) && r.HasNextResultSet() {
+		err = r.NextResultSet()
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	if finishErr := r.finishStatement(); err == nil {
+		err = finishErr
+	}
 	r.closed = true
-	_ = turso_statement_finalize(r.stmt)
-	turso_statement_deinit(r.stmt)
-	return nil
+	if r.next != nil {
+		_ = turso_statement_finalize(r.next)
+		turso_statement_deinit(r.next)
+		r.next = nil
+	}
+	if r.stop != nil {
+		r.stop()
+	}
+	if r.cancel != nil {
+		r.cancel()
+	}
+	if err == nil {
+		// DML with RETURNING clause is a write as well
+		r.conn.observeWrite()
+	}
+	// statement interrupted because ctx is done is not an error of Close
+	if r.ctx != nil && r.ctx.Err() != nil && errors.Is(err, ErrTursoInterrupt) {
+		err = nil
+	}
+	if r.logger != nil {
+		event := QueryEvent{Kind: QueryEventQuery, SQL: r.logSQL, Args: args, Rows: r.returned, Err: r.err}
+		if event.Err == nil {
+			event.Err = err
+		}
+		r.logger.log(r.ctx, event, r.logStart)
+	}
+	return err
 }
 
-func (r *tursoDbRows) Next(dest []driver.Value) error {
+// finishStatement completes the current statement and detaches it from the rows.
+func (r *tursoDbRows) finishStatement() error {
+	if r.stmt == nil {
+		return nil
+	}
+	// finalize completes the statement if rows were not consumed fully:
+	// DML with RETURNING clause must apply all its changes even if caller read only some of the rows,
+	// while a read-only statement is reset right away, so breaking out of a large result set is cheap
+	r.conn.finishProfile(r.profile, r.stmt)
+	r.profile = statementProfile{}
+	var err error
+	if r.done && r.cached != "" {
+		r.conn.mu.Lock()
+		err = r.conn.releaseStatement(r.cached, r.stmt)
+		r.conn.mu.Unlock()
+	} else {
+		err = r.conn.finalize(r.stmt)
+	}
+	r.stmt = nil
+	r.cached = ""
+	return r.conn.collationError(err)
+}
+
+// HasNextResultSet reports whether a multi-statement query has statements after the current result set.
+func (r *tursoDbRows) HasNextResultSet() bool {
+	if r.closed {
+		return false
+	}
+	if r.next == nil && r.nextErr == nil && strings.TrimSpace(r.rest) != "" {
+		stmt, tail, err := turso_connection_prepare_first(r.conn.conn, r.rest)
+		switch {
+		case err != nil:
+			r.rest, r.nextErr = "", err
+		case stmt == nil:
+			// only comments are left
+			r.rest = ""
+		default:
+			r.rest, r.next, r.nextSQL = r.rest[tail:], stmt, r.rest[:tail]
+		}
+	}
+	return r.next != nil || r.nextErr != nil
+}
+
+// NextResultSet advances to the result set of the next statement of a multi-statement query which returns rows:
+// the current statement is completed, statements which return no rows are executed and skipped.
+// It returns io.EOF once no statement is left. Arguments of the query are bound only to its first statement.
+// A failed statement stops the query: its error is returned and the statements after it never run.
+func (r *tursoDbRows) NextResultSet() error {
 	if r.closed {
 		return io.EOF
 	}
+	// changes of the current statement apply even if its rows were not read
+	if r.stmt != nil && !r.done && !turso_statement_readonly(r.stmt) {
+		if _, err := r.conn.executeFully(r.ctx, r.stmt); err != nil {
+			r.err = r.ctxError(err)
+			return r.err
+		}
+		r.done = true
+	}
+	if err := r.finishStatement(); err != nil {
+		r.err = err
+		return err
+	}
+	r.conn.observeWrite()
+	r.columns, r.decltypes, r.done, r.err = nil, nil, false, nil
+	r.args, r.catchUp, r.busyRetries = nil, false, 0
+	for r.HasNextResultSet() {
+		if r.nextErr != nil {
+			r.err, r.nextErr = r.nextErr, nil
+			return r.err
+		}
+		stmt, sql := r.next, r.nextSQL
+		r.next, r.nextSQL = nil, ""
+		profile := r.conn.startProfile(stmt, sql)
+		if turso_statement_column_count(stmt) > 0 {
+			r.stmt = stmt
+			r.profile = profile
+			if r.conn.ryw != nil && turso_connection_get_autocommit(r.conn.conn) {
+				r.catchUp = true
+				r.required = r.conn.ryw.latestWrite()
+				r.restarts = 0
+			}
+			return nil
+		}
+		_, err := r.conn.executeFully(r.ctx, stmt)
+		r.conn.finishProfile(profile, stmt)
+		if finalizeErr := r.conn.finalize(stmt); err == nil {
+			err = finalizeErr
+		}
+		if err != nil {
+			r.err = r.ctxError(err)
+			return r.err
+		}
+		r.conn.observeWrite()
+	}
+	return io.EOF
+}
+
+func (r *tursoDbRows) Next(dest []driver.Value) error {
+	if r.closed || r.stmt == nil {
+		return io.EOF
+	}
 	// Ensure decltypes are populated
 	_ = r.Columns()
+	if errors.Is(r.err, ErrTursoMaxRows) {
+		return r.err
+	}
+	if r.ctx != nil && r.ctx.Err() != nil {
+		r.err = r.ctx.Err()
+		if r.scanTimedOut() {
+			r.err = ErrTursoScanTimeout
+		}
+		return r.err
+	}
 	for {
 		status, err := turso_statement_step(r.stmt)
 		if err != nil {
-			r.err = err
-			return err
+			if retry, retryErr := r.retryBusy(err); retry {
+				continue
+			} else if retryErr != nil {
+				err = retryErr
+			}
+			r.err = r.ctxError(err)
+			return r.err
+		}
+		if r.catchUp && (status == TURSO_ROW || status == TURSO_DONE) {
+			restarted, err := r.restartIfStale()
+			if err != nil {
+				r.err = err
+				return err
+			}
+			if restarted {
+				continue
+			}
 		}
 		switch status {
 		case TURSO_ROW:
-			// Fill destination
-			n := int(turso_statement_column_count(r.stmt))
+			if r.maxRows > 0 && r.returned >= r.maxRows {
+				r.err = fmt.Errorf("%w: limit is %d", ErrTursoMaxRows, r.maxRows)
+				return r.err
+			}
+			r.returned++
+			// Fill destination: only the current row is converted, rows are never accumulated
+			n := len(r.columns)
 			if len(dest) != n {
 				return fmt.Errorf("turso: expected %d dests, got %d", n, len(dest))
 			}
@@ -535,6 +3630,12 @@ func (r *tursoDbRows) Next(dest []driver.Value) error {
 					dest[i] = nil
 				case TURSO_TYPE_INTEGER:
 					dest[i] = turso_statement_row_value_int(r.stmt, i)
+					// integers of time column are timestamps only if the connection stores time as numbers
+					if i < len(r.decltypes) && isTimeColumn(r.decltypes[i]) {
+						if t, ok := r.conn.timeFormat.decodeInt(dest[i].(int64)); ok {
+							dest[i] = t
+						}
+					}
 				case TURSO_TYPE_REAL:
 					dest[i] = turso_statement_row_value_double(r.stmt, i)
 				case TURSO_TYPE_TEXT:
@@ -550,13 +3651,23 @@ func (r *tursoDbRows) Next(dest []driver.Value) error {
 						dest[i] = text
 					}
 				case TURSO_TYPE_BLOB:
-					dest[i] = turso_statement_row_value_bytes(r.stmt, i)
+					// empty BLOB stays distinct from NULL
+					blob := turso_statement_row_value_bytes(r.stmt, i)
+					if blob == nil {
+						blob = []byte{}
+					}
+					dest[i] = blob
 				default:
 					dest[i] = nil
 				}
 			}
 			return nil
 		case TURSO_DONE:
+			if err := r.conn.collationError(nil); err != nil {
+				r.err = err
+				return err
+			}
+			r.done = true
 			return io.EOF
 		case TURSO_IO:
 			// Run IO iteration
@@ -567,8 +3678,8 @@ func (r *tursoDbRows) Next(dest []driver.Value) error {
 				}
 			}
 			if err := turso_statement_run_io(r.stmt); err != nil {
-				r.err = err
-				return err
+				r.err = r.ctxError(err)
+				return r.err
 			}
 			continue
 		case TURSO_OK:
@@ -580,10 +3691,132 @@ func (r *tursoDbRows) Next(dest []driver.Value) error {
 	}
 }
 
+// restartIfStale restarts the statement if it reads from a snapshot older than the latest write committed through the pool.
+// It's called once the statement started its read transaction and returns true if it must be stepped again.
+func (r *tursoDbRows) restartIfStale() (bool, error) {
+	if !r.conn.walPosition().before(r.required) {
+		r.catchUp = false
+		return false, nil
+	}
+	if r.restarts >= readYourWritesMaxRestarts {
+		return false, ErrTursoStaleRead
+	}
+	r.restarts++
+	if err := turso_statement_reset(r.stmt); err != nil {
+		return false, err
+	}
+	if err := bindArgs(r.stmt, r.args, r.conn.timeFormat); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *tursoDbRows) ctxError(err error) error {
+	err = r.conn.collationError(err)
+	if r.ctx == nil {
+		return r.conn.concurrentError(err)
+	}
+	// statement interrupted by the scan timeout reports the timeout rather than context.DeadlineExceeded
+	if errors.Is(err, ErrTursoInterrupt) && r.scanTimedOut() {
+		return ErrTursoScanTimeout
+	}
+	return r.conn.concurrentError(ctxError(r.ctx, err))
+}
+
+// retryBusy restarts the statement which failed with SQLITE_BUSY before returning any row if the busy retry policy
+// permits another attempt and the retry is safe: the statement is read-only or runs outside of a transaction,
+// so its failure has no effects. It returns the error which must be reported instead of err if the retry failed.
+func (r *tursoDbRows) retryBusy(err error) (bool, error) {
+	policy := r.conn.busyRetryPolicy()
+	if policy == nil || r.returned > 0 || r.busyRetries >= policy.attempts || !IsBusy(err) {
+		return false, nil
+	}
+	if !turso_connection_get_autocommit(r.conn.conn) && !turso_statement_readonly(r.stmt) {
+		return false, nil
+	}
+	r.busyRetries++
+	if err := policy.wait(r.ctx, r.busyRetries); err != nil {
+		if r.scanTimedOut() {
+			return false, ErrTursoScanTimeout
+		}
+		return false, err
+	}
+	if err := turso_statement_reset(r.stmt); err != nil {
+		return false, err
+	}
+	if err := bindArgs(r.stmt, r.args, r.conn.timeFormat); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// scanTimedOut reports whether ctx of the rows is done because the timeout set with WithScanTimeout elapsed.
+func (r *tursoDbRows) scanTimedOut() bool {
+	return r.cancel != nil && errors.Is(context.Cause(r.ctx), ErrTursoScanTimeout)
+}
+
+// ColumnTypeDatabaseTypeName returns the upper-cased declared type of the column (e.g. "INTEGER", "VARCHAR(10)"),
+// or an empty string if the column is an expression without a declared type.
+func (r *tursoDbRows) ColumnTypeDatabaseTypeName(index int) string {
+	_ = r.Columns()
+	if index < 0 || index >= len(r.decltypes) {
+		return ""
+	}
+	return strings.ToUpper(r.decltypes[index])
+}
+
+// ColumnTypeNullable reports whether the column can hold NULL.
+// Nullability is known only for table columns: ok is false for expressions.
+func (r *tursoDbRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if r.closed || r.stmt == nil {
+		return false, false
+	}
+	return turso_statement_column_nullable(r.stmt, index)
+}
+
+// ColumnTypeScanType returns the Go type which values of the column are scanned into,
+// derived from the affinity of the declared type with the rules of https://sqlite.org/datatype3.html.
+// Columns without a declared type and columns of NUMERIC affinity (which hold either integers or reals)
+// have the type interface{}.
+func (r *tursoDbRows) ColumnTypeScanType(index int) reflect.Type {
+	return scanTypeOf(r.ColumnTypeDatabaseTypeName(index))
+}
+
+var (
+	scanTypeAny     = reflect.TypeOf((*any)(nil)).Elem()
+	scanTypeInt64   = reflect.TypeOf(int64(0))
+	scanTypeFloat64 = reflect.TypeOf(float64(0))
+	scanTypeString  = reflect.TypeOf("")
+	scanTypeBytes   = reflect.TypeOf([]byte(nil))
+	scanTypeTime    = reflect.TypeOf(time.Time{})
+)
+
+func scanTypeOf(decltype string) reflect.Type {
+	switch {
+	case decltype == "":
+		return scanTypeAny
+	case isTimeColumn(decltype):
+		return scanTypeTime
+	case strings.Contains(decltype, "INT"):
+		return scanTypeInt64
+	case strings.Contains(decltype, "CHAR"), strings.Contains(decltype, "CLOB"), strings.Contains(decltype, "TEXT"):
+		return scanTypeString
+	case strings.Contains(decltype, "BLOB"):
+		return scanTypeBytes
+	case strings.Contains(decltype, "REAL"), strings.Contains(decltype, "FLOA"), strings.Contains(decltype, "DOUB"):
+		return scanTypeFloat64
+	default:
+		return scanTypeAny
+	}
+}
+
 // --- driver.Result ---
 
 var _ driver.Result = (*tursoDbResult)(nil)
 
+// LastInsertId returns the rowid of the row inserted by the Exec (by its latest inserting statement if Exec ran several).
+// It is 0 if no row was inserted: the statement is not an INSERT, INSERT OR IGNORE skipped the row
+// or the table is WITHOUT ROWID, which has no rowid. Rows inserted by triggers don't change it.
 func (r *tursoDbResult) LastInsertId() (int64, error) {
 	return r.lastInsertId, nil
 }
@@ -600,8 +3833,14 @@ func (tx *tursoDbTx) Commit() error {
 	if tx.done {
 		return ErrTursoTxDone
 	}
-	_, err := tx.conn.ExecContext(context.Background(), "COMMIT", nil)
+	_, err := tx.conn.execLogged(context.Background(), QueryEventCommit, "COMMIT", nil)
 	tx.done = true
+	tx.conn.concurrentTx = false
+	// database/sql ends the transaction even if COMMIT failed (e.g. with SQLITE_BUSY):
+	// roll it back, so the connection returns to the pool outside of a transaction
+	if err != nil && tx.conn.checkOpen() == nil && tx.conn.inTransaction() {
+		_, _ = tx.conn.execLogged(context.Background(), QueryEventRollback, "ROLLBACK", nil)
+	}
 	return err
 }
 
@@ -609,14 +3848,81 @@ func (tx *tursoDbTx) Rollback() error {
 	if tx.done {
 		return ErrTursoTxDone
 	}
-	_, err := tx.conn.ExecContext(context.Background(), "ROLLBACK", nil)
 	tx.done = true
+	defer func() { tx.conn.concurrentTx = false }()
+	// the library could already roll back the transaction (e.g. after concurrent conflict)
+	if err := tx.conn.checkOpen(); err == nil && !tx.conn.inTransaction() {
+		return nil
+	}
+	_, err := tx.conn.execLogged(context.Background(), QueryEventRollback, "ROLLBACK", nil)
 	return err
 }
 
+// inTransaction reports whether a transaction is open on the connection: locally or, with RouteWrites, on the primary
+func (c *tursoDbConnection) inTransaction() bool {
+	return !turso_connection_get_autocommit(c.conn) || c.primary != nil && c.primary.inTx
+}
+
+// TransactionOption configures Transaction.
+type TransactionOption func(*transactionOptions)
+
+type transactionOptions struct {
+	retry *busyRetryPolicy
+}
+
+// WithTransactionRetry makes Transaction run fn again in a new transaction when a statement or the commit fails
+// with SQLITE_BUSY or ErrConcurrentConflict, up to attempts times, waiting backoff before the first retry and
+// doubling it for every next one (DefaultBusyRetryBackoff if backoff is not positive).
+// Use it only for idempotent fn: changes fn makes outside of the transaction happen once per attempt.
+func WithTransactionRetry(attempts int, backoff time.Duration) TransactionOption {
+	return func(o *transactionOptions) { o.retry = newBusyRetryPolicy(attempts, backoff) }
+}
+
+// Transaction runs fn in a transaction started with opts: it commits if fn returns nil and rolls back if fn fails
+// or panics (the panic is propagated after the rollback). The error of fn or of the commit is returned as is.
+// fn must not commit or roll back tx itself. Failed transactions are retried only with WithTransactionRetry.
+func Transaction(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(*sql.Tx) error, options ...TransactionOption) error {
+	var o transactionOptions
+	for _, opt := range options {
+		opt(&o)
+	}
+	for attempt := 1; ; attempt++ {
+		err := runTransaction(ctx, db, opts, fn)
+		if err == nil || o.retry == nil || attempt > o.retry.attempts {
+			return err
+		}
+		if !IsBusy(err) && !errors.Is(err, ErrConcurrentConflict) {
+			return err
+		}
+		if waitErr := o.retry.wait(ctx, attempt); waitErr != nil {
+			return err
+		}
+	}
+}
+
+func runTransaction(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
 // Helpers
 
-// parseDSN supports format: <path>[?experimental=<string>&async=0|1&vfs=<string>&encryption_cipher=<string>&encryption_hexkey=<string>&_busy_timeout=<int>]
+// parseDSN supports format: <path>[?experimental=<string>&async=0|1&vfs=<string>&encryption_cipher=<string>&encryption_hexkey=<string>&_cipher=<string>&_key=<hex>&_busy_timeout=<int>&_time_format=rfc3339|unix|unixms&_allow_load_extension=<bool>&_stmt_cache_size=<int>&_busy_retries=<int>&_busy_retry_backoff=<duration>&_page_size=<int>&_auto_vacuum=none|full|incremental&_profile=<bool>&_null_as_zero=<bool>&mode=ro|rw|rwc|memory&immutable=<bool>&_mutex=no|full]
+// In-memory database is opened with ":memory:", "file::memory:" or "file:<name>?mode=memory"; cache=shared makes it shared by name.
+// _mutex is accepted and ignored.
 func parseDSN(dsn string) (TursoDatabaseConfig, error) {
 	config := TursoDatabaseConfig{Path: dsn}
 	qMark := strings.IndexByte(dsn, '?')
@@ -642,16 +3948,234 @@ func parseDSN(dsn string) (TursoDatabaseConfig, error) {
 		if v := vals.Get("encryption_hexkey"); v != "" {
 			config.Encryption.Hexkey = v
 		}
+		// SQLCipher-style aliases of encryption_hexkey and encryption_cipher
+		if v := vals.Get("_key"); v != "" {
+			if _, err := hex.DecodeString(v); err != nil {
+				return TursoDatabaseConfig{}, fmt.Errorf("turso: invalid _key: expected hex-encoded key")
+			}
+			config.Encryption.Hexkey = v
+		}
+		if v := vals.Get("_cipher"); v != "" {
+			config.Encryption.Cipher = v
+		}
+		if vals.Get("_key") != "" && config.Encryption.Cipher == "" {
+			config.Encryption.Cipher = DefaultCipher
+		}
 		if v := vals.Get("_busy_timeout"); v != "" {
-			var timeout int
-			if _, err := fmt.Sscanf(v, "%d", &timeout); err == nil {
-				config.BusyTimeout = timeout
+			timeout, err := strconv.Atoi(v)
+			if err != nil || timeout < -1 {
+				return TursoDatabaseConfig{}, fmt.Errorf("turso: invalid _busy_timeout %q: expected non-negative number of milliseconds or -1 to disable", v)
+			}
+			config.BusyTimeout = timeout
+		}
+		if v := vals.Get("_read_your_writes"); v != "" {
+			enabled, err := strconv.ParseBool(v)
+			if err != nil {
+				return TursoDatabaseConfig{}, fmt.Errorf("turso: invalid _read_your_writes %q: expected boolean", v)
+			}
+			config.ReadYourWrites = enabled
+		}
+		if v := vals.Get("_allow_load_extension"); v != "" {
+			enabled, err := strconv.ParseBool(v)
+			if err != nil {
+				return TursoDatabaseConfig{}, fmt.Errorf("turso: invalid _allow_load_extension %q: expected boolean", v)
+			}
+			config.AllowLoadExtension = enabled
+		}
+		if v := vals.Get("_stmt_cache_size"); v != "" {
+			size, err := strconv.Atoi(v)
+			if err != nil || size < 0 {
+				return TursoDatabaseConfig{}, fmt.Errorf("turso: invalid _stmt_cache_size %q: expected non-negative number of statements", v)
+			}
+			config.StmtCacheSize = size
+		}
+		if v := vals.Get("_time_format"); v != "" {
+			format, err := parseTimeFormat(v)
+			if err != nil {
+				return TursoDatabaseConfig{}, err
+			}
+			config.TimeFormat = format
+		}
+		if v := vals.Get("_busy_retries"); v != "" {
+			retries, err := strconv.Atoi(v)
+			if err != nil || retries < 0 {
+				return TursoDatabaseConfig{}, fmt.Errorf("turso: invalid _busy_retries %q: expected non-negative number of retries", v)
+			}
+			config.BusyRetries = retries
+		}
+		if v := vals.Get("_busy_retry_backoff"); v != "" {
+			backoff, err := time.ParseDuration(v)
+			if err != nil || backoff < 0 {
+				return TursoDatabaseConfig{}, fmt.Errorf("turso: invalid _busy_retry_backoff %q: expected non-negative duration (e.g. 10ms)", v)
+			}
+			config.BusyRetryBackoff = backoff
+		}
+		if v := vals.Get("_page_size"); v != "" {
+			size, err := strconv.Atoi(v)
+			if err != nil || size < 512 || size > 65536 || size&(size-1) != 0 {
+				return TursoDatabaseConfig{}, fmt.Errorf("turso: invalid _page_size %q: expected power of two between 512 and 65536", v)
+			}
+			config.PageSize = size
+		}
+		if v := vals.Get("_auto_vacuum"); v != "" {
+			if _, ok := autoVacuumModes[strings.ToLower(v)]; !ok {
+				return TursoDatabaseConfig{}, fmt.Errorf("turso: invalid _auto_vacuum %q: expected none, full or incremental", v)
+			}
+			config.AutoVacuum = strings.ToLower(v)
+		}
+		if v := vals.Get("_profile"); v != "" {
+			enabled, err := strconv.ParseBool(v)
+			if err != nil {
+				return TursoDatabaseConfig{}, fmt.Errorf("turso: invalid _profile %q: expected boolean", v)
+			}
+			config.Profile = enabled
+		}
+		if v := vals.Get("_null_as_zero"); v != "" {
+			enabled, err := strconv.ParseBool(v)
+			if err != nil {
+				return TursoDatabaseConfig{}, fmt.Errorf("turso: invalid _null_as_zero %q: expected boolean", v)
+			}
+			if enabled && !nullAsZeroSupported {
+				return TursoDatabaseConfig{}, errors.New("turso: _null_as_zero requires Go 1.27 or later")
 			}
+			config.NullAsZero = enabled
+		}
+		if err := parseOpenMode(&config, vals); err != nil {
+			return TursoDatabaseConfig{}, err
 		}
+		if err := parseMemoryDSN(&config, vals); err != nil {
+			return TursoDatabaseConfig{}, err
+		}
+		if config.Path == ":memory:" && (config.ReadOnly || config.NoCreate || config.Immutable) {
+			return TursoDatabaseConfig{}, errors.New("turso: mode=ro, mode=rw and immutable are not supported for in-memory databases")
+		}
+	} else if config.Path == "file::memory:" {
+		config.Path = ":memory:"
 	}
 	return config, nil
 }
 
+// parseOpenMode translates mode, immutable and _mutex parameters to open flags of the database file.
+// _mutex is a no-op accepted for compatibility only: the driver always serializes access to a connection.
+func parseOpenMode(config *TursoDatabaseConfig, vals url.Values) error {
+	switch mode := vals.Get("mode"); mode {
+	case "", "rwc", "memory":
+	case "ro":
+		config.ReadOnly = true
+	case "rw":
+		config.NoCreate = true
+	default:
+		return fmt.Errorf("turso: invalid mode %q: expected ro, rw, rwc or memory", mode)
+	}
+	if v := vals.Get("immutable"); v != "" {
+		immutable, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("turso: invalid immutable %q: expected boolean", v)
+		}
+		config.Immutable = immutable
+	}
+	switch mutex := vals.Get("_mutex"); mutex {
+	case "", "no", "full":
+	default:
+		return fmt.Errorf("turso: invalid _mutex %q: expected no or full", mutex)
+	}
+	return nil
+}
+
+// parseMemoryDSN recognizes in-memory database URIs and the cache parameter
+func parseMemoryDSN(config *TursoDatabaseConfig, vals url.Values) error {
+	name := config.Path
+	memory := name == ":memory:" || name == "file::memory:"
+	if vals.Get("mode") == "memory" {
+		if !strings.HasPrefix(name, "file:") {
+			return fmt.Errorf("turso: mode=memory requires file: URI, got %q", name)
+		}
+		memory = true
+	}
+	cache := vals.Get("cache")
+	switch cache {
+	case "", "private", "shared":
+	default:
+		return fmt.Errorf("turso: invalid cache %q: expected shared or private", cache)
+	}
+	if !memory {
+		if cache == "shared" {
+			return fmt.Errorf("turso: cache=shared is supported only for in-memory databases, got %q", name)
+		}
+		return nil
+	}
+	config.Path = ":memory:"
+	if cache == "shared" {
+		// "file::memory:?cache=shared" names the same database as ":memory:?cache=shared"
+		if name == "file::memory:" {
+			name = ":memory:"
+		}
+		config.SharedMemory = strings.TrimPrefix(name, "file:")
+	}
+	return nil
+}
+
+// ctxError reports ctx error instead of the interruption caused by ctx cancellation
+func ctxError(ctx context.Context, err error) error {
+	if ctx.Err() != nil && errors.Is(err, ErrTursoInterrupt) {
+		return ctx.Err()
+	}
+	return err
+}
+
+// takeStatement removes the idle statement prepared for query from the statement cache and returns it
+// with the bindings of its previous execution cleared, or nil if there is none; c.mu must be held.
+func (c *tursoDbConnection) takeStatement(query string) TursoStatement {
+	if c.stmts == nil {
+		return nil
+	}
+	stmt, ok := c.stmts.take(query)
+	if !ok {
+		return nil
+	}
+	// reset clears the bindings, so parameters without arguments are NULL instead of the previous values
+	if err := turso_statement_reset(stmt); err != nil {
+		_ = c.finalize(stmt)
+		return nil
+	}
+	return stmt
+}
+
+// releaseStatement resets the statement which ran to completion and returns it to the statement cache;
+// c.mu must be held.
+func (c *tursoDbConnection) releaseStatement(query string, stmt TursoStatement) error {
+	if err := turso_statement_reset(stmt); err != nil {
+		_ = c.finalize(stmt)
+		return err
+	}
+	for _, evicted := range c.stmts.put(query, stmt) {
+		_ = c.finalize(evicted)
+	}
+	return nil
+}
+
+// finalize runs pending execution of the statement to completion and releases it.
+func (c *tursoDbConnection) finalize(stmt TursoStatement) error {
+	defer turso_statement_deinit(stmt)
+	for {
+		status, err := turso_statement_finalize_status(stmt)
+		if err != nil {
+			return err
+		}
+		if status != TURSO_IO {
+			return nil
+		}
+		if c.extraIo != nil {
+			if err := c.extraIo(); err != nil {
+				return err
+			}
+		}
+		if err := turso_statement_run_io(stmt); err != nil {
+			return err
+		}
+	}
+}
+
 func (c *tursoDbConnection) executeFully(ctx context.Context, stmt TursoStatement) (uint64, error) {
 	var latest uint64
 	for {
@@ -734,32 +4258,44 @@ func (c *tursoDbConnection) executeFully(ctx context.Context, stmt TursoStatemen
 
 // bindArgs binds ordered and named values to a statement.
 // Named values are resolved via turso_statement_parameter_name, otherwise ordinal positions are used (1-based).
-func bindArgs(stmt TursoStatement, args []driver.NamedValue) error {
+// When named and positional values are mixed, positional values fill the unnamed placeholders in SQL order.
+// time.Time values are stored in timeFormat.
+func bindArgs(stmt TursoStatement, args []driver.NamedValue, timeFormat TimeFormat) error {
 	paramCount := int(turso_statement_parameters_count(stmt))
-	if paramCount >= 0 && len(args) != paramCount {
-		return fmt.Errorf("turso: got %d args, want %d", len(args), paramCount)
-	}
 
 	// Build bare-name → position map from statement metadata.
 	// Go's database/sql strips the SQL prefix from named parameters:
 	// sql.Named("a", v) arrives as Name="a", but the statement knows
 	// the full name (e.g. ":a", "@a", "$a"). We strip the prefix from
 	// the statement's parameter names to build the lookup table.
+	// A named parameter used several times in SQL occupies a single position.
 	var nameMap map[string]int
+	var names []string // full parameter names, indexed by position - 1
+	var unnamed []int  // positions of the placeholders without name
+	hasNamedArgs := false
+	for _, nv := range args {
+		if nv.Name != "" {
+			hasNamedArgs = true
+			break
+		}
+	}
 	if paramCount > 0 {
 		nameMap = make(map[string]int, paramCount)
+		names = make([]string, paramCount)
 		for i := 1; i <= paramCount; i++ {
 			pname := turso_statement_parameter_name(stmt, i)
-			if pname == "" {
+			names[i-1] = pname
+			if !isNamedParameter(pname) {
+				unnamed = append(unnamed, i)
 				continue
 			}
-			bare := strings.TrimLeft(pname, ":@$")
-			if bare != "" {
-				nameMap[bare] = i
-			}
+			nameMap[pname[1:]] = i
 		}
 	}
 
+	positions := make([]int, len(args))
+	bound := make([]bool, paramCount+1)
+	nextUnnamed := 0
 	for idx, nv := range args {
 		pos := idx + 1
 		if nv.Name != "" {
@@ -768,17 +4304,74 @@ func bindArgs(stmt TursoStatement, args []driver.NamedValue) error {
 				return fmt.Errorf("turso: unknown named parameter %q", nv.Name)
 			}
 			pos = p
+		} else if hasNamedArgs {
+			if nextUnnamed >= len(unnamed) {
+				return fmt.Errorf("turso: got %d args, want %d", len(args), paramCount)
+			}
+			pos = unnamed[nextUnnamed]
+			nextUnnamed++
 		} else if nv.Ordinal > 0 {
 			pos = nv.Ordinal
 		}
-		if err := bindOne(stmt, pos, nv.Value); err != nil {
+		positions[idx] = pos
+		if pos > 0 && pos <= paramCount {
+			bound[pos] = true
+		}
+	}
+	for i := 1; i <= paramCount; i++ {
+		if !bound[i] && isNamedParameter(names[i-1]) {
+			return fmt.Errorf("turso: missing argument for named parameter %q", names[i-1])
+		}
+	}
+	if paramCount >= 0 && len(args) != paramCount {
+		return fmt.Errorf("turso: got %d args, want %d", len(args), paramCount)
+	}
+
+	for idx, nv := range args {
+		if err := bindOne(stmt, positions[idx], nv.Value, timeFormat); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func bindOne(stmt TursoStatement, position int, v any) error {
+// isNamedParameter reports whether the statement parameter name has :name, @name or $name form.
+// Positional ? and numbered ?NNN placeholders are not named.
+func isNamedParameter(name string) bool {
+	return len(name) > 1 && strings.IndexByte(":@$", name[0]) >= 0
+}
+
+// checkNamedValue keeps values which bindOne binds natively as-is and defers everything else
+// to the default database/sql conversion.
+func checkNamedValue(nv *driver.NamedValue) error {
+	switch x := nv.Value.(type) {
+	case nil, int, int8, int16, int32, int64, uint8, uint16, uint32,
+		float32, float64, bool, []byte, []float32, string, time.Time:
+		return nil
+	case uint:
+		return checkUint64(uint64(x))
+	case uint64:
+		return checkUint64(x)
+	case *big.Int:
+		nv.Value = bigIntValue(x)
+		return nil
+	default:
+		return driver.ErrSkip
+	}
+}
+
+// errUint64HighBit matches the error of database/sql for unsigned values which don't fit into INTEGER
+var errUint64HighBit = errors.New("turso: uint64 values with high bit set are not supported")
+
+// checkUint64 rejects unsigned values above math.MaxInt64 instead of letting them overflow
+func checkUint64(x uint64) error {
+	if x > math.MaxInt64 {
+		return errUint64HighBit
+	}
+	return nil
+}
+
+func bindOne(stmt TursoStatement, position int, v any, timeFormat TimeFormat) error {
 	if v == nil {
 		return turso_statement_bind_positional_null(stmt, position)
 	}
@@ -794,6 +4387,9 @@ func bindOne(stmt TursoStatement, position int, v any) error {
 	case int64:
 		return turso_statement_bind_positional_int(stmt, position, x)
 	case uint:
+		if err := checkUint64(uint64(x)); err != nil {
+			return err
+		}
 		return turso_statement_bind_positional_int(stmt, position, int64(x))
 	case uint8:
 		return turso_statement_bind_positional_int(stmt, position, int64(x))
@@ -802,14 +4398,10 @@ func bindOne(stmt TursoStatement, position int, v any) error {
 	case uint32:
 		return turso_statement_bind_positional_int(stmt, position, int64(x))
 	case uint64:
-		// cap at MaxInt64 to avoid overflow
-		i := int64(0)
-		if x > uint64(math.MaxInt64) {
-			i = math.MaxInt64
-		} else {
-			i = int64(x)
+		if err := checkUint64(x); err != nil {
+			return err
 		}
-		return turso_statement_bind_positional_int(stmt, position, i)
+		return turso_statement_bind_positional_int(stmt, position, int64(x))
 	case float32:
 		return turso_statement_bind_positional_double(stmt, position, float64(x))
 	case float64:
@@ -821,17 +4413,87 @@ func bindOne(stmt TursoStatement, position int, v any) error {
 		return turso_statement_bind_positional_int(stmt, position, 0)
 	case []byte:
 		return turso_statement_bind_positional_blob(stmt, position, x)
+	case []float32:
+		return turso_statement_bind_positional_blob(stmt, position, encodeVector(x))
 	case string:
 		return turso_statement_bind_positional_text(stmt, position, x)
 	case time.Time:
-		// encode as RFC3339Nano string
-		return turso_statement_bind_positional_text(stmt, position, x.Format(time.RFC3339Nano))
+		switch t := timeFormat.encode(x).(type) {
+		case int64:
+			return turso_statement_bind_positional_int(stmt, position, t)
+		default:
+			return turso_statement_bind_positional_text(stmt, position, t.(string))
+		}
 	default:
 		// Fallback to fmt to string
 		return turso_statement_bind_positional_text(stmt, position, fmt.Sprint(v))
 	}
 }
 
+// TimeFormat selects how time.Time arguments are stored and how integers of time columns are read back.
+// It's set with the _time_format DSN parameter.
+type TimeFormat int
+
+const (
+	// TimeFormatRFC3339 stores time as RFC3339 text with nanoseconds (_time_format=rfc3339, default);
+	// integers of time columns are returned as int64
+	TimeFormatRFC3339 TimeFormat = iota
+	// TimeFormatUnix stores time as INTEGER number of seconds since Unix epoch (_time_format=unix);
+	// sub-second part is truncated
+	TimeFormatUnix
+	// TimeFormatUnixMilli stores time as INTEGER number of milliseconds since Unix epoch (_time_format=unixms);
+	// sub-millisecond part is truncated
+	TimeFormatUnixMilli
+)
+
+func (f TimeFormat) String() string {
+	switch f {
+	case TimeFormatRFC3339:
+		return "rfc3339"
+	case TimeFormatUnix:
+		return "unix"
+	case TimeFormatUnixMilli:
+		return "unixms"
+	default:
+		return fmt.Sprintf("TimeFormat(%d)", int(f))
+	}
+}
+
+// parseTimeFormat parses the value of _time_format DSN parameter
+func parseTimeFormat(s string) (TimeFormat, error) {
+	for _, format := range []TimeFormat{TimeFormatRFC3339, TimeFormatUnix, TimeFormatUnixMilli} {
+		if strings.EqualFold(s, format.String()) {
+			return format, nil
+		}
+	}
+	return 0, fmt.Errorf("turso: invalid _time_format %q: expected rfc3339, unix or unixms", s)
+}
+
+// encode returns t as string or int64 value stored in the database
+func (f TimeFormat) encode(t time.Time) any {
+	switch f {
+	case TimeFormatUnix:
+		return t.Unix()
+	case TimeFormatUnixMilli:
+		return t.UnixMilli()
+	default:
+		return t.Format(time.RFC3339Nano)
+	}
+}
+
+// decodeInt converts integer of time column to time.Time in UTC; it reports false if the format stores time as text,
+// so the integer is not a timestamp
+func (f TimeFormat) decodeInt(v int64) (time.Time, bool) {
+	switch f {
+	case TimeFormatUnix:
+		return time.Unix(v, 0).UTC(), true
+	case TimeFormatUnixMilli:
+		return time.UnixMilli(v).UTC(), true
+	default:
+		return time.Time{}, false
+	}
+}
+
 // isTimeColumn checks if the column declared type indicates a time/date column.
 // This matches the behavior of github.com/mattn/go-sqlite3.
 func isTimeColumn(decltype string) bool {
@@ -870,3 +4532,68 @@ func parseTimeString(s string) (time.Time, error) {
 	}
 	return time.Time{}, fmt.Errorf("cannot parse %q as time", s)
 }
+
+// stmtCache keeps idle prepared statements of the connection keyed by SQL text, the most recently used first.
+// A statement is removed from the cache while it's in use, so the same statement is never handed to two callers.
+// The cache is guarded by mu of its connection.
+type stmtCache struct {
+	size   int
+	lru    *list.List // of *cachedStmt
+	items  map[string]*list.Element
+	closed bool
+}
+
+type cachedStmt struct {
+	query string
+	stmt  TursoStatement
+}
+
+func newStmtCache(size int) *stmtCache {
+	return &stmtCache{size: size, lru: list.New(), items: make(map[string]*list.Element)}
+}
+
+// take removes the idle statement prepared for query from the cache.
+func (s *stmtCache) take(query string) (TursoStatement, bool) {
+	e, ok := s.items[query]
+	if !ok {
+		return nil, false
+	}
+	s.lru.Remove(e)
+	delete(s.items, query)
+	return e.Value.(*cachedStmt).stmt, true
+}
+
+// put returns the idle statement to the cache along with the statements which the caller must finalize:
+// the least recently used one if the cache is full, or stmt itself if the cache already holds query or is drained.
+func (s *stmtCache) put(query string, stmt TursoStatement) []TursoStatement {
+	if _, ok := s.items[query]; ok || s.closed {
+		return []TursoStatement{stmt}
+	}
+	s.items[query] = s.lru.PushFront(&cachedStmt{query: query, stmt: stmt})
+	var evicted []TursoStatement
+	for s.lru.Len() > s.size {
+		e := s.lru.Back()
+		s.lru.Remove(e)
+		cached := e.Value.(*cachedStmt)
+		delete(s.items, cached.query)
+		evicted = append(evicted, cached.stmt)
+	}
+	return evicted
+}
+
+// evictAll empties the cache and returns all idle statements which the caller must finalize.
+func (s *stmtCache) evictAll() []TursoStatement {
+	var stmts []TursoStatement
+	for e := s.lru.Front(); e != nil; e = e.Next() {
+		stmts = append(stmts, e.Value.(*cachedStmt).stmt)
+	}
+	s.lru.Init()
+	clear(s.items)
+	return stmts
+}
+
+// drain empties the cache for good and returns all idle statements which the caller must finalize.
+func (s *stmtCache) drain() []TursoStatement {
+	s.closed = true
+	return s.evictAll()
+}
@@ -4,14 +4,21 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"math"
+	"math/big"
 	"os"
 	"path"
+	"reflect"
 	"runtime"
 	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -547,7 +554,7 @@ func TestRollbackReadTxnWithCDC(t *testing.T) {
 func TestVectorOperations(t *testing.T) {
 	db := openMem(t)
 	// Test creating table with vector columns
-	_, err := db.Exec(`CREATE TABLE vector_test (id INTEGER PRIMARY KEY, embedding F32_BLOB(64))`)
+	_, err := db.Exec(`CREATE TABLE vector_test (id INTEGER PRIMARY KEY, embedding F32_BLOB(5))`)
 	if err != nil {
 		t.Fatalf("Error creating vector table: %v", err)
 	}
@@ -916,6 +923,68 @@ func TestParametersNamed(t *testing.T) {
 	}
 }
 
+func TestParametersNamedMixedAndRepeated(t *testing.T) {
+	db := openMem(t)
+
+	t.Run("repeated named parameter", func(t *testing.T) {
+		var sum, product int
+		err := db.QueryRow("SELECT :a + :a, @b * :a", sql.Named("a", 3), sql.Named("b", 4)).Scan(&sum, &product)
+		require.NoError(t, err)
+		require.Equal(t, 6, sum)
+		require.Equal(t, 12, product)
+	})
+
+	t.Run("mixed named and positional", func(t *testing.T) {
+		var first, second, third string
+		err := db.QueryRow("SELECT ?, :name, ?", "x", sql.Named("name", "y"), "z").Scan(&first, &second, &third)
+		require.NoError(t, err)
+		require.Equal(t, []string{"x", "y", "z"}, []string{first, second, third})
+	})
+
+	t.Run("prepared statement", func(t *testing.T) {
+		stmt, err := db.Prepare("SELECT $x || $y")
+		require.NoError(t, err)
+		defer stmt.Close()
+		var value string
+		require.NoError(t, stmt.QueryRow(sql.Named("y", "b"), sql.Named("x", "a")).Scan(&value))
+		require.Equal(t, "ab", value)
+	})
+
+	t.Run("missing named parameter", func(t *testing.T) {
+		var a, b int
+		err := db.QueryRow("SELECT :a, :b", sql.Named("a", 1)).Scan(&a, &b)
+		require.ErrorContains(t, err, `missing argument for named parameter ":b"`)
+
+		stmt, err := db.Prepare("SELECT @a, @b")
+		require.NoError(t, err)
+		defer stmt.Close()
+		_, err = stmt.Exec(sql.Named("b", 1))
+		require.ErrorContains(t, err, `missing argument for named parameter "@a"`)
+	})
+
+	t.Run("unknown named parameter", func(t *testing.T) {
+		var a int
+		err := db.QueryRow("SELECT :a", sql.Named("a", 1), sql.Named("c", 2)).Scan(&a)
+		require.ErrorContains(t, err, `unknown named parameter "c"`)
+	})
+}
+
+func TestParametersUint64HighBit(t *testing.T) {
+	db := openMem(t)
+	_, err := db.ExecContext(t.Context(), "CREATE TABLE t (x INTEGER)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(t.Context(), "INSERT INTO t VALUES (?)", uint64(math.MaxInt64))
+	require.NoError(t, err)
+	// values which don't fit into INTEGER fail instead of being clamped
+	_, err = db.ExecContext(t.Context(), "INSERT INTO t VALUES (?)", uint64(math.MaxInt64)+1)
+	require.ErrorContains(t, err, "high bit set")
+	_, err = db.ExecContext(t.Context(), "INSERT INTO t VALUES (:x)", sql.Named("x", uint(math.MaxUint64)))
+	require.ErrorContains(t, err, "high bit set")
+	var n int
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&n))
+	require.Equal(t, 1, n)
+}
+
 func TestLimitOffsetParameters(t *testing.T) {
 	newConn := openMem(t)
 	sql := "CREATE TABLE test (a, b);"
@@ -1973,3 +2042,2926 @@ func TestFTS(t *testing.T) {
 		require.Greater(t, count, 0)
 	})
 }
+
+func TestContextCancellation(t *testing.T) {
+	db := openMem(t)
+	db.SetMaxOpenConns(1)
+	// long running query which never finishes in reasonable time on its own
+	longQuery := "SELECT count(*) FROM generate_series(1, 1000000000000)"
+
+	t.Run("deadline interrupts query", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		var count int
+		err := db.QueryRowContext(ctx, longQuery).Scan(&count)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Less(t, time.Since(start), 10*time.Second)
+	})
+
+	t.Run("cancel interrupts exec", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		_, err := db.ExecContext(ctx, longQuery)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("cancelled before call", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		conn, err := db.Conn(t.Context())
+		require.NoError(t, err)
+		defer conn.Close()
+		err = conn.Raw(func(driverConn any) error {
+			tc := driverConn.(*tursoDbConnection)
+			_, err := tc.ExecContext(ctx, "CREATE TABLE not_created(x)", nil)
+			require.ErrorIs(t, err, context.Canceled)
+			_, err = tc.QueryContext(ctx, "SELECT 1", nil)
+			require.ErrorIs(t, err, context.Canceled)
+			return nil
+		})
+		require.NoError(t, err)
+		var count int
+		require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM sqlite_schema WHERE name = 'not_created'").Scan(&count))
+		require.Equal(t, 0, count)
+	})
+
+	t.Run("connection is usable after interrupt", func(t *testing.T) {
+		var one int
+		require.NoError(t, db.QueryRow("SELECT 1").Scan(&one))
+		require.Equal(t, 1, one)
+	})
+}
+
+func TestInterrupt(t *testing.T) {
+	db := openMem(t)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+	var tc *tursoDbConnection
+	require.NoError(t, conn.Raw(func(driverConn any) error {
+		tc = driverConn.(*tursoDbConnection)
+		return nil
+	}))
+
+	// interrupting an idle connection doesn't affect later statements
+	tc.Interrupt()
+	var one int
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT 1").Scan(&one))
+	require.Equal(t, 1, one)
+
+	timer := time.AfterFunc(100*time.Millisecond, tc.Interrupt)
+	defer timer.Stop()
+	var count int
+	err = conn.QueryRowContext(t.Context(), "SELECT count(*) FROM generate_series(1, 1000000000000)").Scan(&count)
+	require.ErrorIs(t, err, ErrTursoInterrupt)
+	var tursoErr Error
+	require.ErrorAs(t, err, &tursoErr)
+	require.Equal(t, SQLITE_INTERRUPT, tursoErr.Code)
+
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT 1").Scan(&one))
+	require.NoError(t, conn.Close())
+	tc.Interrupt()
+}
+
+func TestCreateScalarFunction(t *testing.T) {
+	db := openMem(t)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		tc, ok := driverConn.(*tursoDbConnection)
+		require.True(t, ok, "expected *tursoDbConnection")
+		require.NoError(t, tc.CreateScalarFunction("double_it", 1, true, func(args []driver.Value) (driver.Value, error) {
+			switch x := args[0].(type) {
+			case nil:
+				return nil, nil
+			case int64:
+				return x * 2, nil
+			case float64:
+				return x * 2, nil
+			case string:
+				return x + x, nil
+			case []byte:
+				return append(append([]byte{}, x...), x...), nil
+			default:
+				return nil, fmt.Errorf("unexpected type %T", x)
+			}
+		}))
+		require.NoError(t, tc.CreateScalarFunction("concat_all", -1, false, func(args []driver.Value) (driver.Value, error) {
+			var sb strings.Builder
+			for _, arg := range args {
+				fmt.Fprint(&sb, arg)
+			}
+			return sb.String(), nil
+		}))
+		require.NoError(t, tc.CreateScalarFunction("fail", 0, false, func(args []driver.Value) (driver.Value, error) {
+			return nil, errors.New("boom")
+		}))
+		require.NoError(t, tc.CreateScalarFunction("is_positive", 1, true, func(args []driver.Value) (driver.Value, error) {
+			return args[0].(int64) > 0, nil
+		}))
+		return nil
+	})
+	require.NoError(t, err)
+
+	var i int64
+	var f float64
+	var s string
+	var b []byte
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT double_it(21), double_it(1.5), double_it('ab'), double_it(x'0102')").Scan(&i, &f, &s, &b))
+	require.Equal(t, int64(42), i)
+	require.Equal(t, 3.0, f)
+	require.Equal(t, "abab", s)
+	require.Equal(t, []byte{1, 2, 1, 2}, b)
+
+	var null sql.NullInt64
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT double_it(NULL)").Scan(&null))
+	require.False(t, null.Valid)
+
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT concat_all('a', 1, 2.5, '')").Scan(&s))
+	require.Equal(t, "a12.5", s)
+
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT is_positive(5)").Scan(&i))
+	require.Equal(t, int64(1), i)
+
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE t(x INTEGER)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "INSERT INTO t VALUES (1), (2), (3)")
+	require.NoError(t, err)
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT sum(double_it(x)) FROM t").Scan(&i))
+	require.Equal(t, int64(12), i)
+
+	err = conn.QueryRowContext(t.Context(), "SELECT fail()").Scan(&s)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "boom")
+
+	err = conn.Raw(func(driverConn any) error {
+		return driverConn.(*tursoDbConnection).RemoveFunction("double_it")
+	})
+	require.NoError(t, err)
+	err = conn.QueryRowContext(t.Context(), "SELECT double_it(1)").Scan(&i)
+	require.Error(t, err)
+}
+
+func TestOpenBlob(t *testing.T) {
+	db := openMem(t)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE media(id INTEGER PRIMARY KEY, data BLOB)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "INSERT INTO media VALUES (1, x'00010203040506070809'), (2, zeroblob(4))")
+	require.NoError(t, err)
+
+	var blob *Blob
+	withConn := func(fn func(tc *tursoDbConnection) error) error {
+		return conn.Raw(func(driverConn any) error { return fn(driverConn.(*tursoDbConnection)) })
+	}
+
+	t.Run("read and seek", func(t *testing.T) {
+		require.NoError(t, withConn(func(tc *tursoDbConnection) (err error) {
+			blob, err = tc.OpenBlob("main", "media", "data", 1, false)
+			return err
+		}))
+		defer blob.Close()
+		require.Equal(t, int64(10), blob.Len())
+
+		buf := make([]byte, 4)
+		n, err := blob.Read(buf)
+		require.NoError(t, err)
+		require.Equal(t, 4, n)
+		require.Equal(t, []byte{0, 1, 2, 3}, buf)
+
+		pos, err := blob.Seek(-2, io.SeekEnd)
+		require.NoError(t, err)
+		require.Equal(t, int64(8), pos)
+		n, err = blob.Read(buf)
+		require.ErrorIs(t, err, io.EOF)
+		require.Equal(t, 2, n)
+		require.Equal(t, []byte{8, 9}, buf[:n])
+
+		n, err = blob.ReadAt(buf[:3], 5)
+		require.NoError(t, err)
+		require.Equal(t, []byte{5, 6, 7}, buf[:n])
+
+		all, err := io.ReadAll(io.NewSectionReader(blob, 0, blob.Len()))
+		require.NoError(t, err)
+		require.Equal(t, []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, all)
+
+		_, err = blob.Write([]byte{1})
+		require.Error(t, err)
+	})
+
+	t.Run("write within size", func(t *testing.T) {
+		require.NoError(t, withConn(func(tc *tursoDbConnection) (err error) {
+			blob, err = tc.OpenBlob("", "media", "data", 2, true)
+			return err
+		}))
+		n, err := blob.Write([]byte{0xa, 0xb})
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		_, err = blob.WriteAt([]byte{0xc, 0xd}, 2)
+		require.NoError(t, err)
+
+		_, err = blob.WriteAt([]byte{1, 2}, 3)
+		require.ErrorIs(t, err, ErrTursoBlobOutOfRange)
+		require.NoError(t, blob.Close())
+		require.NoError(t, blob.Close())
+		_, err = blob.ReadAt(make([]byte, 1), 0)
+		require.ErrorIs(t, err, ErrTursoBlobClosed)
+
+		var data []byte
+		require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT data FROM media WHERE id = 2").Scan(&data))
+		require.Equal(t, []byte{0xa, 0xb, 0xc, 0xd}, data)
+	})
+
+	t.Run("missing row or column", func(t *testing.T) {
+		require.Error(t, withConn(func(tc *tursoDbConnection) (err error) {
+			_, err = tc.OpenBlob("main", "media", "data", 42, false)
+			return err
+		}))
+		require.Error(t, withConn(func(tc *tursoDbConnection) (err error) {
+			_, err = tc.OpenBlob("main", "media", "nope", 1, false)
+			return err
+		}))
+		require.Error(t, withConn(func(tc *tursoDbConnection) (err error) {
+			_, err = tc.OpenBlob("aux", "media", "data", 1, false)
+			return err
+		}))
+	})
+
+	t.Run("connection close releases blob", func(t *testing.T) {
+		require.NoError(t, withConn(func(tc *tursoDbConnection) (err error) {
+			blob, err = tc.OpenBlob("main", "media", "data", 1, false)
+			return err
+		}))
+		require.NoError(t, withConn(func(tc *tursoDbConnection) error { return tc.Close() }))
+		require.NoError(t, blob.Close())
+	})
+}
+
+func TestTypedErrors(t *testing.T) {
+	db := openMem(t)
+	_, err := db.Exec("CREATE TABLE users(id INTEGER PRIMARY KEY, email TEXT UNIQUE, name TEXT NOT NULL, age INTEGER CHECK (age >= 0))")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO users VALUES (1, 'a@example.com', 'a', 1)")
+	require.NoError(t, err)
+
+	cases := []struct {
+		query        string
+		extendedCode int
+	}{
+		{"INSERT INTO users VALUES (2, 'a@example.com', 'b', 1)", SQLITE_CONSTRAINT_UNIQUE},
+		{"INSERT INTO users VALUES (1, 'b@example.com', 'b', 1)", SQLITE_CONSTRAINT_PRIMARYKEY},
+		{"INSERT INTO users VALUES (3, 'c@example.com', NULL, 1)", SQLITE_CONSTRAINT_NOTNULL},
+		{"INSERT INTO users VALUES (4, 'd@example.com', 'd', -1)", SQLITE_CONSTRAINT_CHECK},
+	}
+	for _, c := range cases {
+		_, err = db.Exec(c.query)
+		require.Error(t, err, c.query)
+		var e Error
+		require.ErrorAs(t, err, &e, c.query)
+		require.Equal(t, SQLITE_CONSTRAINT, e.Code, c.query)
+		require.Equal(t, c.extendedCode, e.ExtendedCode, c.query)
+		require.NotEmpty(t, e.Message)
+		require.ErrorIs(t, err, ErrTursoConstraint)
+		require.True(t, IsConstraint(err))
+		require.False(t, IsBusy(err))
+	}
+
+	_, err = db.Exec("SELECT * FROM missing_table")
+	var e Error
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, SQLITE_ERROR, e.Code)
+	require.Equal(t, SQLITE_ERROR, e.ExtendedCode)
+	require.False(t, IsConstraint(err))
+
+	err = db.QueryRow("SELECT id FROM users WHERE id = 42").Scan(new(int))
+	require.True(t, IsNotFound(err))
+
+	require.True(t, IsBusy(fmt.Errorf("wrapped: %w", Error{Code: SQLITE_BUSY, ExtendedCode: SQLITE_BUSY_SNAPSHOT})))
+	require.Equal(t, "turso: database is busy: database is locked", Error{Code: SQLITE_BUSY, ExtendedCode: SQLITE_BUSY, Message: "database is locked"}.Error())
+}
+
+func TestBusyTimeoutValidation(t *testing.T) {
+	for _, dsn := range []string{":memory:?_busy_timeout=abc", ":memory:?_busy_timeout=-5", ":memory:?_busy_timeout=10ms"} {
+		_, err := sql.Open("turso", dsn)
+		require.Error(t, err, dsn)
+		require.Contains(t, err.Error(), "_busy_timeout", dsn)
+	}
+	_, err := NewConnector(":memory:", WithBusyTimeout(-2))
+	require.Error(t, err)
+}
+
+func TestBusyTimeoutAppliedToEveryPoolConnection(t *testing.T) {
+	db, err := sql.Open("turso", ":memory:?_busy_timeout=1234")
+	require.NoError(t, err)
+	defer db.Close()
+
+	// hold several connections at once so the pool has to open new physical connections
+	conns := make([]*sql.Conn, 3)
+	for i := range conns {
+		conns[i], err = db.Conn(t.Context())
+		require.NoError(t, err)
+		defer conns[i].Close()
+	}
+	for _, conn := range conns {
+		err = conn.Raw(func(driverConn any) error {
+			require.Equal(t, 1234, driverConn.(*tursoDbConnection).GetBusyTimeout())
+			return nil
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestExecScript(t *testing.T) {
+	db := openMem(t)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	execScript := func(script string) error {
+		return conn.Raw(func(driverConn any) error {
+			return driverConn.(*tursoDbConnection).ExecScript(t.Context(), script)
+		})
+	}
+
+	script := `
+-- schema
+CREATE TABLE notes(id INTEGER PRIMARY KEY, body TEXT, data BLOB);
+CREATE TABLE audit(note_id INTEGER, action TEXT);;
+;
+CREATE TRIGGER notes_ai AFTER INSERT ON notes BEGIN
+	INSERT INTO audit VALUES (new.id, 'insert; with semicolon');
+END;
+INSERT INTO notes(body, data) VALUES ('a; b', x'3b3b'); /* block; comment */
+INSERT INTO notes(body) VALUES ('it''s; fine');
+-- trailing comment`
+	require.NoError(t, execScript(script))
+
+	var count int
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM notes").Scan(&count))
+	require.Equal(t, 2, count)
+	var action string
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT action FROM audit WHERE note_id = 1").Scan(&action))
+	require.Equal(t, "insert; with semicolon", action)
+	var body string
+	var data []byte
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT body, data FROM notes WHERE id = 1").Scan(&body, &data))
+	require.Equal(t, "a; b", body)
+	require.Equal(t, []byte(";;"), data)
+
+	err = execScript("INSERT INTO notes(body) VALUES ('x'); INSERT INTO missing VALUES (1); INSERT INTO notes(body) VALUES ('y');")
+	var scriptErr *ScriptError
+	require.ErrorAs(t, err, &scriptErr)
+	require.Equal(t, 1, scriptErr.Index)
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM notes").Scan(&count))
+	require.Equal(t, 3, count, "statements before the failure must be applied and after it skipped")
+
+	err = execScript("INSERT INTO notes(id, body) VALUES (1, 'dup')")
+	require.ErrorAs(t, err, &scriptErr)
+	require.Equal(t, 0, scriptErr.Index)
+	require.ErrorIs(t, err, ErrTursoConstraint)
+
+	require.NoError(t, execScript("-- only comments\n;;"))
+}
+
+func TestCheckpoint(t *testing.T) {
+	dbPath := path.Join(t.TempDir(), "checkpoint.db")
+	db, err := sql.Open("turso", dbPath+"?_busy_timeout=100")
+	require.NoError(t, err)
+	defer db.Close()
+
+	writer, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer writer.Close()
+	_, err = writer.ExecContext(t.Context(), "PRAGMA journal_mode=WAL")
+	require.NoError(t, err)
+	_, err = writer.ExecContext(t.Context(), "CREATE TABLE t(x)")
+	require.NoError(t, err)
+	for i := range 10 {
+		_, err = writer.ExecContext(t.Context(), "INSERT INTO t VALUES (?)", i)
+		require.NoError(t, err)
+	}
+
+	checkpoint := func(conn *sql.Conn, mode CheckpointMode) (busy, log int, err error) {
+		rawErr := conn.Raw(func(driverConn any) error {
+			busy, log, err = driverConn.(*tursoDbConnection).Checkpoint(mode)
+			return nil
+		})
+		if rawErr != nil {
+			return 0, 0, rawErr
+		}
+		return busy, log, err
+	}
+
+	busy, log, err := checkpoint(writer, CheckpointPassive)
+	require.NoError(t, err)
+	require.Equal(t, 0, busy)
+	require.Greater(t, log, 0)
+
+	busy, log, err = checkpoint(writer, CheckpointTruncate)
+	require.NoError(t, err)
+	require.Equal(t, 0, busy)
+	require.Equal(t, 0, log)
+
+	_, _, err = checkpoint(writer, CheckpointMode(42))
+	require.Error(t, err)
+
+	// an open read transaction on another connection must make RESTART report busy instead of hanging
+	reader, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer reader.Close()
+	_, err = writer.ExecContext(t.Context(), "INSERT INTO t VALUES (100)")
+	require.NoError(t, err)
+	tx, err := reader.BeginTx(t.Context(), nil)
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, tx.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 11, count)
+	_, err = writer.ExecContext(t.Context(), "INSERT INTO t VALUES (101)")
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		busy, _, err = checkpoint(writer, CheckpointRestart)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("RESTART checkpoint blocked while reader holds a read lock")
+	}
+	require.NoError(t, err)
+	require.NotEqual(t, 0, busy)
+	require.NoError(t, tx.Rollback())
+
+	busy, _, err = checkpoint(writer, CheckpointTruncate)
+	require.NoError(t, err)
+	require.Equal(t, 0, busy)
+}
+
+func TestReturningRows(t *testing.T) {
+	db := openMem(t)
+	_, err := db.Exec("CREATE TABLE items(id INTEGER PRIMARY KEY, name TEXT, created_at INTEGER DEFAULT 42)")
+	require.NoError(t, err)
+
+	// Exec drops the returned rows but still reports the result of modification
+	res, err := db.Exec("INSERT INTO items(name) VALUES ('a'), ('b'), ('c') RETURNING id, created_at")
+	require.NoError(t, err)
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(3), affected)
+	lastID, err := res.LastInsertId()
+	require.NoError(t, err)
+	require.Equal(t, int64(3), lastID)
+
+	rows, err := db.QueryContext(t.Context(), "INSERT INTO items(name) VALUES ('d'), ('e') RETURNING id, created_at")
+	require.NoError(t, err)
+	var ids []int64
+	for rows.Next() {
+		var id, createdAt int64
+		require.NoError(t, rows.Scan(&id, &createdAt))
+		require.Equal(t, int64(42), createdAt)
+		ids = append(ids, id)
+	}
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+	require.Equal(t, []int64{4, 5}, ids)
+
+	// closing rows before all of them are consumed must still apply every change
+	stmt, err := db.PrepareContext(t.Context(), "UPDATE items SET name = upper(name) RETURNING id")
+	require.NoError(t, err)
+	defer stmt.Close()
+	rows, err = stmt.QueryContext(t.Context())
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Close())
+
+	var lower int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM items WHERE name <> upper(name)").Scan(&lower))
+	require.Equal(t, 0, lower)
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM items").Scan(&count))
+	require.Equal(t, 5, count)
+}
+
+func TestReadYourWrites(t *testing.T) {
+	_, err := sql.Open("turso", ":memory:?_read_your_writes=maybe")
+	require.ErrorContains(t, err, "_read_your_writes")
+
+	dbPath := path.Join(t.TempDir(), "ryw.db")
+	db, err := sql.Open("turso", dbPath+"?_read_your_writes=true")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(4)
+
+	_, err = db.Exec("CREATE TABLE t(id INTEGER PRIMARY KEY, v TEXT)")
+	require.NoError(t, err)
+
+	// pin separate physical connections so writes and reads never share one
+	writer, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer writer.Close()
+	reader, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	position := func(conn *sql.Conn) (pos walPosition, ryw *readYourWrites) {
+		require.NoError(t, conn.Raw(func(driverConn any) error {
+			tc := driverConn.(*tursoDbConnection)
+			pos, ryw = tc.walPosition(), tc.ryw
+			return nil
+		}))
+		return pos, ryw
+	}
+	_, writerRyw := position(writer)
+	_, readerRyw := position(reader)
+	require.NotNil(t, writerRyw)
+	require.Same(t, writerRyw, readerRyw, "write position must be shared by the whole pool")
+
+	for i := range 20 {
+		_, err = writer.ExecContext(t.Context(), "INSERT INTO t VALUES (?, ?)", i, fmt.Sprint(i))
+		require.NoError(t, err)
+		written, _ := position(writer)
+		require.False(t, writerRyw.latestWrite().before(written))
+
+		var count int
+		require.NoError(t, reader.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+		require.Equal(t, i+1, count)
+		read, _ := position(reader)
+		require.False(t, read.before(written))
+	}
+
+	tx, err := writer.BeginTx(t.Context(), nil)
+	require.NoError(t, err)
+	_, err = tx.Exec("INSERT INTO t VALUES (100, 'tx')")
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	var v string
+	require.NoError(t, db.QueryRow("SELECT v FROM t WHERE id = 100").Scan(&v))
+	require.Equal(t, "tx", v)
+
+	rows, err := writer.QueryContext(t.Context(), "INSERT INTO t VALUES (101, 'returning') RETURNING id")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	require.NoError(t, reader.QueryRowContext(t.Context(), "SELECT v FROM t WHERE id = 101").Scan(&v))
+	require.Equal(t, "returning", v)
+
+	require.True(t, walPosition{checkpointSeq: 1, maxFrame: 10}.before(walPosition{checkpointSeq: 2}))
+	require.False(t, walPosition{checkpointSeq: 2}.before(walPosition{checkpointSeq: 1, maxFrame: 10}))
+}
+
+func TestSavepoints(t *testing.T) {
+	db := openMem(t)
+	_, err := db.Exec("CREATE TABLE t(x)")
+	require.NoError(t, err)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	raw := func(fn func(c *tursoDbConnection) error) error {
+		return conn.Raw(func(driverConn any) error { return fn(driverConn.(*tursoDbConnection)) })
+	}
+	values := func() []int64 {
+		rows, err := conn.QueryContext(t.Context(), "SELECT x FROM t ORDER BY x")
+		require.NoError(t, err)
+		defer rows.Close()
+		var result []int64
+		for rows.Next() {
+			var x int64
+			require.NoError(t, rows.Scan(&x))
+			result = append(result, x)
+		}
+		require.NoError(t, rows.Err())
+		return result
+	}
+	insert := func(x int) {
+		_, err := conn.ExecContext(t.Context(), "INSERT INTO t VALUES (?)", x)
+		require.NoError(t, err)
+	}
+
+	// names with quotes, spaces and reserved words are quoted
+	names := []string{`outer "one"`, "select", "level 3; DROP TABLE t"}
+	_, err = conn.ExecContext(t.Context(), "BEGIN")
+	require.NoError(t, err)
+	insert(0)
+	for i, name := range names {
+		require.NoError(t, raw(func(c *tursoDbConnection) error { return c.Savepoint(name) }))
+		insert(i + 1)
+	}
+	require.Equal(t, []int64{0, 1, 2, 3}, values())
+
+	// partial rollback of the innermost level keeps the savepoint active
+	require.NoError(t, raw(func(c *tursoDbConnection) error { return c.RollbackToSavepoint(names[2]) }))
+	require.Equal(t, []int64{0, 1, 2}, values())
+	insert(4)
+	// rollback to the middle level reverts its nested level too
+	require.NoError(t, raw(func(c *tursoDbConnection) error { return c.RollbackToSavepoint(names[1]) }))
+	require.Equal(t, []int64{0, 1}, values())
+	require.NoError(t, raw(func(c *tursoDbConnection) error { return c.ReleaseSavepoint(names[0]) }))
+	_, err = conn.ExecContext(t.Context(), "COMMIT")
+	require.NoError(t, err)
+	require.Equal(t, []int64{0, 1}, values())
+
+	// savepoint outside of transaction starts one which ends with release
+	require.NoError(t, raw(func(c *tursoDbConnection) error { return c.Savepoint("auto") }))
+	insert(5)
+	require.NoError(t, raw(func(c *tursoDbConnection) error { return c.ReleaseSavepoint("auto") }))
+	require.Equal(t, []int64{0, 1, 5}, values())
+
+	// rollback to savepoint after the outer transaction rolled back is an error
+	_, err = conn.ExecContext(t.Context(), "BEGIN")
+	require.NoError(t, err)
+	require.NoError(t, raw(func(c *tursoDbConnection) error { return c.Savepoint("gone") }))
+	insert(6)
+	_, err = conn.ExecContext(t.Context(), "ROLLBACK")
+	require.NoError(t, err)
+	err = raw(func(c *tursoDbConnection) error { return c.RollbackToSavepoint("gone") })
+	require.ErrorIs(t, err, ErrTursoNoSavepoint)
+	err = raw(func(c *tursoDbConnection) error { return c.ReleaseSavepoint("gone") })
+	require.ErrorIs(t, err, ErrTursoNoSavepoint)
+	require.Equal(t, []int64{0, 1, 5}, values())
+
+	// unknown savepoint inside transaction is reported by the library
+	_, err = conn.ExecContext(t.Context(), "BEGIN")
+	require.NoError(t, err)
+	err = raw(func(c *tursoDbConnection) error { return c.RollbackToSavepoint("missing") })
+	require.ErrorContains(t, err, "no such savepoint")
+	_, err = conn.ExecContext(t.Context(), "ROLLBACK")
+	require.NoError(t, err)
+
+	err = raw(func(c *tursoDbConnection) error { return c.Savepoint("") })
+	require.Error(t, err)
+}
+
+func TestConcurrentTransactions(t *testing.T) {
+	dbPath := path.Join(t.TempDir(), "concurrent.db")
+	db, err := sql.Open("turso", dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("PRAGMA journal_mode = 'mvcc'")
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE counter(id INTEGER PRIMARY KEY, value INTEGER)")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO counter VALUES (1, 0)")
+	require.NoError(t, err)
+
+	concurrent := &sql.TxOptions{Isolation: LevelConcurrent}
+
+	// two transactions updating the same row: the second one must report conflict
+	tx1, err := db.BeginTx(t.Context(), concurrent)
+	require.NoError(t, err)
+	tx2, err := db.BeginTx(t.Context(), concurrent)
+	require.NoError(t, err)
+	_, err = tx1.Exec("UPDATE counter SET value = value + 1 WHERE id = 1")
+	require.NoError(t, err)
+	_, err = tx2.Exec("UPDATE counter SET value = value + 10 WHERE id = 1")
+	if err == nil {
+		require.NoError(t, tx1.Commit())
+		err = tx2.Commit()
+	} else {
+		require.NoError(t, tx1.Commit())
+		require.NoError(t, tx2.Rollback())
+	}
+	require.ErrorIs(t, err, ErrConcurrentConflict)
+	var conflict Error
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, SQLITE_BUSY_SNAPSHOT, conflict.ExtendedCode)
+	var value int
+	require.NoError(t, db.QueryRow("SELECT value FROM counter WHERE id = 1").Scan(&value))
+	require.Equal(t, 1, value)
+
+	// conflict-retry loop of parallel writers
+	increment := func() error {
+		for {
+			tx, err := db.BeginTx(t.Context(), concurrent)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec("UPDATE counter SET value = value + 1 WHERE id = 1")
+			if err == nil {
+				err = tx.Commit()
+			} else {
+				_ = tx.Rollback()
+			}
+			if errors.Is(err, ErrConcurrentConflict) || IsBusy(err) {
+				continue
+			}
+			return err
+		}
+	}
+	const workers, increments = 4, 10
+	done := make(chan error, workers)
+	for range workers {
+		go func() {
+			for range increments {
+				if err := increment(); err != nil {
+					done <- err
+					return
+				}
+			}
+			done <- nil
+		}()
+	}
+	for range workers {
+		require.NoError(t, <-done)
+	}
+	require.NoError(t, db.QueryRow("SELECT value FROM counter WHERE id = 1").Scan(&value))
+	require.Equal(t, 1+workers*increments, value)
+
+	// plain transactions are not affected
+	tx, err := db.BeginTx(t.Context(), nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+}
+
+func TestConnectionHooks(t *testing.T) {
+	db := openMem(t)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	type change struct {
+		op    Op
+		db    string
+		table string
+		rowid int64
+	}
+	var (
+		changes   []change
+		commits   int
+		rollbacks int
+	)
+	require.NoError(t, conn.Raw(func(driverConn any) error {
+		c := driverConn.(*tursoDbConnection)
+		require.NoError(t, c.SetUpdateHook(func(op Op, db, table string, rowid int64) {
+			changes = append(changes, change{op, db, table, rowid})
+		}))
+		require.NoError(t, c.SetCommitHook(func() { commits++ }))
+		return c.SetRollbackHook(func() { rollbacks++ })
+	}))
+
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE t (id INTEGER PRIMARY KEY AUTOINCREMENT, v TEXT)")
+	require.NoError(t, err)
+	changes, commits = nil, 0
+	_, err = conn.ExecContext(t.Context(), "INSERT INTO t (v) VALUES ('a'), ('b')")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "UPDATE t SET v = 'c' WHERE id = 2")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "DELETE FROM t WHERE id = 1")
+	require.NoError(t, err)
+	// internal sqlite_sequence changes are not reported
+	require.Equal(t, []change{
+		{OpInsert, "main", "t", 1},
+		{OpInsert, "main", "t", 2},
+		{OpUpdate, "main", "t", 2},
+		{OpDelete, "main", "t", 1},
+	}, changes)
+	require.Equal(t, 3, commits)
+	require.Zero(t, rollbacks)
+
+	// reads report neither commit nor rollback
+	var n int
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&n))
+	require.Equal(t, 3, commits)
+
+	tx, err := conn.BeginTx(t.Context(), nil)
+	require.NoError(t, err)
+	_, err = tx.Exec("INSERT INTO t (v) VALUES ('d')")
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+	require.Equal(t, 3, commits)
+	require.Equal(t, 1, rollbacks)
+	require.Len(t, changes, 5)
+
+	require.NoError(t, conn.Raw(func(driverConn any) error {
+		c := driverConn.(*tursoDbConnection)
+		require.NoError(t, c.SetUpdateHook(nil))
+		require.NoError(t, c.SetCommitHook(nil))
+		return c.SetRollbackHook(nil)
+	}))
+	_, err = conn.ExecContext(t.Context(), "INSERT INTO t (v) VALUES ('e')")
+	require.NoError(t, err)
+	require.Len(t, changes, 5)
+	require.Equal(t, 3, commits)
+	require.Equal(t, "UPDATE", OpUpdate.String())
+}
+
+func TestSetAuthorizer(t *testing.T) {
+	db := openMem(t)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE t (id INTEGER PRIMARY KEY, v TEXT)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE secret (v TEXT)")
+	require.NoError(t, err)
+
+	type access struct {
+		action     Action
+		arg1, arg2 string
+		db         string
+	}
+	var accesses []access
+	setAuthorizer := func(fn func(action Action, arg1, arg2, dbName, trigger string) AuthResult) {
+		require.NoError(t, conn.Raw(func(driverConn any) error {
+			return driverConn.(*tursoDbConnection).SetAuthorizer(fn)
+		}))
+	}
+	setAuthorizer(func(action Action, arg1, arg2, dbName, trigger string) AuthResult {
+		accesses = append(accesses, access{action, arg1, arg2, dbName})
+		switch {
+		case action == ActionRead && arg1 == "secret":
+			return AuthDeny
+		case action == ActionDelete:
+			return AuthIgnore
+		}
+		return AuthAllow
+	})
+
+	_, err = conn.ExecContext(t.Context(), "UPDATE t SET v = upper(v) WHERE id = 1")
+	require.NoError(t, err)
+	require.Equal(t, []access{
+		{ActionUpdate, "t", "v", "main"},
+		{ActionFunction, "", "upper", ""},
+	}, accesses)
+
+	accesses = nil
+	var n int
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&n))
+	require.Equal(t, []access{
+		{ActionSelect, "", "", ""},
+		{ActionFunction, "", "count", ""},
+		{ActionRead, "t", "", "main"},
+	}, accesses)
+
+	err = conn.QueryRowContext(t.Context(), "SELECT v FROM secret").Scan(new(string))
+	require.ErrorContains(t, err, `not authorized: Read "secret"`)
+
+	// ignored statement is a no-op
+	_, err = conn.ExecContext(t.Context(), "INSERT INTO t VALUES (1, 'a')")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "DELETE FROM t")
+	require.NoError(t, err)
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&n))
+	require.Equal(t, 1, n)
+
+	// panic denies the access
+	setAuthorizer(func(Action, string, string, string, string) AuthResult { panic("boom") })
+	_, err = conn.ExecContext(t.Context(), "DELETE FROM t")
+	require.ErrorContains(t, err, "not authorized")
+
+	// accesses made through views and triggers are checked as well
+	setAuthorizer(nil)
+	_, err = conn.ExecContext(t.Context(), "CREATE VIEW leak AS SELECT v FROM secret")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE log (v TEXT)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "CREATE TRIGGER copy AFTER INSERT ON log BEGIN INSERT INTO secret VALUES (new.v); END")
+	require.NoError(t, err)
+	type responsible struct {
+		action  Action
+		arg1    string
+		trigger string
+	}
+	var through []responsible
+	setAuthorizer(func(action Action, arg1, arg2, dbName, trigger string) AuthResult {
+		if trigger != "" {
+			through = append(through, responsible{action, arg1, trigger})
+		}
+		if (action == ActionRead || action == ActionInsert) && arg1 == "secret" {
+			return AuthDeny
+		}
+		return AuthAllow
+	})
+	err = conn.QueryRowContext(t.Context(), "SELECT count(*) FROM leak").Scan(&n)
+	require.ErrorContains(t, err, `not authorized: Read "secret" (through "leak")`)
+	_, err = conn.ExecContext(t.Context(), "INSERT INTO log VALUES ('x')")
+	require.ErrorContains(t, err, `not authorized: Insert "secret" (through "copy")`)
+	require.Equal(t, []responsible{
+		{ActionSelect, "", "leak"},
+		{ActionRead, "secret", "leak"},
+		{ActionInsert, "secret", "copy"},
+	}, through)
+
+	setAuthorizer(nil)
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM secret").Scan(&n))
+	_, err = conn.ExecContext(t.Context(), "DELETE FROM t")
+	require.NoError(t, err)
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&n))
+	require.Zero(t, n)
+}
+
+func TestCreateCollation(t *testing.T) {
+	db := openMem(t)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	fold := strings.NewReplacer("é", "e", "è", "e", "É", "e", "ö", "o", "Ö", "o")
+	registerCollation := func(name string, cmp func(a, b string) int) {
+		require.NoError(t, conn.Raw(func(driverConn any) error {
+			return driverConn.(*tursoDbConnection).CreateCollation(name, cmp)
+		}))
+	}
+	registerCollation("nocase_accents", func(a, b string) int {
+		return strings.Compare(fold.Replace(strings.ToLower(a)), fold.Replace(strings.ToLower(b)))
+	})
+
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE words (w TEXT)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "INSERT INTO words VALUES ('Örebro'), ('apple'), ('école'), ('Banana'), ('zebra')")
+	require.NoError(t, err)
+
+	queryWords := func(query string) []string {
+		rows, err := conn.QueryContext(t.Context(), query)
+		require.NoError(t, err)
+		defer rows.Close()
+		var words []string
+		for rows.Next() {
+			var w string
+			require.NoError(t, rows.Scan(&w))
+			words = append(words, w)
+		}
+		require.NoError(t, rows.Err())
+		return words
+	}
+	require.Equal(t, []string{"apple", "Banana", "école", "Örebro", "zebra"}, queryWords("SELECT w FROM words ORDER BY w COLLATE nocase_accents"))
+
+	_, err = conn.ExecContext(t.Context(), "CREATE INDEX words_folded ON words (w COLLATE nocase_accents)")
+	require.NoError(t, err)
+	require.Equal(t, []string{"école"}, queryWords("SELECT w FROM words WHERE w = 'ECOLE' COLLATE nocase_accents"))
+
+	// registering the same name again replaces the comparator
+	registerCollation("nocase_accents", func(a, b string) int {
+		return -strings.Compare(fold.Replace(strings.ToLower(a)), fold.Replace(strings.ToLower(b)))
+	})
+	require.Equal(t, []string{"zebra", "Örebro", "école", "Banana", "apple"}, queryWords("SELECT w FROM words ORDER BY w COLLATE nocase_accents"))
+
+	// panic in the comparator fails the query and keeps the connection usable
+	registerCollation("broken", func(a, b string) int {
+		panic("comparator bug")
+	})
+	rows, err := conn.QueryContext(t.Context(), "SELECT w FROM words ORDER BY w COLLATE broken")
+	if err == nil {
+		for rows.Next() {
+		}
+		err = rows.Err()
+		rows.Close()
+	}
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "comparator bug")
+
+	var count int
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM words").Scan(&count))
+	require.Equal(t, 5, count)
+
+	require.NoError(t, conn.Raw(func(driverConn any) error {
+		return driverConn.(*tursoDbConnection).RemoveCollation("broken")
+	}))
+	_, err = conn.QueryContext(t.Context(), "SELECT w FROM words ORDER BY w COLLATE broken")
+	require.Error(t, err)
+}
+
+func TestMemoryDatabase(t *testing.T) {
+	for _, tc := range []struct {
+		dsn    string
+		shared string
+	}{
+		{dsn: ":memory:"},
+		{dsn: "file::memory:"},
+		{dsn: "file::memory:?cache=private"},
+		{dsn: "file::memory:?cache=shared", shared: ":memory:"},
+		{dsn: "file:cachedb?mode=memory&cache=shared", shared: "cachedb"},
+		{dsn: "file:cachedb?mode=memory"},
+	} {
+		config, err := parseDSN(tc.dsn)
+		require.NoError(t, err, tc.dsn)
+		require.Equal(t, ":memory:", config.Path, tc.dsn)
+		require.Equal(t, tc.shared, config.SharedMemory, tc.dsn)
+	}
+	for _, dsn := range []string{"test.db?cache=shared", ":memory:?cache=yes", "cachedb?mode=memory"} {
+		_, err := parseDSN(dsn)
+		require.Error(t, err, dsn)
+	}
+
+	t.Run("pool shares private database", func(t *testing.T) {
+		db := openMem(t)
+		db.SetMaxOpenConns(4)
+		conn1, err := db.Conn(t.Context())
+		require.NoError(t, err)
+		defer conn1.Close()
+		conn2, err := db.Conn(t.Context())
+		require.NoError(t, err)
+		defer conn2.Close()
+
+		_, err = conn1.ExecContext(t.Context(), "CREATE TABLE t (x INTEGER)")
+		require.NoError(t, err)
+		_, err = conn1.ExecContext(t.Context(), "INSERT INTO t VALUES (1), (2)")
+		require.NoError(t, err)
+		var count int
+		require.NoError(t, conn2.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+		require.Equal(t, 2, count)
+
+		// another pool gets its own database
+		other := openMem(t)
+		_, err = other.Exec("SELECT count(*) FROM t")
+		require.Error(t, err)
+	})
+
+	t.Run("shared cache by name", func(t *testing.T) {
+		dsn := "file:" + t.Name() + "?mode=memory&cache=shared"
+		db1, err := sql.Open("turso", dsn)
+		require.NoError(t, err)
+		db2, err := sql.Open("turso", dsn)
+		require.NoError(t, err)
+
+		_, err = db1.Exec("CREATE TABLE t (x INTEGER)")
+		require.NoError(t, err)
+		_, err = db1.Exec("INSERT INTO t VALUES (1)")
+		require.NoError(t, err)
+		var count int
+		require.NoError(t, db2.QueryRow("SELECT count(*) FROM t").Scan(&count))
+		require.Equal(t, 1, count)
+
+		// the database is dropped once every pool using it is closed
+		require.NoError(t, db1.Close())
+		require.NoError(t, db2.QueryRow("SELECT count(*) FROM t").Scan(&count))
+		require.NoError(t, db2.Close())
+		db3, err := sql.Open("turso", dsn)
+		require.NoError(t, err)
+		defer db3.Close()
+		_, err = db3.Exec("SELECT count(*) FROM t")
+		require.Error(t, err)
+	})
+}
+
+func TestAttach(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := path.Join(dir, "main.db")
+	otherPath := path.Join(dir, "other.db")
+
+	other, err := sql.Open("turso", otherPath)
+	require.NoError(t, err)
+	_, err = other.Exec("CREATE TABLE prices (item TEXT, price INTEGER)")
+	require.NoError(t, err)
+	_, err = other.Exec("INSERT INTO prices VALUES ('apple', 3), ('pear', 5)")
+	require.NoError(t, err)
+	require.NoError(t, other.Close())
+
+	db, err := sql.Open("turso", mainPath+"?experimental=attach")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE orders (item TEXT, qty INTEGER)")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO orders VALUES ('apple', 2), ('pear', 1)")
+	require.NoError(t, err)
+
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+	raw := func(f func(tc *tursoDbConnection) error) error {
+		return conn.Raw(func(driverConn any) error {
+			return f(driverConn.(*tursoDbConnection))
+		})
+	}
+
+	require.NoError(t, raw(func(tc *tursoDbConnection) error { return tc.Attach(otherPath, "other") }))
+	var total int
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT sum(o.qty * p.price) FROM orders o JOIN other.prices p ON p.item = o.item").Scan(&total))
+	require.Equal(t, 11, total)
+
+	// the attachment persists for the dedicated connection
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM other.prices").Scan(&total))
+	require.Equal(t, 2, total)
+
+	err = raw(func(tc *tursoDbConnection) error { return tc.Attach(otherPath, "OTHER") })
+	require.ErrorIs(t, err, ErrTursoAlreadyAttached)
+	err = raw(func(tc *tursoDbConnection) error { return tc.Attach(otherPath, "main") })
+	require.ErrorIs(t, err, ErrTursoAlreadyAttached)
+	err = raw(func(tc *tursoDbConnection) error { return tc.Attach(mainPath, "self") })
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cannot attach the main database")
+
+	require.NoError(t, raw(func(tc *tursoDbConnection) error { return tc.Detach("other") }))
+	err = raw(func(tc *tursoDbConnection) error { return tc.Detach("other") })
+	require.ErrorIs(t, err, ErrTursoNotAttached)
+	err = raw(func(tc *tursoDbConnection) error { return tc.Detach("main") })
+	require.Error(t, err)
+	_, err = conn.ExecContext(t.Context(), "SELECT count(*) FROM other.prices")
+	require.Error(t, err)
+}
+
+func TestBackupToFile(t *testing.T) {
+	dir := t.TempDir()
+	db, err := sql.Open("turso", path.Join(dir, "source.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE INDEX t_name ON t (name)")
+	require.NoError(t, err)
+	for i := range 100 {
+		_, err = db.Exec("INSERT INTO t (name) VALUES (?)", fmt.Sprintf("name-%d", i))
+		require.NoError(t, err)
+	}
+
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+	backup := func(target string) error {
+		return conn.Raw(func(driverConn any) error {
+			return driverConn.(*tursoDbConnection).BackupToFile(target)
+		})
+	}
+	backupPath := path.Join(dir, "backup.db")
+	require.NoError(t, backup(backupPath))
+	require.Error(t, backup(backupPath), "backup must not overwrite existing file")
+	quotedPath := path.Join(dir, `it's "quoted".db`)
+	require.NoError(t, backup(quotedPath))
+	_, err = os.Stat(quotedPath)
+	require.NoError(t, err)
+
+	// writes after the snapshot don't change the copy
+	_, err = db.Exec("INSERT INTO t (name) VALUES ('late')")
+	require.NoError(t, err)
+
+	copied, err := sql.Open("turso", backupPath)
+	require.NoError(t, err)
+	defer copied.Close()
+	var count int
+	require.NoError(t, copied.QueryRow("SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 100, count)
+	var name string
+	require.NoError(t, copied.QueryRow("SELECT name FROM t WHERE name = 'name-42'").Scan(&name))
+	require.Equal(t, "name-42", name)
+	var check string
+	require.NoError(t, copied.QueryRow("PRAGMA integrity_check").Scan(&check))
+	require.Equal(t, "ok", check)
+}
+
+func TestBackup(t *testing.T) {
+	dir := t.TempDir()
+	src, err := sql.Open("turso", path.Join(dir, "source.db"))
+	require.NoError(t, err)
+	defer src.Close()
+	_, err = src.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY, payload BLOB)")
+	require.NoError(t, err)
+	for range 50 {
+		_, err = src.Exec("INSERT INTO t (payload) VALUES (randomblob(1000))")
+		require.NoError(t, err)
+	}
+	dst, err := sql.Open("turso", path.Join(dir, "backup.db"))
+	require.NoError(t, err)
+	defer dst.Close()
+
+	conn, err := src.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+	backup := func(pagesPerStep int) *BackupHandle {
+		var handle *BackupHandle
+		require.NoError(t, conn.Raw(func(driverConn any) error {
+			var err error
+			handle, err = driverConn.(*tursoDbConnection).Backup(dst, "main", "main", pagesPerStep)
+			return err
+		}))
+		return handle
+	}
+	count := func(db *sql.DB) int {
+		var n int
+		require.NoError(t, db.QueryRow("SELECT count(*) FROM t").Scan(&n))
+		return n
+	}
+
+	// unfinished backup leaves the destination as it was
+	handle := backup(2)
+	remaining, total, err := handle.Step()
+	require.NoError(t, err)
+	require.Greater(t, total, 2)
+	require.Equal(t, total-2, remaining)
+	require.NoError(t, handle.Finish())
+	require.NoError(t, handle.Finish())
+	_, err = dst.Exec("SELECT count(*) FROM t")
+	require.ErrorContains(t, err, "no such table")
+
+	handle = backup(2)
+	defer handle.Finish()
+	_, firstTotal, err := handle.Step()
+	require.NoError(t, err)
+	// the write between steps restarts the copy, so it gets into the destination
+	_, err = src.Exec("INSERT INTO t (payload) VALUES (randomblob(5000))")
+	require.NoError(t, err)
+	steps := 1
+	for {
+		remaining, total, err = handle.Step()
+		require.NoError(t, err)
+		steps++
+		if remaining == 0 {
+			break
+		}
+	}
+	require.Greater(t, total, firstTotal)
+	require.Greater(t, steps, total/2)
+	remaining, _, err = handle.Step()
+	require.NoError(t, err)
+	require.Zero(t, remaining)
+	require.NoError(t, handle.Finish())
+	_, _, err = handle.Step()
+	require.Error(t, err)
+
+	require.Equal(t, 51, count(dst))
+	var check string
+	require.NoError(t, dst.QueryRow("PRAGMA integrity_check").Scan(&check))
+	require.Equal(t, "ok", check)
+	// the destination is a regular database after the backup
+	_, err = dst.Exec("INSERT INTO t (payload) VALUES (x'00')")
+	require.NoError(t, err)
+	require.Equal(t, 52, count(dst))
+	require.Equal(t, 51, count(src))
+
+	_, err = conn.ExecContext(t.Context(), "BEGIN")
+	require.NoError(t, err)
+	handle = backup(-1)
+	defer handle.Finish()
+	_, _, err = handle.Step()
+	require.Error(t, err, "source inside transaction")
+	_, err = conn.ExecContext(t.Context(), "ROLLBACK")
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Raw(func(driverConn any) error {
+		_, err := driverConn.(*tursoDbConnection).Backup(dst, "main", "temp", 1)
+		require.ErrorContains(t, err, "only main database")
+		return nil
+	}))
+}
+
+func TestTimeFormat(t *testing.T) {
+	_, err := sql.Open("turso", ":memory:?_time_format=iso")
+	require.Error(t, err)
+
+	moment := time.Date(2024, 6, 15, 14, 30, 45, 123456789, time.UTC)
+	for _, tc := range []struct {
+		format string
+		stored any
+		read   time.Time
+	}{
+		{format: "rfc3339", stored: moment.Format(time.RFC3339Nano), read: moment},
+		{format: "unix", stored: moment.Unix(), read: moment.Truncate(time.Second)},
+		{format: "unixms", stored: moment.UnixMilli(), read: moment.Truncate(time.Millisecond)},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			db, err := sql.Open("turso", ":memory:?_time_format="+tc.format)
+			require.NoError(t, err)
+			defer db.Close()
+			_, err = db.Exec("CREATE TABLE events (id INTEGER PRIMARY KEY, at DATETIME, deleted_at DATETIME)")
+			require.NoError(t, err)
+			_, err = db.Exec("INSERT INTO events (id, at, deleted_at) VALUES (1, ?, NULL)", moment)
+			require.NoError(t, err)
+
+			// expression columns have no declared type, so the stored value comes back as-is
+			var stored any
+			require.NoError(t, db.QueryRow("SELECT coalesce(at, NULL) FROM events").Scan(&stored))
+			require.Equal(t, tc.stored, stored)
+
+			var at time.Time
+			require.NoError(t, db.QueryRow("SELECT at FROM events").Scan(&at))
+			require.True(t, tc.read.Equal(at), "expected %v, got %v", tc.read, at)
+
+			var deleted sql.NullTime
+			require.NoError(t, db.QueryRow("SELECT deleted_at FROM events").Scan(&deleted))
+			require.False(t, deleted.Valid)
+			require.Error(t, db.QueryRow("SELECT deleted_at FROM events").Scan(&at), "NULL must not scan into time.Time")
+		})
+	}
+
+	t.Run("integers follow the configured format", func(t *testing.T) {
+		for format, expected := range map[string]time.Time{
+			"unix":   time.Unix(1700000000, 0).UTC(),
+			"unixms": time.UnixMilli(1700000000).UTC(),
+		} {
+			db, err := sql.Open("turso", ":memory:?_time_format="+format)
+			require.NoError(t, err)
+			_, err = db.Exec("CREATE TABLE events (at TIMESTAMP)")
+			require.NoError(t, err)
+			_, err = db.Exec("INSERT INTO events VALUES (1700000000)")
+			require.NoError(t, err)
+			var at time.Time
+			require.NoError(t, db.QueryRow("SELECT at FROM events").Scan(&at))
+			require.True(t, expected.Equal(at), "%s: expected %v, got %v", format, expected, at)
+			require.NoError(t, db.Close())
+		}
+
+		// with text format integers are not timestamps
+		db := openMem(t)
+		_, err := db.Exec("CREATE TABLE events (at TIMESTAMP)")
+		require.NoError(t, err)
+		_, err = db.Exec("INSERT INTO events VALUES (1700000000)")
+		require.NoError(t, err)
+		var raw int64
+		require.NoError(t, db.QueryRow("SELECT at FROM events").Scan(&raw))
+		require.Equal(t, int64(1700000000), raw)
+	})
+}
+
+func TestLoadExtension(t *testing.T) {
+	_, err := sql.Open("turso", ":memory:?_allow_load_extension=sure")
+	require.Error(t, err)
+
+	load := func(db *sql.DB, path, entryPoint string) error {
+		conn, err := db.Conn(t.Context())
+		require.NoError(t, err)
+		defer conn.Close()
+		return conn.Raw(func(driverConn any) error {
+			return driverConn.(*tursoDbConnection).LoadExtension(path, entryPoint)
+		})
+	}
+	missing := path.Join(t.TempDir(), "missing_extension.so")
+
+	require.ErrorIs(t, load(openMem(t), missing, ""), ErrTursoLoadExtensionDisabled)
+
+	db, err := sql.Open("turso", ":memory:?_allow_load_extension=true")
+	require.NoError(t, err)
+	defer db.Close()
+	err = load(db, missing, "")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrTursoLoadExtensionDisabled)
+	require.Contains(t, err.Error(), "missing_extension")
+
+	// the error carries the reason reported by the dynamic loader
+	notLibrary := path.Join(t.TempDir(), "not_a_library.so")
+	// long enough for the loader to check the header instead of reporting a too short file
+	require.NoError(t, os.WriteFile(notLibrary, []byte(strings.Repeat("not a shared library\n", 16)), 0o600))
+	loaderReasons := map[string]struct{ missing, notLibrary string }{
+		"linux":  {"cannot open shared object file", "invalid ELF header"},
+		"darwin": {"no such file", "not a mach-o file"},
+	}
+	if reasons, ok := loaderReasons[runtime.GOOS]; ok {
+		require.ErrorContains(t, err, reasons.missing)
+		require.ErrorContains(t, load(db, notLibrary, ""), reasons.notLibrary)
+	} else {
+		require.Error(t, load(db, notLibrary, ""))
+	}
+
+	err = load(db, missing, "sqlite3_extension_init")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "entry point")
+}
+
+func TestVector(t *testing.T) {
+	db := openMem(t)
+	_, err := db.Exec("CREATE TABLE docs (id INTEGER PRIMARY KEY, embedding F32_BLOB(3))")
+	require.NoError(t, err)
+	for id, embedding := range map[int][]float32{
+		1: {1, 0, 0},
+		2: {0, 1, 0},
+		3: {0.9, 0.1, 0},
+	} {
+		_, err = db.Exec("INSERT INTO docs VALUES (?, ?)", id, Vector(embedding))
+		require.NoError(t, err)
+	}
+	// plain []float32 binds as vector too
+	_, err = db.Exec("INSERT INTO docs VALUES (4, ?)", []float32{0, 0, 1})
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO docs VALUES (5, NULL)")
+	require.NoError(t, err)
+
+	var floats []float32
+	require.NoError(t, db.QueryRow("SELECT embedding FROM docs WHERE id = 3").Scan((*Vector)(&floats)))
+	require.Equal(t, []float32{0.9, 0.1, 0}, floats)
+	// vectors built by the library scan in the same way
+	require.NoError(t, db.QueryRow("SELECT vector32('[0.5, 0.25]')").Scan((*Vector)(&floats)))
+	require.Equal(t, []float32{0.5, 0.25}, floats)
+	var extracted string
+	require.NoError(t, db.QueryRow("SELECT vector_extract(embedding) FROM docs WHERE id = 4").Scan(&extracted))
+	require.Equal(t, "[0,0,1]", extracted)
+
+	var v Vector
+	require.NoError(t, db.QueryRow("SELECT embedding FROM docs WHERE id = 5").Scan(&v))
+	require.Nil(t, v)
+	require.Error(t, db.QueryRow("SELECT x'010203040506'").Scan(&v), "length must be a multiple of 4")
+
+	// writes of vectors with another number of dimensions are rejected
+	_, err = db.Exec("INSERT INTO docs VALUES (6, ?)", Vector{1, 0})
+	require.ErrorIs(t, err, ErrVectorDimension)
+	require.True(t, IsConstraint(err))
+	_, err = db.Exec("UPDATE docs SET embedding = ? WHERE id = 5", []float32{1, 0, 0, 0})
+	require.ErrorIs(t, err, ErrVectorDimension)
+	require.NoError(t, db.QueryRow("SELECT embedding FROM docs WHERE id = 5").Scan(&v))
+	require.Nil(t, v)
+
+	neighbors, err := KNN(t.Context(), db, "docs", "embedding", []float32{1, 0, 0}, 2)
+	require.NoError(t, err)
+	require.Len(t, neighbors, 2)
+	require.Equal(t, int64(1), neighbors[0].RowID)
+	require.InDelta(t, 0, neighbors[0].Distance, 1e-6)
+	require.Equal(t, int64(3), neighbors[1].RowID)
+	require.Less(t, neighbors[0].Distance, neighbors[1].Distance)
+
+	neighbors, err = KNN(t.Context(), db, "docs", "embedding", []float32{0, 0, 1}, 10)
+	require.NoError(t, err)
+	require.Len(t, neighbors, 4, "rows without vector are skipped")
+
+	_, err = KNN(t.Context(), db, "docs", "embedding", []float32{1, 0}, 2)
+	require.ErrorIs(t, err, ErrVectorDimension)
+	_, err = KNN(t.Context(), db, "docs", "missing", []float32{1, 0, 0}, 2)
+	require.Error(t, err)
+
+	// KNN goes through vector_top_k only for vector indexes on the column
+	require.True(t, isVectorIndexOn("CREATE INDEX docs_idx ON docs (libsql_vector_idx(embedding))", "embedding"))
+	require.True(t, isVectorIndexOn(`CREATE INDEX docs_idx ON docs(LIBSQL_VECTOR_IDX( "Embedding", 'metric=cosine'))`, "embedding"))
+	require.False(t, isVectorIndexOn("CREATE INDEX docs_idx ON docs (libsql_vector_idx(embedding2))", "embedding"))
+	require.False(t, isVectorIndexOn("CREATE INDEX docs_idx ON docs (embedding)", "embedding"))
+	_, err = db.Exec("CREATE INDEX docs_embedding ON docs (embedding)")
+	require.NoError(t, err)
+	neighbors, err = KNN(t.Context(), db, "docs", "embedding", []float32{1, 0, 0}, 1)
+	require.NoError(t, err)
+	require.Equal(t, []Neighbor{{RowID: 1, Distance: neighbors[0].Distance}}, neighbors)
+}
+
+func TestPragmaHelpers(t *testing.T) {
+	db, err := sql.Open("turso", path.Join(t.TempDir(), "pragma.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.Raw(func(driverConn any) error {
+		tc := driverConn.(*tursoDbConnection)
+
+		require.NoError(t, tc.SetJournalMode("wal"))
+		mode, err := tc.JournalMode()
+		require.NoError(t, err)
+		require.Equal(t, "wal", mode)
+
+		require.NoError(t, tc.CacheSize(-4000))
+		size, err := tc.GetPragmaInt("cache_size")
+		require.NoError(t, err)
+		require.Equal(t, int64(-4000), size)
+		require.NoError(t, tc.CacheSize(500))
+		size, err = tc.GetPragmaInt("main.cache_size")
+		require.NoError(t, err)
+		require.Equal(t, int64(500), size)
+
+		require.NoError(t, tc.ForeignKeys(true))
+		enabled, err := tc.GetPragmaInt("foreign_keys")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), enabled)
+		require.NoError(t, tc.ForeignKeys(false))
+		enabled, err = tc.GetPragmaInt("foreign_keys")
+		require.NoError(t, err)
+		require.Equal(t, int64(0), enabled)
+
+		require.NoError(t, tc.SetPragma("synchronous", "NORMAL"))
+		synchronous, err := tc.Synchronous()
+		require.NoError(t, err)
+		require.Equal(t, int64(1), synchronous)
+
+		encoding, err := tc.GetPragmaString("encoding")
+		require.NoError(t, err)
+		require.Equal(t, "UTF-8", encoding)
+
+		require.Error(t, tc.SetPragma("cache_size; DROP TABLE t", 1))
+		require.Error(t, tc.SetPragma("journal_mode", "wal'; --"))
+		require.Error(t, tc.SetPragma("cache_size", []int{1}))
+		_, err = tc.GetPragmaInt("encoding")
+		require.Error(t, err)
+		return nil
+	}))
+}
+
+func TestVersion(t *testing.T) {
+	version := Version()
+	require.NotEmpty(t, version)
+	n, err := parseVersionNumber(version)
+	require.NoError(t, err)
+	require.Equal(t, n, VersionNumber())
+	require.Greater(t, VersionNumber(), 0)
+	require.NotEmpty(t, SourceID())
+	_ = CompileOptions()
+
+	n, err = parseVersionNumber("0.4.1-pre.2")
+	require.NoError(t, err)
+	require.Equal(t, 4001, n)
+	n, err = parseVersionNumber("3.45.12+build")
+	require.NoError(t, err)
+	require.Equal(t, 3045012, n)
+	_, err = parseVersionNumber("0.4")
+	require.Error(t, err)
+	_, err = parseVersionNumber("a.b.c")
+	require.Error(t, err)
+}
+
+func TestShutdownLibrary(t *testing.T) {
+	db := openMem(t)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	require.ErrorIs(t, ShutdownLibrary(), ErrTursoLibraryInUse)
+	require.NoError(t, conn.Close())
+	require.NoError(t, db.Close())
+	if libraryUsers.Load() > 0 {
+		t.Skip("library is held by databases of other tests")
+	}
+
+	require.NoError(t, ShutdownLibrary())
+	require.NoError(t, ShutdownLibrary())
+	require.Panics(t, func() { _ = Version() })
+	_, err = openConnection(TursoDatabaseConfig{Path: ":memory:"})
+	require.ErrorIs(t, err, ErrTursoLibraryNotLoaded)
+
+	InitLibrary(turso_libs.LoadTursoLibraryConfig{LoadStrategy: "mixed"})
+	require.NotEmpty(t, Version())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				InitLibrary(turso_libs.LoadTursoLibraryConfig{LoadStrategy: "mixed"})
+				_ = ShutdownLibrary()
+			}
+		}()
+	}
+	wg.Wait()
+	InitLibrary(turso_libs.LoadTursoLibraryConfig{LoadStrategy: "mixed"})
+	require.NotEmpty(t, Version())
+}
+
+func TestStmtCache(t *testing.T) {
+	_, err := parseDSN(":memory:?_stmt_cache_size=-1")
+	require.Error(t, err)
+	config, err := parseDSN(":memory:?_stmt_cache_size=2")
+	require.NoError(t, err)
+	require.Equal(t, 2, config.StmtCacheSize)
+
+	db, err := sql.Open("turso", ":memory:?_stmt_cache_size=2")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// cached returns the idle statement cached for query (nil if there is none)
+	cached := func(query string) TursoStatement {
+		var stmt TursoStatement
+		require.NoError(t, conn.Raw(func(driverConn any) error {
+			c := driverConn.(*tursoDbConnection)
+			if e, ok := c.stmts.items[query]; ok {
+				stmt = e.Value.(*cachedStmt).stmt
+			}
+			return nil
+		}))
+		return stmt
+	}
+
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE t (id INTEGER PRIMARY KEY, v TEXT)")
+	require.NoError(t, err)
+	const insert = "INSERT INTO t (id, v) VALUES (?, ?)"
+	var insertStmt TursoStatement
+	for i := 0; i < 10; i++ {
+		_, err = conn.ExecContext(t.Context(), insert, i, fmt.Sprint("v", i))
+		require.NoError(t, err)
+		// every execution after the first one reuses the same statement
+		if i == 0 {
+			insertStmt = cached(insert)
+			require.NotNil(t, insertStmt)
+		}
+		require.Equal(t, insertStmt, cached(insert))
+	}
+	for i := 0; i < 10; i++ {
+		var v string
+		require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT v FROM t WHERE id = ?", i).Scan(&v))
+		require.Equal(t, fmt.Sprint("v", i), v)
+	}
+
+	// partially consumed rows are finalized and the statement is prepared again
+	rows, err := conn.QueryContext(t.Context(), "SELECT id FROM t ORDER BY id")
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Close())
+	var count int
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 10, count)
+
+	// more distinct queries than the cache holds evict the least recently used statements
+	for _, q := range []string{"SELECT 1", "SELECT 2", "SELECT 3", "SELECT 1"} {
+		var n int
+		require.NoError(t, conn.QueryRowContext(t.Context(), q).Scan(&n))
+	}
+	require.Nil(t, cached(insert))
+	require.Nil(t, cached("SELECT 2"))
+	require.NotNil(t, cached("SELECT 3"))
+	require.NotNil(t, cached("SELECT 1"))
+
+	// cached statement observes schema changes
+	_, err = conn.ExecContext(t.Context(), "ALTER TABLE t ADD COLUMN w INTEGER DEFAULT 7")
+	require.NoError(t, err)
+	var w int
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT coalesce(w, 0) FROM t WHERE id = ?", 1).Scan(&w))
+	require.Equal(t, 7, w)
+	var v string
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT v FROM t WHERE id = ?", 3).Scan(&v))
+	require.Equal(t, "v3", v)
+
+	cache := newStmtCache(1)
+	require.Empty(t, cache.put("a", TursoStatement(nil)))
+	_, ok := cache.take("b")
+	require.False(t, ok)
+	_, ok = cache.take("a")
+	require.True(t, ok)
+	_, ok = cache.take("a")
+	require.False(t, ok)
+}
+
+func TestBulkInsert(t *testing.T) {
+	db := openMem(t)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT, score REAL)")
+	require.NoError(t, err)
+
+	rows := make([][]any, 1000)
+	for i := range rows {
+		rows[i] = []any{i, fmt.Sprint("name", i), float64(i) / 2}
+	}
+	bulkInsert := func(rows [][]any, batchSize int) (inserted int64, err error) {
+		err = conn.Raw(func(driverConn any) error {
+			inserted, err = driverConn.(*tursoDbConnection).BulkInsert(t.Context(), "t", []string{"id", "name", "score"}, rows, batchSize)
+			return err
+		})
+		return inserted, err
+	}
+	// 3 columns allow at most 333 rows per statement
+	inserted, err := bulkInsert(rows, 500)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), inserted)
+	var count int
+	var sum float64
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*), sum(score) FROM t").Scan(&count, &sum))
+	require.Equal(t, 1000, count)
+	require.Equal(t, 249750.0, sum)
+
+	_, err = bulkInsert([][]any{{1000, "a", 1.0}, {1001, "b"}}, 0)
+	require.ErrorContains(t, err, "row 1 has 2 values")
+	// failed batch reverts the whole call
+	inserted, err = bulkInsert([][]any{{1000, "a", 1.0}, {1001, "b", 2.0}, {0, "dup", 3.0}}, 2)
+	require.Error(t, err)
+	require.Zero(t, inserted)
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 1000, count)
+
+	// inside of transaction rows of the call are committed by the transaction
+	_, err = conn.ExecContext(t.Context(), "BEGIN")
+	require.NoError(t, err)
+	inserted, err = bulkInsert([][]any{{1000, nil, nil}}, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), inserted)
+	_, err = conn.ExecContext(t.Context(), "ROLLBACK")
+	require.NoError(t, err)
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 1000, count)
+}
+
+func TestOpenMode(t *testing.T) {
+	config, err := parseDSN("test.db?mode=ro&_mutex=no")
+	require.NoError(t, err)
+	require.True(t, config.ReadOnly)
+	config, err = parseDSN("test.db?mode=rw&immutable=1&_mutex=full")
+	require.NoError(t, err)
+	require.True(t, config.NoCreate)
+	require.True(t, config.Immutable)
+	for _, dsn := range []string{"test.db?mode=wr", "test.db?immutable=maybe", "test.db?_mutex=serialized", ":memory:?mode=ro"} {
+		_, err := parseDSN(dsn)
+		require.Error(t, err, dsn)
+	}
+
+	dir := t.TempDir()
+	missing := path.Join(dir, "missing.db")
+	for _, mode := range []string{"ro", "rw"} {
+		db, err := sql.Open("turso", missing+"?mode="+mode)
+		require.NoError(t, err)
+		require.Error(t, db.Ping())
+		require.NoError(t, db.Close())
+		_, err = os.Stat(missing)
+		require.True(t, os.IsNotExist(err))
+	}
+
+	file := path.Join(dir, "local.db")
+	rw, err := sql.Open("turso", file)
+	require.NoError(t, err)
+	defer rw.Close()
+	_, err = rw.Exec("CREATE TABLE t (x INTEGER)")
+	require.NoError(t, err)
+	_, err = rw.Exec("INSERT INTO t VALUES (1)")
+	require.NoError(t, err)
+
+	ro, err := sql.Open("turso", file+"?mode=ro")
+	require.NoError(t, err)
+	defer ro.Close()
+	var count int
+	require.NoError(t, ro.QueryRow("SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 1, count)
+	_, err = ro.Exec("INSERT INTO t VALUES (2)")
+	require.ErrorIs(t, err, ErrTursoReadOnly)
+
+	_, err = rw.Exec("INSERT INTO t VALUES (3)")
+	require.NoError(t, err)
+	require.NoError(t, ro.QueryRow("SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 2, count)
+
+	// the file opened read-only first can't be opened read-write while the read-only database is open
+	first := path.Join(dir, "first.db")
+	db, err := sql.Open("turso", first)
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE t (x INTEGER)")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+	roFirst, err := sql.Open("turso", first+"?mode=ro")
+	require.NoError(t, err)
+	require.NoError(t, roFirst.Ping())
+	rwLater, err := sql.Open("turso", first)
+	require.NoError(t, err)
+	_, err = rwLater.Exec("INSERT INTO t VALUES (1)")
+	require.ErrorIs(t, err, ErrTursoReadOnly)
+	require.ErrorContains(t, err, "already opened read-only")
+	require.NoError(t, rwLater.Close())
+	_, err = roFirst.Exec("INSERT INTO t VALUES (1)")
+	require.ErrorIs(t, err, ErrTursoReadOnly)
+	require.NoError(t, roFirst.Close())
+
+	rwLater, err = sql.Open("turso", first)
+	require.NoError(t, err)
+	defer rwLater.Close()
+	_, err = rwLater.Exec("INSERT INTO t VALUES (1)")
+	require.NoError(t, err)
+}
+
+func TestColumnTypes(t *testing.T) {
+	db := openMem(t)
+	_, err := db.ExecContext(t.Context(), `CREATE TABLE t (
+		id INTEGER PRIMARY KEY,
+		name varchar(10) NOT NULL,
+		score REAL,
+		data BLOB,
+		note TEXT,
+		created DATETIME
+	)`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(t.Context(), "INSERT INTO t VALUES (1, 'a', 1.5, x'01', NULL, '2024-01-02 03:04:05')")
+	require.NoError(t, err)
+
+	rows, err := db.QueryContext(t.Context(), "SELECT id, name, score, data, note, created, count(*) FROM t")
+	require.NoError(t, err)
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	require.Len(t, types, 7)
+
+	expected := []struct {
+		name     string
+		scanType reflect.Type
+		nullable bool
+		ok       bool
+	}{
+		{"INTEGER", reflect.TypeOf(int64(0)), false, true},
+		{"VARCHAR(10)", reflect.TypeOf(""), false, true},
+		{"REAL", reflect.TypeOf(float64(0)), true, true},
+		{"BLOB", reflect.TypeOf([]byte(nil)), true, true},
+		{"TEXT", reflect.TypeOf(""), true, true},
+		{"DATETIME", reflect.TypeOf(time.Time{}), true, true},
+		{"", reflect.TypeOf((*any)(nil)).Elem(), false, false},
+	}
+	for i, column := range types {
+		require.Equal(t, expected[i].name, column.DatabaseTypeName(), column.Name())
+		require.Equal(t, expected[i].scanType, column.ScanType(), column.Name())
+		nullable, ok := column.Nullable()
+		require.Equal(t, expected[i].ok, ok, column.Name())
+		require.Equal(t, expected[i].nullable, nullable, column.Name())
+	}
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Err())
+}
+
+func TestRowsStreaming(t *testing.T) {
+	db := openMem(t)
+	rows, err := db.QueryContext(t.Context(), "SELECT value, 'row ' || value FROM generate_series(1, 1000000)")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	heapAlloc := func() uint64 {
+		runtime.GC()
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		return stats.HeapAlloc
+	}
+	before := heapAlloc()
+	var peak uint64
+	count, sum := 0, int64(0)
+	for rows.Next() {
+		var value int64
+		var text string
+		require.NoError(t, rows.Scan(&value, &text))
+		count++
+		sum += value
+		if count%100000 == 0 {
+			peak = max(peak, heapAlloc())
+		}
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, 1000000, count)
+	require.Equal(t, int64(500000500000), sum)
+	// rows are converted one at a time: live heap must not grow with the size of the result set
+	require.Less(t, int64(peak)-int64(before), int64(8<<20))
+}
+
+func TestRowsCloseEarly(t *testing.T) {
+	db := openMem(t)
+	rows, err := db.QueryContext(t.Context(), "SELECT value FROM generate_series(1, 1000000000000)")
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		require.True(t, rows.Next())
+	}
+	require.NoError(t, rows.Err())
+	// closing must not step through the remaining rows of a read-only statement
+	start := time.Now()
+	require.NoError(t, rows.Close())
+	require.Less(t, time.Since(start), time.Second)
+
+	var one int
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT 1").Scan(&one))
+	require.Equal(t, 1, one)
+}
+
+func TestPingBadConn(t *testing.T) {
+	db := openMem(t)
+	require.NoError(t, db.PingContext(t.Context()))
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.PingContext(t.Context()))
+
+	require.NoError(t, conn.Raw(func(driverConn any) error {
+		c := driverConn.(*tursoDbConnection)
+		require.True(t, c.IsValid())
+		require.NoError(t, c.Close())
+		require.False(t, c.IsValid())
+		require.ErrorIs(t, c.Ping(t.Context()), driver.ErrBadConn)
+		return nil
+	}))
+	// the pool replaces the broken connection
+	require.NoError(t, db.PingContext(t.Context()))
+}
+
+func TestLastInsertId(t *testing.T) {
+	db := openMem(t)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT UNIQUE)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE log (id INTEGER PRIMARY KEY, msg TEXT)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE kv (k TEXT PRIMARY KEY, v TEXT) WITHOUT ROWID")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "INSERT INTO log VALUES (100, 'start')")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "CREATE TRIGGER t_insert AFTER INSERT ON t BEGIN INSERT INTO log (msg) VALUES (new.name); END")
+	require.NoError(t, err)
+
+	lastInsertId := func(query string) int64 {
+		result, err := conn.ExecContext(t.Context(), query)
+		require.NoError(t, err)
+		id, err := result.LastInsertId()
+		require.NoError(t, err)
+		return id
+	}
+	// rows inserted by the trigger don't change the rowid of the statement
+	require.Equal(t, int64(5), lastInsertId("INSERT INTO t VALUES (5, 'a')"))
+	require.Equal(t, int64(0), lastInsertId("INSERT OR IGNORE INTO t VALUES (6, 'a')"))
+	require.Equal(t, int64(0), lastInsertId("UPDATE t SET name = 'b' WHERE id = 5"))
+	require.Equal(t, int64(0), lastInsertId("INSERT INTO kv VALUES ('k', 'v')"))
+	require.Equal(t, int64(7), lastInsertId("INSERT INTO t VALUES (7, 'c'); UPDATE t SET name = 'd' WHERE id = 7"))
+
+	// last_insert_rowid() of the connection is not affected by statements which didn't insert
+	var rowid int64
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT last_insert_rowid()").Scan(&rowid))
+	require.Equal(t, int64(7), rowid)
+}
+
+func TestQueryLimits(t *testing.T) {
+	db := openMem(t)
+
+	ctx := WithMaxRows(t.Context(), 10)
+	rows, err := db.QueryContext(ctx, "SELECT value FROM generate_series(1, 100)")
+	require.NoError(t, err)
+	count := 0
+	for rows.Next() {
+		count++
+	}
+	require.ErrorIs(t, rows.Err(), ErrTursoMaxRows)
+	require.Equal(t, 10, count)
+	require.NoError(t, rows.Close())
+	// result set within the limit is not affected
+	var n int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT count(*) FROM generate_series(1, 100)").Scan(&n))
+	require.Equal(t, 100, n)
+
+	longQuery := "SELECT count(*) FROM generate_series(1, 1000000000000)"
+	ctx = WithScanTimeout(ctx, 100*time.Millisecond)
+	start := time.Now()
+	err = db.QueryRowContext(ctx, longQuery).Scan(&n)
+	require.ErrorIs(t, err, ErrTursoScanTimeout)
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	// the deadline of the context stops the scan if it expires first
+	deadline, cancel := context.WithTimeout(WithScanTimeout(t.Context(), time.Hour), 100*time.Millisecond)
+	defer cancel()
+	err = db.QueryRowContext(deadline, longQuery).Scan(&n)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the timeout starts anew for every query
+	for i := 0; i < 3; i++ {
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT 1").Scan(&n))
+	}
+}
+
+func TestQueryLogger(t *testing.T) {
+	db := openMem(t)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var mu sync.Mutex
+	var events []QueryEvent
+	SetContextLogger(func(ctx context.Context, event QueryEvent) {
+		require.NotNil(t, ctx)
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	})
+	t.Cleanup(func() { SetLogger(nil) })
+	takeEvents := func() []QueryEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		taken := events
+		events = nil
+		return taken
+	}
+
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE t (x INTEGER)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "INSERT INTO t VALUES (?), (?)", 1, 2)
+	require.NoError(t, err)
+	rows, err := conn.QueryContext(t.Context(), "SELECT x FROM t")
+	require.NoError(t, err)
+	for rows.Next() {
+		var x int
+		require.NoError(t, rows.Scan(&x))
+	}
+	require.NoError(t, rows.Close())
+	_, err = conn.ExecContext(t.Context(), "INSERT INTO missing VALUES (1)")
+	require.Error(t, err)
+
+	events = takeEvents()
+	require.Len(t, events, 4)
+	require.Equal(t, QueryEventExec, events[0].Kind)
+	require.Equal(t, "CREATE TABLE t (x INTEGER)", events[0].SQL)
+	require.Equal(t, QueryEventExec, events[1].Kind)
+	require.Equal(t, int64(2), events[1].RowsAffected)
+	require.Len(t, events[1].Args, 2)
+	require.Equal(t, int64(1), events[1].Args[0].Value)
+	require.Equal(t, QueryEventQuery, events[2].Kind)
+	require.Equal(t, int64(2), events[2].Rows)
+	require.NoError(t, events[2].Err)
+	require.Error(t, events[3].Err)
+	for _, event := range events {
+		require.Positive(t, event.Duration)
+	}
+
+	tx, err := conn.BeginTx(t.Context(), nil)
+	require.NoError(t, err)
+	stmt, err := tx.PrepareContext(t.Context(), "INSERT INTO t VALUES (?)")
+	require.NoError(t, err)
+	_, err = stmt.ExecContext(t.Context(), 3)
+	require.NoError(t, err)
+	require.NoError(t, stmt.Close())
+	require.NoError(t, tx.Commit())
+	tx, err = conn.BeginTx(t.Context(), nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	var kinds []QueryEventKind
+	for _, event := range takeEvents() {
+		kinds = append(kinds, event.Kind)
+	}
+	require.Equal(t, []QueryEventKind{QueryEventBegin, QueryEventPrepare, QueryEventExec, QueryEventCommit, QueryEventBegin, QueryEventRollback}, kinds)
+
+	SetLogger(func(event QueryEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}, WithRedactedArgs())
+	_, err = conn.ExecContext(t.Context(), "INSERT INTO t VALUES (:x)", sql.Named("x", "secret"))
+	require.NoError(t, err)
+	events = takeEvents()
+	require.Len(t, events, 1)
+	require.Equal(t, []driver.NamedValue{{Name: "x", Ordinal: 1}}, events[0].Args)
+
+	SetLogger(nil)
+	_, err = conn.ExecContext(t.Context(), "DELETE FROM t")
+	require.NoError(t, err)
+	require.Empty(t, takeEvents())
+}
+
+func TestNumericAndBigInt(t *testing.T) {
+	db := openMem(t)
+	_, err := db.ExecContext(t.Context(), "CREATE TABLE t (id INTEGER PRIMARY KEY, amount TEXT, n)")
+	require.NoError(t, err)
+
+	huge, ok := new(big.Int).SetString("123456789012345678901234567890", 10)
+	require.True(t, ok)
+	_, err = db.ExecContext(t.Context(), "INSERT INTO t VALUES (1, ?, ?)", Numeric("1234567890.123456789012345"), huge)
+	require.NoError(t, err)
+	_, err = db.ExecContext(t.Context(), "INSERT INTO t VALUES (2, ?, ?)", Numeric("-0.1"), big.NewInt(42))
+	require.NoError(t, err)
+
+	var amount Numeric
+	n := new(big.Int)
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT amount, n FROM t WHERE id = 1").Scan(&amount, (*BigInt)(n)))
+	require.Equal(t, Numeric("1234567890.123456789012345"), amount)
+	require.Zero(t, huge.Cmp(n))
+	// small *big.Int is bound as INTEGER
+	var kind string
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT amount, n, typeof(n) FROM t WHERE id = 2").Scan(&amount, (*BigInt)(n), &kind))
+	require.Equal(t, Numeric("-0.1"), amount)
+	require.Equal(t, int64(42), n.Int64())
+	require.Equal(t, "integer", kind)
+	r, ok := amount.Rat()
+	require.True(t, ok)
+	require.Zero(t, big.NewRat(-1, 10).Cmp(r))
+
+	// BulkInsert binds *big.Int in the same way as Exec
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, conn.Raw(func(driverConn any) error {
+		_, err := driverConn.(*tursoDbConnection).BulkInsert(t.Context(), "t", []string{"id", "n"}, [][]any{
+			{10, big.NewInt(5)},
+			{11, (*big.Int)(nil)},
+			{12, huge},
+		}, 0)
+		return err
+	}))
+	require.NoError(t, conn.Close())
+	for id, want := range map[int]string{10: "integer", 11: "null", 12: "text"} {
+		require.NoError(t, db.QueryRowContext(t.Context(), "SELECT typeof(n) FROM t WHERE id = ?", id).Scan(&kind))
+		require.Equal(t, want, kind, "id %d", id)
+	}
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT n FROM t WHERE id = 10").Scan((*BigInt)(n)))
+	require.Equal(t, int64(5), n.Int64())
+
+	// exact integers of other representations scan into big.Int, fractions fail instead of truncating
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT '1e3'").Scan((*BigInt)(n)))
+	require.Equal(t, int64(1000), n.Int64())
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT '-1.50e1'").Scan((*BigInt)(n)))
+	require.Equal(t, int64(-15), n.Int64())
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT '0.0e-999999999999'").Scan((*BigInt)(n)))
+	require.Zero(t, n.Sign())
+	err = db.QueryRowContext(t.Context(), "SELECT '12.5'").Scan((*BigInt)(n))
+	require.ErrorContains(t, err, "fractional part")
+	err = db.QueryRowContext(t.Context(), "SELECT '1e-5'").Scan((*BigInt)(n))
+	require.ErrorContains(t, err, "fractional part")
+	err = db.QueryRowContext(t.Context(), "SELECT '1e999999999'").Scan((*BigInt)(n))
+	require.ErrorContains(t, err, "exponent is out of range")
+	err = db.QueryRowContext(t.Context(), "SELECT '12abc'").Scan((*BigInt)(n))
+	require.ErrorContains(t, err, "not a number")
+	err = db.QueryRowContext(t.Context(), "SELECT 12.5").Scan((*BigInt)(n))
+	require.ErrorContains(t, err, "not an integer")
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT 7").Scan(&amount))
+	require.Equal(t, Numeric("7"), amount)
+
+	_, err = db.ExecContext(t.Context(), "INSERT INTO t VALUES (3, ?, NULL)", Numeric("12,5"))
+	require.ErrorContains(t, err, `invalid Numeric "12,5"`)
+	err = db.QueryRowContext(t.Context(), "SELECT 'abc'").Scan(&amount)
+	require.ErrorContains(t, err, "not a decimal number")
+}
+
+func TestBusyRetry(t *testing.T) {
+	dbPath := path.Join(t.TempDir(), "busy.db")
+	db, err := sql.Open("turso", dbPath+"?_busy_timeout=-1&_busy_retries=8&_busy_retry_backoff=10ms")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.ExecContext(t.Context(), "CREATE TABLE t (x INTEGER)")
+	require.NoError(t, err)
+
+	holder, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer holder.Close()
+	writer, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer writer.Close()
+
+	// autocommit write is retried until the other connection releases the lock
+	_, err = holder.ExecContext(t.Context(), "BEGIN IMMEDIATE")
+	require.NoError(t, err)
+	released := make(chan error, 1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, err := holder.ExecContext(context.Background(), "COMMIT")
+		released <- err
+	}()
+	_, err = writer.ExecContext(t.Context(), "INSERT INTO t VALUES (1)")
+	require.NoError(t, err)
+	require.NoError(t, <-released)
+
+	// write inside an open transaction is never retried
+	_, err = holder.ExecContext(t.Context(), "BEGIN IMMEDIATE")
+	require.NoError(t, err)
+	_, err = writer.ExecContext(t.Context(), "BEGIN")
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = writer.ExecContext(t.Context(), "INSERT INTO t VALUES (2)")
+	require.True(t, IsBusy(err), "expected busy error, got %v", err)
+	require.Less(t, time.Since(start), 10*time.Millisecond*8)
+	_, err = writer.ExecContext(t.Context(), "ROLLBACK")
+	require.NoError(t, err)
+
+	// waiting for the retry is interrupted by the context
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Millisecond)
+	defer cancel()
+	_, err = writer.ExecContext(ctx, "INSERT INTO t VALUES (3)")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = holder.ExecContext(t.Context(), "COMMIT")
+	require.NoError(t, err)
+
+	var count int
+	require.NoError(t, writer.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 1, count)
+
+	_, err = NewConnector(dbPath + "?_busy_retry_backoff=soon")
+	require.ErrorContains(t, err, "invalid _busy_retry_backoff")
+	_, err = NewConnector(dbPath + "?_busy_retries=-1")
+	require.ErrorContains(t, err, "invalid _busy_retries")
+}
+
+func TestNextResultSet(t *testing.T) {
+	db := openMem(t)
+	db.SetMaxOpenConns(1)
+	_, err := db.ExecContext(t.Context(), "CREATE TABLE t (x INTEGER)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(t.Context(), "INSERT INTO t VALUES (1), (2)")
+	require.NoError(t, err)
+
+	rows, err := db.QueryContext(t.Context(), "SELECT 1; UPDATE t SET x = x + 10; SELECT 2; SELECT sum(x) FROM t;")
+	require.NoError(t, err)
+	defer rows.Close()
+	var values []int
+	for {
+		for rows.Next() {
+			var v int
+			require.NoError(t, rows.Scan(&v))
+			values = append(values, v)
+		}
+		require.NoError(t, rows.Err())
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []int{1, 2, 23}, values)
+
+	// statements ahead of the first query are executed too
+	rows, err = db.QueryContext(t.Context(), "DELETE FROM t; SELECT count(*) FROM t")
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	var count int
+	require.NoError(t, rows.Scan(&count))
+	require.Equal(t, 0, count)
+	require.False(t, rows.Next())
+	require.False(t, rows.NextResultSet())
+	require.NoError(t, rows.Err())
+
+	// a failing statement is reported once its result set is reached
+	rows, err = db.QueryContext(t.Context(), "SELECT 1; SELECT * FROM missing")
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	require.False(t, rows.Next())
+	require.False(t, rows.NextResultSet())
+	require.Error(t, rows.Err())
+
+	// Close runs the statements after the result sets which weren't read; arguments bind to the first statement only
+	rows, err = db.QueryContext(t.Context(), "SELECT ?; INSERT INTO t VALUES (?); SELECT 3; INSERT INTO t VALUES (5)", 7)
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Close())
+	var inserted []any
+	rows, err = db.QueryContext(t.Context(), "SELECT x FROM t ORDER BY x")
+	require.NoError(t, err)
+	for rows.Next() {
+		var v any
+		require.NoError(t, rows.Scan(&v))
+		inserted = append(inserted, v)
+	}
+	require.NoError(t, rows.Close())
+	require.Equal(t, []any{nil, int64(5)}, inserted)
+
+	// nothing runs after a failed statement, on Close as well
+	rows, err = db.QueryContext(t.Context(), "SELECT 1; INSERT INTO missing VALUES (1); DELETE FROM t")
+	require.NoError(t, err)
+	require.False(t, rows.NextResultSet())
+	require.Error(t, rows.Err())
+	require.NoError(t, rows.Close())
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 2, count)
+}
+
+func TestChanges(t *testing.T) {
+	db := openMem(t)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	counters := func() (changes, total int64) {
+		require.NoError(t, conn.Raw(func(driverConn any) error {
+			tc := driverConn.(*tursoDbConnection)
+			if changes, err = tc.Changes(); err != nil {
+				return err
+			}
+			total, err = tc.TotalChanges()
+			return err
+		}))
+		return changes, total
+	}
+
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE t (x INTEGER)")
+	require.NoError(t, err)
+	_, total := counters()
+	require.Equal(t, int64(0), total)
+
+	_, err = conn.ExecContext(t.Context(), "INSERT INTO t VALUES (1), (2), (3)")
+	require.NoError(t, err)
+	changes, total := counters()
+	require.Equal(t, int64(3), changes)
+	require.Equal(t, int64(3), total)
+
+	_, err = conn.ExecContext(t.Context(), "UPDATE t SET x = x + 1 WHERE x > 1")
+	require.NoError(t, err)
+	changes, total = counters()
+	require.Equal(t, int64(2), changes)
+	require.Equal(t, int64(5), total)
+
+	// queries do not reset the counter of the last change
+	var count int
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+	changes, _ = counters()
+	require.Equal(t, int64(2), changes)
+}
+
+func TestEncryptionKey(t *testing.T) {
+	key := "b1bbfda4f589dc9daaf004fe21111e00dc00c98237102f5c7002a5669fc76327"
+	wrongKey := "aaaaaaa4f589dc9daaf004fe21111e00dc00c98237102f5c7002a5669fc76327"
+	dbPath := path.Join(t.TempDir(), "encrypted.db")
+	dsn := func(key string) string {
+		return fmt.Sprintf("%s?experimental=encryption&_cipher=aegis256&_key=%s", dbPath, key)
+	}
+
+	db, err := sql.Open("turso", dsn(key))
+	require.NoError(t, err)
+	_, err = db.ExecContext(t.Context(), "CREATE TABLE t (x TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(t.Context(), "INSERT INTO t SELECT 'secret' FROM generate_series(1, 1024)")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// reopen with the correct key
+	db, err = sql.Open("turso", dsn(key))
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 1024, count)
+	require.NoError(t, db.Close())
+
+	// wrong key is reported as "not a database" and leaves the file intact
+	db, err = sql.Open("turso", dsn(wrongKey))
+	require.NoError(t, err)
+	err = db.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count)
+	require.ErrorIs(t, err, ErrTursoNotADb)
+	require.NoError(t, db.Close())
+
+	db, err = sql.Open("turso", dsn(key))
+	require.NoError(t, err)
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 1024, count)
+	require.NoError(t, db.Close())
+
+	_, err = NewConnector(dbPath + "?_key=not-hex")
+	require.ErrorContains(t, err, "invalid _key")
+}
+
+func TestSetKey(t *testing.T) {
+	key, err := hex.DecodeString("b1bbfda4f589dc9daaf004fe21111e00dc00c98237102f5c7002a5669fc76327")
+	require.NoError(t, err)
+	dbPath := path.Join(t.TempDir(), "encrypted.db")
+
+	db, err := sql.Open("turso", dbPath+"?experimental=encryption")
+	require.NoError(t, err)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, conn.Raw(func(driverConn any) error {
+		return driverConn.(*tursoDbConnection).SetKey(key)
+	}))
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE t (x TEXT)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "INSERT INTO t SELECT 'secret' FROM generate_series(1, 1024)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(t.Context(), "PRAGMA wal_checkpoint(TRUNCATE)")
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	require.NoError(t, db.Close())
+
+	content, err := os.ReadFile(dbPath)
+	require.NoError(t, err)
+	require.False(t, bytes.Contains(content, []byte("secret")))
+
+	// the key set with SetKey opens the database through the DSN with the default cipher
+	db, err = sql.Open("turso", fmt.Sprintf("%s?experimental=encryption&_key=%x", dbPath, key))
+	require.NoError(t, err)
+	defer db.Close()
+	var count int
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 1024, count)
+}
+
+func TestRekey(t *testing.T) {
+	key := "b1bbfda4f589dc9daaf004fe21111e00dc00c98237102f5c7002a5669fc76327"
+	newKey, err := hex.DecodeString("c2ccfda4f589dc9daaf004fe21111e00dc00c98237102f5c7002a5669fc76327")
+	require.NoError(t, err)
+	dbPath := path.Join(t.TempDir(), "encrypted.db")
+	dsn := func(key string) string {
+		return fmt.Sprintf("%s?experimental=encryption,vacuum&_cipher=aegis256&_key=%s", dbPath, key)
+	}
+
+	db, err := sql.Open("turso", dsn(key))
+	require.NoError(t, err)
+	_, err = db.ExecContext(t.Context(), "CREATE TABLE t (x TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(t.Context(), "INSERT INTO t SELECT 'secret' FROM generate_series(1, 1024)")
+	require.NoError(t, err)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	rekey := func(key []byte) error {
+		return conn.Raw(func(driverConn any) error {
+			return driverConn.(*tursoDbConnection).Rekey(key)
+		})
+	}
+	// failed rekey leaves the database encrypted with the old key
+	var count int
+	require.Error(t, rekey(newKey[:7]))
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 1024, count)
+	require.NoError(t, rekey(newKey))
+	// the connection keeps working with the new key
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 1024, count)
+	require.NoError(t, conn.Close())
+	require.NoError(t, db.Close())
+
+	db, err = sql.Open("turso", dsn(key))
+	require.NoError(t, err)
+	err = db.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count)
+	require.ErrorIs(t, err, ErrTursoNotADb)
+	require.NoError(t, db.Close())
+
+	db, err = sql.Open("turso", dsn(hex.EncodeToString(newKey)))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 1024, count)
+
+	// only an encrypted database can be rekeyed
+	plain, err := sql.Open("turso", path.Join(t.TempDir(), "plain.db")+"?experimental=encryption,vacuum")
+	require.NoError(t, err)
+	defer plain.Close()
+	conn, err = plain.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+	require.ErrorContains(t, rekey(newKey), "not encrypted")
+}
+
+func TestJSON(t *testing.T) {
+	type doc struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	db := openMem(t)
+	_, err := db.ExecContext(t.Context(), "CREATE TABLE docs (id INTEGER PRIMARY KEY, body TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(t.Context(), "INSERT INTO docs VALUES (1, ?)", JSON(doc{Name: "a", Tags: []string{"x", "y"}}))
+	require.NoError(t, err)
+
+	var got doc
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT body FROM docs WHERE id = 1").Scan(JSONInto(&got)))
+	require.Equal(t, doc{Name: "a", Tags: []string{"x", "y"}}, got)
+
+	// the stored text is usable by the JSON functions
+	var name string
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT body ->> '$.name' FROM docs").Scan(&name))
+	require.Equal(t, "a", name)
+	var count int
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT json_extract(body, '$.tags[1]') = 'y' FROM docs").Scan(&count))
+	require.Equal(t, 1, count)
+	var n float64
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT json_array_length(body, '$.tags') FROM docs").Scan(JSONInto(&n)))
+	require.Equal(t, float64(2), n)
+
+	// malformed JSON never reaches the database
+	_, err = db.ExecContext(t.Context(), "INSERT INTO docs VALUES (2, ?)", JSON(json.RawMessage(`{"name":`)))
+	require.ErrorIs(t, err, ErrTursoInvalidJSON)
+	_, err = db.ExecContext(t.Context(), "INSERT INTO docs VALUES (2, ?)", JSON(make(chan int)))
+	require.ErrorContains(t, err, "can't bind chan int as JSON")
+
+	// text which is not JSON is reported as such
+	_, err = db.ExecContext(t.Context(), "INSERT INTO docs VALUES (3, 'not json')")
+	require.NoError(t, err)
+	err = db.QueryRowContext(t.Context(), "SELECT body FROM docs WHERE id = 3").Scan(JSONInto(&got))
+	require.ErrorIs(t, err, ErrTursoInvalidJSON)
+	// valid JSON of a wrong shape is an unmarshal error
+	err = db.QueryRowContext(t.Context(), "SELECT '[1, 2]'").Scan(JSONInto(&got))
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrTursoInvalidJSON)
+
+	// NULL leaves the destination unchanged
+	got = doc{Name: "kept"}
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT NULL").Scan(JSONInto(&got)))
+	require.Equal(t, "kept", got.Name)
+}
+
+func TestTransactionHelper(t *testing.T) {
+	dbPath := path.Join(t.TempDir(), "tx.db")
+	db, err := sql.Open("turso", dbPath+"?_busy_timeout=-1")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.ExecContext(t.Context(), "CREATE TABLE t (x INTEGER)")
+	require.NoError(t, err)
+	count := func() int {
+		var n int
+		require.NoError(t, db.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&n))
+		return n
+	}
+	insert := func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(t.Context(), "INSERT INTO t VALUES (1)")
+		return err
+	}
+
+	require.NoError(t, Transaction(t.Context(), db, nil, insert))
+	require.Equal(t, 1, count())
+
+	// error and panic of fn roll the transaction back
+	failure := errors.New("failure")
+	err = Transaction(t.Context(), db, nil, func(tx *sql.Tx) error {
+		require.NoError(t, insert(tx))
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+	require.Panics(t, func() {
+		_ = Transaction(t.Context(), db, nil, func(tx *sql.Tx) error {
+			require.NoError(t, insert(tx))
+			panic("boom")
+		})
+	})
+	require.Equal(t, 1, count())
+
+	holder, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer holder.Close()
+
+	// without the option a busy transaction is not retried
+	_, err = holder.ExecContext(t.Context(), "BEGIN IMMEDIATE")
+	require.NoError(t, err)
+	attempts := 0
+	err = Transaction(t.Context(), db, nil, func(tx *sql.Tx) error {
+		attempts++
+		return insert(tx)
+	})
+	require.True(t, IsBusy(err), "expected busy error, got %v", err)
+	require.Equal(t, 1, attempts)
+
+	// with the option the whole function runs again once the lock is released
+	released := make(chan error, 1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, err := holder.ExecContext(context.Background(), "COMMIT")
+		released <- err
+	}()
+	attempts = 0
+	err = Transaction(t.Context(), db, nil, func(tx *sql.Tx) error {
+		attempts++
+		return insert(tx)
+	}, WithTransactionRetry(8, 10*time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, <-released)
+	require.Greater(t, attempts, 1)
+	require.Equal(t, 2, count())
+}
+
+func TestBlobAndTextStorageClass(t *testing.T) {
+	type rawBytes []byte
+	type label string
+	db := openMem(t)
+	_, err := db.ExecContext(t.Context(), "CREATE TABLE t (id INTEGER PRIMARY KEY, v)")
+	require.NoError(t, err)
+	for i, tc := range []struct {
+		arg    any
+		typeof string
+	}{
+		{[]byte("abc"), "blob"},
+		{[]byte{}, "blob"},
+		{rawBytes("abc"), "blob"},
+		{json.RawMessage(`{"a":1}`), "blob"},
+		{"abc", "text"},
+		{"", "text"},
+		{label("abc"), "text"},
+		{[]byte(nil), "blob"},
+	} {
+		_, err := db.ExecContext(t.Context(), "INSERT INTO t VALUES (?, ?)", i, tc.arg)
+		require.NoError(t, err)
+		var typeof string
+		require.NoError(t, db.QueryRowContext(t.Context(), "SELECT typeof(v) FROM t WHERE id = ?", i).Scan(&typeof))
+		require.Equal(t, tc.typeof, typeof, "argument %#v", tc.arg)
+	}
+
+	// scanning keeps the storage class of the value
+	var value any
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT v FROM t WHERE id = 0").Scan(&value))
+	require.Equal(t, []byte("abc"), value)
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT v FROM t WHERE id = 4").Scan(&value))
+	require.Equal(t, "abc", value)
+
+	// empty BLOB is not NULL
+	var blob []byte
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT v FROM t WHERE id = 1").Scan(&blob))
+	require.NotNil(t, blob)
+	require.Empty(t, blob)
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT NULL").Scan(&blob))
+	require.Nil(t, blob)
+
+	// BLOB scanned into *string is converted explicitly, bytes are copied as they are
+	var text string
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT v FROM t WHERE id = 0").Scan(&text))
+	require.Equal(t, "abc", text)
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT v FROM t WHERE id = 4").Scan(&blob))
+	require.Equal(t, []byte("abc"), blob)
+
+	// comparisons distinguish the storage classes
+	var count int
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT count(*) FROM t WHERE v = ?", []byte("abc")).Scan(&count))
+	require.Equal(t, 2, count)
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT count(*) FROM t WHERE v = ?", "abc").Scan(&count))
+	require.Equal(t, 2, count)
+}
+
+func TestDescribe(t *testing.T) {
+	db := openMem(t)
+	_, err := db.ExecContext(t.Context(), "CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT, score REAL)")
+	require.NoError(t, err)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	describe := func(query string) (info StmtInfo, err error) {
+		require.NoError(t, conn.Raw(func(driverConn any) error {
+			info, err = driverConn.(*tursoDbConnection).Describe(query)
+			return nil
+		}))
+		return info, err
+	}
+
+	info, err := describe("SELECT id, name AS label FROM t WHERE id = ? AND name = :name AND score > ?")
+	require.NoError(t, err)
+	require.Equal(t, StmtInfo{
+		ParamCount:  3,
+		ParamNames:  []string{"", ":name", ""},
+		ColumnCount: 2,
+		ColumnNames: []string{"id", "label"},
+	}, info)
+
+	info, err = describe("UPDATE t SET score = @score WHERE name = @name OR @name IS NULL")
+	require.NoError(t, err)
+	require.Equal(t, 2, info.ParamCount)
+	require.Equal(t, []string{"@score", "@name"}, info.ParamNames)
+	require.Equal(t, 0, info.ColumnCount)
+	require.Empty(t, info.ColumnNames)
+
+	// describing doesn't execute the statement
+	_, err = describe("INSERT INTO t (name) VALUES ('x')")
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 0, count)
+
+	_, err = describe("SELECT * FROM missing")
+	require.Error(t, err)
+}
+
+func TestPageSizeAndAutoVacuum(t *testing.T) {
+	dbPath := path.Join(t.TempDir(), "pages.db")
+	db, err := sql.Open("turso", dbPath+"?experimental=autovacuum&_page_size=8192&_auto_vacuum=incremental")
+	require.NoError(t, err)
+	_, err = db.ExecContext(t.Context(), "CREATE TABLE t (x INTEGER)")
+	require.NoError(t, err)
+	var pageSize, autoVacuum int
+	require.NoError(t, db.QueryRowContext(t.Context(), "PRAGMA page_size").Scan(&pageSize))
+	require.Equal(t, 8192, pageSize)
+	require.NoError(t, db.QueryRowContext(t.Context(), "PRAGMA auto_vacuum").Scan(&autoVacuum))
+	require.Equal(t, 2, autoVacuum)
+	require.NoError(t, db.Close())
+
+	// page size of the database with content is kept and the logger receives a warning
+	var mu sync.Mutex
+	var warnings []QueryEvent
+	SetLogger(func(event QueryEvent) {
+		if event.Kind == QueryEventWarning {
+			mu.Lock()
+			defer mu.Unlock()
+			warnings = append(warnings, event)
+		}
+	})
+	t.Cleanup(func() { SetLogger(nil) })
+	db, err = sql.Open("turso", dbPath+"?_page_size=16384")
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.QueryRowContext(t.Context(), "PRAGMA page_size").Scan(&pageSize))
+	require.Equal(t, 8192, pageSize)
+	// every connection of the pool keeps the page size, but the mismatch is reported once
+	conns := make([]*sql.Conn, 3)
+	for i := range conns {
+		conns[i], err = db.Conn(t.Context())
+		require.NoError(t, err)
+		require.NoError(t, conns[i].QueryRowContext(t.Context(), "PRAGMA page_size").Scan(&pageSize))
+		require.Equal(t, 8192, pageSize)
+	}
+	for _, conn := range conns {
+		require.NoError(t, conn.Close())
+	}
+	mu.Lock()
+	require.Len(t, warnings, 1)
+	require.Equal(t, "PRAGMA page_size = 16384", warnings[0].SQL)
+	require.ErrorContains(t, warnings[0].Err, "page_size is not applied")
+	mu.Unlock()
+
+	_, err = NewConnector(dbPath + "?_page_size=1000")
+	require.ErrorContains(t, err, "invalid _page_size")
+	_, err = NewConnector(dbPath + "?_auto_vacuum=sometimes")
+	require.ErrorContains(t, err, "invalid _auto_vacuum")
+}
+
+func TestQuoteIdentifierAndString(t *testing.T) {
+	db := openMem(t)
+	for _, name := range []string{
+		"plain",
+		"select",
+		`with "double" quotes`,
+		`""`,
+		"with `backticks`",
+		"[brackets]",
+		"with 'single' quotes",
+		"unicode ключ 名前 🙂",
+		"semi; DROP TABLE x; --",
+	} {
+		table, literal := QuoteIdentifier(name), QuoteString(name)
+		_, err := db.ExecContext(t.Context(), fmt.Sprintf("CREATE TABLE %s (%s TEXT)", table, table))
+		require.NoError(t, err, "name %q", name)
+		_, err = db.ExecContext(t.Context(), fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, table, literal))
+		require.NoError(t, err, "name %q", name)
+		var got string
+		require.NoError(t, db.QueryRowContext(t.Context(), fmt.Sprintf("SELECT %s FROM %s", table, table)).Scan(&got))
+		require.Equal(t, name, got)
+		var count int
+		require.NoError(t, db.QueryRowContext(t.Context(), "SELECT count(*) FROM sqlite_schema WHERE name = ?", name).Scan(&count))
+		require.Equal(t, 1, count, "name %q", name)
+	}
+
+	require.Equal(t, `"a""b"`, QuoteIdentifier(`a"b`))
+	require.Equal(t, "'it''s'", QuoteString("it's"))
+
+	require.Panics(t, func() { QuoteIdentifier("nul\x00byte") })
+	require.Panics(t, func() { QuoteString("nul\x00byte") })
+	quoted, err := QuoteIdentifierE(`a"b`)
+	require.NoError(t, err)
+	require.Equal(t, `"a""b"`, quoted)
+	_, err = QuoteIdentifierE("nul\x00byte")
+	require.ErrorContains(t, err, "NUL byte")
+	_, err = QuoteStringE("nul\x00byte")
+	require.ErrorContains(t, err, "NUL byte")
+}
+
+func TestSerializeDeserialize(t *testing.T) {
+	source := openMem(t)
+	_, err := source.ExecContext(t.Context(), `
+		CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT UNIQUE);
+		CREATE TABLE notes (body TEXT, user_id INTEGER);
+		CREATE INDEX notes_user ON notes (user_id);
+		CREATE VIEW user_notes AS SELECT name, body FROM users JOIN notes ON users.id = notes.user_id;
+		INSERT INTO users (name) VALUES ('alice'), ('bob');
+		INSERT INTO notes VALUES ('hello', 1), ('world', 2), ('again', 1);
+		DELETE FROM notes WHERE rowid = 2;`)
+	require.NoError(t, err)
+	conn, err := source.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+	var data []byte
+	require.NoError(t, conn.Raw(func(driverConn any) error {
+		data, err = driverConn.(*tursoDbConnection).Serialize("main")
+		return err
+	}))
+	require.Equal(t, "SQLite format 3\x00", string(data[:16]))
+	require.ErrorContains(t, conn.Raw(func(driverConn any) error {
+		_, err := driverConn.(*tursoDbConnection).Serialize("temp")
+		return err
+	}), "only main is supported")
+
+	target, err := sql.Open("turso", ":memory:")
+	require.NoError(t, err)
+	defer target.Close()
+	restored, err := target.Conn(t.Context())
+	require.NoError(t, err)
+	defer restored.Close()
+	_, err = restored.ExecContext(t.Context(), "CREATE TABLE stale (x); INSERT INTO stale VALUES (1)")
+	require.NoError(t, err)
+	deserialize := func(data []byte) error {
+		return restored.Raw(func(driverConn any) error {
+			return driverConn.(*tursoDbConnection).Deserialize("main", data)
+		})
+	}
+	require.NoError(t, deserialize(data))
+
+	// the previous content is replaced
+	var count int
+	require.NoError(t, restored.QueryRowContext(t.Context(), "SELECT count(*) FROM sqlite_schema WHERE name = 'stale'").Scan(&count))
+	require.Equal(t, 0, count)
+	rows, err := restored.QueryContext(t.Context(), "SELECT rowid, body FROM notes ORDER BY rowid")
+	require.NoError(t, err)
+	var notes []string
+	for rows.Next() {
+		var rowid int
+		var body string
+		require.NoError(t, rows.Scan(&rowid, &body))
+		notes = append(notes, fmt.Sprintf("%d:%s", rowid, body))
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []string{"1:hello", "3:again"}, notes)
+	// other connections of the pool see the new content
+	require.NoError(t, target.QueryRowContext(t.Context(), "SELECT count(*) FROM notes").Scan(&count))
+	require.Equal(t, 2, count)
+	require.NoError(t, restored.QueryRowContext(t.Context(), "SELECT count(*) FROM user_notes WHERE name = 'alice'").Scan(&count))
+	require.Equal(t, 2, count)
+	_, err = restored.ExecContext(t.Context(), "INSERT INTO users (name) VALUES ('alice')")
+	require.True(t, IsConstraint(err), "expected unique index to be restored, got %v", err)
+	var id int64
+	require.NoError(t, restored.QueryRowContext(t.Context(), "INSERT INTO users (name) VALUES ('carol') RETURNING id").Scan(&id))
+	require.Equal(t, int64(3), id)
+
+	// invalid data fails without touching the database
+	require.ErrorIs(t, deserialize([]byte("definitely not a database")), ErrTursoNotADb)
+	corrupt := append([]byte(nil), data...)
+	for i := 100; i < len(corrupt); i++ {
+		corrupt[i] = 0xff
+	}
+	require.Error(t, deserialize(corrupt))
+	require.NoError(t, restored.QueryRowContext(t.Context(), "SELECT count(*) FROM users").Scan(&count))
+	require.Equal(t, 3, count)
+
+	require.ErrorContains(t, restored.Raw(func(driverConn any) error {
+		return driverConn.(*tursoDbConnection).Deserialize("aux", data)
+	}), "only main is supported")
+}
+
+func TestProfileHookAndConnStats(t *testing.T) {
+	type profiled struct {
+		sql     string
+		vmSteps int64
+	}
+	var mu sync.Mutex
+	var statements []profiled
+	SetProfileHook(func(sql string, nanoseconds int64, vmSteps int64) {
+		require.GreaterOrEqual(t, nanoseconds, int64(0))
+		mu.Lock()
+		statements = append(statements, profiled{sql: sql, vmSteps: vmSteps})
+		mu.Unlock()
+	})
+	t.Cleanup(func() { SetProfileHook(nil) })
+
+	db := openMem(t)
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE t (x INTEGER); INSERT INTO t VALUES (1), (2), (3)")
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, conn.QueryRowContext(t.Context(), "SELECT count(*) FROM t WHERE x > 1").Scan(&count))
+	require.Equal(t, 2, count)
+
+	mu.Lock()
+	require.Len(t, statements, 3)
+	require.Equal(t, "CREATE TABLE t (x INTEGER)", strings.TrimSuffix(statements[0].sql, ";"))
+	require.Equal(t, "INSERT INTO t VALUES (1), (2), (3)", statements[1].sql)
+	require.Equal(t, "SELECT count(*) FROM t WHERE x > 1", statements[2].sql)
+	for _, s := range statements {
+		require.Positive(t, s.vmSteps, s.sql)
+	}
+	mu.Unlock()
+
+	require.NoError(t, conn.Raw(func(driverConn any) error {
+		stats, err := driverConn.(*tursoDbConnection).ConnStats()
+		if err != nil {
+			return err
+		}
+		require.GreaterOrEqual(t, stats.Statements, int64(3))
+		require.Positive(t, stats.VMSteps)
+		require.Positive(t, stats.FullscanSteps)
+		require.Equal(t, int64(3), stats.RowsWritten)
+		require.NotZero(t, stats.CacheSize)
+		require.Equal(t, int64(3), stats.ProfiledStatements)
+		return nil
+	}))
+
+	// without the hook statements are measured only with _profile
+	SetProfileHook(nil)
+	quiet, err := sql.Open("turso", ":memory:?_profile=true")
+	require.NoError(t, err)
+	defer quiet.Close()
+	_, err = quiet.ExecContext(t.Context(), "SELECT 1")
+	require.NoError(t, err)
+	qconn, err := quiet.Conn(t.Context())
+	require.NoError(t, err)
+	defer qconn.Close()
+	require.NoError(t, qconn.Raw(func(driverConn any) error {
+		stats, err := driverConn.(*tursoDbConnection).ConnStats()
+		require.Equal(t, int64(1), stats.ProfiledStatements)
+		require.Positive(t, stats.ProfiledTime)
+		return err
+	}))
+	require.Len(t, statements, 3)
+
+	_, err = parseDSN("test.db?_profile=maybe")
+	require.Error(t, err)
+}
+
+func TestNullAsZero(t *testing.T) {
+	_, err := parseDSN(":memory:?_null_as_zero=maybe")
+	require.ErrorContains(t, err, "invalid _null_as_zero")
+	if !nullAsZeroSupported {
+		_, err = parseDSN(":memory:?_null_as_zero=true")
+		require.ErrorContains(t, err, "requires Go 1.27")
+		t.Skip("_null_as_zero requires Go 1.27 or later")
+	}
+
+	db, err := sql.Open("turso", ":memory:?_null_as_zero=true")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	_, err = db.ExecContext(t.Context(), "CREATE TABLE t(i INTEGER, f REAL, s TEXT, b BLOB, d DATETIME, flag BOOLEAN)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(t.Context(), "INSERT INTO t VALUES (NULL, NULL, NULL, NULL, NULL, NULL)")
+	require.NoError(t, err)
+
+	i, u, f, s, b, d, flag := 1, uint8(1), 1.5, "x", []byte("x"), time.Now(), true
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT i, i, f, s, b, d, flag FROM t").Scan(&i, &u, &f, &s, &b, &d, &flag))
+	require.Zero(t, i)
+	require.Zero(t, u)
+	require.Zero(t, f)
+	require.Zero(t, s)
+	require.Nil(t, b)
+	require.True(t, d.IsZero())
+	require.False(t, flag)
+
+	// nullable destinations still see NULL
+	var (
+		ni  sql.NullInt64
+		ns  sql.NullString
+		nt  sql.NullTime
+		ptr     = new(int)
+		v   any = 1
+	)
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT i, s, d, i, i FROM t").Scan(&ni, &ns, &nt, &ptr, &v))
+	require.False(t, ni.Valid)
+	require.False(t, ns.Valid)
+	require.False(t, nt.Valid)
+	require.Nil(t, ptr)
+	require.Nil(t, v)
+
+	// non-NULL values are scanned as usual
+	require.NoError(t, db.QueryRowContext(t.Context(), "SELECT 42, 'turso'").Scan(&i, &s))
+	require.Equal(t, 42, i)
+	require.Equal(t, "turso", s)
+
+	plain := openMem(t)
+	err = plain.QueryRowContext(t.Context(), "SELECT NULL").Scan(&i)
+	require.ErrorContains(t, err, "converting NULL to int is unsupported")
+}
@@ -0,0 +1,90 @@
+//go:build go1.27
+
+package turso
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"time"
+)
+
+// Since Go 1.27 database/sql scans rows through driver.RowsColumnScanner instead of Next,
+// which lets the driver see the destination of every column and store zero values for NULL with _null_as_zero.
+
+// nullAsZeroSupported reports whether _null_as_zero can be honoured by this build
+const nullAsZeroSupported = true
+
+var (
+	_ driver.RowsColumnScanner = (*tursoDbRows)(nil)
+	_ driver.RowsColumnScanner = (*primaryRows)(nil)
+)
+
+func (r *tursoDbRows) NextRow() error {
+	return nextRow(r, &r.scanned)
+}
+
+func (r *tursoDbRows) ScanColumn(scanCtx driver.ScanContext, index int, dest any) error {
+	return scanColumn(scanCtx, r.scanned[index], dest, r.conn.nullAsZero)
+}
+
+func (r *primaryRows) NextRow() error {
+	return nextRow(r, &r.scanned)
+}
+
+func (r *primaryRows) ScanColumn(scanCtx driver.ScanContext, index int, dest any) error {
+	return scanColumn(scanCtx, r.scanned[index], dest, r.nullAsZero)
+}
+
+// nextRow reads the next row of rows into row, which is resized to the columns of the current result set
+func nextRow(rows driver.Rows, row *[]driver.Value) error {
+	n := len(rows.Columns())
+	if cap(*row) < n {
+		*row = make([]driver.Value, n)
+	}
+	*row = (*row)[:n]
+	clear(*row)
+	return rows.Next(*row)
+}
+
+// scanColumn converts value into dest like database/sql does; with nullAsZero NULL is stored
+// as the zero value of a destination which can't hold NULL itself
+func scanColumn(scanCtx driver.ScanContext, value driver.Value, dest any, nullAsZero bool) error {
+	if value == nil && nullAsZero && storeZero(dest) {
+		return nil
+	}
+	return sql.ConvertAssign(scanCtx, dest, value)
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// storeZero sets the zero value of the basic type or time.Time dest points to and reports whether it did.
+// Scanners (e.g. sql.NullInt64), pointers and *any are left to database/sql, which handles NULL for them.
+func storeZero(dest any) bool {
+	if _, ok := dest.(sql.Scanner); ok {
+		return false
+	}
+	pointer := reflect.ValueOf(dest)
+	if pointer.Kind() != reflect.Pointer || pointer.IsNil() {
+		return false
+	}
+	value := pointer.Elem()
+	switch value.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.Uint8 {
+			return false
+		}
+	case reflect.Struct:
+		if value.Type() != timeType {
+			return false
+		}
+	default:
+		return false
+	}
+	value.SetZero()
+	return true
+}
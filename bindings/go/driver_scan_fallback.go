@@ -0,0 +1,8 @@
+//go:build !go1.27
+
+package turso
+
+// Before Go 1.27 database/sql converts NULL itself, so the driver can't honour _null_as_zero.
+
+// nullAsZeroSupported reports whether _null_as_zero can be honoured by this build
+const nullAsZeroSupported = false
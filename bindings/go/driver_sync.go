@@ -5,15 +5,21 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	turso_libs "github.com/tursodatabase/turso-go-platform-libs"
@@ -39,6 +45,10 @@ type TursoSyncDbConfig struct {
 	// Supports DSN-style options: "mydb.db?_busy_timeout=5000"
 	// Supported options:
 	//   - _busy_timeout: busy timeout in milliseconds (default: 5000, use -1 to disable)
+	//   - _read_your_writes: same as ReadYourWrites field
+	//   - _time_format: same as TimeFormat field (rfc3339, unix or unixms)
+	//   - _ping_remote: same as PingRemote field
+	//   - _route_writes: same as RouteWrites field
 	Path string
 
 	// remote url for the sync
@@ -86,6 +96,127 @@ type TursoSyncDbConfig struct {
 	// in chunks. 0 (default) bootstraps in a single round-trip. no-op when partial sync uses the
 	// query bootstrap strategy.
 	PullBytesThreshold int
+
+	// optional handler which receives sync progress, starting from the initial bootstrap (see SetSyncProgressHandler)
+	ProgressHandler func(SyncProgress)
+
+	// if set, reads on any connection created by Connect observe local writes already committed through any other of them
+	// Can also be specified via Path DSN: "mydb.db?_read_your_writes=true"
+	ReadYourWrites bool
+
+	// how time.Time arguments are stored and how integers of time columns are read back
+	// Can also be specified via Path DSN: "mydb.db?_time_format=unixms"
+	TimeFormat TimeFormat
+
+	// if set, Ping of connections created by Connect also checks that the sync endpoint answers
+	// Can also be specified via Path DSN: "mydb.db?_ping_remote=true"
+	PingRemote bool
+
+	// if set, connections created by Connect run writes on the remote primary and reads on the local replica.
+	// A statement is routed by its leading keyword without being prepared locally (the replica may not know the tables
+	// created on the primary yet): INSERT, UPDATE, DELETE, REPLACE, CREATE, DROP, ALTER, ANALYZE, REINDEX and BEGIN
+	// go to the primary, and so does WITH ... INSERT/UPDATE/DELETE; INSERT ... RETURNING run by Query returns its rows
+	// from the primary as well. WithRoute overrides the decision for a single statement.
+	// Transactions started by BeginTx run on the primary as a whole, unless they are read-only (sql.TxOptions.ReadOnly)
+	// or started with RouteReplica: such transactions stay on the local replica.
+	// Reads observe the remote state as of the latest Pull or Sync, so with StartPeriodicSync they lag behind the primary
+	// by up to the sync interval (longer while sync attempts fail); writes routed to the primary, including the ones made
+	// by this process, reach the replica only with the next pull. Use RoutePrimary for reads which must observe
+	// a preceding write, or call Pull before reading.
+	// Can also be specified via Path DSN: "mydb.db?_route_writes=true"
+	RouteWrites bool
+
+	// optional callback which decides the fate of every local row change the sync engine replays:
+	// Pull (and the pull of Sync) rebases not yet pushed local changes on top of the pulled remote ones,
+	// and Push sends them to the remote. Without the callback every local change is kept, so the local version
+	// of a row changed both locally and remotely wins (see LocalRowChange and RowResolution).
+	ConflictResolver func(LocalRowChange) RowResolution
+}
+
+// RowChangeType is the kind of the local row change
+type RowChangeType string
+
+const (
+	RowInsert RowChangeType = "insert"
+	RowUpdate RowChangeType = "update"
+	RowDelete RowChangeType = "delete"
+)
+
+// LocalRowChange is the local change of a row passed to the ConflictResolver.
+// Values of the columns are nil, int64, float64, string or []byte.
+type LocalRowChange struct {
+	Table string
+	RowID int64
+	Type  RowChangeType
+	// unix time of the change in seconds
+	ChangeTime int64
+	// values of the columns before the change (nil for inserts)
+	Before map[string]any
+	// values of the columns after the change (nil for deletes)
+	After map[string]any
+	// values of the columns changed by the update (nil if unknown)
+	Updates map[string]any
+}
+
+// RowResolution tells the sync engine what to do with the local row change: KeepLocalChange applies it
+// (the local version of the row wins), SkipLocalChange drops it (the remote version wins) and
+// RewriteLocalChange replaces it with a statement (e.g. one which merges both versions).
+type RowResolution struct {
+	skip bool
+	sql  string
+	args []any
+}
+
+var (
+	KeepLocalChange = RowResolution{}
+	SkipLocalChange = RowResolution{skip: true}
+)
+
+// RewriteLocalChange replaces the local row change with the statement; args bind to its positional parameters
+func RewriteLocalChange(sql string, args ...any) RowResolution {
+	return RowResolution{sql: sql, args: args}
+}
+
+// SyncPhase is the stage of the sync operation reported to the progress handler.
+type SyncPhase int
+
+const (
+	// initial creation of the database which downloads remote state if local database is empty
+	SyncPhaseBootstrapping SyncPhase = iota
+	// fetching and applying remote changes
+	SyncPhasePulling
+	// sending local changes to the remote
+	SyncPhasePushing
+	// sync operation completed successfully
+	SyncPhaseDone
+)
+
+func (p SyncPhase) String() string {
+	switch p {
+	case SyncPhaseBootstrapping:
+		return "Bootstrapping"
+	case SyncPhasePulling:
+		return "Pulling"
+	case SyncPhasePushing:
+		return "Pushing"
+	case SyncPhaseDone:
+		return "Done"
+	default:
+		return fmt.Sprintf("SyncPhase(%d)", int(p))
+	}
+}
+
+// SyncProgress describes progress of the running sync operation.
+// Counters are maintained by the sync engine and cover the whole operation (e.g. both push and pull of Sync).
+type SyncProgress struct {
+	Phase SyncPhase
+	// amount of page frames received from the remote by the current operation
+	// (pulls which receive logical changes instead of pages don't advance it)
+	PulledFrames int64
+	// amount of local changes (CDC operations) sent to the remote by the current operation, updated after every batch
+	PushedChanges int64
+	// amount of frames the current phase is expected to transfer: pages of the remote database during bootstrap; 0 if unknown
+	TotalFrames int64
 }
 
 // statistics for the synced database.
@@ -106,6 +237,20 @@ type TursoSyncDbStats struct {
 	NetworkReceivedBytes int64
 	// opaque server revision - it MUST NOT be interpreted/parsed in any way
 	Revision string
+	// revision pulled last, as recorded by the sync engine
+	SyncedRevision SyncedRevision
+}
+
+// SyncedRevision is the revision of the remote database pulled last by an embedded replica.
+type SyncedRevision struct {
+	// set if the remote speaks the legacy protocol: Generation and Frame identify the revision then
+	Legacy bool
+	// generation of the remote database
+	Generation uint64
+	// last frame pulled within the generation (0 if only the generation is known)
+	Frame uint64
+	// opaque server revision (same as TursoSyncDbStats.Revision) - it MUST NOT be interpreted/parsed in any way
+	Revision string
 }
 
 // define public structs here
@@ -119,8 +264,75 @@ type TursoSyncDb struct {
 	namespace   string
 	client      *http.Client
 	busyTimeout int // busy timeout in milliseconds (0 = disabled)
+	timeFormat  TimeFormat
+	// position of the latest local write shared by all connections (nil if read-your-writes is disabled)
+	ryw *readYourWrites
+	// set if Ping of connections probes the sync endpoint
+	pingRemote bool
+	// set if connections route writes to the remote primary
+	routeWrites bool
+	// resolves local row changes passed by the sync engine with transform IO requests (nil if disabled)
+	conflictResolver func(LocalRowChange) RowResolution
 
 	mu sync.Mutex
+	// syncMu serializes Sync calls (manual and periodic) so push/pull pairs never overlap
+	syncMu sync.Mutex
+	// periodic is set while the background loop started by StartPeriodicSync is running
+	periodic atomic.Bool
+	// progressHandler is set by SetSyncProgressHandler; progress tracks running operation (guarded by mu)
+	progressHandler atomic.Pointer[func(SyncProgress)]
+	progress        *syncProgressTracker
+	// closed is set by Close; dbs are the pools created by Connect which Close closes (both guarded by mu)
+	closed bool
+	dbs    []*sql.DB
+}
+
+// syncProgressTracker reports progress of a single sync operation to the handler;
+// base holds counters of the sync engine at the start of the operation
+type syncProgressTracker struct {
+	handler  func(SyncProgress)
+	base     TursoSyncProgress
+	progress SyncProgress
+}
+
+func (t *syncProgressTracker) report() {
+	t.handler(t.progress)
+}
+
+// ReplicaOption configures the embedded replica opened with OpenEmbeddedReplica
+type ReplicaOption func(*TursoSyncDbConfig)
+
+// WithReplicaClientName sets unique client name of the replica
+func WithReplicaClientName(name string) ReplicaOption {
+	return func(c *TursoSyncDbConfig) { c.ClientName = name }
+}
+
+// WithReplicaNamespace sets remote namespace of the replica
+func WithReplicaNamespace(namespace string) ReplicaOption {
+	return func(c *TursoSyncDbConfig) { c.Namespace = namespace }
+}
+
+// WithConflictResolver sets the callback which resolves local row changes replayed by Sync (see TursoSyncDbConfig.ConflictResolver)
+func WithConflictResolver(resolver func(LocalRowChange) RowResolution) ReplicaOption {
+	return func(c *TursoSyncDbConfig) { c.ConflictResolver = resolver }
+}
+
+// OpenEmbeddedReplica opens the offline-first replica of the remote database at url stored locally at path.
+// Only the first open of an empty replica needs the remote (to bootstrap it); afterwards the replica works offline:
+// writes are committed locally and tracked until Sync (or Push) delivers them once the remote is reachable again,
+// PendingChanges reports how many of them are waiting.
+// If the same row is changed both locally and remotely, Sync rebases local changes on top of the pulled remote ones,
+// so the local version of the row wins unless WithConflictResolver decides otherwise.
+func OpenEmbeddedReplica(path, url, token string, opts ...ReplicaOption) (*TursoSyncDb, error) {
+	config := TursoSyncDbConfig{
+		Path:      path,
+		RemoteUrl: url,
+		AuthToken: token,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return NewTursoSyncDb(context.Background(), config)
 }
 
 // main constructor to create synced database
@@ -172,9 +384,15 @@ func NewTursoSyncDb(ctx context.Context, config TursoSyncDbConfig) (*TursoSyncDb
 		PartialBootstrapPrefetch:       config.PartialSyncExperimental.Prefetch,
 		PushOperationsThreshold:        config.PushOperationsThreshold,
 		PullBytesThreshold:             config.PullBytesThreshold,
+		UseTransform:                   config.ConflictResolver != nil,
+	}
+	// sync database keeps the library in use until Close
+	if err := acquireLibrary(); err != nil {
+		return nil, err
 	}
 	sdb, err := turso_sync_database_new(dbCfg, syncCfg)
 	if err != nil {
+		releaseLibrary()
 		return nil, err
 	}
 
@@ -196,18 +414,100 @@ func NewTursoSyncDb(ctx context.Context, config TursoSyncDbConfig) (*TursoSyncDb
 		},
 	}
 
+	if config.ReadYourWrites || dsnOpts.ReadYourWrites {
+		d.ryw = &readYourWrites{}
+	}
+	d.pingRemote = config.PingRemote || dsnOpts.PingRemote
+	d.routeWrites = config.RouteWrites || dsnOpts.RouteWrites
+	d.conflictResolver = config.ConflictResolver
+	// explicit config field takes precedence over DSN
+	d.timeFormat = config.TimeFormat
+	if d.timeFormat == TimeFormatRFC3339 {
+		d.timeFormat = dsnOpts.TimeFormat
+	}
+	d.SetSyncProgressHandler(config.ProgressHandler)
+
 	// Create/open database with bootstrap logic as needed.
 	op, err := turso_sync_database_create(d.db)
 	if err != nil {
+		d.release()
 		return nil, err
 	}
+	d.startProgress(SyncPhaseBootstrapping)
 	_, _, err = d.driveOpUntilDone(ctx, op)
 	if err != nil {
+		d.progress = nil
+		d.release()
 		return nil, err
 	}
+	d.finishProgress()
 	return d, nil
 }
 
+// SetSyncProgressHandler sets handler which receives progress of Pull, Push, Sync and the initial bootstrap
+// (use TursoSyncDbConfig.ProgressHandler to observe the bootstrap); nil handler disables progress reporting.
+// Handler is called synchronously from the goroutine which runs the sync operation and calls are never concurrent,
+// but it must return quickly and must not call methods of the TursoSyncDb as they wait for the running operation.
+// Phase of each operation ends with SyncPhaseDone if it completed successfully.
+func (d *TursoSyncDb) SetSyncProgressHandler(handler func(p SyncProgress)) {
+	if handler == nil {
+		d.progressHandler.Store(nil)
+		return
+	}
+	d.progressHandler.Store(&handler)
+}
+
+// startProgress switches running operation to the given phase; must be called with d.mu held
+// it does nothing (and costs nothing for the sync path) if no progress handler was set
+func (d *TursoSyncDb) startProgress(phase SyncPhase) {
+	if d.progress == nil {
+		handler := d.progressHandler.Load()
+		if handler == nil {
+			return
+		}
+		// counters are informational - if they can't be read progress just starts from zero
+		base, _ := turso_sync_database_progress(d.db)
+		d.progress = &syncProgressTracker{handler: *handler, base: base}
+	}
+	d.progress.progress.Phase = phase
+	d.progress.progress.TotalFrames = 0
+	d.progress.report()
+}
+
+// pollProgress reads counters of the sync engine and reports them if they changed; must be called with d.mu held
+func (d *TursoSyncDb) pollProgress() {
+	if d.progress == nil {
+		return
+	}
+	counters, err := turso_sync_database_progress(d.db)
+	if err != nil {
+		return
+	}
+	next := d.progress.progress
+	next.PulledFrames = counters.PulledPages - d.progress.base.PulledPages
+	next.PushedChanges = counters.PushedChanges - d.progress.base.PushedChanges
+	if next.Phase == SyncPhaseBootstrapping {
+		next.TotalFrames = counters.RemoteDbPages
+	}
+	if next == d.progress.progress {
+		return
+	}
+	d.progress.progress = next
+	d.progress.report()
+}
+
+// finishProgress reports successful completion of the running operation; must be called with d.mu held
+func (d *TursoSyncDb) finishProgress() {
+	if d.progress == nil {
+		return
+	}
+	d.pollProgress()
+	d.progress.progress.Phase = SyncPhaseDone
+	d.progress.progress.TotalFrames = 0
+	d.progress.report()
+	d.progress = nil
+}
+
 // create turso db local connnection
 
 // internal connector to integrate with database/sql pool
@@ -216,6 +516,9 @@ type tursoSyncConnector struct{ db *TursoSyncDb }
 func (c *tursoSyncConnector) Connect(ctx context.Context) (driver.Conn, error) {
 	c.db.mu.Lock()
 	defer c.db.mu.Unlock()
+	if c.db.closed {
+		return nil, ErrTursoSyncDbClosed
+	}
 
 	op, err := turso_sync_database_connect(c.db.db)
 	if err != nil {
@@ -248,6 +551,15 @@ func (c *tursoSyncConnector) Connect(ctx context.Context) (driver.Conn, error) {
 		turso_connection_set_busy_timeout_ms(conn, int64(timeout))
 	}
 	dbConn.busyTimeout = timeout
+	dbConn.timeFormat = c.db.timeFormat
+	dbConn.ryw = c.db.ryw
+	dbConn.replica = true
+	if c.db.pingRemote {
+		dbConn.pingRemote = c.db.probeRemote
+	}
+	if c.db.routeWrites {
+		dbConn.primary = &primaryRoute{db: c.db}
+	}
 
 	return dbConn, nil
 }
@@ -256,7 +568,50 @@ func (c *tursoSyncConnector) Driver() driver.Driver { return &tursoDbDriver{} }
 
 // create tursodb connection using NewConnection(...) from driver_db.go and tursoSyncConnector helper
 func (d *TursoSyncDb) Connect(ctx context.Context) (*sql.DB, error) {
-	return sql.OpenDB(&tursoSyncConnector{db: d}), nil
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return nil, ErrTursoSyncDbClosed
+	}
+	db := sql.OpenDB(&tursoSyncConnector{db: d})
+	d.dbs = append(d.dbs, db)
+	return db, nil
+}
+
+// Close closes the pools created by Connect, waits for the running sync operations and releases the sync database
+// and the native library; methods of the database fail with ErrTursoSyncDbClosed afterwards.
+// Stop StartPeriodicSync loops and finish using the connections first: Close waits for the queries which
+// already started, but connections held by the application (e.g. sql.Conn) must be closed by it.
+// Close is a no-op if the database is already closed.
+func (d *TursoSyncDb) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	dbs := d.dbs
+	d.dbs = nil
+	d.mu.Unlock()
+	var errs []error
+	for _, db := range dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	d.syncMu.Lock()
+	defer d.syncMu.Unlock()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.release()
+	return errors.Join(errs...)
+}
+
+// release frees the sync database and the reference to the native library it holds
+func (d *TursoSyncDb) release() {
+	turso_sync_database_deinit(d.db)
+	d.db = nil
+	releaseLibrary()
 }
 
 // implement EXTRA sync methods
@@ -264,9 +619,26 @@ func (d *TursoSyncDb) Connect(ctx context.Context) (*sql.DB, error) {
 // Pull fresh data from the remote
 // Pull DO NOT sent any local changes to the remote and instead "rebase" them on top of new changes from remote
 // Return true, if new changes were applied locally - otherwise return false
+// Cancellation of ctx aborts fetching changes promptly; once fetched, changes are applied as a whole ignoring ctx,
+// so the local replica is either at its previous state or has all fetched changes. The next Pull starts over.
 func (d *TursoSyncDb) Pull(ctx context.Context) (bool, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
+	if d.closed {
+		return false, ErrTursoSyncDbClosed
+	}
+
+	changed, err := d.pull(ctx)
+	if err != nil {
+		d.progress = nil
+		return false, err
+	}
+	d.finishProgress()
+	return changed, nil
+}
+
+func (d *TursoSyncDb) pull(ctx context.Context) (bool, error) {
+	d.startProgress(SyncPhasePulling)
 
 	// 1) Wait for remote changes
 	waitOp, err := turso_sync_database_wait_changes(d.db)
@@ -274,10 +646,10 @@ func (d *TursoSyncDb) Pull(ctx context.Context) (bool, error) {
 		return false, err
 	}
 	kind, waitFinal, err := d.driveOpUntilDone(ctx, waitOp)
+	defer turso_sync_operation_deinit(waitFinal)
 	if err != nil {
 		return false, err
 	}
-	defer turso_sync_operation_deinit(waitFinal)
 	if kind != TURSO_ASYNC_RESULT_CHANGES {
 		return false, errors.New("turso: unexpected result kind for wait_changes")
 	}
@@ -296,37 +668,159 @@ func (d *TursoSyncDb) Pull(ctx context.Context) (bool, error) {
 		// changes ownership is transferred to apply_changes even in case of error
 		return false, err
 	}
-	_, applyFinal, err := d.driveOpUntilDone(ctx, applyOp)
+	// applying changes is never interrupted by ctx: otherwise the local replica can be left with half-applied changes
+	_, applyFinal, err := d.driveOpUntilDone(context.WithoutCancel(ctx), applyOp)
+	turso_sync_operation_deinit(applyFinal)
 	if err != nil {
 		return false, err
 	}
-	turso_sync_operation_deinit(applyFinal)
+	if d.ryw != nil {
+		// local changes were rebased on top of the remote ones: WAL positions observed before are meaningless now
+		d.ryw.reset()
+	}
 	return true, nil
 }
 
 // Push local changes to the remote
 // Push DO NOT fetch any remote changes
+// Cancellation of ctx aborts the push promptly; local changes stay pending and the next Push sends the ones
+// which the remote didn't acknowledge.
 func (d *TursoSyncDb) Push(ctx context.Context) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
+	if d.closed {
+		return ErrTursoSyncDbClosed
+	}
+
+	if err := d.push(ctx); err != nil {
+		d.progress = nil
+		return err
+	}
+	d.finishProgress()
+	return nil
+}
+
+func (d *TursoSyncDb) push(ctx context.Context) error {
+	d.startProgress(SyncPhasePushing)
 
 	op, err := turso_sync_database_push_changes(d.db)
 	if err != nil {
 		return err
 	}
 	_, opFinal, err := d.driveOpUntilDone(ctx, op)
+	turso_sync_operation_deinit(opFinal)
 	if err != nil {
 		return err
 	}
-	turso_sync_operation_deinit(opFinal)
 	return nil
 }
 
+// Sync pushes local changes to the remote and then pulls fresh data from it
+// Sync calls never overlap: concurrent calls (including the ones made by StartPeriodicSync) run one after another
+// Cancellation of ctx aborts Sync promptly with the same guarantees as Push and Pull: the local replica is never left
+// with half-applied changes and the next Sync resumes from the last state committed locally.
+func (d *TursoSyncDb) Sync(ctx context.Context) error {
+	d.syncMu.Lock()
+	defer d.syncMu.Unlock()
+	return d.sync(ctx)
+}
+
+func (d *TursoSyncDb) sync(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return ErrTursoSyncDbClosed
+	}
+
+	if err := d.push(ctx); err != nil {
+		d.progress = nil
+		return err
+	}
+	if _, err := d.pull(ctx); err != nil {
+		d.progress = nil
+		return err
+	}
+	d.finishProgress()
+	return nil
+}
+
+const (
+	// upper bound for the delay between failed periodic sync attempts
+	periodicSyncMaxBackoff = 5 * time.Minute
+)
+
+// StartPeriodicSync runs Sync every interval in a background goroutine until ctx is done or stop is called
+// Failed attempts (e.g. network errors) are retried with exponential backoff: interval, 2*interval, 4*interval, ...
+// capped at 5 minutes (or interval, if it's larger); the delay goes back to interval after a successful sync
+// If a manual Sync is running when the tick fires, the periodic attempt is skipped instead of queued behind it
+// stop cancels an in-progress sync and waits for the loop to exit; local changes are never left half-applied
+// because applying pulled changes is not interrupted. Only one periodic loop can run at a time
+func (d *TursoSyncDb) StartPeriodicSync(ctx context.Context, interval time.Duration) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("turso: invalid periodic sync interval %v", interval)
+	}
+	if !d.periodic.CompareAndSwap(false, true) {
+		return nil, errors.New("turso: periodic sync is already running")
+	}
+	loopCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer d.periodic.Store(false)
+		failures := 0
+		timer := time.NewTimer(interval)
+		defer timer.Stop()
+		for {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-timer.C:
+			}
+			if d.syncMu.TryLock() {
+				err := d.sync(loopCtx)
+				d.syncMu.Unlock()
+				if loopCtx.Err() != nil {
+					return
+				}
+				if err != nil {
+					failures++
+				} else {
+					failures = 0
+				}
+			}
+			timer.Reset(periodicSyncBackoff(interval, failures))
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}, nil
+}
+
+// periodicSyncBackoff returns the delay before the next periodic sync attempt after given amount of consecutive failures
+func periodicSyncBackoff(interval time.Duration, failures int) time.Duration {
+	limit := max(interval, periodicSyncMaxBackoff)
+	delay := interval
+	for i := 0; i < failures && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
 // Get stats for the synced database
 func (d *TursoSyncDb) Stats(ctx context.Context) (TursoSyncDbStats, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
+	if d.closed {
+		return TursoSyncDbStats{}, ErrTursoSyncDbClosed
+	}
+	return d.stats(ctx)
+}
 
+func (d *TursoSyncDb) stats(ctx context.Context) (TursoSyncDbStats, error) {
 	op, err := turso_sync_database_stats(d.db)
 	if err != nil {
 		return TursoSyncDbStats{}, err
@@ -352,13 +846,41 @@ func (d *TursoSyncDb) Stats(ctx context.Context) (TursoSyncDbStats, error) {
 		NetworkSentBytes:     stats.NetworkSentBytes,
 		NetworkReceivedBytes: stats.NetworkReceivedBytes,
 		Revision:             stats.Revision,
+		SyncedRevision:       syncedRevision(stats),
 	}, nil
 }
 
+func syncedRevision(stats TursoSyncStats) SyncedRevision {
+	switch stats.SyncedRevisionType {
+	case TURSO_SYNC_REVISION_LEGACY:
+		revision := SyncedRevision{Legacy: true, Generation: uint64(stats.SyncedGeneration), Revision: stats.Revision}
+		if stats.SyncedFrameNo >= 0 {
+			revision.Frame = uint64(stats.SyncedFrameNo)
+		}
+		return revision
+	case TURSO_SYNC_REVISION_V1:
+		return SyncedRevision{Revision: stats.Revision}
+	default:
+		return SyncedRevision{}
+	}
+}
+
+// PendingChanges returns amount of local changes which are not pushed to the remote yet
+func (d *TursoSyncDb) PendingChanges() (int, error) {
+	stats, err := d.Stats(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	return int(stats.CdcOperations), nil
+}
+
 // Checkpoint local WAL of the database
 func (d *TursoSyncDb) Checkpoint(ctx context.Context) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
+	if d.closed {
+		return ErrTursoSyncDbClosed
+	}
 
 	op, err := turso_sync_database_checkpoint(d.db)
 	if err != nil {
@@ -372,15 +894,759 @@ func (d *TursoSyncDb) Checkpoint(ctx context.Context) error {
 	return nil
 }
 
+// probeRemote checks that the sync endpoint answers an empty pipeline request with the credentials of the database.
+// It doesn't take d.mu, so it is never blocked by a running sync operation.
+func (d *TursoSyncDb) probeRemote(ctx context.Context) error {
+	resp, err := d.postPipeline(ctx, []byte(`{"baton":null,"requests":[]}`))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// postPipeline sends the body to /v2/pipeline endpoint of the remote with the credentials of the database.
+// Failures to get a successful response are reported as ErrTursoRemoteUnreachable (or ctx error once ctx is done);
+// the caller must close the body of the returned response.
+func (d *TursoSyncDb) postPipeline(ctx context.Context, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", joinUrl(d.baseURL, "/v2/pipeline"), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTursoRemoteUnreachable, err)
+	}
+	host, err := buildHostname(d.baseURL, d.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTursoRemoteUnreachable, err)
+	}
+	req.Host = host
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "turso-sync-go")
+	if d.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+d.authToken)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrTursoRemoteUnreachable, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrTursoRemoteUnreachable, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// Route selects where a statement of a replica connection opened with RouteWrites runs.
+type Route int
+
+const (
+	// RouteAuto routes the statement by its leading keyword
+	RouteAuto Route = iota
+	// RoutePrimary runs the statement on the remote primary, e.g. a read which must observe a preceding write
+	RoutePrimary
+	// RouteReplica runs the statement on the local replica: a write made this way reaches the primary with the next push
+	RouteReplica
+)
+
+type routeKey struct{}
+
+// WithRoute returns a copy of ctx which makes connections opened with RouteWrites run statements on the given route.
+// Statements of a transaction run where the transaction was started regardless of the route.
+// Other connections ignore the route.
+func WithRoute(ctx context.Context, route Route) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// primaryRoute runs statements of a replica connection opened with RouteWrites on the remote primary over /v2/pipeline.
+// Statements outside of a transaction are sent as one-shot pipelines which close their stream, while a transaction
+// keeps the stream it was started on until it ends.
+type primaryRoute struct {
+	db *TursoSyncDb
+	// set while a transaction started with BEGIN runs on the primary; baton continues the stream of the transaction
+	inTx  bool
+	baton string
+	// cancels the pipeline request in flight (guarded by cancelMu, as Interrupt doesn't hold the connection lock)
+	cancelMu sync.Mutex
+	cancel   context.CancelCauseFunc
+}
+
+// primaryCloseTimeout bounds the request which rolls back the transaction of a closed connection on the primary
+const primaryCloseTimeout = 5 * time.Second
+
+// routesToPrimary reports whether the query runs on the remote primary (always false unless the connection has RouteWrites).
+// caller must hold c.mu
+func (c *tursoDbConnection) routesToPrimary(ctx context.Context, query string) bool {
+	if c.primary == nil {
+		return false
+	}
+	if c.primary.inTx {
+		return true
+	}
+	// transaction of the local replica keeps all its statements local
+	if !turso_connection_get_autocommit(c.conn) {
+		return false
+	}
+	switch route, _ := ctx.Value(routeKey{}).(Route); route {
+	case RoutePrimary:
+		return true
+	case RouteReplica:
+		return false
+	}
+	for _, statement := range splitStatements(query) {
+		if isWriteStatement(statement) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryPrimary runs the query on the remote primary if the connection routes it there;
+// it returns false if the query must run locally
+func (c *tursoDbConnection) queryPrimary(ctx context.Context, query string, args []driver.NamedValue) (*primaryRows, bool, error) {
+	if c.checkOpen() != nil {
+		return nil, false, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.routesToPrimary(ctx, query) {
+		return nil, false, nil
+	}
+	rows, err := c.primary.query(ctx, query, args, c.timeFormat)
+	if rows != nil {
+		rows.nullAsZero = c.nullAsZero
+	}
+	return rows, true, err
+}
+
+// exec runs the statements of query on the primary; like local Exec, args are bound to the first statement only
+func (p *primaryRoute) exec(ctx context.Context, query string, args []driver.NamedValue, timeFormat TimeFormat) (driver.Result, error) {
+	results, err := p.run(ctx, query, args, timeFormat, false)
+	if err != nil {
+		return nil, err
+	}
+	result := &tursoDbResult{}
+	for _, r := range results {
+		result.rowsAffected += r.AffectedRowCount
+		if r.LastInsertRowid != nil {
+			if rowid, err := strconv.ParseInt(*r.LastInsertRowid, 10, 64); err == nil {
+				result.lastInsertId = rowid
+			}
+		}
+	}
+	return result, nil
+}
+
+// query runs the statements of query on the primary and returns the result sets of the ones which return rows
+func (p *primaryRoute) query(ctx context.Context, query string, args []driver.NamedValue, timeFormat TimeFormat) (*primaryRows, error) {
+	results, err := p.run(ctx, query, args, timeFormat, true)
+	if err != nil {
+		return nil, err
+	}
+	rows := &primaryRows{timeFormat: timeFormat}
+	for _, r := range results {
+		if len(r.Cols) > 0 {
+			rows.sets = append(rows.sets, r)
+		}
+	}
+	return rows, nil
+}
+
+// run sends the statements of query to the primary as a single batch which stops at the first failed statement
+func (p *primaryRoute) run(ctx context.Context, query string, args []driver.NamedValue, timeFormat TimeFormat, wantRows bool) ([]pipelineStmtResult, error) {
+	statements := splitStatements(query)
+	if len(statements) == 0 {
+		return nil, nil
+	}
+	batch := &pipelineBatch{Steps: make([]pipelineBatchStep, len(statements))}
+	for i, statement := range statements {
+		step := pipelineBatchStep{Stmt: pipelineStmt{SQL: statement, WantRows: wantRows}}
+		if i == 0 {
+			if err := step.Stmt.setArgs(args, timeFormat); err != nil {
+				return nil, err
+			}
+		} else {
+			step.Condition = &pipelineCondition{Type: "ok", Step: i - 1}
+		}
+		batch.Steps[i] = step
+	}
+	// the stream is kept only for the transaction which is active or is started by the batch
+	keepStream := p.inTx
+	for _, statement := range statements {
+		keyword, _ := nextSQLToken(statement, 0)
+		keepStream = keepStream || strings.EqualFold(keyword, "BEGIN")
+	}
+	request := pipelineRequest{Requests: []pipelineStreamRequest{{Type: "batch", Batch: batch}}}
+	if !keepStream {
+		request.Requests = append(request.Requests, pipelineStreamRequest{Type: "close"})
+	}
+	if p.inTx && p.baton != "" {
+		request.Baton = &p.baton
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	p.setCancel(cancel)
+	defer p.setCancel(nil)
+	resp, err := p.db.postPipeline(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var response pipelineResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		return nil, fmt.Errorf("%w: malformed pipeline response: %w", ErrTursoRemoteUnreachable, err)
+	}
+	if len(response.Results) != len(request.Requests) {
+		return nil, fmt.Errorf("%w: malformed pipeline response: got %d results, want %d",
+			ErrTursoRemoteUnreachable, len(response.Results), len(request.Requests))
+	}
+	if response.Results[0].Type != "ok" {
+		return nil, response.Results[0].Error.err()
+	}
+	steps := response.Results[0].Response.Result
+	results := make([]pipelineStmtResult, 0, len(statements))
+	var failure error
+	for i, statement := range statements {
+		succeeded := i < len(steps.StepResults) && steps.StepResults[i] != nil
+		p.trackTransaction(statement, succeeded)
+		if !succeeded {
+			var stepErr *pipelineError
+			if i < len(steps.StepErrors) {
+				stepErr = steps.StepErrors[i]
+			}
+			failure = stepErr.err()
+			break
+		}
+		results = append(results, *steps.StepResults[i])
+	}
+	p.baton = ""
+	if keepStream && response.Baton != nil {
+		p.baton = *response.Baton
+	}
+	// the stream of the transaction which ended with the batch is not needed anymore
+	if !p.inTx && p.baton != "" {
+		_ = p.closeStream(ctx)
+	}
+	if failure != nil {
+		return nil, failure
+	}
+	return results, nil
+}
+
+// closeStream rolls back the transaction which runs on the primary, if any, and closes the stream kept for it
+func (p *primaryRoute) closeStream(ctx context.Context) error {
+	request := pipelineRequest{Requests: []pipelineStreamRequest{{Type: "close"}}}
+	if p.baton != "" {
+		request.Baton = &p.baton
+	}
+	if p.inTx {
+		rollback := pipelineStreamRequest{Type: "execute", Stmt: &pipelineStmt{SQL: "ROLLBACK"}}
+		request.Requests = append([]pipelineStreamRequest{rollback}, request.Requests...)
+	} else if p.baton == "" {
+		return nil
+	}
+	p.inTx, p.baton = false, ""
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	resp, err := p.db.postPipeline(ctx, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// setCancel publishes the cancel function of the pipeline request in flight (nil once it completes)
+func (p *primaryRoute) setCancel(cancel context.CancelCauseFunc) {
+	p.cancelMu.Lock()
+	p.cancel = cancel
+	p.cancelMu.Unlock()
+}
+
+// interrupt cancels the pipeline request in flight, so the statement fails with ErrTursoInterrupt
+func (p *primaryRoute) interrupt() {
+	p.cancelMu.Lock()
+	defer p.cancelMu.Unlock()
+	if p.cancel != nil {
+		p.cancel(ErrTursoInterrupt)
+	}
+}
+
+// trackTransaction follows the transaction control statement which ran on the primary
+func (p *primaryRoute) trackTransaction(statement string, succeeded bool) {
+	keyword, next := nextSQLToken(statement, 0)
+	switch strings.ToUpper(keyword) {
+	case "BEGIN":
+		p.inTx = p.inTx || succeeded
+	case "COMMIT", "END":
+		// failed COMMIT leaves the transaction open, so it can be rolled back
+		p.inTx = p.inTx && !succeeded
+	case "ROLLBACK":
+		token, next := nextSQLToken(statement, next)
+		if strings.EqualFold(token, "TRANSACTION") {
+			token, _ = nextSQLToken(statement, next)
+		}
+		// ROLLBACK TO keeps the transaction, while plain ROLLBACK fails only if no transaction is active
+		if !strings.EqualFold(token, "TO") {
+			p.inTx = false
+		}
+	}
+}
+
+// primaryRows are rows of a query which ran on the remote primary: the whole result is received at once
+type primaryRows struct {
+	sets       []pipelineStmtResult
+	timeFormat TimeFormat
+	// result set and the row within it returned by the next call of Next
+	current int
+	row     int
+	// set with _null_as_zero on the connection, and the current row read by NextRow for ScanColumn
+	nullAsZero bool
+	scanned    []driver.Value
+}
+
+var (
+	_ driver.Rows                           = (*primaryRows)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*primaryRows)(nil)
+	_ driver.RowsNextResultSet              = (*primaryRows)(nil)
+)
+
+func (r *primaryRows) Columns() []string {
+	if r.current >= len(r.sets) {
+		return []string{}
+	}
+	cols := r.sets[r.current].Cols
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		if col.Name != nil {
+			names[i] = *col.Name
+		}
+	}
+	return names
+}
+
+func (r *primaryRows) Close() error {
+	r.sets = nil
+	return nil
+}
+
+func (r *primaryRows) Next(dest []driver.Value) error {
+	if r.current >= len(r.sets) || r.row >= len(r.sets[r.current].Rows) {
+		return io.EOF
+	}
+	set := &r.sets[r.current]
+	row := set.Rows[r.row]
+	if len(dest) != len(row) {
+		return fmt.Errorf("turso: expected %d dests, got %d", len(row), len(dest))
+	}
+	r.row++
+	for i, value := range row {
+		v, err := value.decode()
+		if err != nil {
+			return err
+		}
+		// time columns are converted the same way as the ones of local rows
+		if i < len(set.Cols) && set.Cols[i].Decltype != nil && isTimeColumn(*set.Cols[i].Decltype) {
+			switch x := v.(type) {
+			case int64:
+				if t, ok := r.timeFormat.decodeInt(x); ok {
+					v = t
+				}
+			case string:
+				if t, err := parseTimeString(x); err == nil {
+					v = t
+				}
+			}
+		}
+		dest[i] = v
+	}
+	return nil
+}
+
+func (r *primaryRows) HasNextResultSet() bool {
+	return r.current+1 < len(r.sets)
+}
+
+func (r *primaryRows) NextResultSet() error {
+	if !r.HasNextResultSet() {
+		return io.EOF
+	}
+	r.current++
+	r.row = 0
+	return nil
+}
+
+func (r *primaryRows) ColumnTypeDatabaseTypeName(index int) string {
+	if r.current >= len(r.sets) || index < 0 || index >= len(r.sets[r.current].Cols) {
+		return ""
+	}
+	if decltype := r.sets[r.current].Cols[index].Decltype; decltype != nil {
+		return strings.ToUpper(*decltype)
+	}
+	return ""
+}
+
+// rowCount returns the number of rows received from the primary
+func (r *primaryRows) rowCount() int64 {
+	var n int64
+	for _, set := range r.sets {
+		n += int64(len(set.Rows))
+	}
+	return n
+}
+
+// messages of /v2/pipeline protocol used to run statements on the primary
+
+type pipelineRequest struct {
+	Baton    *string                 `json:"baton"`
+	Requests []pipelineStreamRequest `json:"requests"`
+}
+
+type pipelineStreamRequest struct {
+	Type  string         `json:"type"`
+	Stmt  *pipelineStmt  `json:"stmt,omitempty"`
+	Batch *pipelineBatch `json:"batch,omitempty"`
+}
+
+type pipelineBatch struct {
+	Steps []pipelineBatchStep `json:"steps"`
+}
+
+type pipelineBatchStep struct {
+	Condition *pipelineCondition `json:"condition,omitempty"`
+	Stmt      pipelineStmt       `json:"stmt"`
+}
+
+type pipelineCondition struct {
+	Type string `json:"type"`
+	Step int    `json:"step"`
+}
+
+type pipelineStmt struct {
+	SQL       string             `json:"sql"`
+	Args      []pipelineValue    `json:"args,omitempty"`
+	NamedArgs []pipelineNamedArg `json:"named_args,omitempty"`
+	WantRows  bool               `json:"want_rows"`
+}
+
+type pipelineNamedArg struct {
+	Name  string        `json:"name"`
+	Value pipelineValue `json:"value"`
+}
+
+type pipelineResponse struct {
+	Baton   *string `json:"baton"`
+	Results []struct {
+		Type     string `json:"type"`
+		Response struct {
+			Result struct {
+				StepResults []*pipelineStmtResult `json:"step_results"`
+				StepErrors  []*pipelineError      `json:"step_errors"`
+			} `json:"result"`
+		} `json:"response"`
+		Error *pipelineError `json:"error"`
+	} `json:"results"`
+}
+
+type pipelineStmtResult struct {
+	Cols []struct {
+		Name     *string `json:"name"`
+		Decltype *string `json:"decltype"`
+	} `json:"cols"`
+	Rows             [][]pipelineValue `json:"rows"`
+	AffectedRowCount int64             `json:"affected_row_count"`
+	LastInsertRowid  *string           `json:"last_insert_rowid"`
+}
+
+// pipelineError is the failure of a request or a statement reported by the primary
+type pipelineError struct {
+	Message string `json:"message"`
+	Code    string `json:"code"`
+}
+
+func (e *pipelineError) err() error {
+	switch {
+	case e == nil:
+		return fmt.Errorf("%w: no error reported", ErrTursoPrimary)
+	case e.Code != "":
+		return fmt.Errorf("%w: %s (%s)", ErrTursoPrimary, e.Message, e.Code)
+	default:
+		return fmt.Errorf("%w: %s", ErrTursoPrimary, e.Message)
+	}
+}
+
+// pipelineValue is a value of the protocol: integers are sent as decimal strings and blobs as base64 without padding
+type pipelineValue struct {
+	Type   string          `json:"type"`
+	Value  json.RawMessage `json:"value,omitempty"`
+	Base64 *string         `json:"base64,omitempty"`
+}
+
+// setArgs sets arguments of the statement converted the same way bindOne binds them locally
+func (s *pipelineStmt) setArgs(args []driver.NamedValue, timeFormat TimeFormat) error {
+	for _, arg := range args {
+		value, err := encodePipelineValue(arg.Value, timeFormat)
+		if err != nil {
+			return err
+		}
+		if arg.Name != "" {
+			s.NamedArgs = append(s.NamedArgs, pipelineNamedArg{Name: arg.Name, Value: value})
+		} else {
+			s.Args = append(s.Args, value)
+		}
+	}
+	return nil
+}
+
+func encodePipelineValue(v any, timeFormat TimeFormat) (pipelineValue, error) {
+	if t, ok := v.(time.Time); ok {
+		v = timeFormat.encode(t)
+	}
+	switch x := v.(type) {
+	case nil:
+		return pipelineValue{Type: "null"}, nil
+	case bool:
+		if x {
+			return pipelineInteger(1), nil
+		}
+		return pipelineInteger(0), nil
+	case int, int8, int16, int32, int64:
+		return pipelineInteger(reflect.ValueOf(x).Int()), nil
+	case uint, uint8, uint16, uint32, uint64:
+		u := reflect.ValueOf(x).Uint()
+		if err := checkUint64(u); err != nil {
+			return pipelineValue{}, err
+		}
+		return pipelineInteger(int64(u)), nil
+	case float32, float64:
+		f := reflect.ValueOf(x).Float()
+		if math.IsNaN(f) {
+			return pipelineValue{Type: "null"}, nil
+		}
+		if math.IsInf(f, 0) {
+			return pipelineValue{}, fmt.Errorf("turso: infinite float %v can't be sent to the primary", f)
+		}
+		return pipelineValue{Type: "float", Value: json.RawMessage(strconv.FormatFloat(f, 'g', -1, 64))}, nil
+	case []byte:
+		encoded := base64.RawStdEncoding.EncodeToString(x)
+		return pipelineValue{Type: "blob", Base64: &encoded}, nil
+	case []float32:
+		return encodePipelineValue(encodeVector(x), timeFormat)
+	case string:
+		return pipelineText(x)
+	default:
+		return pipelineText(fmt.Sprint(v))
+	}
+}
+
+func pipelineInteger(i int64) pipelineValue {
+	return pipelineValue{Type: "integer", Value: json.RawMessage(strconv.Quote(strconv.FormatInt(i, 10)))}
+}
+
+func pipelineText(s string) (pipelineValue, error) {
+	text, err := json.Marshal(s)
+	if err != nil {
+		return pipelineValue{}, err
+	}
+	return pipelineValue{Type: "text", Value: text}, nil
+}
+
+// decode converts the value received from the primary to the driver value
+func (v pipelineValue) decode() (driver.Value, error) {
+	switch v.Type {
+	case "null":
+		return nil, nil
+	case "integer":
+		var text string
+		if err := json.Unmarshal(v.Value, &text); err != nil {
+			// tolerate integers sent as JSON numbers
+			text = string(v.Value)
+		}
+		i, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed integer %s", ErrTursoPrimary, v.Value)
+		}
+		return i, nil
+	case "float":
+		var f float64
+		if err := json.Unmarshal(v.Value, &f); err != nil {
+			return nil, fmt.Errorf("%w: malformed float %s", ErrTursoPrimary, v.Value)
+		}
+		return f, nil
+	case "text":
+		var s string
+		if err := json.Unmarshal(v.Value, &s); err != nil {
+			return nil, fmt.Errorf("%w: malformed text %s", ErrTursoPrimary, v.Value)
+		}
+		return s, nil
+	case "blob":
+		if v.Base64 == nil {
+			return []byte{}, nil
+		}
+		blob, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(*v.Base64, "="))
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed blob: %w", ErrTursoPrimary, err)
+		}
+		if blob == nil {
+			blob = []byte{}
+		}
+		return blob, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown value type %q", ErrTursoPrimary, v.Type)
+	}
+}
+
+// splitStatements splits query into statements without parsing them (the local schema may not know their tables),
+// skipping empty statements: semicolons inside literals, comments and trigger bodies don't end a statement
+func splitStatements(query string) []string {
+	var statements []string
+	start, depth, tokens := 0, 0, 0
+	var lead []string // leading words of the current statement, enough to recognize CREATE [TEMP] TRIGGER
+	trigger := false
+	for i := 0; ; {
+		token, next := nextSQLToken(query, i)
+		if token == "" {
+			break
+		}
+		if token == ";" && depth == 0 {
+			if tokens > 0 {
+				statements = append(statements, strings.TrimSpace(query[start:i]))
+			}
+			start, tokens, lead, trigger = next, 0, lead[:0], false
+			i = next
+			continue
+		}
+		if len(lead) < 3 {
+			lead = append(lead, strings.ToUpper(token))
+			trigger = len(lead) >= 2 && lead[0] == "CREATE" && (lead[1] == "TRIGGER" || len(lead) == 3 && lead[2] == "TRIGGER")
+		}
+		// statements of a trigger body end with semicolons too: the body is closed with END of its BEGIN
+		switch strings.ToUpper(token) {
+		case "BEGIN":
+			if trigger {
+				depth++
+			}
+		case "CASE":
+			depth++
+		case "END":
+			depth = max(depth-1, 0)
+		}
+		tokens++
+		i = next
+	}
+	if tokens > 0 {
+		statements = append(statements, strings.TrimSpace(query[start:]))
+	}
+	return statements
+}
+
+// isWriteStatement reports whether the statement changes the database, judging by its leading keyword;
+// transaction control statements other than BEGIN, PRAGMA, ATTACH, DETACH and VACUUM are not writes as they belong
+// to the local connection
+func isWriteStatement(statement string) bool {
+	token, next := nextSQLToken(statement, 0)
+	switch strings.ToUpper(token) {
+	case "INSERT", "UPDATE", "DELETE", "REPLACE", "CREATE", "DROP", "ALTER", "ANALYZE", "REINDEX", "BEGIN":
+		return true
+	case "WITH":
+		// the statement after common table expressions decides
+		depth := 0
+		for token, next = nextSQLToken(statement, next); token != ""; token, next = nextSQLToken(statement, next) {
+			switch {
+			case token == "(":
+				depth++
+			case token == ")":
+				depth--
+			case depth == 0:
+				switch strings.ToUpper(token) {
+				case "INSERT", "UPDATE", "DELETE", "REPLACE":
+					return true
+				case "SELECT", "VALUES":
+					return false
+				}
+			}
+		}
+	}
+	return false
+}
+
+// nextSQLToken returns the token of sql which starts at offset i or after it along with the offset after the token.
+// Whitespace and comments are skipped; a word, a quoted literal or identifier is a single token and any other character
+// is a token on its own. It returns an empty token at the end of sql.
+func nextSQLToken(sql string, i int) (string, int) {
+	for i < len(sql) {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				return "", len(sql)
+			}
+			i += end + 1
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return "", len(sql)
+			}
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(sql[i+1:], closing)
+			if end < 0 {
+				return sql[i:], len(sql)
+			}
+			return sql[i : i+end+2], i + end + 2
+		case isSQLWordByte(c):
+			start := i
+			for i < len(sql) && isSQLWordByte(sql[i]) {
+				i++
+			}
+			return sql[start:i], i
+		default:
+			return sql[i : i+1], i + 1
+		}
+	}
+	return "", len(sql)
+}
+
+func isSQLWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '$' || c >= 0x80
+}
+
 // driveOpUntilDone resumes an async operation until completion, serving IO requests as needed.
 // It returns the final result kind and the operation handle that must be deinitialized by the caller.
+// If ctx is done, the operation is abandoned and ctx error is returned.
 func (d *TursoSyncDb) driveOpUntilDone(ctx context.Context, op TursoSyncOperation) (TursoSyncOperationResultType, TursoSyncOperation, error) {
 	for {
 		if ctx != nil && ctx.Err() != nil {
+			d.settleIoQueue(ctx.Err())
 			return TURSO_ASYNC_RESULT_NONE, op, ctx.Err()
 		}
 		code, err := turso_sync_operation_resume(op)
+		d.pollProgress()
 		if err != nil {
+			if ctx != nil && ctx.Err() != nil {
+				// the operation failed because its HTTP request was cancelled
+				d.settleIoQueue(ctx.Err())
+				return TURSO_ASYNC_RESULT_NONE, op, ctx.Err()
+			}
 			return TURSO_ASYNC_RESULT_NONE, op, err
 		}
 		switch code {
@@ -388,6 +1654,10 @@ func (d *TursoSyncDb) driveOpUntilDone(ctx context.Context, op TursoSyncOperatio
 			return turso_sync_operation_result_kind(op), op, nil
 		case TURSO_IO:
 			if err := d.processIoQueue(ctx); err != nil {
+				if ctx != nil && ctx.Err() != nil {
+					d.settleIoQueue(ctx.Err())
+					return TURSO_ASYNC_RESULT_NONE, op, ctx.Err()
+				}
 				return TURSO_ASYNC_RESULT_NONE, op, err
 			}
 			continue
@@ -400,6 +1670,26 @@ func (d *TursoSyncDb) driveOpUntilDone(ctx context.Context, op TursoSyncOperatio
 	}
 }
 
+// settleIoQueue completes IO requests left in the queue by the operation abandoned because of cancellation,
+// so they don't leak into the next operation: HTTP and transform requests fail with cause, while local file requests
+// are executed, as the engine already committed to the metadata they write.
+func (d *TursoSyncDb) settleIoQueue(cause error) {
+	for {
+		item, err := turso_sync_database_io_take_item(d.db)
+		if err != nil || item == nil {
+			break
+		}
+		if kind := turso_sync_database_io_request_kind(item); kind == TURSO_SYNC_IO_HTTP || kind == TURSO_SYNC_IO_TRANSFORM {
+			_ = turso_sync_database_io_poison(item, cause.Error())
+			_ = turso_sync_database_io_done(item)
+		} else {
+			_ = d.handleIoItem(context.Background(), item)
+		}
+		turso_sync_database_io_item_deinit(item)
+	}
+	_ = turso_sync_database_io_step_callbacks(d.db)
+}
+
 // processOneIo handles at most one IO item (used as extra IO iteration inside SQL driver).
 func (d *TursoSyncDb) processOneIo() error {
 	item, err := turso_sync_database_io_take_item(d.db)
@@ -581,9 +1871,9 @@ func (d *TursoSyncDb) handleIoItem(ctx context.Context, item TursoSyncIoItem) er
 		if dir := filepath.Dir(r.Path); dir != "" && dir != "." {
 			_ = os.MkdirAll(dir, 0o755)
 		}
-		// Write file atomically-ish by writing to a temp and renaming
+		// Write file atomically by writing to a synced temp and renaming: readers see either old or new content
 		tmp := r.Path + ".tmp"
-		if werr := os.WriteFile(tmp, r.Content, 0o644); werr != nil {
+		if werr := writeFileSynced(tmp, r.Content); werr != nil {
 			_ = turso_sync_database_io_poison(item, werr.Error())
 			_ = turso_sync_database_io_done(item)
 			return werr
@@ -596,6 +1886,20 @@ func (d *TursoSyncDb) handleIoItem(ctx context.Context, item TursoSyncIoItem) er
 		_ = turso_sync_database_io_done(item)
 		return nil
 
+	case TURSO_SYNC_IO_TRANSFORM:
+		r, err := turso_sync_database_io_request_transform(item)
+		if err == nil {
+			var results []byte
+			if results, err = d.resolveLocalChanges(r.Mutations); err == nil {
+				_ = turso_sync_database_io_push_buffer(item, results)
+			}
+		}
+		if err != nil {
+			_ = turso_sync_database_io_poison(item, err.Error())
+		}
+		_ = turso_sync_database_io_done(item)
+		return err
+
 	default:
 		// Unknown or none; mark done
 		_ = turso_sync_database_io_done(item)
@@ -603,6 +1907,102 @@ func (d *TursoSyncDb) handleIoItem(ctx context.Context, item TursoSyncIoItem) er
 	}
 }
 
+// rowMutation is the local row change of the transform request; values are encoded as in the pipeline protocol
+type rowMutation struct {
+	ChangeTime int64                    `json:"change_time"`
+	TableName  string                   `json:"table_name"`
+	ID         int64                    `json:"id"`
+	ChangeType RowChangeType            `json:"change_type"`
+	Before     map[string]pipelineValue `json:"before"`
+	After      map[string]pipelineValue `json:"after"`
+	Updates    map[string]pipelineValue `json:"updates"`
+}
+
+// rowTransform is the result of the transform request for a single row mutation
+type rowTransform struct {
+	Type string          `json:"type"`
+	SQL  string          `json:"sql,omitempty"`
+	Args []pipelineValue `json:"args,omitempty"`
+}
+
+// resolveLocalChanges runs the conflict resolver for every mutation of the transform request and encodes the results
+func (d *TursoSyncDb) resolveLocalChanges(data []byte) (_ []byte, err error) {
+	if d.conflictResolver == nil {
+		return nil, errors.New("turso: transform requested without conflict resolver")
+	}
+	var mutations []rowMutation
+	if err := json.Unmarshal(data, &mutations); err != nil {
+		return nil, fmt.Errorf("turso: malformed transform request: %w", err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("turso: conflict resolver panicked: %v", r)
+		}
+	}()
+	results := make([]rowTransform, len(mutations))
+	for i, m := range mutations {
+		change := LocalRowChange{Table: m.TableName, RowID: m.ID, Type: m.ChangeType, ChangeTime: m.ChangeTime}
+		if change.Before, err = decodeRowValues(m.Before); err != nil {
+			return nil, err
+		}
+		if change.After, err = decodeRowValues(m.After); err != nil {
+			return nil, err
+		}
+		if change.Updates, err = decodeRowValues(m.Updates); err != nil {
+			return nil, err
+		}
+		resolution := d.conflictResolver(change)
+		switch {
+		case resolution.skip:
+			results[i] = rowTransform{Type: "skip"}
+		case resolution.sql != "":
+			args := make([]pipelineValue, len(resolution.args))
+			for j, arg := range resolution.args {
+				if args[j], err = encodePipelineValue(arg, d.timeFormat); err != nil {
+					return nil, err
+				}
+			}
+			results[i] = rowTransform{Type: "rewrite", SQL: resolution.sql, Args: args}
+		default:
+			results[i] = rowTransform{Type: "keep"}
+		}
+	}
+	return json.Marshal(results)
+}
+
+// decodeRowValues converts column values of the row mutation to the driver values (nil map stays nil)
+func decodeRowValues(values map[string]pipelineValue) (map[string]any, error) {
+	if values == nil {
+		return nil, nil
+	}
+	row := make(map[string]any, len(values))
+	for column, value := range values {
+		v, err := value.decode()
+		if err != nil {
+			return nil, err
+		}
+		row[column] = v
+	}
+	return row, nil
+}
+
+// writeFileSynced writes content to the file at path and flushes it to the disk
+func writeFileSynced(path string, content []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func joinUrl(base, p string) string {
 	if !strings.HasPrefix(p, "/") {
 		p = "/" + p
@@ -619,7 +2019,11 @@ func normalizeUrl(base string) string {
 
 // syncDSNOptions holds options parsed from DSN-style path
 type syncDSNOptions struct {
-	BusyTimeout int // 0 = not set, >0 = custom, <0 = disabled
+	BusyTimeout    int // 0 = not set, >0 = custom, <0 = disabled
+	ReadYourWrites bool
+	TimeFormat     TimeFormat
+	PingRemote     bool
+	RouteWrites    bool
 }
 
 // parseSyncDSN parses a DSN-style path like "mydb.db?_busy_timeout=5000"
@@ -642,5 +2046,25 @@ func parseSyncDSN(dsn string) (string, syncDSNOptions) {
 			opts.BusyTimeout = timeout
 		}
 	}
+	if v := vals.Get("_read_your_writes"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			opts.ReadYourWrites = enabled
+		}
+	}
+	if v := vals.Get("_time_format"); v != "" {
+		if format, err := parseTimeFormat(v); err == nil {
+			opts.TimeFormat = format
+		}
+	}
+	if v := vals.Get("_ping_remote"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			opts.PingRemote = enabled
+		}
+	}
+	if v := vals.Get("_route_writes"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			opts.RouteWrites = enabled
+		}
+	}
 	return path, opts
 }
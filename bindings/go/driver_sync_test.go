@@ -81,6 +81,24 @@ func TestSyncDSNParsing(t *testing.T) {
 	}
 }
 
+func TestSyncDSNReadYourWrites(t *testing.T) {
+	path, opts := parseSyncDSN("test.db?_read_your_writes=true&_busy_timeout=10")
+	require.Equal(t, "test.db", path)
+	require.True(t, opts.ReadYourWrites)
+	require.Equal(t, 10, opts.BusyTimeout)
+
+	_, opts = parseSyncDSN("test.db?_read_your_writes=0")
+	require.False(t, opts.ReadYourWrites)
+}
+
+func TestSyncDSNTimeFormat(t *testing.T) {
+	_, opts := parseSyncDSN("test.db?_time_format=unixms")
+	require.Equal(t, TimeFormatUnixMilli, opts.TimeFormat)
+
+	_, opts = parseSyncDSN("test.db?_time_format=bogus")
+	require.Equal(t, TimeFormatRFC3339, opts.TimeFormat)
+}
+
 func TestSyncBusyTimeoutConfigPrecedence(t *testing.T) {
 	// Test that explicit BusyTimeout in config takes precedence over DSN
 	t.Run("config overrides DSN", func(t *testing.T) {
@@ -140,6 +158,55 @@ func TestSyncBusyTimeoutConfigPrecedence(t *testing.T) {
 	})
 }
 
+func TestSyncDSNRouteWrites(t *testing.T) {
+	_, opts := parseSyncDSN("test.db?_route_writes=true")
+	require.True(t, opts.RouteWrites)
+
+	_, opts = parseSyncDSN("test.db?_route_writes=bogus")
+	require.False(t, opts.RouteWrites)
+}
+
+func TestRouteStatements(t *testing.T) {
+	require.Equal(t, []string{
+		"CREATE TABLE t(x)",
+		"INSERT INTO t VALUES ('a;b')",
+		"CREATE TEMP TRIGGER tr AFTER INSERT ON t BEGIN UPDATE t SET x = CASE WHEN x = 1 THEN 2 ELSE x END; DELETE FROM t WHERE x = 3; END",
+		"SELECT 1 -- trailing; comment",
+	}, splitStatements(`CREATE TABLE t(x); ; INSERT INTO t VALUES ('a;b');
+		CREATE TEMP TRIGGER tr AFTER INSERT ON t BEGIN UPDATE t SET x = CASE WHEN x = 1 THEN 2 ELSE x END; DELETE FROM t WHERE x = 3; END;
+		/* empty; */ SELECT 1 -- trailing; comment`))
+	require.Empty(t, splitStatements(" ; -- nothing"))
+
+	for statement, write := range map[string]bool{
+		"insert into t values (1)":                              true,
+		"  /* comment */ UPDATE t SET x = 1":                    true,
+		"DELETE FROM t RETURNING x":                             true,
+		"REPLACE INTO t VALUES (1)":                             true,
+		"CREATE INDEX i ON t(x)":                                true,
+		"DROP TABLE t":                                          true,
+		"BEGIN IMMEDIATE":                                       true,
+		"WITH c(x) AS (SELECT 1) INSERT INTO t SELECT x FROM c": true,
+		"WITH RECURSIVE c AS (SELECT 1 UNION SELECT 2) DELETE FROM t WHERE x IN c": true,
+		"WITH c AS (SELECT 1) SELECT * FROM c":                                     false,
+		"SELECT * FROM t WHERE x = 'INSERT'":                                       false,
+		"VALUES (1)":                                                               false,
+		"PRAGMA user_version = 1":                                                  false,
+		"COMMIT":                                                                   false,
+		"EXPLAIN DELETE FROM t":                                                    false,
+	} {
+		require.Equal(t, write, isWriteStatement(statement), statement)
+	}
+}
+
+func TestPeriodicSyncBackoff(t *testing.T) {
+	require.Equal(t, time.Second, periodicSyncBackoff(time.Second, 0))
+	require.Equal(t, 2*time.Second, periodicSyncBackoff(time.Second, 1))
+	require.Equal(t, 8*time.Second, periodicSyncBackoff(time.Second, 3))
+	require.Equal(t, periodicSyncMaxBackoff, periodicSyncBackoff(time.Second, 100))
+	// interval larger than the cap is never shortened
+	require.Equal(t, time.Hour, periodicSyncBackoff(time.Hour, 5))
+}
+
 func randomString() string {
 	return fmt.Sprintf("r-%v", rand.Intn(1000_000_000))
 }
@@ -419,6 +486,33 @@ func TestSyncRollbackReadTxnAfterCommit(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestSyncClose(t *testing.T) {
+	ctx := context.Background()
+	bootstrapIfEmpty := false
+	sdb, err := NewTursoSyncDb(ctx, TursoSyncDbConfig{
+		Path:             path.Join(t.TempDir(), "local.db"),
+		BootstrapIfEmpty: &bootstrapIfEmpty,
+	})
+	require.Nil(t, err)
+	users := libraryUsers.Load()
+	db, err := sdb.Connect(ctx)
+	require.Nil(t, err)
+	_, err = db.ExecContext(ctx, "CREATE TABLE t (x)")
+	require.Nil(t, err)
+
+	require.Nil(t, sdb.Close())
+	require.Equal(t, users-1, libraryUsers.Load())
+	_, err = db.ExecContext(ctx, "INSERT INTO t VALUES (1)")
+	require.Error(t, err, "pools created by Connect are closed")
+	_, err = sdb.Connect(ctx)
+	require.ErrorIs(t, err, ErrTursoSyncDbClosed)
+	_, err = sdb.Stats(ctx)
+	require.ErrorIs(t, err, ErrTursoSyncDbClosed)
+	require.ErrorIs(t, sdb.Sync(ctx), ErrTursoSyncDbClosed)
+	require.Nil(t, sdb.Close())
+	require.Equal(t, users-1, libraryUsers.Load())
+}
+
 func TestSyncConfigPersistence(t *testing.T) {
 	server, err := NewTursoServer()
 	require.Nil(t, err)
@@ -896,3 +990,506 @@ func TestSyncPullBytesThreshold(t *testing.T) {
 	require.Greater(t, withThreshold, withoutThreshold)
 	require.Greater(t, withThreshold, int64(1))
 }
+
+func TestSyncPeriodic(t *testing.T) {
+	server, err := NewTursoServer()
+	require.Nil(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	_, err = server.DbSql("CREATE TABLE t(x)")
+	require.Nil(t, err)
+
+	db, err := NewTursoSyncDb(context.Background(), TursoSyncDbConfig{
+		Path:       ":memory:",
+		ClientName: "turso-sync-go",
+		RemoteUrl:  server.DbUrl,
+	})
+	require.Nil(t, err)
+	conn, err := db.Connect(context.Background())
+	require.Nil(t, err)
+
+	_, err = db.StartPeriodicSync(context.Background(), 0)
+	require.Error(t, err)
+
+	stop, err := db.StartPeriodicSync(context.Background(), 20*time.Millisecond)
+	require.Nil(t, err)
+	_, err = db.StartPeriodicSync(context.Background(), 20*time.Millisecond)
+	require.Error(t, err, "only one periodic loop is allowed")
+
+	_, err = conn.Exec("INSERT INTO t VALUES ('local')")
+	require.Nil(t, err)
+	_, err = server.DbSql("INSERT INTO t VALUES ('remote')")
+	require.Nil(t, err)
+
+	// manual syncs running concurrently with the periodic loop must not overlap with it
+	for range 5 {
+		require.Nil(t, db.Sync(context.Background()))
+	}
+	require.Eventually(t, func() bool {
+		rows, err := server.DbSql("SELECT * FROM t WHERE x = 'local'")
+		return err == nil && len(rows) == 1
+	}, 10*time.Second, 20*time.Millisecond)
+	require.Eventually(t, func() bool {
+		var count int
+		err := conn.QueryRow("SELECT count(*) FROM t WHERE x = 'remote'").Scan(&count)
+		return err == nil && count == 1
+	}, 10*time.Second, 20*time.Millisecond)
+
+	stop()
+	stop()
+
+	// loop can be restarted after stop and is stopped by ctx
+	ctx, cancel := context.WithCancel(context.Background())
+	stop, err = db.StartPeriodicSync(ctx, 20*time.Millisecond)
+	require.Nil(t, err)
+	cancel()
+	stop()
+}
+
+func TestSyncProgressHandler(t *testing.T) {
+	server, err := NewTursoServer()
+	require.Nil(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	_, err = server.DbSql("CREATE TABLE t(x)")
+	require.Nil(t, err)
+	_, err = server.DbSql("INSERT INTO t SELECT randomblob(4096) FROM generate_series(1, 16)")
+	require.Nil(t, err)
+
+	var reports []SyncProgress
+	handler := func(p SyncProgress) { reports = append(reports, p) }
+	db, err := NewTursoSyncDb(context.Background(), TursoSyncDbConfig{
+		Path:            ":memory:",
+		ClientName:      "turso-sync-go",
+		RemoteUrl:       server.DbUrl,
+		ProgressHandler: handler,
+	})
+	require.Nil(t, err)
+	require.NotEmpty(t, reports)
+	require.Equal(t, SyncPhaseBootstrapping, reports[0].Phase)
+	last := reports[len(reports)-1]
+	require.Equal(t, SyncPhaseDone, last.Phase)
+	require.Greater(t, last.PulledFrames, int64(16))
+	require.GreaterOrEqual(t, reports[len(reports)-2].TotalFrames, last.PulledFrames)
+
+	conn, err := db.Connect(context.Background())
+	require.Nil(t, err)
+	_, err = conn.Exec("INSERT INTO t VALUES ('a'), ('b')")
+	require.Nil(t, err)
+
+	reports = nil
+	require.Nil(t, db.Sync(context.Background()))
+	phases := []SyncPhase{}
+	for _, p := range reports {
+		if len(phases) == 0 || phases[len(phases)-1] != p.Phase {
+			phases = append(phases, p.Phase)
+		}
+	}
+	require.Equal(t, []SyncPhase{SyncPhasePushing, SyncPhasePulling, SyncPhaseDone}, phases)
+	require.Equal(t, int64(2), reports[len(reports)-1].PushedChanges)
+
+	db.SetSyncProgressHandler(nil)
+	reports = nil
+	require.Nil(t, db.Push(context.Background()))
+	require.Empty(t, reports)
+}
+
+// cancelOnPullTransport cancels the sync context once the remote starts streaming pulled changes
+type cancelOnPullTransport struct {
+	http.RoundTripper
+	cancel atomic.Pointer[context.CancelFunc]
+}
+
+func (t *cancelOnPullTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if cancel := t.cancel.Load(); err == nil && cancel != nil && strings.HasSuffix(req.URL.Path, "/pull-updates") {
+		(*cancel)()
+	}
+	return resp, err
+}
+
+func TestSyncPullCancel(t *testing.T) {
+	server, err := NewTursoServer()
+	require.Nil(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	_, err = server.DbSql("CREATE TABLE t(x)")
+	require.Nil(t, err)
+	_, err = server.DbSql("INSERT INTO t VALUES (1)")
+	require.Nil(t, err)
+
+	local := path.Join(t.TempDir(), "local.db")
+	db, err := NewTursoSyncDb(context.Background(), TursoSyncDbConfig{
+		Path:       local,
+		ClientName: "turso-sync-go",
+		RemoteUrl:  server.DbUrl,
+	})
+	require.Nil(t, err)
+	transport := &cancelOnPullTransport{RoundTripper: db.client.Transport}
+	db.client.Transport = transport
+
+	_, err = server.DbSql("INSERT INTO t SELECT randomblob(4096) FROM generate_series(1, 64)")
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	transport.cancel.Store(&cancel)
+	_, err = db.Pull(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	transport.cancel.Store(nil)
+
+	count := func(db *TursoSyncDb) int {
+		conn, err := db.Connect(context.Background())
+		require.Nil(t, err)
+		defer conn.Close()
+		var n int
+		require.Nil(t, conn.QueryRow("SELECT count(*) FROM t").Scan(&n))
+		return n
+	}
+	// the replica stays at its previous state
+	require.Equal(t, 1, count(db))
+
+	changed, err := db.Pull(context.Background())
+	require.Nil(t, err)
+	require.True(t, changed)
+	require.Equal(t, 65, count(db))
+
+	reopened, err := NewTursoSyncDb(context.Background(), TursoSyncDbConfig{
+		Path:      local,
+		RemoteUrl: server.DbUrl,
+	})
+	require.Nil(t, err)
+	require.Equal(t, 65, count(reopened))
+}
+
+// offlineTransport fails all requests while offline is set
+type offlineTransport struct {
+	http.RoundTripper
+	offline atomic.Bool
+}
+
+func (t *offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.offline.Load() {
+		return nil, errors.New("network is unreachable")
+	}
+	return t.RoundTripper.RoundTrip(req)
+}
+
+func TestSyncOfflineWrites(t *testing.T) {
+	server, err := NewTursoServer()
+	require.Nil(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	_, err = server.DbSql("CREATE TABLE t(x)")
+	require.Nil(t, err)
+
+	db, err := OpenEmbeddedReplica(path.Join(t.TempDir(), "local.db"), server.DbUrl, "", WithReplicaClientName("turso-sync-go"))
+	require.Nil(t, err)
+	transport := &offlineTransport{RoundTripper: db.client.Transport}
+	db.client.Transport = transport
+	conn, err := db.Connect(context.Background())
+	require.Nil(t, err)
+	defer conn.Close()
+
+	transport.offline.Store(true)
+	_, err = conn.Exec("INSERT INTO t VALUES (1), (2)")
+	require.Nil(t, err)
+	_, err = conn.Exec("INSERT INTO t VALUES (3)")
+	require.Nil(t, err)
+	pending, err := db.PendingChanges()
+	require.Nil(t, err)
+	require.Equal(t, 3, pending)
+	require.NotNil(t, db.Sync(context.Background()))
+	pending, err = db.PendingChanges()
+	require.Nil(t, err)
+	require.Equal(t, 3, pending)
+
+	transport.offline.Store(false)
+	require.Nil(t, db.Sync(context.Background()))
+	pending, err = db.PendingChanges()
+	require.Nil(t, err)
+	require.Zero(t, pending)
+	rows, err := server.DbSql("SELECT x FROM t ORDER BY x")
+	require.Nil(t, err)
+	require.Len(t, rows, 3)
+}
+
+func TestSyncConflictResolver(t *testing.T) {
+	server, err := NewTursoServer()
+	require.Nil(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	_, err = server.DbSql("CREATE TABLE t(id INTEGER PRIMARY KEY, v TEXT)")
+	require.Nil(t, err)
+	_, err = server.DbSql("INSERT INTO t VALUES (1, 'base'), (2, 'base')")
+	require.Nil(t, err)
+
+	// the resolver runs synchronously within Sync
+	changes := map[int64]LocalRowChange{}
+	resolver := func(change LocalRowChange) RowResolution {
+		changes[change.RowID] = change
+		switch change.RowID {
+		case 1:
+			return SkipLocalChange
+		case 3:
+			return RewriteLocalChange("INSERT OR REPLACE INTO t VALUES (?, ?)", 3, "rewritten")
+		default:
+			return KeepLocalChange
+		}
+	}
+	db, err := OpenEmbeddedReplica(path.Join(t.TempDir(), "local.db"), server.DbUrl, "", WithReplicaClientName("turso-sync-go"), WithConflictResolver(resolver))
+	require.Nil(t, err)
+	conn, err := db.Connect(context.Background())
+	require.Nil(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec("UPDATE t SET v = 'local'")
+	require.Nil(t, err)
+	_, err = conn.Exec("INSERT INTO t VALUES (3, 'local')")
+	require.Nil(t, err)
+	_, err = server.DbSql("UPDATE t SET v = 'remote'")
+	require.Nil(t, err)
+	require.Nil(t, db.Sync(context.Background()))
+
+	require.Len(t, changes, 3)
+	update := changes[1]
+	require.Equal(t, "t", update.Table)
+	require.Equal(t, RowUpdate, update.Type)
+	require.Equal(t, map[string]any{"id": int64(1), "v": "base"}, update.Before)
+	require.Equal(t, map[string]any{"id": int64(1), "v": "local"}, update.After)
+	require.Equal(t, RowInsert, changes[3].Type)
+	require.Nil(t, changes[3].Before)
+
+	// the remote version of row 1 wins, the local versions of rows 2 and 3 (the latter rewritten) win
+	expected := [][]any{{"1", "remote"}, {"2", "local"}, {"3", "rewritten"}}
+	rows, err := server.DbSql("SELECT id, v FROM t ORDER BY id")
+	require.Nil(t, err)
+	require.Equal(t, expected, rows)
+	local, err := conn.Query("SELECT id, v FROM t ORDER BY id")
+	require.Nil(t, err)
+	defer local.Close()
+	for _, row := range expected {
+		var v string
+		var id int
+		require.True(t, local.Next())
+		require.Nil(t, local.Scan(&id, &v))
+		require.Equal(t, row, []any{fmt.Sprint(id), v})
+	}
+	require.False(t, local.Next())
+}
+
+func TestSyncPingRemote(t *testing.T) {
+	server, err := NewTursoServer()
+	require.Nil(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	db, err := OpenEmbeddedReplica(path.Join(t.TempDir(), "local.db?_ping_remote=true"), server.DbUrl, "", WithReplicaClientName("turso-sync-go"))
+	require.Nil(t, err)
+	transport := &offlineTransport{RoundTripper: db.client.Transport}
+	db.client.Transport = transport
+	conn, err := db.Connect(context.Background())
+	require.Nil(t, err)
+	defer conn.Close()
+
+	require.Nil(t, conn.PingContext(context.Background()))
+	transport.offline.Store(true)
+	err = conn.PingContext(context.Background())
+	require.ErrorIs(t, err, ErrTursoRemoteUnreachable)
+	// local database stays usable while the remote is unreachable
+	_, err = conn.Exec("SELECT 1")
+	require.Nil(t, err)
+	transport.offline.Store(false)
+	require.Nil(t, conn.PingContext(context.Background()))
+}
+
+func TestSyncRouteWrites(t *testing.T) {
+	server, err := NewTursoServer()
+	require.Nil(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	db, err := OpenEmbeddedReplica(path.Join(t.TempDir(), "local.db?_route_writes=true"), server.DbUrl, "", WithReplicaClientName("turso-sync-go"))
+	require.Nil(t, err)
+	conn, err := db.Connect(context.Background())
+	require.Nil(t, err)
+	defer conn.Close()
+	ctx := context.Background()
+
+	// writes run on the primary even if the local replica doesn't know the table yet
+	_, err = conn.ExecContext(ctx, "CREATE TABLE t(x)")
+	require.Nil(t, err)
+	result, err := conn.ExecContext(ctx, "INSERT INTO t VALUES (?), (?)", 1, "two")
+	require.Nil(t, err)
+	affected, err := result.RowsAffected()
+	require.Nil(t, err)
+	require.Equal(t, int64(2), affected)
+	rows, err := server.DbSql("SELECT x FROM t ORDER BY rowid")
+	require.Nil(t, err)
+	require.Equal(t, [][]any{{"1"}, {"two"}}, rows)
+	pending, err := db.PendingChanges()
+	require.Nil(t, err)
+	require.Zero(t, pending)
+
+	// reads stay local until the replica pulls the writes, unless they are routed to the primary
+	_, err = conn.QueryContext(ctx, "SELECT x FROM t")
+	require.NotNil(t, err)
+	var count int
+	require.Nil(t, conn.QueryRowContext(WithRoute(ctx, RoutePrimary), "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 2, count)
+	_, err = db.Pull(ctx)
+	require.Nil(t, err)
+	require.Nil(t, conn.QueryRowContext(ctx, "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 2, count)
+
+	// transaction runs on the primary as a whole
+	tx, err := conn.BeginTx(ctx, nil)
+	require.Nil(t, err)
+	_, err = tx.ExecContext(ctx, "INSERT INTO t VALUES (3)")
+	require.Nil(t, err)
+	require.Nil(t, tx.QueryRowContext(ctx, "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 3, count)
+	require.Nil(t, tx.Commit())
+	rows, err = server.DbSql("SELECT count(*) FROM t")
+	require.Nil(t, err)
+	require.Equal(t, [][]any{{"3"}}, rows)
+
+	// read-only transaction reads the local replica
+	tx, err = conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	require.Nil(t, err)
+	require.Nil(t, tx.QueryRowContext(ctx, "SELECT count(*) FROM t").Scan(&count))
+	require.Equal(t, 2, count)
+	require.Nil(t, tx.Rollback())
+
+	_, err = conn.ExecContext(ctx, "INSERT INTO missing VALUES (1)")
+	require.ErrorIs(t, err, ErrTursoPrimary)
+
+	// a transaction left open on the primary is rolled back before the pool reuses the connection
+	conn.SetMaxOpenConns(1)
+	_, err = conn.ExecContext(ctx, "BEGIN")
+	require.Nil(t, err)
+	_, err = conn.ExecContext(ctx, "INSERT INTO t VALUES (4)")
+	require.Nil(t, err)
+	_, err = conn.ExecContext(ctx, "BEGIN")
+	require.Nil(t, err)
+	_, err = conn.ExecContext(ctx, "INSERT INTO t VALUES (5)")
+	require.Nil(t, err)
+	rows, err = server.DbSql("SELECT count(*) FROM t")
+	require.Nil(t, err)
+	require.Equal(t, [][]any{{"5"}}, rows)
+
+	// Interrupt cancels the request of the statement which runs on the primary
+	transport := &stallingTransport{RoundTripper: db.client.Transport, stalled: make(chan struct{})}
+	db.client.Transport = transport
+	raw, err := conn.Conn(ctx)
+	require.Nil(t, err)
+	defer raw.Close()
+	var driverConn *tursoDbConnection
+	require.Nil(t, raw.Raw(func(c any) error {
+		driverConn = c.(*tursoDbConnection)
+		return nil
+	}))
+	transport.stall.Store(true)
+	go func() {
+		<-transport.stalled
+		driverConn.Interrupt()
+	}()
+	_, err = raw.ExecContext(ctx, "INSERT INTO t VALUES (6)")
+	require.ErrorIs(t, err, ErrTursoInterrupt)
+	transport.stall.Store(false)
+	_, err = raw.ExecContext(ctx, "INSERT INTO t VALUES (6)")
+	require.Nil(t, err)
+}
+
+// stallingTransport holds requests while stall is set until they are cancelled, reporting every held request on stalled
+type stallingTransport struct {
+	http.RoundTripper
+	stall   atomic.Bool
+	stalled chan struct{}
+}
+
+func (t *stallingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.stall.Load() {
+		return t.RoundTripper.RoundTrip(req)
+	}
+	t.stalled <- struct{}{}
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestSyncCurrentFrame(t *testing.T) {
+	server, err := NewTursoServer()
+	require.Nil(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	_, err = server.DbSql("CREATE TABLE t(x)")
+	require.Nil(t, err)
+	db, err := OpenEmbeddedReplica(path.Join(t.TempDir(), "local.db"), server.DbUrl, "", WithReplicaClientName("turso-sync-go"))
+	require.Nil(t, err)
+	conn, err := db.Connect(context.Background())
+	require.Nil(t, err)
+	defer conn.Close()
+	c, err := conn.Conn(context.Background())
+	require.Nil(t, err)
+	defer c.Close()
+
+	type position struct{ generation, frame uint64 }
+	currentFrame := func() (position, error) {
+		var pos position
+		err := c.Raw(func(driverConn any) error {
+			var err error
+			pos.generation, pos.frame, err = driverConn.(*tursoDbConnection).CurrentFrame()
+			return err
+		})
+		return pos, err
+	}
+	after := func(a, b position) bool {
+		return a.generation > b.generation || a.generation == b.generation && a.frame > b.frame
+	}
+	bootstrapped, err := currentFrame()
+	require.Nil(t, err)
+	again, err := currentFrame()
+	require.Nil(t, err)
+	require.Equal(t, bootstrapped, again)
+
+	stats, err := db.Stats(context.Background())
+	require.Nil(t, err)
+	require.NotEmpty(t, stats.SyncedRevision.Revision, "the remote revision is reported by stats")
+
+	// the position follows local writes and pulled changes
+	_, err = c.ExecContext(context.Background(), "INSERT INTO t VALUES (1)")
+	require.Nil(t, err)
+	afterWrite, err := currentFrame()
+	require.Nil(t, err)
+	require.True(t, after(afterWrite, bootstrapped), "%v must be after %v", afterWrite, bootstrapped)
+
+	// writes of other connections of the replica are visible as well
+	_, err = conn.ExecContext(context.Background(), "INSERT INTO t VALUES (3)")
+	require.Nil(t, err)
+	afterOtherWrite, err := currentFrame()
+	require.Nil(t, err)
+	require.True(t, after(afterOtherWrite, afterWrite), "%v must be after %v", afterOtherWrite, afterWrite)
+
+	_, err = server.DbSql("INSERT INTO t VALUES (2)")
+	require.Nil(t, err)
+	_, err = db.Pull(context.Background())
+	require.Nil(t, err)
+	afterPull, err := currentFrame()
+	require.Nil(t, err)
+	require.True(t, after(afterPull, afterOtherWrite), "%v must be after %v", afterPull, afterOtherWrite)
+
+	tx, err := c.BeginTx(context.Background(), nil)
+	require.Nil(t, err)
+	_, err = currentFrame()
+	require.ErrorContains(t, err, "inside a transaction")
+	require.Nil(t, tx.Rollback())
+
+	local, err := sql.Open("turso", ":memory:")
+	require.Nil(t, err)
+	defer local.Close()
+	lc, err := local.Conn(context.Background())
+	require.Nil(t, err)
+	defer lc.Close()
+	err = lc.Raw(func(driverConn any) error {
+		_, _, err := driverConn.(*tursoDbConnection).CurrentFrame()
+		return err
+	})
+	require.ErrorIs(t, err, ErrTursoNotReplica)
+}
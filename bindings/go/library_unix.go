@@ -0,0 +1,9 @@
+//go:build !windows
+
+package turso
+
+import "github.com/ebitengine/purego"
+
+func closeLibrary(handle uintptr) error {
+	return purego.Dlclose(handle)
+}
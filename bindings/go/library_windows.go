@@ -0,0 +1,9 @@
+//go:build windows
+
+package turso
+
+import "syscall"
+
+func closeLibrary(handle uintptr) error {
+	return syscall.FreeLibrary(syscall.Handle(handle))
+}
@@ -220,6 +220,7 @@ pub fn py_turso_sync_new(
         push_operations_threshold: sync_config.push_operations_threshold,
         pull_bytes_threshold: sync_config.pull_bytes_threshold,
         logical_mvcc_pull: sync_config.logical_mvcc_pull,
+        use_transform: false,
     };
     let database =
         TursoDatabaseSync::<Vec<u8>>::new(db_config, sync_config).map_err(turso_error_to_py_err)?;
@@ -530,6 +531,12 @@ impl PyTursoSyncIoItem {
                 )?),
                 http: None,
             }),
+            // transformation is never enabled for the python client
+            turso_sync_sdk_kit::sync_engine_io::SyncEngineIoRequest::Transform { .. } => {
+                Err(pyo3::exceptions::PyNotImplementedError::new_err(
+                    "transform IO requests are not supported",
+                ))
+            }
         }
     }
     /// set error as the final completion result of the IO queue item
@@ -386,6 +386,7 @@ impl Builder {
             push_operations_threshold: None,
             pull_bytes_threshold: None,
             logical_mvcc_pull: self.logical_mvcc_pull,
+            use_transform: false,
         };
 
         // Create sync wrapper.
@@ -748,6 +749,14 @@ impl IoWorker {
                         )
                         .await;
                     }
+                    // transformation is never enabled for the Rust client
+                    turso_sync_sdk_kit::sync_engine_io::SyncEngineIoRequest::Transform {
+                        ..
+                    } => {
+                        let completion = item.get_completion();
+                        completion.poison("transform IO requests are not supported".to_string());
+                        completion.done();
+                    }
                 }
             }
 
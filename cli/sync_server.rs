@@ -14,11 +14,12 @@ use tracing::{debug, error, info};
 
 use turso_core::{Connection, Value as CoreValue};
 use turso_sync_engine::server_proto::{
-    BatchCond, BatchResult, BatchStep, BatchStreamReq, BatchStreamResp, Col, Error,
-    ExecuteStreamReq, ExecuteStreamResp, MvccLogicalLogMetadataProto, MvccLogicalLogRangeProto,
-    PageData, PageSetRawEncodingProto, PageUpdatesEncodingReq, PipelineReqBody, PipelineRespBody,
-    PullUpdatesApplyMode, PullUpdatesReqProtoBody, PullUpdatesRespProtoBody, PullUpdatesStreamKind,
-    Row, StmtResult, StreamRequest, StreamResponse, StreamResult, Value,
+    BatchCond, BatchResult, BatchStep, BatchStreamReq, BatchStreamResp, CloseStreamResp, Col,
+    Error, ExecuteStreamReq, ExecuteStreamResp, MvccLogicalLogMetadataProto,
+    MvccLogicalLogRangeProto, PageData, PageSetRawEncodingProto, PageUpdatesEncodingReq,
+    PipelineReqBody, PipelineRespBody, PullUpdatesApplyMode, PullUpdatesReqProtoBody,
+    PullUpdatesRespProtoBody, PullUpdatesStreamKind, Row, StmtResult, StreamRequest,
+    StreamResponse, StreamResult, Value,
 };
 
 const WAL_FRAME_HEADER_SIZE: usize = 24;
@@ -200,6 +201,11 @@ impl TursoSyncServer {
             let result = match request {
                 StreamRequest::Execute(exec_req) => self.execute_statement(&conn, &exec_req),
                 StreamRequest::Batch(batch_req) => self.execute_batch(&conn, &batch_req),
+                // all streams share the connection of the server, so there is nothing to release:
+                // clients roll back their transaction explicitly before closing its stream
+                StreamRequest::Close(_) => StreamResult::Ok {
+                    response: StreamResponse::Close(CloseStreamResp {}),
+                },
                 StreamRequest::None => StreamResult::Error {
                     error: Error {
                         message: "Unknown request type".to_string(),
@@ -0,0 +1,129 @@
+use crate::sync::RwLock;
+
+/// Action checked by the authorizer, with the codes of the SQLite action constants
+/// passed to `sqlite3_set_authorizer()` callbacks.
+///
+/// The arguments of [AuthorizerCallback] for every action follow SQLite:
+/// - `CreateIndex`, `CreateTempIndex`, `DropIndex`, `DropTempIndex`: index name, table name
+/// - `CreateTable`, `CreateTempTable`, `DropTable`, `DropTempTable`, `Delete`, `Insert`, `Analyze`: table name
+/// - `CreateTrigger`, `CreateTempTrigger`, `DropTrigger`, `DropTempTrigger`: trigger name, table name
+/// - `CreateView`, `CreateTempView`, `DropView`, `DropTempView`: view name
+/// - `CreateVtable`: table name, module name
+/// - `Read`, `Update`: table name, column name
+/// - `Pragma`: pragma name, argument
+/// - `Transaction`: operation (`BEGIN`, `COMMIT` or `ROLLBACK`)
+/// - `Savepoint`: operation (`BEGIN`, `RELEASE` or `ROLLBACK`), savepoint name
+/// - `Attach`: file name; `Detach`: database name
+/// - `AlterTable`: database name, table name
+/// - `Reindex`: index name
+/// - `Function`: none, function name
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+#[repr(i32)]
+pub enum AuthorizerAction {
+    CreateIndex = 1,
+    CreateTable = 2,
+    CreateTempIndex = 3,
+    CreateTempTable = 4,
+    CreateTempTrigger = 5,
+    CreateTempView = 6,
+    CreateTrigger = 7,
+    CreateView = 8,
+    Delete = 9,
+    DropIndex = 10,
+    DropTable = 11,
+    DropTempIndex = 12,
+    DropTempTable = 13,
+    DropTempTrigger = 14,
+    DropTempView = 15,
+    DropTrigger = 16,
+    DropView = 17,
+    Insert = 18,
+    Pragma = 19,
+    Read = 20,
+    Select = 21,
+    Transaction = 22,
+    Update = 23,
+    Attach = 24,
+    Detach = 25,
+    AlterTable = 26,
+    Reindex = 27,
+    Analyze = 28,
+    CreateVtable = 29,
+    Function = 31,
+    Savepoint = 32,
+}
+
+/// Decision of the authorizer, with the codes of `SQLITE_OK`, `SQLITE_DENY` and `SQLITE_IGNORE`.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+#[repr(i32)]
+pub enum AuthorizerResult {
+    #[default]
+    Allow = 0,
+    /// fail the statement preparation with "not authorized" error
+    Deny = 1,
+    /// prepare the statement as a no-op which does nothing when executed
+    Ignore = 2,
+}
+
+/// Authorizer callback: action, two action specific arguments (see [AuthorizerAction]),
+/// database name and the name of the trigger or view responsible for the access
+pub type AuthorizerCallback = Box<
+    dyn Fn(
+            AuthorizerAction,
+            Option<&str>,
+            Option<&str>,
+            Option<&str>,
+            Option<&str>,
+        ) -> AuthorizerResult
+        + Send
+        + Sync,
+>;
+
+/// Connection-scoped authorizer which models SQLite's `sqlite3_set_authorizer()`.
+///
+/// The callback is consulted when a statement is prepared (including automatic re-preparation
+/// after schema changes), never while it executes, and must not use the connection.
+/// Accesses made through views and triggers of the main database are checked as well, with the
+/// name of the inner-most view or trigger as the last argument.
+/// Statements generated by the engine itself (e.g. schema parsing) are not checked.
+#[derive(Default)]
+pub(crate) struct Authorizer {
+    callback: RwLock<Option<AuthorizerCallback>>,
+}
+
+impl std::fmt::Debug for Authorizer {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("Authorizer")
+            .field("enabled", &self.callback.read().is_some())
+            .finish()
+    }
+}
+
+impl Authorizer {
+    pub(crate) fn new() -> Self {
+        Self::default()
+    }
+
+    /// Install or clear (with `None`) the authorizer callback.
+    pub(crate) fn set(&self, callback: Option<AuthorizerCallback>) {
+        *self.callback.write() = callback;
+    }
+
+    pub(crate) fn enabled(&self) -> bool {
+        self.callback.read().is_some()
+    }
+
+    pub(crate) fn check(
+        &self,
+        action: AuthorizerAction,
+        arg1: Option<&str>,
+        arg2: Option<&str>,
+        database: Option<&str>,
+        responsible: Option<&str>,
+    ) -> AuthorizerResult {
+        match self.callback.read().as_ref() {
+            Some(callback) => callback(action, arg1, arg2, database, responsible),
+            None => AuthorizerResult::Allow,
+        }
+    }
+}
@@ -17,7 +17,10 @@ use crate::util::{OpenMode, OpenOptions};
 #[cfg(all(feature = "fs", feature = "conn_raw_api"))]
 use crate::Page;
 use crate::{
-    ast, function,
+    ast,
+    authorizer::{Authorizer, AuthorizerCallback},
+    function,
+    hooks::{ConnectionHooks, TransactionHookCallback, UpdateHookCallback},
     io::{MemoryIO, IO},
     progress::{ProgressHandler, ProgressHandlerCallback},
     translate,
@@ -393,6 +396,9 @@ pub struct Connection {
     /// Attached databases
     pub(super) attached_databases: RwLock<DatabaseCatalog>,
     pub(super) query_only: AtomicBool,
+    /// Set for connections opened read-only on a database which may be shared (within the process)
+    /// with read-write connections: writes to the main database fail with [LimboError::ReadOnly]
+    pub(super) read_only: AtomicBool,
     pub(super) vdbe_trace: AtomicBool,
     /// If enabled, the UPDATE/DELETE statements must have a WHERE clause
     pub(super) dml_require_where: AtomicBool,
@@ -446,6 +452,8 @@ pub struct Connection {
     /// Only prevents the same trigger from firing again, allowing different triggers on the same table to fire
     pub(super) executing_triggers: RwLock<Vec<Arc<Trigger>>>,
     pub(crate) encryption_key: RwLock<Option<EncryptionKey>>,
+    /// New encryption key of the main database picked up by the in-place `VACUUM` run by [Connection::rekey]
+    pub(crate) pending_rekey: RwLock<Option<EncryptionKey>>,
     pub(super) encryption_cipher_mode: AtomicCipherMode,
     pub(super) sync_mode: AtomicSyncMode,
     pub(super) temp_store: AtomicTempStore,
@@ -455,6 +463,10 @@ pub struct Connection {
     pub(super) busy_handler: RwLock<BusyHandler>,
     /// Step-based progress callback for SQLite-compatible cancellation hooks.
     pub(super) progress_handler: ProgressHandler,
+    /// Change notification hooks (update, commit and rollback).
+    pub(super) hooks: ConnectionHooks,
+    /// Authorizer consulted when statements are prepared.
+    pub(super) authorizer: Authorizer,
     /// Maximum execution time for a single statement on this connection.
     /// `Duration::ZERO` means disabled.
     pub(super) query_timeout_ms: AtomicU64,
@@ -2126,6 +2138,33 @@ impl Connection {
         self.pager.load().wal_state()
     }
 
+    /// Start read transaction on the main database which pins the WAL snapshot returned by the method:
+    /// until [Self::wal_read_end] is called, pages read with [Self::try_wal_watermark_read_page] without watermark
+    /// belong to this snapshot and checkpoints can't backfill frames after it
+    #[cfg(all(feature = "fs", feature = "conn_raw_api"))]
+    pub fn wal_read_begin(&self) -> Result<WalState> {
+        if !self.auto_commit.load(Ordering::SeqCst) {
+            return Err(LimboError::TxError(
+                "cannot start WAL read session within transaction".to_string(),
+            ));
+        }
+        let pager = self.pager.load();
+        pager.begin_read_tx()?;
+        pager.wal_state().inspect_err(|_| pager.end_read_tx())
+    }
+
+    /// Finish read transaction taken in the [Self::wal_read_begin] method
+    #[cfg(all(feature = "fs", feature = "conn_raw_api"))]
+    pub fn wal_read_end(&self) {
+        self.pager.load().end_read_tx();
+    }
+
+    /// This connection's frozen WAL position `(checkpoint_seq, max_frame)`: the read mark of its
+    /// last read transaction or the position after its last commit. See `Pager::wal_pos`.
+    pub fn wal_pos(&self) -> (u32, u64) {
+        self.pager.load().wal_pos()
+    }
+
     #[cfg(all(feature = "fs", feature = "conn_raw_api"))]
     pub fn wal_get_frame(&self, frame_no: u64, frame: &mut [u8]) -> Result<WalFrameInfo> {
         use crate::storage::sqlite3_ondisk::parse_wal_frame_header;
@@ -2616,7 +2655,7 @@ impl Connection {
     /// Check if a specific attached database is read only or not, by its index
     pub fn is_readonly(&self, index: usize) -> bool {
         match index {
-            crate::MAIN_DB_ID => self.db.is_readonly(),
+            crate::MAIN_DB_ID => self.read_only.load(Ordering::SeqCst) || self.db.is_readonly(),
             crate::TEMP_DB_ID => self
                 .temp
                 .database
@@ -3374,7 +3413,10 @@ impl Connection {
                     } else {
                         self.db.io.clone()
                     };
-                    let main_db_flags = self.db.open_flags;
+                    let mut main_db_flags = self.db.open_flags;
+                    if self.read_only.load(Ordering::SeqCst) {
+                        main_db_flags |= OpenFlags::ReadOnly;
+                    }
                     let (db, encryption_opts) = Self::from_uri_attached(
                         path,
                         db_opts,
@@ -3767,6 +3809,12 @@ impl Connection {
         self.bump_prepare_context_generation();
     }
 
+    /// Make the connection read-only (as if its database was opened with [OpenFlags::ReadOnly]).
+    /// Databases attached later inherit the mode.
+    pub fn set_read_only(&self, value: bool) {
+        self.read_only.store(value, Ordering::SeqCst);
+    }
+
     pub fn set_vdbe_trace(&self, value: bool) {
         self.vdbe_trace.store(value, Ordering::SeqCst);
     }
@@ -4491,6 +4539,30 @@ impl Connection {
         self.set_encryption_context()
     }
 
+    /// Re-encrypts the main database with a new key in place.
+    ///
+    /// The rekey runs as an in-place `VACUUM` (so the experimental vacuum feature must be enabled):
+    /// the compacted image is encrypted with the new key and committed as a single WAL transaction,
+    /// so a failure or a crash leaves the database readable with either the old or the new key.
+    /// Other connections to the database must reopen with the new key.
+    pub fn rekey(self: &Arc<Connection>, key: EncryptionKey) -> Result<()> {
+        if !self.pager.load().is_encryption_ctx_set() {
+            return Err(LimboError::InvalidArgument(
+                "cannot rekey a database which is not encrypted".to_string(),
+            ));
+        }
+        if !self.experimental_vacuum_enabled() {
+            return Err(LimboError::InvalidArgument(
+                "rekey requires the experimental vacuum feature".to_string(),
+            ));
+        }
+        *self.pending_rekey.write() = Some(key);
+        let result = self.execute("VACUUM");
+        // VACUUM takes the key once it starts: clear it if VACUUM failed before that
+        self.pending_rekey.write().take();
+        result
+    }
+
     pub fn set_reserved_bytes(&self, reserved_bytes: u8) -> Result<()> {
         let pager = self.pager.load();
         pager.set_reserved_space_bytes(reserved_bytes);
@@ -4597,6 +4669,38 @@ impl Connection {
         self.progress_handler.should_interrupt(vm_steps)
     }
 
+    /// Sets the hook invoked after a row of a rowid table is inserted, updated or deleted.
+    /// Passing `None` clears the hook.
+    pub fn set_update_hook(&self, hook: Option<UpdateHookCallback>) {
+        self.hooks.set_update(hook);
+    }
+
+    /// Sets the hook invoked after a write transaction is committed.
+    /// Passing `None` clears the hook.
+    pub fn set_commit_hook(&self, hook: Option<TransactionHookCallback>) {
+        self.hooks.set_commit(hook);
+    }
+
+    /// Sets the hook invoked after a write transaction is rolled back.
+    /// Passing `None` clears the hook.
+    pub fn set_rollback_hook(&self, hook: Option<TransactionHookCallback>) {
+        self.hooks.set_rollback(hook);
+    }
+
+    pub(crate) fn hooks(&self) -> &ConnectionHooks {
+        &self.hooks
+    }
+
+    /// Sets the authorizer consulted for every access a statement makes when it is prepared.
+    /// Passing `None` clears the authorizer.
+    pub fn set_authorizer(&self, callback: Option<AuthorizerCallback>) {
+        self.authorizer.set(callback);
+    }
+
+    pub(crate) fn authorizer(&self) -> &Authorizer {
+        &self.authorizer
+    }
+
     /// Request interruption of currently running root statements on this connection.
     /// If no root statement is active, the request is ignored to match SQLite semantics.
     pub fn interrupt(&self) {
@@ -4845,6 +4949,7 @@ impl Connection {
         self.rollback_attached_wal_txns();
         self.set_tx_state(TransactionState::None);
         self.clear_tx_poison();
+        self.hooks.fire_rollback();
     }
 
     /// Roll back transaction state for helpers that start a manual `BEGIN`
@@ -4879,6 +4984,7 @@ impl Connection {
         self.set_cdc_transaction_id(-1);
         self.clear_named_savepoints();
         self.clear_deferred_foreign_key_violations();
+        self.hooks.fire_rollback();
     }
 
     /// Iterate over all attached MVCC transactions, calling `f(db_id, tx_id)` for each.
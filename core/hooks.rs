@@ -0,0 +1,159 @@
+use crate::sync::{atomic::AtomicBool, RwLock};
+use std::sync::atomic::Ordering;
+
+/// Kind of the row change reported to the update hook.
+/// Values match the SQLite action codes passed to `sqlite3_update_hook()` callbacks.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+#[repr(i32)]
+pub enum UpdateHookOp {
+    Delete = 9,
+    Insert = 18,
+    Update = 23,
+}
+
+pub type UpdateHookCallback = Box<dyn Fn(UpdateHookOp, &str, &str, i64) + Send + Sync>;
+pub type TransactionHookCallback = Box<dyn Fn() + Send + Sync>;
+
+/// Connection-scoped change notification hooks.
+///
+/// This models SQLite's `sqlite3_update_hook()`, `sqlite3_commit_hook()` and
+/// `sqlite3_rollback_hook()` for notification purposes:
+/// - the update hook runs after a row of a rowid table is inserted, updated or deleted;
+///   changes of internal tables (e.g. sqlite_schema, sqlite_sequence) are not reported
+/// - the commit hook runs after a write transaction is committed (it can't turn the commit into a rollback)
+/// - the rollback hook runs after a write transaction is rolled back
+///
+/// Callbacks run synchronously on the thread which executes the statement and
+/// must not use the connection.
+#[derive(Default)]
+pub(crate) struct ConnectionHooks {
+    update: RwLock<Option<UpdateHookCallback>>,
+    commit: RwLock<Option<TransactionHookCallback>>,
+    rollback: RwLock<Option<TransactionHookCallback>>,
+    update_enabled: AtomicBool,
+    /// set once the current transaction opens a write cursor, so commit and rollback hooks
+    /// fire only for write transactions and at most once per transaction
+    tx_wrote: AtomicBool,
+}
+
+impl std::fmt::Debug for ConnectionHooks {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("ConnectionHooks")
+            .field("update", &self.update_enabled())
+            .field("commit", &self.commit.read().is_some())
+            .field("rollback", &self.rollback.read().is_some())
+            .finish()
+    }
+}
+
+impl ConnectionHooks {
+    pub(crate) fn new() -> Self {
+        Self::default()
+    }
+
+    /// Install or clear (with `None`) the update hook.
+    pub(crate) fn set_update(&self, callback: Option<UpdateHookCallback>) {
+        let enabled = callback.is_some();
+        *self.update.write() = callback;
+        self.update_enabled.store(enabled, Ordering::SeqCst);
+    }
+
+    /// Install or clear (with `None`) the commit hook.
+    pub(crate) fn set_commit(&self, callback: Option<TransactionHookCallback>) {
+        *self.commit.write() = callback;
+    }
+
+    /// Install or clear (with `None`) the rollback hook.
+    pub(crate) fn set_rollback(&self, callback: Option<TransactionHookCallback>) {
+        *self.rollback.write() = callback;
+    }
+
+    pub(crate) fn update_enabled(&self) -> bool {
+        self.update_enabled.load(Ordering::SeqCst)
+    }
+
+    pub(crate) fn fire_update(&self, op: UpdateHookOp, database: &str, table: &str, rowid: i64) {
+        if !self.update_enabled() || crate::schema::is_system_table(table) {
+            return;
+        }
+        if let Some(callback) = self.update.read().as_ref() {
+            callback(op, database, table, rowid);
+        }
+    }
+
+    /// Marks the current transaction as a write transaction.
+    pub(crate) fn mark_write(&self) {
+        self.tx_wrote.store(true, Ordering::SeqCst);
+    }
+
+    /// Reports commit of the current transaction if it wrote anything.
+    pub(crate) fn fire_commit(&self) {
+        if !self.tx_wrote.swap(false, Ordering::SeqCst) {
+            return;
+        }
+        if let Some(callback) = self.commit.read().as_ref() {
+            callback();
+        }
+    }
+
+    /// Reports rollback of the current transaction if it wrote anything.
+    pub(crate) fn fire_rollback(&self) {
+        if !self.tx_wrote.swap(false, Ordering::SeqCst) {
+            return;
+        }
+        if let Some(callback) = self.rollback.read().as_ref() {
+            callback();
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::sync::{atomic::AtomicUsize, Arc, Mutex};
+
+    #[test]
+    fn update_hook_skips_system_tables_and_can_be_cleared() {
+        let hooks = ConnectionHooks::new();
+        let seen = Arc::new(Mutex::new(Vec::new()));
+        let sink = seen.clone();
+        hooks.set_update(Some(Box::new(move |op, db, table, rowid| {
+            sink.lock()
+                .unwrap()
+                .push((op, db.to_string(), table.to_string(), rowid));
+        })));
+        hooks.fire_update(UpdateHookOp::Insert, "main", "t", 1);
+        hooks.fire_update(UpdateHookOp::Insert, "main", "sqlite_sequence", 1);
+        hooks.set_update(None);
+        hooks.fire_update(UpdateHookOp::Delete, "main", "t", 1);
+        assert_eq!(
+            *seen.lock().unwrap(),
+            vec![(UpdateHookOp::Insert, "main".to_string(), "t".to_string(), 1)]
+        );
+    }
+
+    #[test]
+    fn transaction_hooks_fire_once_per_write_transaction() {
+        let hooks = ConnectionHooks::new();
+        let commits = Arc::new(AtomicUsize::new(0));
+        let rollbacks = Arc::new(AtomicUsize::new(0));
+        let (c, r) = (commits.clone(), rollbacks.clone());
+        hooks.set_commit(Some(Box::new(move || {
+            c.fetch_add(1, Ordering::SeqCst);
+        })));
+        hooks.set_rollback(Some(Box::new(move || {
+            r.fetch_add(1, Ordering::SeqCst);
+        })));
+
+        // read transaction
+        hooks.fire_commit();
+        hooks.mark_write();
+        hooks.fire_commit();
+        hooks.fire_rollback();
+        hooks.mark_write();
+        hooks.fire_rollback();
+        hooks.fire_commit();
+        assert_eq!(commits.load(Ordering::SeqCst), 1);
+        assert_eq!(rollbacks.load(Ordering::SeqCst), 1);
+    }
+}
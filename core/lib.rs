@@ -47,6 +47,7 @@ pub(crate) mod sync;
 pub(crate) mod thread;
 
 mod assert;
+mod authorizer;
 mod connection;
 pub mod dialect;
 mod error;
@@ -55,6 +56,7 @@ mod fast_lock;
 mod function;
 #[cfg(not(any(feature = "fuzz", feature = "bench")))]
 mod functions;
+mod hooks;
 mod incremental;
 mod incremental_blob;
 pub use incremental_blob::Blob;
@@ -91,7 +93,9 @@ pub use function::Func;
 pub use function::MathFunc;
 
 use crate::{
+    authorizer::Authorizer,
     busy::{BusyHandler, BusyHandlerCallback},
+    hooks::ConnectionHooks,
     incremental::view::AllViewsTxState,
     index_method::IndexMethod,
     progress::ProgressHandler,
@@ -136,11 +140,13 @@ use tracing::{instrument, Level};
 use turso_macros::AtomicEnum;
 use turso_parser::{ast, ast::Cmd};
 
+pub use authorizer::{AuthorizerAction, AuthorizerCallback, AuthorizerResult};
 pub use connection::{resolve_ext_path, Connection, Row, StepResult, SymbolTable};
 pub(crate) use connection::{AtomicTransactionState, TransactionState};
 pub use dialect::{Dialect, SqliteDialect};
 pub use error::{io_error, CompletionError, LimboError};
 pub use function::ContextCollationFunction;
+pub use hooks::{TransactionHookCallback, UpdateHookCallback, UpdateHookOp};
 #[cfg(feature = "io_memory_yield")]
 pub use io::MemoryYieldIO;
 #[cfg(all(feature = "fs", target_family = "unix", not(miri)))]
@@ -2309,6 +2315,7 @@ impl Database {
             temp: crate::connection::TempDbContext::new(),
             attached_databases: RwLock::new(DatabaseCatalog::new()),
             query_only: AtomicBool::new(false),
+            read_only: AtomicBool::new(false),
             vdbe_trace: AtomicBool::new(false),
             dml_require_where: AtomicBool::new(false),
             dqs_dml: AtomicBool::new(true),
@@ -2327,12 +2334,15 @@ impl Database {
             compiling_triggers: RwLock::new(Vec::new()),
             executing_triggers: RwLock::new(Vec::new()),
             encryption_key: RwLock::new(encryption_key),
+            pending_rekey: RwLock::new(None),
             encryption_cipher_mode: AtomicCipherMode::new(encryption_cipher),
             sync_mode: AtomicSyncMode::new(SyncMode::Full),
             temp_store: AtomicTempStore::new(TempStore::Default),
             data_sync_retry: AtomicBool::new(false),
             busy_handler: RwLock::new(BusyHandler::None),
             progress_handler: ProgressHandler::new(),
+            hooks: ConnectionHooks::new(),
+            authorizer: Authorizer::new(),
             query_timeout_ms: AtomicU64::new(0),
             interrupt_requested: AtomicBool::new(false),
             is_mvcc_bootstrap_connection: AtomicBool::new(is_mvcc_bootstrap_connection),
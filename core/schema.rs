@@ -5415,6 +5415,21 @@ impl Column {
         (self.raw & ARRAY_DIM_MASK) >> ARRAY_DIM_SHIFT
     }
 
+    /// Number of vector dimensions N declared by a vector column type
+    /// (`F32_BLOB(N)`, `F64_BLOB(N)`, `F8_BLOB(N)` or `F1BIT_BLOB(N)`), None for other columns
+    pub fn vector_dimensions(&self) -> Option<usize> {
+        let is_vector = ["F32_BLOB", "F64_BLOB", "F8_BLOB", "F1BIT_BLOB"]
+            .iter()
+            .any(|ty| self.ty_str.eq_ignore_ascii_case(ty));
+        if !is_vector || self.ty_params.len() != 1 {
+            return None;
+        }
+        match self.ty_params[0].as_ref() {
+            Expr::Literal(Literal::Numeric(dims)) => dims.parse().ok(),
+            _ => None,
+        }
+    }
+
     #[inline]
     pub fn set_array_dimensions(&mut self, dims: u32) {
         assert!(dims <= 7, "array dimensions must be <= 7");
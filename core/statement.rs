@@ -391,12 +391,24 @@ impl Statement {
         &self.pager
     }
 
+    /// SQLite extended result code of the Halt which failed the last execution
+    /// (e.g. SQLITE_CONSTRAINT_UNIQUE), 0 if the statement wasn't halted with an error.
+    pub fn halt_error_code(&self) -> usize {
+        self.state.halt_error_code
+    }
+
     pub fn n_change(&self) -> i64 {
         self.state
             .n_change
             .load(crate::sync::atomic::Ordering::SeqCst)
     }
 
+    /// Whether the last execution inserted a row into a rowid table and updated the last insert
+    /// rowid of the connection; rows inserted by triggers are not counted.
+    pub fn inserted_rowid(&self) -> bool {
+        self.state.inserted_rowid
+    }
+
     pub fn set_n_change(&self, n: i64) {
         self.state
             .n_change
@@ -1065,6 +1077,34 @@ impl Statement {
         }
     }
 
+    /// Returns whether a result column can hold NULL.
+    ///
+    /// Like `sqlite3_table_column_metadata()`, this is only known when the Nth
+    /// column of the result set is a table column: `Some(false)` for NOT NULL
+    /// columns and rowid aliases, `Some(true)` for other table columns. For
+    /// expressions, subqueries and EXPLAIN output `None` is returned.
+    pub fn get_column_nullable(&self, idx: usize) -> Option<bool> {
+        if self.query_mode != QueryMode::Normal {
+            return None;
+        }
+        let column = self.program.result_columns.get(idx)?;
+        match &column.expr {
+            turso_parser::ast::Expr::Column {
+                table,
+                column: column_idx,
+                ..
+            } => {
+                let (_, table_ref) = self
+                    .program
+                    .table_references
+                    .find_table_by_internal_id(*table)?;
+                let table_column = table_ref.get_column_at(*column_idx)?;
+                Some(!(table_column.notnull() || table_column.is_rowid_alias()))
+            }
+            _ => None,
+        }
+    }
+
     /// Returns rich type information for a result column.
     ///
     /// This is Turso's single entry point for "what is the type of this
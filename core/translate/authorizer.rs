@@ -0,0 +1,769 @@
+//! Translate-time authorization of statements, see [crate::authorizer::Authorizer].
+
+use crate::authorizer::{AuthorizerAction, AuthorizerResult};
+use crate::schema::Schema;
+use crate::translate::expr::{sanitize_string, walk_expr, WalkControl};
+use crate::{Connection, LimboError, Result};
+use turso_parser::ast;
+
+/// Single access the statement makes: action, its arguments, the database name and
+/// the inner-most view or trigger which makes it
+struct AuthRequest {
+    action: AuthorizerAction,
+    arg1: Option<String>,
+    arg2: Option<String>,
+    database: Option<String>,
+    responsible: Option<String>,
+}
+
+impl AuthRequest {
+    /// error for the denied access naming the action and the object, e.g. `not authorized: Read "secret.v"`
+    fn denied(&self) -> LimboError {
+        let arg1 = self.arg1.as_deref().filter(|arg| !arg.is_empty());
+        let arg2 = self.arg2.as_deref().filter(|arg| !arg.is_empty());
+        let object = match (self.action, arg1, arg2) {
+            (AuthorizerAction::Read | AuthorizerAction::Update, Some(table), Some(column)) => {
+                format!("{table}.{column}")
+            }
+            (_, Some(arg1), Some(arg2)) => format!("{arg1} {arg2}"),
+            (_, Some(arg), None) | (_, None, Some(arg)) => arg.to_string(),
+            (_, None, None) => String::new(),
+        };
+        let mut message = format!("not authorized: {:?}", self.action);
+        if !object.is_empty() {
+            message.push_str(&format!(" \"{object}\""));
+        }
+        if let Some(responsible) = &self.responsible {
+            message.push_str(&format!(" (through \"{responsible}\")"));
+        }
+        LimboError::ParseError(message)
+    }
+}
+
+/// Checks every access made by `stmt` with the authorizer of the connection, including
+/// the accesses made through views and triggers of the main database in `schema`.
+///
+/// Returns `Ok(true)` if the statement must be prepared as a no-op and fails with "not authorized"
+/// error naming the action and the object if any access is denied. Ignoring a `Read` access would
+/// require to substitute NULL for the column, and ignoring an access of a view or a trigger would
+/// require to skip a part of the statement, neither of which is supported, so they deny the
+/// statement as well.
+pub(crate) fn authorize_stmt(
+    stmt: &ast::Stmt,
+    schema: &Schema,
+    connection: &Connection,
+) -> Result<bool> {
+    let authorizer = connection.authorizer();
+    if !authorizer.enabled() {
+        return Ok(false);
+    }
+    let mut collector = AuthCollector::new(Some(schema));
+    collector.stmt(stmt)?;
+    let mut ignore = false;
+    for request in collector.requests {
+        let result = authorizer.check(
+            request.action,
+            request.arg1.as_deref(),
+            request.arg2.as_deref(),
+            request.database.as_deref(),
+            request.responsible.as_deref(),
+        );
+        match result {
+            AuthorizerResult::Allow => {}
+            AuthorizerResult::Ignore
+                if request.action != AuthorizerAction::Read && request.responsible.is_none() =>
+            {
+                ignore = true
+            }
+            AuthorizerResult::Ignore | AuthorizerResult::Deny => return Err(request.denied()),
+        }
+    }
+    Ok(ignore)
+}
+
+struct AuthCollector<'a> {
+    /// schema of the main database used to expand views and triggers (none in tests)
+    schema: Option<&'a Schema>,
+    requests: Vec<AuthRequest>,
+    /// names of common table expressions, which are not reported as table reads
+    ctes: Vec<String>,
+    /// views and triggers being expanded, inner-most last
+    expanding: Vec<String>,
+}
+
+fn database_name(name: &ast::QualifiedName) -> String {
+    name.db_name
+        .as_ref()
+        .map_or_else(|| "main".to_string(), |db| db.as_str().to_string())
+}
+
+/// text of the expression used as a name or a value (e.g. ATTACH file name or PRAGMA argument)
+fn expr_text(expr: &ast::Expr) -> String {
+    match expr {
+        ast::Expr::Literal(ast::Literal::String(s)) if s.len() >= 2 => sanitize_string(s),
+        ast::Expr::Id(name) | ast::Expr::Name(name) => name.as_str().to_string(),
+        expr => expr.to_string(),
+    }
+}
+
+impl<'a> AuthCollector<'a> {
+    fn new(schema: Option<&'a Schema>) -> Self {
+        Self {
+            schema,
+            requests: Vec::new(),
+            ctes: Vec::new(),
+            expanding: Vec::new(),
+        }
+    }
+
+    fn push(
+        &mut self,
+        action: AuthorizerAction,
+        arg1: Option<&str>,
+        arg2: Option<&str>,
+        database: Option<String>,
+    ) {
+        self.requests.push(AuthRequest {
+            action,
+            arg1: arg1.map(str::to_string),
+            arg2: arg2.map(str::to_string),
+            database,
+            responsible: self.expanding.last().cloned(),
+        });
+    }
+
+    /// runs `f` with accesses attributed to the view or trigger `name`; does nothing if `name`
+    /// is already being expanded (e.g. a trigger which fires itself)
+    fn expand(&mut self, name: &str, f: impl FnOnce(&mut Self) -> Result<()>) -> Result<()> {
+        if self
+            .expanding
+            .iter()
+            .any(|expanding| expanding.eq_ignore_ascii_case(name))
+        {
+            return Ok(());
+        }
+        // CTE names of the statement don't shadow tables used by the view or trigger
+        let ctes = std::mem::take(&mut self.ctes);
+        self.expanding.push(name.to_string());
+        let result = f(self);
+        self.expanding.pop();
+        self.ctes = ctes;
+        result
+    }
+
+    /// reports accesses made by the triggers of the main database table which fire for `fires` events
+    fn triggers(
+        &mut self,
+        table: &ast::QualifiedName,
+        fires: impl Fn(&ast::TriggerEvent) -> bool,
+    ) -> Result<()> {
+        let Some(schema) = self.schema else {
+            return Ok(());
+        };
+        if !database_name(table).eq_ignore_ascii_case("main") {
+            return Ok(());
+        }
+        for trigger in schema.get_triggers_for_table(table.name.as_str()) {
+            if !fires(&trigger.event) {
+                continue;
+            }
+            self.expand(&trigger.name, |collector| {
+                if let Some(expr) = &trigger.when_clause {
+                    collector.expr(expr)?;
+                }
+                for command in &trigger.commands {
+                    collector.trigger_cmd(command)?;
+                }
+                Ok(())
+            })?;
+        }
+        Ok(())
+    }
+
+    fn trigger_cmd(&mut self, command: &ast::TriggerCmd) -> Result<()> {
+        use AuthorizerAction as A;
+        let qualified = |name: &ast::Name| ast::QualifiedName::single(name.clone());
+        match command {
+            ast::TriggerCmd::Update {
+                tbl_name,
+                sets,
+                from,
+                where_clause,
+                ..
+            } => {
+                let table = qualified(tbl_name);
+                self.sets(&table, "main", sets)?;
+                if let Some(from) = from {
+                    self.from(from)?;
+                }
+                if let Some(expr) = where_clause {
+                    self.expr(expr)?;
+                }
+                self.update_triggers(&table, sets)?;
+            }
+            ast::TriggerCmd::Insert {
+                tbl_name,
+                select,
+                upsert,
+                returning,
+                ..
+            } => {
+                let table = qualified(tbl_name);
+                self.insert(&table, "main", select, upsert.as_deref())?;
+                self.result_columns(returning)?;
+            }
+            ast::TriggerCmd::Delete {
+                tbl_name,
+                where_clause,
+            } => {
+                let table = qualified(tbl_name);
+                self.push(
+                    A::Delete,
+                    Some(tbl_name.as_str()),
+                    None,
+                    Some("main".into()),
+                );
+                if let Some(expr) = where_clause {
+                    self.expr(expr)?;
+                }
+                self.triggers(&table, |event| matches!(event, ast::TriggerEvent::Delete))?;
+            }
+            ast::TriggerCmd::Select(select) => self.select(select)?,
+        }
+        Ok(())
+    }
+
+    fn update_triggers(&mut self, table: &ast::QualifiedName, sets: &[ast::Set]) -> Result<()> {
+        self.triggers(table, |event| match event {
+            ast::TriggerEvent::Update => true,
+            ast::TriggerEvent::UpdateOf(columns) => columns.iter().any(|column| {
+                sets.iter()
+                    .flat_map(|set| &set.col_names)
+                    .any(|set| set.as_str().eq_ignore_ascii_case(column.as_str()))
+            }),
+            _ => false,
+        })
+    }
+
+    /// reports insert into the table by `select` with its upsert clauses and the fired triggers
+    fn insert(
+        &mut self,
+        table: &ast::QualifiedName,
+        database: &str,
+        select: &ast::Select,
+        upsert: Option<&ast::Upsert>,
+    ) -> Result<()> {
+        self.push(
+            AuthorizerAction::Insert,
+            Some(table.name.as_str()),
+            None,
+            Some(database.to_string()),
+        );
+        self.select(select)?;
+        let mut upsert = upsert;
+        while let Some(clause) = upsert {
+            if let ast::UpsertDo::Set { sets, where_clause } = &clause.do_clause {
+                self.sets(table, database, sets)?;
+                if let Some(expr) = where_clause {
+                    self.expr(expr)?;
+                }
+                self.update_triggers(table, sets)?;
+            }
+            upsert = clause.next.as_deref();
+        }
+        self.triggers(table, |event| matches!(event, ast::TriggerEvent::Insert))
+    }
+
+    fn stmt(&mut self, stmt: &ast::Stmt) -> Result<()> {
+        use AuthorizerAction as A;
+        match stmt {
+            ast::Stmt::AlterTable(alter) => {
+                let database = database_name(&alter.name);
+                self.push(
+                    A::AlterTable,
+                    Some(&database),
+                    Some(alter.name.name.as_str()),
+                    Some(database.clone()),
+                );
+            }
+            ast::Stmt::Analyze { name } => {
+                let table = name.as_ref().map(|name| name.name.as_str());
+                self.push(A::Analyze, table, None, name.as_ref().map(database_name));
+            }
+            ast::Stmt::Attach { expr, .. } => {
+                self.push(A::Attach, Some(&expr_text(expr)), None, None);
+            }
+            ast::Stmt::Detach { name } => {
+                self.push(A::Detach, Some(&expr_text(name)), None, None);
+            }
+            ast::Stmt::Begin { .. } => self.push(A::Transaction, Some("BEGIN"), None, None),
+            ast::Stmt::Commit { .. } => self.push(A::Transaction, Some("COMMIT"), None, None),
+            ast::Stmt::Rollback {
+                savepoint_name: Some(savepoint),
+                ..
+            } => self.push(
+                A::Savepoint,
+                Some("ROLLBACK"),
+                Some(savepoint.as_str()),
+                None,
+            ),
+            ast::Stmt::Rollback { .. } => self.push(A::Transaction, Some("ROLLBACK"), None, None),
+            ast::Stmt::Savepoint { name } => {
+                self.push(A::Savepoint, Some("BEGIN"), Some(name.as_str()), None)
+            }
+            ast::Stmt::Release { name } => {
+                self.push(A::Savepoint, Some("RELEASE"), Some(name.as_str()), None)
+            }
+            ast::Stmt::CreateIndex {
+                idx_name,
+                tbl_name,
+                where_clause,
+                ..
+            } => {
+                let database = database_name(idx_name);
+                let action = if database.eq_ignore_ascii_case("temp") {
+                    A::CreateTempIndex
+                } else {
+                    A::CreateIndex
+                };
+                self.push(
+                    action,
+                    Some(idx_name.name.as_str()),
+                    Some(tbl_name.as_str()),
+                    Some(database),
+                );
+                if let Some(expr) = where_clause {
+                    self.expr(expr)?;
+                }
+            }
+            ast::Stmt::CreateTable {
+                temporary,
+                tbl_name,
+                body,
+                ..
+            } => {
+                let database = database_name(tbl_name);
+                let action = if *temporary || database.eq_ignore_ascii_case("temp") {
+                    A::CreateTempTable
+                } else {
+                    A::CreateTable
+                };
+                self.push(action, Some(tbl_name.name.as_str()), None, Some(database));
+                if let ast::CreateTableBody::AsSelect(select) = body {
+                    self.select(select)?;
+                }
+            }
+            ast::Stmt::CreateTrigger {
+                temporary,
+                trigger_name,
+                tbl_name,
+                ..
+            } => {
+                let database = database_name(trigger_name);
+                let action = if *temporary || database.eq_ignore_ascii_case("temp") {
+                    A::CreateTempTrigger
+                } else {
+                    A::CreateTrigger
+                };
+                self.push(
+                    action,
+                    Some(trigger_name.name.as_str()),
+                    Some(tbl_name.name.as_str()),
+                    Some(database),
+                );
+            }
+            ast::Stmt::CreateView {
+                temporary,
+                view_name,
+                ..
+            } => {
+                let database = database_name(view_name);
+                let action = if *temporary || database.eq_ignore_ascii_case("temp") {
+                    A::CreateTempView
+                } else {
+                    A::CreateView
+                };
+                self.push(action, Some(view_name.name.as_str()), None, Some(database));
+            }
+            ast::Stmt::CreateMaterializedView { view_name, .. } => {
+                self.push(
+                    A::CreateView,
+                    Some(view_name.name.as_str()),
+                    None,
+                    Some(database_name(view_name)),
+                );
+            }
+            ast::Stmt::CreateVirtualTable(vtab) => {
+                self.push(
+                    A::CreateVtable,
+                    Some(vtab.tbl_name.name.as_str()),
+                    Some(vtab.module_name.as_str()),
+                    Some(database_name(&vtab.tbl_name)),
+                );
+            }
+            ast::Stmt::Delete {
+                with,
+                tbl_name,
+                where_clause,
+                returning,
+                limit,
+                ..
+            } => {
+                self.with(with.as_ref())?;
+                self.push(
+                    A::Delete,
+                    Some(tbl_name.name.as_str()),
+                    None,
+                    Some(database_name(tbl_name)),
+                );
+                if let Some(expr) = where_clause {
+                    self.expr(expr)?;
+                }
+                self.result_columns(returning)?;
+                self.limit(limit.as_ref())?;
+                self.triggers(tbl_name, |event| matches!(event, ast::TriggerEvent::Delete))?;
+            }
+            ast::Stmt::DropIndex { idx_name, .. } => {
+                let database = database_name(idx_name);
+                let action = if database.eq_ignore_ascii_case("temp") {
+                    A::DropTempIndex
+                } else {
+                    A::DropIndex
+                };
+                self.push(action, Some(idx_name.name.as_str()), None, Some(database));
+            }
+            ast::Stmt::DropTable { tbl_name, .. } => {
+                let database = database_name(tbl_name);
+                let action = if database.eq_ignore_ascii_case("temp") {
+                    A::DropTempTable
+                } else {
+                    A::DropTable
+                };
+                self.push(action, Some(tbl_name.name.as_str()), None, Some(database));
+            }
+            ast::Stmt::DropTrigger { trigger_name, .. } => {
+                let database = database_name(trigger_name);
+                let action = if database.eq_ignore_ascii_case("temp") {
+                    A::DropTempTrigger
+                } else {
+                    A::DropTrigger
+                };
+                self.push(
+                    action,
+                    Some(trigger_name.name.as_str()),
+                    None,
+                    Some(database),
+                );
+            }
+            ast::Stmt::DropView { view_name, .. } => {
+                let database = database_name(view_name);
+                let action = if database.eq_ignore_ascii_case("temp") {
+                    A::DropTempView
+                } else {
+                    A::DropView
+                };
+                self.push(action, Some(view_name.name.as_str()), None, Some(database));
+            }
+            ast::Stmt::Insert {
+                with,
+                tbl_name,
+                body,
+                returning,
+                ..
+            } => {
+                self.with(with.as_ref())?;
+                let database = database_name(tbl_name);
+                match body {
+                    ast::InsertBody::Select(select, upsert) => {
+                        self.insert(tbl_name, &database, select, upsert.as_deref())?
+                    }
+                    ast::InsertBody::DefaultValues => {
+                        self.push(
+                            A::Insert,
+                            Some(tbl_name.name.as_str()),
+                            None,
+                            Some(database),
+                        );
+                        self.triggers(tbl_name, |event| {
+                            matches!(event, ast::TriggerEvent::Insert)
+                        })?;
+                    }
+                }
+                self.result_columns(returning)?;
+            }
+            ast::Stmt::Pragma { name, body } => {
+                let argument = body.as_ref().map(|body| match body {
+                    ast::PragmaBody::Equals(value) | ast::PragmaBody::Call(value) => {
+                        expr_text(value)
+                    }
+                });
+                self.push(
+                    A::Pragma,
+                    Some(name.name.as_str()),
+                    argument.as_deref(),
+                    name.db_name.as_ref().map(|db| db.as_str().to_string()),
+                );
+            }
+            ast::Stmt::Reindex { name } => {
+                let index = name.as_ref().map(|name| name.name.as_str());
+                self.push(A::Reindex, index, None, name.as_ref().map(database_name));
+            }
+            ast::Stmt::Select(select) => self.select(select)?,
+            ast::Stmt::Update(update) => {
+                self.with(update.with.as_ref())?;
+                let database = database_name(&update.tbl_name);
+                self.sets(&update.tbl_name, &database, &update.sets)?;
+                if let Some(from) = &update.from {
+                    self.from(from)?;
+                }
+                if let Some(expr) = &update.where_clause {
+                    self.expr(expr)?;
+                }
+                self.result_columns(&update.returning)?;
+                self.update_triggers(&update.tbl_name, &update.sets)?;
+            }
+            // SQLite has no actions for the rest of statements
+            _ => {}
+        }
+        Ok(())
+    }
+
+    fn sets(
+        &mut self,
+        table: &ast::QualifiedName,
+        database: &str,
+        sets: &[ast::Set],
+    ) -> Result<()> {
+        for set in sets {
+            for column in &set.col_names {
+                self.push(
+                    AuthorizerAction::Update,
+                    Some(table.name.as_str()),
+                    Some(column.as_str()),
+                    Some(database.to_string()),
+                );
+            }
+            self.expr(&set.expr)?;
+        }
+        Ok(())
+    }
+
+    fn with(&mut self, with: Option<&ast::With>) -> Result<()> {
+        let Some(with) = with else {
+            return Ok(());
+        };
+        for cte in &with.ctes {
+            self.ctes.push(cte.tbl_name.as_str().to_string());
+        }
+        for cte in &with.ctes {
+            self.select(&cte.select)?;
+        }
+        Ok(())
+    }
+
+    fn select(&mut self, select: &ast::Select) -> Result<()> {
+        self.with(select.with.as_ref())?;
+        self.push(AuthorizerAction::Select, None, None, None);
+        self.one_select(&select.body.select)?;
+        for compound in &select.body.compounds {
+            self.one_select(&compound.select)?;
+        }
+        for column in &select.order_by {
+            self.expr(&column.expr)?;
+        }
+        self.limit(select.limit.as_ref())
+    }
+
+    fn one_select(&mut self, select: &ast::OneSelect) -> Result<()> {
+        match select {
+            ast::OneSelect::Select {
+                columns,
+                from,
+                where_clause,
+                group_by,
+                ..
+            } => {
+                self.result_columns(columns)?;
+                if let Some(from) = from {
+                    self.from(from)?;
+                }
+                if let Some(expr) = where_clause {
+                    self.expr(expr)?;
+                }
+                if let Some(group_by) = group_by {
+                    for expr in &group_by.exprs {
+                        self.expr(expr)?;
+                    }
+                    if let Some(expr) = &group_by.having {
+                        self.expr(expr)?;
+                    }
+                }
+            }
+            ast::OneSelect::Values(rows) => {
+                for expr in rows.iter().flatten() {
+                    self.expr(expr)?;
+                }
+            }
+        }
+        Ok(())
+    }
+
+    fn from(&mut self, from: &ast::FromClause) -> Result<()> {
+        self.select_table(&from.select)?;
+        for join in &from.joins {
+            self.select_table(&join.table)?;
+            if let Some(ast::JoinConstraint::On(expr)) = &join.constraint {
+                self.expr(expr)?;
+            }
+        }
+        Ok(())
+    }
+
+    fn select_table(&mut self, table: &ast::SelectTable) -> Result<()> {
+        match table {
+            ast::SelectTable::Table(name, ..) => self.read(name)?,
+            ast::SelectTable::TableCall(_, args, _) => {
+                for expr in args {
+                    self.expr(expr)?;
+                }
+            }
+            ast::SelectTable::Select(select, _) => self.select(select)?,
+            ast::SelectTable::Sub(from, _) => self.from(from)?,
+        }
+        Ok(())
+    }
+
+    /// report read of the table; reads are reported per table with empty column name.
+    /// Reading a view of the main database reports the accesses made by its SELECT as well
+    fn read(&mut self, name: &ast::QualifiedName) -> Result<()> {
+        if name.db_name.is_none()
+            && self
+                .ctes
+                .iter()
+                .any(|cte| cte.eq_ignore_ascii_case(name.name.as_str()))
+        {
+            return Ok(());
+        }
+        let database = database_name(name);
+        self.push(
+            AuthorizerAction::Read,
+            Some(name.name.as_str()),
+            Some(""),
+            Some(database.clone()),
+        );
+        let view = self
+            .schema
+            .filter(|_| database.eq_ignore_ascii_case("main"))
+            .and_then(|schema| schema.get_view(name.name.as_str()));
+        if let Some(view) = view {
+            self.expand(&view.name, |collector| collector.select(&view.select_stmt))?;
+        }
+        Ok(())
+    }
+
+    fn result_columns(&mut self, columns: &[ast::ResultColumn]) -> Result<()> {
+        for column in columns {
+            if let ast::ResultColumn::Expr(expr, _) = column {
+                self.expr(expr)?;
+            }
+        }
+        Ok(())
+    }
+
+    fn limit(&mut self, limit: Option<&ast::Limit>) -> Result<()> {
+        if let Some(limit) = limit {
+            self.expr(&limit.expr)?;
+            if let Some(offset) = &limit.offset {
+                self.expr(offset)?;
+            }
+        }
+        Ok(())
+    }
+
+    fn expr(&mut self, expr: &ast::Expr) -> Result<()> {
+        walk_expr(expr, &mut |expr: &ast::Expr| -> Result<WalkControl> {
+            match expr {
+                ast::Expr::FunctionCall { name, .. } | ast::Expr::FunctionCallStar { name, .. } => {
+                    self.push(AuthorizerAction::Function, None, Some(name.as_str()), None);
+                }
+                ast::Expr::Exists(select) | ast::Expr::Subquery(select) => self.select(select)?,
+                ast::Expr::InSelect { rhs, .. } => self.select(rhs)?,
+                ast::Expr::InTable { rhs, .. } => self.read(rhs)?,
+                _ => {}
+            }
+            Ok(WalkControl::Continue)
+        })?;
+        Ok(())
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use turso_parser::parser::Parser;
+
+    fn collect(sql: &str) -> Vec<(AuthorizerAction, Option<String>, Option<String>)> {
+        let mut parser = Parser::new(sql.as_bytes());
+        let Some(ast::Cmd::Stmt(stmt)) = parser.next_cmd().unwrap() else {
+            panic!("expected statement");
+        };
+        let mut collector = AuthCollector::new(None);
+        collector.stmt(&stmt).unwrap();
+        collector
+            .requests
+            .into_iter()
+            .map(|request| (request.action, request.arg1, request.arg2))
+            .collect()
+    }
+
+    #[test]
+    fn collects_accesses_of_the_statement() {
+        use AuthorizerAction as A;
+        let s = |v: &str| Some(v.to_string());
+        assert_eq!(
+            collect("WITH c AS (SELECT 1) SELECT abs(x) FROM t, c WHERE x IN (SELECT y FROM u)"),
+            vec![
+                (A::Select, None, None),
+                (A::Select, None, None),
+                (A::Function, None, s("abs")),
+                (A::Read, s("t"), s("")),
+                (A::Select, None, None),
+                (A::Read, s("u"), s("")),
+            ]
+        );
+        assert_eq!(
+            collect("UPDATE t SET a = 1, b = 2"),
+            vec![(A::Update, s("t"), s("a")), (A::Update, s("t"), s("b"))]
+        );
+        assert_eq!(
+            collect("CREATE TEMP TABLE t(x)"),
+            vec![(A::CreateTempTable, s("t"), None)]
+        );
+        assert_eq!(
+            collect("PRAGMA cache_size = 10"),
+            vec![(A::Pragma, s("cache_size"), s("10"))]
+        );
+        assert_eq!(
+            collect("ATTACH 'it''s.db' AS aux"),
+            vec![(A::Attach, s("it's.db"), None)]
+        );
+    }
+
+    #[test]
+    fn denial_names_the_action_and_the_object() {
+        let request = |action, arg1: &str, arg2: &str, responsible: Option<&str>| AuthRequest {
+            action,
+            arg1: Some(arg1.to_string()),
+            arg2: Some(arg2.to_string()),
+            database: Some("main".to_string()),
+            responsible: responsible.map(str::to_string),
+        };
+        let message = |request: AuthRequest| request.denied().to_string();
+        assert!(message(request(AuthorizerAction::Update, "t", "v", None))
+            .ends_with(r#"not authorized: Update "t.v""#));
+        assert!(
+            message(request(AuthorizerAction::Read, "secret", "", Some("leak")))
+                .ends_with(r#"not authorized: Read "secret" (through "leak")"#)
+        );
+    }
+}
@@ -2179,6 +2179,30 @@ fn check_expr_references_columns(expr: &ast::Expr, column_names: &HashSet<String
         .any(|name| check_expr_references_column(expr, name))
 }
 
+/// Emit dimension checks for vector columns (e.g. `F32_BLOB(N)`) after type affinity/TypeCheck.
+/// Takes column-to-register mappings of the columns written by INSERT, UPDATE, or UPSERT.
+pub(crate) fn emit_vector_checks<'a>(
+    program: &mut ProgramBuilder,
+    table_name: &str,
+    column_mappings: impl Iterator<Item = (&'a Column, usize)>,
+) {
+    for (col, reg) in column_mappings {
+        if col.is_virtual_generated() {
+            continue;
+        }
+        let Some(dims) = col.vector_dimensions() else {
+            continue;
+        };
+        program.emit_insn(Insn::VectorCheck {
+            reg,
+            dims,
+            column_type: col.ty_str.to_uppercase().into(),
+            table_name: table_name.into(),
+            col_name: col.name.as_deref().unwrap_or("").into(),
+        });
+    }
+}
+
 /// Emit CHECK constraint evaluation with resolver cache setup and teardown.
 /// Takes column-to-register mappings as an iterator to avoid heap allocation.
 #[allow(clippy::too_many_arguments)]
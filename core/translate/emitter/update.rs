@@ -21,7 +21,7 @@ use crate::{
             emit_cdc_autocommit_commit, emit_cdc_full_record, emit_cdc_insns,
             emit_cdc_patch_record, emit_check_constraints, emit_index_column_value_new_image,
             emit_index_column_value_old_image, emit_make_record, emit_program_for_select,
-            init_limit, OperationMode, Resolver, UpdateRowSource,
+            emit_vector_checks, init_limit, OperationMode, Resolver, UpdateRowSource,
         },
         expr::{
             emit_dml_expr_index_value, emit_returning_results, emit_returning_scan_back,
@@ -1695,6 +1695,18 @@ fn emit_update_insns<'a>(
             });
         }
 
+        // Vectors stored earlier are not re-checked, only the ones written by this UPDATE.
+        emit_vector_checks(
+            program,
+            table_name,
+            btree_table
+                .columns()
+                .iter()
+                .enumerate()
+                .filter(|(idx, _)| affected_columns.get(*idx))
+                .map(|(idx, col)| (col, layout.to_register(start, idx))),
+        );
+
         if !btree_table.check_constraints.is_empty() {
             // SQLite only evaluates CHECK constraints that reference at least one
             // column in the SET clause. Build a set of updated column names to filter.
@@ -2314,8 +2326,9 @@ fn emit_update_insns<'a>(
                         .require_seek()
                         .update_rowid_change()
                         .skip_last_rowid()
+                        .update()
                 } else {
-                    InsertFlags::new().skip_last_rowid()
+                    InsertFlags::new().skip_last_rowid().update()
                 },
                 table_name: target_table.identifier.clone(),
             });
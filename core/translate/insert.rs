@@ -12,7 +12,8 @@ use crate::{
         emitter::{
             delete::emit_fk_child_decrement_on_delete, emit_cdc_autocommit_commit,
             emit_cdc_full_record, emit_cdc_insns, emit_cdc_patch_record, emit_check_constraints,
-            emit_make_record, prepare_cdc_if_necessary, OperationMode, Resolver,
+            emit_make_record, emit_vector_checks, prepare_cdc_if_necessary, OperationMode,
+            Resolver,
         },
         expr::{
             bind_and_rewrite_expr, emit_returning_results, emit_returning_scan_back,
@@ -664,6 +665,14 @@ pub fn translate_insert(
         });
     }
     // Non-STRICT tables: Affinity was already emitted earlier (before BEFORE triggers).
+    emit_vector_checks(
+        program,
+        &ctx.table.name,
+        insertion
+            .col_mappings
+            .iter()
+            .map(|m| (m.column, m.register)),
+    );
 
     // For AUTOINCREMENT tables with an explicit rowid, update sqlite_sequence
     // before CHECK constraints. SQLite updates sqlite_sequence even when
@@ -11,6 +11,7 @@ pub(crate) mod aggregation;
 pub(crate) mod alter;
 pub(crate) mod analyze;
 pub(crate) mod attach;
+pub(crate) mod authorizer;
 pub(crate) mod collate;
 mod compound_select;
 pub(crate) mod delete;
@@ -123,7 +124,12 @@ pub fn translate(
         },
     );
 
+    // statements ignored by the authorizer are prepared as a no-op
+    let ignored = matches!(origin, crate::statement::StatementOrigin::Root)
+        && authorizer::authorize_stmt(&stmt, schema, &connection)?;
+
     match stmt {
+        _ if ignored => {}
         // There can be no nesting with pragma, so lift it up here
         ast::Stmt::Pragma { name, body } => {
             pragma::translate_pragma(
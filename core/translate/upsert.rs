@@ -8,7 +8,9 @@ use super::emitter::gencol::compute_virtual_columns;
 use crate::alloc::TursoIteratorExt;
 use crate::error::SQLITE_CONSTRAINT_PRIMARYKEY;
 use crate::schema::{BTreeTable, ColumnLayout, IndexColumn, ROWID_SENTINEL};
-use crate::translate::emitter::{emit_check_constraints, emit_make_record, UpdateRowSource};
+use crate::translate::emitter::{
+    emit_check_constraints, emit_make_record, emit_vector_checks, UpdateRowSource,
+};
 use crate::translate::expr::{walk_expr, WalkControl};
 use crate::translate::fkeys::{
     emit_fk_child_update_counters, emit_fk_update_parent_actions, fire_fk_update_actions,
@@ -780,6 +782,18 @@ pub fn emit_upsert(
             }
         }
 
+        // Vectors kept from the existing row are not re-checked, only the ones written by SET.
+        emit_vector_checks(
+            program,
+            &bt.name,
+            set_pairs.iter().map(|(col_idx, _)| {
+                (
+                    &bt.columns()[*col_idx],
+                    layout.to_register(new_start, *col_idx),
+                )
+            }),
+        );
+
         // Evaluate CHECK constraints on the new values
         emit_check_constraints(
             program,
@@ -1289,7 +1303,8 @@ pub fn emit_upsert(
             flag: InsertFlags::new()
                 .require_seek()
                 .update_rowid_change()
-                .skip_last_rowid(),
+                .skip_last_rowid()
+                .update(),
             table_name: table.get_name().to_string(),
         });
 
@@ -1328,7 +1343,7 @@ pub fn emit_upsert(
             cursor: ctx.cursor_id,
             key_reg: ctx.conflict_rowid_reg,
             record_reg,
-            flag: InsertFlags::new().skip_last_rowid(),
+            flag: InsertFlags::new().skip_last_rowid().update(),
             table_name: table.get_name().to_string(),
         });
     }
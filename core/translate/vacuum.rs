@@ -71,12 +71,18 @@ pub fn translate_vacuum(
 fn extract_path_from_expr(expr: &Expr) -> Result<String> {
     match expr {
         Expr::Literal(Literal::String(s)) => {
-            // Remove surrounding quotes if present
-            let path = s.trim_matches('\'').trim_matches('"');
+            // Remove surrounding quotes and unescape doubled quotes inside
+            let path = match s.chars().next() {
+                Some(quote @ ('\'' | '"')) if s.len() >= 2 && s.ends_with(quote) => {
+                    let doubled: String = [quote, quote].iter().collect();
+                    s[1..s.len() - 1].replace(&doubled, &quote.to_string())
+                }
+                _ => s.to_string(),
+            };
             if path.is_empty() {
                 bail_parse_error!("VACUUM INTO path cannot be empty");
             }
-            Ok(path.to_string())
+            Ok(path)
         }
         Expr::Id(name) => {
             // Allow identifier as path (unusual but valid)
@@ -106,6 +112,13 @@ mod tests {
         assert_eq!(path, "test.db");
     }
 
+    #[test]
+    fn test_extract_path_unescapes_quotes() {
+        let expr = Expr::Literal(Literal::String("'it''s \"backup\".db'".to_string()));
+        let path = extract_path_from_expr(&expr).unwrap();
+        assert_eq!(path, "it's \"backup\".db");
+    }
+
     #[test]
     fn test_extract_path_from_identifier() {
         let expr = Expr::Id(Name::exact("myfile".to_string()));
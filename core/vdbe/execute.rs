@@ -4,6 +4,7 @@ use crate::alloc::{
 };
 use crate::error::SQLITE_CONSTRAINT_UNIQUE;
 use crate::function::{AccumulatorFunc, AlterTableFunc, WindowFunc};
+use crate::hooks::UpdateHookOp;
 use crate::io::TempFile;
 use crate::mvcc::cursor::{MvccCursorType, NextRowidResult};
 use crate::mvcc::database::{BootstrapState, CheckpointStateMachine, TxID};
@@ -1522,6 +1523,7 @@ pub fn op_vupdate(
             if *conflict_action == 5 {
                 // ResolveType::Replace
                 program.connection.update_last_rowid(new_rowid);
+                state.inserted_rowid = true;
             }
             state.pc += 1;
         }
@@ -2250,6 +2252,51 @@ pub fn op_array_encode(
     Ok(InsnFunctionStepResult::Step)
 }
 
+/// Check that a vector BLOB has the number of dimensions declared by the vector column type.
+pub fn op_vector_check(
+    _program: &Program,
+    state: &mut ProgramState,
+    insn: &Insn,
+    _pager: &Arc<Pager>,
+) -> Result<InsnFunctionStepResult> {
+    load_insn!(
+        VectorCheck {
+            reg,
+            dims,
+            column_type,
+            table_name,
+            col_name,
+        },
+        insn
+    );
+
+    if let Value::Blob(blob) = state.registers[*reg].get_value() {
+        let Ok(vector) = crate::vector::vector_types::Vector::from_slice(blob) else {
+            bail_constraint_error!(
+                "cannot store non-vector value in {}({}) column {}.{} ({})",
+                column_type,
+                dims,
+                table_name,
+                col_name,
+                SQLITE_CONSTRAINT
+            );
+        };
+        if vector.dims != *dims {
+            bail_constraint_error!(
+                "vector dimension mismatch: {}({}) column {}.{} can't store a vector of {} dimensions ({})",
+                column_type,
+                dims,
+                table_name,
+                col_name,
+                vector.dims,
+                SQLITE_CONSTRAINT
+            );
+        }
+    }
+    state.pc += 1;
+    Ok(InsnFunctionStepResult::Step)
+}
+
 /// Convert a native record-format array BLOB to PG text representation for display.
 pub fn op_array_decode(
     _program: &Program,
@@ -3194,6 +3241,7 @@ pub fn halt(
 
     // Handle constraint errors
     if let Some(error) = constraint_error {
+        state.halt_error_code = err_code;
         // For FAIL mode with autocommit, commit partial changes before returning error.
         // This matches SQLite behavior where FAIL keeps changes made before the error.
         // Note: ON CONFLICT FAIL does NOT apply to FK violations, so we check for those first.
@@ -4361,6 +4409,7 @@ pub fn op_auto_commit(
                 conn.set_tx_state(TransactionState::None);
                 conn.auto_commit.store(true, Ordering::SeqCst);
                 conn.set_cdc_transaction_id(-1);
+                conn.hooks().fire_rollback();
             }
             TxOp::Commit => {
                 if conn.tx_is_poisoned() {
@@ -10578,6 +10627,15 @@ pub fn op_insert(
                     if let Some(rowid) = maybe_rowid {
                         if !flag.has(InsertFlags::SKIP_LAST_ROWID) {
                             program.connection.update_last_rowid(rowid);
+                            state.inserted_rowid = true;
+                        }
+                        if !table_name.is_empty() {
+                            let op = if flag.has(InsertFlags::UPDATE) {
+                                UpdateHookOp::Update
+                            } else {
+                                UpdateHookOp::Insert
+                            };
+                            fire_update_hook(program, state, *cursor_id, op, table_name, rowid);
                         }
                         if flag.has(InsertFlags::SKIP_STATEMENT_CHANGE_COUNT) {
                             state.record_total_change();
@@ -10671,6 +10729,26 @@ pub fn op_insert(
     Ok(InsnFunctionStepResult::Step)
 }
 
+/// Reports the row change made through the write cursor to the update hook of the connection.
+fn fire_update_hook(
+    program: &Program,
+    state: &ProgramState,
+    cursor_id: usize,
+    op: UpdateHookOp,
+    table_name: &str,
+    rowid: i64,
+) {
+    let hooks = program.connection.hooks();
+    if !hooks.update_enabled() {
+        return;
+    }
+    let database = state
+        .write_cursor_databases
+        .get(&cursor_id)
+        .and_then(|db| program.connection.get_database_name_by_index(*db));
+    hooks.fire_update(op, database.as_deref().unwrap_or("main"), table_name, rowid);
+}
+
 pub fn op_int_64(
     _program: &Program,
     state: &mut ProgramState,
@@ -10694,6 +10772,8 @@ pub fn op_int_64(
 pub struct OpDeleteState {
     pub sub_state: OpDeleteSubState,
     pub deleted_record: Option<(i64, crate::alloc::Vec<Value>)>,
+    /// Rowid of the deleted row to report to the update hook, captured before deletion.
+    pub hook_rowid: Option<i64>,
 }
 
 #[derive(Clone, Copy)]
@@ -10724,6 +10804,21 @@ pub fn op_delete(
     loop {
         match state.active_op_state.delete().sub_state {
             OpDeleteSubState::MaybeCaptureRecord => {
+                if !is_part_of_update
+                    && !table_name.is_empty()
+                    && program.connection.hooks().update_enabled()
+                {
+                    let hook_rowid = {
+                        let cursor = state.get_cursor(*cursor_id);
+                        let cursor = cursor.as_btree_mut();
+                        if cursor.has_rowid() {
+                            return_if_io!(cursor.rowid())
+                        } else {
+                            None
+                        }
+                    };
+                    state.active_op_state.delete().hook_rowid = hook_rowid;
+                }
                 let schema = program.connection.schema.read();
                 let dependent_views = schema.get_dependent_materialized_views(table_name);
                 if dependent_views.is_empty() {
@@ -10796,7 +10891,18 @@ pub fn op_delete(
         }
     }
 
+    let hook_rowid = state.active_op_state.delete().hook_rowid.take();
     state.active_op_state.clear();
+    if let Some(rowid) = hook_rowid {
+        fire_update_hook(
+            program,
+            state,
+            *cursor_id,
+            UpdateHookOp::Delete,
+            table_name,
+            rowid,
+        );
+    }
     if !is_part_of_update {
         // DELETEs do not count towards the total changes if they are part of an UPDATE statement,
         // i.e. the DELETE and subsequent INSERT of a row are the same "change".
@@ -11591,6 +11697,8 @@ pub fn op_open_write(
     }
     let pager = program.get_pager_from_database_index(db)?;
     let mv_store = program.connection.mv_store_for_db(*db);
+    program.connection.hooks().mark_write();
+    state.write_cursor_databases.insert(*cursor_id, *db);
 
     if let (_, CursorType::IndexMethod(module)) = &program.cursor_ref[*cursor_id] {
         if state.cursors[*cursor_id].is_none() {
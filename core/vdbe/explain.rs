@@ -659,6 +659,21 @@ pub fn insn_to_row(
                 0,
                 format!("{table_name}.{col_name} ({element_type})"),
             ),
+            Insn::VectorCheck {
+                reg,
+                dims,
+                column_type,
+                table_name,
+                col_name,
+            } => (
+                "VectorCheck",
+                *reg as i64,
+                *dims as i64,
+                0,
+                Value::build_text(""),
+                0,
+                format!("{table_name}.{col_name} ({column_type}({dims}))"),
+            ),
             Insn::ArrayDecode { reg } => (
                 "ArrayDecode",
                 *reg as i64,
@@ -181,6 +181,7 @@ impl InsertFlags {
     pub const EPHEMERAL_TABLE_INSERT: u8 = 0x04; // Flag indicating that this is an insert into an ephemeral table
     pub const SKIP_LAST_ROWID: u8 = 0x08; // Flag indicating that last_insert_rowid() must not be updated
     pub const SKIP_STATEMENT_CHANGE_COUNT: u8 = 0x10; // Flag indicating that changes() must not count this insert
+    pub const UPDATE: u8 = 0x20; // Flag indicating that the insert writes the new version of a row changed by UPDATE (reported as such to the update hook)
 
     pub fn new() -> Self {
         InsertFlags(0)
@@ -214,6 +215,11 @@ impl InsertFlags {
         self.0 |= InsertFlags::SKIP_STATEMENT_CHANGE_COUNT;
         self
     }
+
+    pub fn update(mut self) -> Self {
+        self.0 |= InsertFlags::UPDATE;
+        self
+    }
 }
 
 #[derive(Clone, Copy, Debug)]
@@ -650,6 +656,18 @@ pub enum Insn {
         col_name: Arc<str>,
     },
 
+    /// Validate that a vector BLOB in reg has the number of dimensions declared
+    /// by the vector column type (e.g. `F32_BLOB(N)`). Values of other types
+    /// (including NULL) are left to the column affinity.
+    /// Raises SQLITE_CONSTRAINT on dimension mismatch or malformed vector BLOB.
+    VectorCheck {
+        reg: usize,
+        dims: usize,
+        column_type: Arc<str>,
+        table_name: Arc<str>,
+        col_name: Arc<str>,
+    },
+
     /// Convert a native record-format BLOB back to PostgreSQL-style array text for display.
     /// Input: reg = record-format BLOB. Output: reg = PG array text like '{1,2,3}'.
     ArrayDecode {
@@ -2048,6 +2066,7 @@ impl InsnVariants {
             InsnVariants::ColumnHasField => execute::op_column_has_field,
             InsnVariants::TypeCheck => execute::op_type_check,
             InsnVariants::ArrayEncode => execute::op_array_encode,
+            InsnVariants::VectorCheck => execute::op_vector_check,
             InsnVariants::ArrayDecode => execute::op_array_decode,
             InsnVariants::ArrayElement => execute::op_array_element,
             InsnVariants::ArrayLength => execute::op_array_length,
@@ -623,6 +623,7 @@ impl ActiveOpStateSlot {
         OpDeleteState {
             sub_state: OpDeleteSubState::MaybeCaptureRecord,
             deleted_record: None,
+            hook_rowid: None,
         }
     );
     active_state_accessor!(
@@ -832,12 +833,17 @@ pub struct ProgramState {
     /// TempFile handles for ephemeral cursors, keyed by cursor_id.
     /// Dropping removes the temp file from disk.
     ephemeral_temp_files: HashMap<usize, TempFile>,
+    /// Database index of the write cursors, keyed by cursor_id (used to report the database to the update hook).
+    write_cursor_databases: HashMap<usize, usize>,
     /// Attached pagers that have open savepoints for statement rollback.
     attached_savepoint_pagers: Vec<Arc<Pager>>,
     /// Pending error to return after FAIL mode commit completes.
     /// When a constraint error occurs with FAIL resolve type in autocommit mode,
     /// we need to commit partial changes before returning the error.
     pub(crate) pending_fail_error: Option<LimboError>,
+    /// SQLite (extended) result code passed to the Halt which failed the program, 0 if none.
+    /// Lets API consumers distinguish e.g. SQLITE_CONSTRAINT_UNIQUE from SQLITE_CONSTRAINT_NOTNULL.
+    pub(crate) halt_error_code: usize,
     /// Pending CDC info to apply after the program completes successfully.
     /// Set by InitCdcVersion opcode, applied at Halt/Done so that if the
     /// transaction rolls back, the connection's CDC state remains unchanged.
@@ -865,6 +871,8 @@ pub struct ProgramState {
     pub(crate) is_active_write: bool,
     /// Whether begin_statement was called (savepoint + FK bookkeeping active).
     has_stmt_transaction: bool,
+    /// Whether the statement itself (not a trigger) inserted a row and updated the last insert rowid.
+    pub(crate) inserted_rowid: bool,
     pub n_change: AtomicI64,
     pub n_total_change: AtomicI64,
 }
@@ -923,14 +931,17 @@ impl ProgramState {
             bloom_filters: HashMap::default(),
             hash_tables: HashMap::default(),
             ephemeral_temp_files: HashMap::default(),
+            write_cursor_databases: HashMap::default(),
             uses_subjournal: false,
             is_active_write: false,
             has_stmt_transaction: false,
+            inserted_rowid: false,
             attached_savepoint_pagers: Vec::new(),
             n_change: AtomicI64::new(0),
             n_total_change: AtomicI64::new(0),
             explain_state: RwLock::new(ExplainState::default()),
             pending_fail_error: None,
+            halt_error_code: 0,
             pending_cdc_info: None,
             subprogram_stmt_cache: HashMap::default(),
         }
@@ -1051,15 +1062,18 @@ impl ProgramState {
         self.bloom_filters.clear();
         self.hash_tables.clear();
         self.ephemeral_temp_files.clear();
+        self.write_cursor_databases.clear();
         self.uses_subjournal = false;
         self.is_active_write = false;
         self.has_stmt_transaction = false;
+        self.inserted_rowid = false;
         self.distinct_key_values.clear();
         self.attached_savepoint_pagers.clear();
         self.n_change.store(0, Ordering::SeqCst);
         self.n_total_change.store(0, Ordering::SeqCst);
         *self.explain_state.write() = ExplainState::default();
         self.pending_fail_error = None;
+        self.halt_error_code = 0;
         self.pending_cdc_info = None;
         self.subprogram_stmt_cache.clear();
     }
@@ -2133,8 +2147,10 @@ impl Program {
                 // uncommitted temp DDL.
                 if rollback {
                     self.connection.rollback_temp_schema();
+                    self.connection.hooks().fire_rollback();
                 } else {
                     self.connection.commit_temp_schema();
+                    self.connection.hooks().fire_commit();
                 }
             }
         }
@@ -254,11 +254,17 @@ pub(crate) struct VacuumTempDb {
 #[cfg(not(target_family = "wasm"))]
 fn vacuum_temp_db_encryption(
     source_conn: &Arc<Connection>,
+    rekey: Option<&EncryptionKey>,
 ) -> Result<(Option<EncryptionOpts>, Option<EncryptionKey>)> {
     let Some(cipher_mode) = source_conn.get_encryption_cipher_mode() else {
         return Ok((None, None));
     };
-    let encryption_key = source_conn.encryption_key.read().clone().ok_or_else(|| {
+    // rekey builds the temp database with the new key, so its pages are copied back already re-encrypted
+    let encryption_key = match rekey {
+        Some(key) => Some(key.clone()),
+        None => source_conn.encryption_key.read().clone(),
+    };
+    let encryption_key = encryption_key.ok_or_else(|| {
         LimboError::InternalError(
             "encrypted in-place VACUUM temp database requires source encryption key".to_string(),
         )
@@ -276,6 +282,7 @@ pub(crate) fn open_vacuum_temp_db(
     source_db: &Arc<Database>,
     page_size: u32,
     reserved_space: u8,
+    rekey: Option<&EncryptionKey>,
 ) -> Result<VacuumTempDb> {
     let temp_dir = source_conn.create_tempdir()?;
     let source_db_name = std::path::Path::new(&source_db.path)
@@ -291,7 +298,7 @@ pub(crate) fn open_vacuum_temp_db(
     #[cfg(test)]
     let test_path = path.clone();
 
-    let (encryption_opts, encryption_key) = vacuum_temp_db_encryption(source_conn)?;
+    let (encryption_opts, encryption_key) = vacuum_temp_db_encryption(source_conn, rekey)?;
     let db = Database::open_file_with_flags(
         source_db.io.clone(),
         &path,
@@ -320,6 +327,7 @@ pub(crate) fn open_vacuum_temp_db(
     _source_db: &Arc<Database>,
     _page_size: u32,
     _reserved_space: u8,
+    _rekey: Option<&EncryptionKey>,
 ) -> Result<VacuumTempDb> {
     Err(LimboError::InternalError(
         "in-place VACUUM requires a file-backed internal temp database".to_string(),
@@ -1228,6 +1236,10 @@ struct VacuumInPlaceCleanupState {
     source_tx_open: bool,
     /// WAL VACUUM lock is held exclusively.
     vacuum_lock_held: bool,
+    /// New encryption key of the source database when the VACUUM runs for [Connection::rekey].
+    rekey: Option<EncryptionKey>,
+    /// Source pager encrypts with the new key, but the rekeyed image isn't committed yet.
+    source_rekeyed: bool,
 }
 
 /// Phases for the in-place VACUUM state machine.
@@ -1761,6 +1773,7 @@ fn vacuum_in_place_step(
                 // we fail fast before doing any expensive work.
                 wal.try_begin_vacuum_checkpoint_lock()?;
                 cleanup_state.checkpoint_cleanup = CheckpointLockCleanup::ReleaseRaw;
+                cleanup_state.rekey = connection.pending_rekey.write().take();
                 *phase = VacuumInPlacePhase::BeginSourceTx;
                 continue;
             }
@@ -1813,8 +1826,13 @@ fn vacuum_in_place_step(
                     })
                 })?;
                 // Create temp database.
-                let new_temp_db =
-                    open_vacuum_temp_db(connection, &source_db, page_size, reserved_space)?;
+                let new_temp_db = open_vacuum_temp_db(
+                    connection,
+                    &source_db,
+                    page_size,
+                    reserved_space,
+                    cleanup_state.rekey.as_ref(),
+                )?;
 
                 mirror_symbols(connection, &new_temp_db.conn);
                 let source_custom_types = capture_custom_types(connection, db);
@@ -1882,6 +1900,16 @@ fn vacuum_in_place_step(
                 );
                 temp_pager.begin_read_tx()?;
 
+                // the source image is fully copied into the temp database: from now on the source
+                // WAL frames are written with the new key
+                if let Some(key) = cleanup_state.rekey.as_ref() {
+                    let cipher_mode = connection.get_encryption_cipher_mode().ok_or_else(|| {
+                        LimboError::InternalError("rekey requires an encrypted source".into())
+                    })?;
+                    source_pager.set_encryption_context(cipher_mode, key)?;
+                    cleanup_state.source_rekeyed = true;
+                }
+
                 // one invariant is that temp db should have disabled checkpoints and all raeds
                 // must happen over WAL only. In that case, the db file should be same as the page
                 // size.
@@ -2198,6 +2226,11 @@ fn vacuum_in_place_step(
 
                 source_pager.end_write_tx();
                 cleanup_state.source_tx_open = false;
+                // the image encrypted with the new key is committed: the connection keeps the new key
+                if let Some(key) = cleanup_state.rekey.take() {
+                    *connection.encryption_key.write() = Some(key);
+                    cleanup_state.source_rekeyed = false;
+                }
 
                 // Restore connection bookkeeping immediately. The commit is
                 // durable so there is nothing to roll back; restoring here
@@ -2359,6 +2392,15 @@ fn vacuum_in_place_cleanup(
         .get_pager_from_database_index(&db)
         .expect("VACUUM cleanup requires source pager");
     pager.rollback_tx(connection);
+    // rekey was not committed: the source is encrypted with the old key again
+    if cleanup_state.source_rekeyed {
+        let old_key = connection.encryption_key.read().clone();
+        if let (Some(cipher_mode), Some(key)) = (connection.get_encryption_cipher_mode(), old_key) {
+            if let Err(err) = pager.set_encryption_context(cipher_mode, &key) {
+                tracing::error!("VACUUM failed to restore the encryption key after rekey: {err}");
+            }
+        }
+    }
 
     if cleanup_state.vacuum_lock_held {
         let pager = connection
@@ -2827,7 +2869,7 @@ mod tests {
             .io_ctx
             .read()
             .get_reserved_space_bytes();
-        let temp = open_vacuum_temp_db(&source_conn, &source_db, 4096, reserved_space)?;
+        let temp = open_vacuum_temp_db(&source_conn, &source_db, 4096, reserved_space, None)?;
 
         temp.conn.execute("BEGIN IMMEDIATE")?;
         temp.conn
@@ -3102,7 +3144,7 @@ mod tests {
             Arc::new(SqliteDialect),
         )?;
         let source_conn = source_db.connect()?;
-        let temp = open_vacuum_temp_db(&source_conn, &source_db, 4096, 0)?;
+        let temp = open_vacuum_temp_db(&source_conn, &source_db, 4096, 0, None)?;
 
         let mut source_header = DatabaseHeader::default();
         source_header.schema_cookie = 41.into();
@@ -3157,7 +3199,7 @@ mod tests {
         )?;
         let source_conn = source_db.connect()?;
 
-        let temp = open_vacuum_temp_db(&source_conn, &source_db, 4096, 0)?;
+        let temp = open_vacuum_temp_db(&source_conn, &source_db, 4096, 0, None)?;
 
         assert!(Arc::ptr_eq(&temp._db.io, &source_db.io));
         assert_ne!(temp.path, source_db.path);
@@ -3196,7 +3238,7 @@ mod tests {
             .get_reserved_bytes()
             .expect("encrypted source should have reserved bytes");
 
-        let temp = open_vacuum_temp_db(&source_conn, &source_db, 4096, reserved_space)?;
+        let temp = open_vacuum_temp_db(&source_conn, &source_db, 4096, reserved_space, None)?;
 
         assert!(temp._db.experimental_encryption_enabled());
         assert_eq!(
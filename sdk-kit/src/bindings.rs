@@ -213,6 +213,15 @@ pub type turso_scalar_function_t = ::std::option::Option<
         value_destructor: turso_value_destructor_t,
     ) -> turso_value_t,
 >;
+#[doc = " Scalar callback which writes its result into result (initialized to NULL) instead of returning it by value.\n Useful for FFI runtimes which can't return structs from callbacks. argv points to argc immutable turso_value_t entries valid only for the call."]
+pub type turso_scalar_function_out_t = ::std::option::Option<
+    unsafe extern "C" fn(
+        context: usize,
+        argc: i32,
+        argv: *const turso_value_t,
+        result: *mut turso_value_t,
+    ),
+>;
 #[doc = " Aggregate initializer. Return value is passed unchanged to step/final/destructor callbacks."]
 pub type turso_aggregate_init_function_t =
     ::std::option::Option<unsafe extern "C" fn(context: usize) -> *mut turso_agg_ctx_t>;
@@ -239,6 +248,35 @@ pub type turso_collation_function_t = ::std::option::Option<
         right_len: usize,
     ) -> i32,
 >;
+#[doc = " Update hook callback. op is the SQLite action code (SQLITE_INSERT = 18, SQLITE_UPDATE = 23, SQLITE_DELETE = 9).\n Byte ranges are UTF-8 database and table names valid only for the call. The callback must not use the connection."]
+pub type turso_update_hook_t = ::std::option::Option<
+    unsafe extern "C" fn(
+        context: usize,
+        op: i32,
+        database_ptr: *const u8,
+        database_len: usize,
+        table_ptr: *const u8,
+        table_len: usize,
+        rowid: i64,
+    ),
+>;
+#[doc = " Commit or rollback hook callback. The callback must not use the connection."]
+pub type turso_transaction_hook_t = ::std::option::Option<unsafe extern "C" fn(context: usize)>;
+#[doc = " Authorizer callback. action is the SQLite action code (SQLITE_CREATE_INDEX = 1 ... SQLITE_SAVEPOINT = 32).\n Byte ranges are UTF-8 action arguments, database name and trigger name valid only for the call; null pointer stands for a missing value.\n Returns SQLITE_OK (0) to allow, SQLITE_DENY (1) to fail the statement preparation or SQLITE_IGNORE (2) to prepare it as a no-op.\n The callback must not use the connection."]
+pub type turso_authorizer_t = ::std::option::Option<
+    unsafe extern "C" fn(
+        context: usize,
+        action: i32,
+        arg1_ptr: *const u8,
+        arg1_len: usize,
+        arg2_ptr: *const u8,
+        arg2_len: usize,
+        database_ptr: *const u8,
+        database_len: usize,
+        trigger_ptr: *const u8,
+        trigger_len: usize,
+    ) -> i32,
+>;
 #[repr(u32)]
 #[derive(Debug, Copy, Clone, Hash, PartialEq, Eq)]
 pub enum turso_tracing_level_t {
@@ -269,6 +307,20 @@ pub struct turso_statement {
 }
 #[doc = " opaque pointer to the TursoStatement instance\n SAFETY: the statement must be used exclusive and can't be accessed concurrently"]
 pub type turso_statement_t = turso_statement;
+#[repr(C)]
+#[derive(Debug, Copy, Clone)]
+pub struct turso_blob {
+    _unused: [u8; 0],
+}
+#[doc = " opaque pointer to the TursoBlob instance (incremental BLOB I/O handle)\n SAFETY: the blob must be used exclusive and can't be accessed concurrently"]
+pub type turso_blob_t = turso_blob;
+#[repr(C)]
+#[derive(Debug, Copy, Clone)]
+pub struct turso_backup {
+    _unused: [u8; 0],
+}
+#[doc = " opaque pointer to the TursoBackup instance (online backup handle)\n SAFETY: the backup must be used exclusive and can't be accessed concurrently"]
+pub type turso_backup_t = turso_backup;
 unsafe extern "C" {
     pub fn turso_version() -> *const ::std::os::raw::c_char;
 }
@@ -348,6 +400,16 @@ unsafe extern "C" {
         error_opt_out: *mut *const ::std::os::raw::c_char,
     ) -> turso_status_code_t;
 }
+unsafe extern "C" {
+    #[doc = " Set flags used by turso_database_open to open the database file\n - read_only: open the file read-only (missing file is never created)\n - create: create the file if it doesn't exist (ignored with read_only)\n - no_lock: don't lock the file (for immutable databases which nobody modifies)\n Must be called before turso_database_open; by default the file is opened read-write and created if missing\n The process shares one database per file: connections of a read-only open are read-only even if the file\n is already open read-write, while a read-write open of a file already open read-only fails with TURSO_READONLY"]
+    pub fn turso_database_set_open_flags(
+        database: *const turso_database_t,
+        read_only: bool,
+        create: bool,
+        no_lock: bool,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
 unsafe extern "C" {
     #[doc = " Open database\n  Can return TURSO_IO result if async_io=true is set"]
     pub fn turso_database_open(
@@ -375,6 +437,47 @@ unsafe extern "C" {
     #[doc = " Get last insert rowid for the connection or 0 if no inserts happened before"]
     pub fn turso_connection_last_insert_rowid(self_: *const turso_connection_t) -> i64;
 }
+unsafe extern "C" {
+    #[doc = " Get number of rows modified, inserted or deleted by the most recently completed statement of the connection (mirrors sqlite3_changes64)"]
+    pub fn turso_connection_changes(self_: *const turso_connection_t) -> i64;
+}
+unsafe extern "C" {
+    #[doc = " Get total number of rows modified, inserted or deleted by statements of the connection since it was opened (mirrors sqlite3_total_changes64)"]
+    pub fn turso_connection_total_changes(self_: *const turso_connection_t) -> i64;
+}
+unsafe extern "C" {
+    #[doc = " Get cumulative metrics of the statements completed by the connection since it was opened:\n number of statements, VM instructions (as turso_statement_vm_steps), full scan steps, sort operations, rows read and rows written"]
+    pub fn turso_connection_metrics(
+        self_: *const turso_connection_t,
+        statements: *mut i64,
+        vm_steps: *mut i64,
+        fullscan_steps: *mut i64,
+        sort_operations: *mut i64,
+        rows_read: *mut i64,
+        rows_written: *mut i64,
+    );
+}
+unsafe extern "C" {
+    #[doc = " Get WAL position of the connection: the read mark of its last read transaction or the position after its last commit\n Positions are ordered lexicographically by (checkpoint_seq, max_frame); both values are set to their max if database has no WAL"]
+    pub fn turso_connection_wal_position(
+        self_: *const turso_connection_t,
+        checkpoint_seq: *mut u32,
+        max_frame: *mut u64,
+    );
+}
+unsafe extern "C" {
+    #[doc = " Get WAL position of the latest transaction committed to the main database by any connection\n The position is read within a short read transaction, so it must not be called inside a transaction;\n positions are ordered lexicographically by (checkpoint_seq, max_frame) as in turso_connection_wal_position"]
+    pub fn turso_connection_wal_commit_position(
+        self_: *const turso_connection_t,
+        checkpoint_seq: *mut u32,
+        max_frame: *mut u64,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
+unsafe extern "C" {
+    #[doc = " Interrupt the statement currently running on the connection (mirrors sqlite3_interrupt)\n The in-flight step/execute call returns TURSO_INTERRUPT; if no statement is active the request is ignored\n SAFETY: unlike other connection methods, this one can be called concurrently from another thread"]
+    pub fn turso_connection_interrupt(self_: *const turso_connection_t);
+}
 unsafe extern "C" {
     #[doc = " Register or replace a per-connection managed scalar function."]
     pub fn turso_connection_register_scalar_function(
@@ -389,6 +492,20 @@ unsafe extern "C" {
         error_opt_out: *mut *const ::std::os::raw::c_char,
     ) -> turso_status_code_t;
 }
+unsafe extern "C" {
+    #[doc = " Register or replace a per-connection managed scalar function which reports its result through an out-parameter.\n Semantic is identical to turso_connection_register_scalar_function; if registration fails context_destructor is not invoked"]
+    pub fn turso_connection_register_scalar_function_out(
+        self_: *const turso_connection_t,
+        name: *const ::std::os::raw::c_char,
+        argc: i32,
+        deterministic: bool,
+        context: usize,
+        callback: turso_scalar_function_out_t,
+        context_destructor: turso_context_destructor_t,
+        value_destructor: turso_value_destructor_t,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
 unsafe extern "C" {
     #[doc = " Register or replace a per-connection managed aggregate function."]
     pub fn turso_connection_register_aggregate_function(
@@ -432,6 +549,46 @@ unsafe extern "C" {
         error_opt_out: *mut *const ::std::os::raw::c_char,
     ) -> turso_status_code_t;
 }
+unsafe extern "C" {
+    #[doc = " Set the hook invoked after a row of a rowid table is inserted, updated or deleted.\n Null callback clears the hook. context_destructor is called once the hook is replaced, cleared or the connection is closed."]
+    pub fn turso_connection_set_update_hook(
+        self_: *const turso_connection_t,
+        context: usize,
+        callback: turso_update_hook_t,
+        context_destructor: turso_context_destructor_t,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
+unsafe extern "C" {
+    #[doc = " Set the hook invoked after a write transaction is committed (the hook can't turn the commit into a rollback).\n Null callback clears the hook. context_destructor is called once the hook is replaced, cleared or the connection is closed."]
+    pub fn turso_connection_set_commit_hook(
+        self_: *const turso_connection_t,
+        context: usize,
+        callback: turso_transaction_hook_t,
+        context_destructor: turso_context_destructor_t,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
+unsafe extern "C" {
+    #[doc = " Set the hook invoked after a write transaction is rolled back.\n Null callback clears the hook. context_destructor is called once the hook is replaced, cleared or the connection is closed."]
+    pub fn turso_connection_set_rollback_hook(
+        self_: *const turso_connection_t,
+        context: usize,
+        callback: turso_transaction_hook_t,
+        context_destructor: turso_context_destructor_t,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
+unsafe extern "C" {
+    #[doc = " Set the authorizer consulted for every access a statement makes when it is prepared on the connection.\n Null callback clears the authorizer. context_destructor is called once the authorizer is replaced, cleared or the connection is closed."]
+    pub fn turso_connection_set_authorizer(
+        self_: *const turso_connection_t,
+        context: usize,
+        callback: turso_authorizer_t,
+        context_destructor: turso_context_destructor_t,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
 unsafe extern "C" {
     #[doc = " Enable or disable SQL load_extension() for this connection."]
     pub fn turso_connection_enable_load_extension(
@@ -448,6 +605,34 @@ unsafe extern "C" {
         error_opt_out: *mut *const ::std::os::raw::c_char,
     ) -> turso_status_code_t;
 }
+unsafe extern "C" {
+    #[doc = " Re-encrypt the main database of the encrypted connection with the hex-encoded key in place\n The database is rewritten with the new key in a single transaction (as in-place VACUUM, which must be enabled with the experimental vacuum feature);\n other connections to the database must reopen with the new key"]
+    pub fn turso_connection_rekey(
+        self_: *const turso_connection_t,
+        hexkey: *const ::std::os::raw::c_char,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
+unsafe extern "C" {
+    #[doc = " Replace the content of the schema database (only main is supported) with the database file image (mirrors sqlite3_deserialize)\n The image is validated first and its pages are committed in a single transaction, so a failure leaves the database as it was"]
+    pub fn turso_connection_deserialize(
+        self_: *const turso_connection_t,
+        schema: *const ::std::os::raw::c_char,
+        ptr: *const ::std::os::raw::c_char,
+        len: usize,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
+unsafe extern "C" {
+    #[doc = " Return the content of the schema database (only main is supported) as a database file image (mirrors sqlite3_serialize)\n Pages are read in memory from a single read snapshot and nothing is written to disk; the image is empty (ptr is NULL) if the database has no pages\n The image allocated by Turso must be freed with turso_bytes_deinit"]
+    pub fn turso_connection_serialize(
+        self_: *const turso_connection_t,
+        schema: *const ::std::os::raw::c_char,
+        ptr: *mut *const ::std::os::raw::c_char,
+        len: *mut usize,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
 unsafe extern "C" {
     #[doc = " Prepare single statement in a connection"]
     pub fn turso_connection_prepare_single(
@@ -504,7 +689,7 @@ unsafe extern "C" {
     ) -> turso_status_code_t;
 }
 unsafe extern "C" {
-    #[doc = " Finalize a statement\n finalize returns TURSO_DONE if finalization completed\n This method must be called in the end of statement execution (either successfull or not)"]
+    #[doc = " Finalize a statement\n finalize returns TURSO_DONE if finalization completed\n This method must be called in the end of statement execution (either successfull or not)\n Pending changes of a write statement are applied by stepping it to completion,\n while a read-only statement is reset without producing its remaining rows"]
     pub fn turso_statement_finalize(
         self_: *const turso_statement_t,
         error_opt_out: *mut *const ::std::os::raw::c_char,
@@ -514,6 +699,22 @@ unsafe extern "C" {
     #[doc = " return amount of row modifications (insert/delete operations) made by the most recent executed statement"]
     pub fn turso_statement_n_change(self_: *const turso_statement_t) -> i64;
 }
+unsafe extern "C" {
+    #[doc = " return true if the most recent execution of the statement inserted a row and set the last insert rowid of the connection\n Rows inserted by triggers are not counted; the value is cleared by turso_statement_reset"]
+    pub fn turso_statement_inserted_rowid(self_: *const turso_statement_t) -> bool;
+}
+unsafe extern "C" {
+    #[doc = " return number of VM instructions executed by the statement (mirrors sqlite3_stmt_status with SQLITE_STMTSTATUS_VM_STEP)\n The counter accumulates over executions: it is not cleared by turso_statement_reset"]
+    pub fn turso_statement_vm_steps(self_: *const turso_statement_t) -> i64;
+}
+unsafe extern "C" {
+    #[doc = " return true if the statement makes no direct changes to the database (mirrors sqlite3_stmt_readonly)"]
+    pub fn turso_statement_readonly(self_: *const turso_statement_t) -> bool;
+}
+unsafe extern "C" {
+    #[doc = " return SQLite extended result code (e.g. SQLITE_CONSTRAINT_UNIQUE = 2067) of the constraint which failed the most recent step/execute\n Returns 0 if the statement wasn't halted by a constraint; the value is cleared by turso_statement_reset"]
+    pub fn turso_statement_extended_error_code(self_: *const turso_statement_t) -> i32;
+}
 unsafe extern "C" {
     #[doc = " Get column count"]
     pub fn turso_statement_column_count(self_: *const turso_statement_t) -> i64;
@@ -532,6 +733,10 @@ unsafe extern "C" {
         index: usize,
     ) -> *const ::std::os::raw::c_char;
 }
+unsafe extern "C" {
+    #[doc = " Get the nullability of the column at the index\n Returns 1 if the column can hold NULL, 0 if it is declared NOT NULL (or is a rowid alias)\n and -1 if nullability is unknown (e.g. the column is an expression and not a table column)"]
+    pub fn turso_statement_column_nullable(self_: *const turso_statement_t, index: usize) -> i32;
+}
 pub const turso_column_kind_t_TURSO_COLUMN_KIND_NONE: turso_column_kind_t = -1;
 pub const turso_column_kind_t_TURSO_COLUMN_KIND_BUILTIN: turso_column_kind_t = 0;
 pub const turso_column_kind_t_TURSO_COLUMN_KIND_CUSTOM: turso_column_kind_t = 1;
@@ -648,10 +853,78 @@ unsafe extern "C" {
         len: usize,
     ) -> turso_status_code_t;
 }
+unsafe extern "C" {
+    #[doc = " Open incremental I/O handle to the BLOB in the column of the row with given rowid (mirrors sqlite3_blob_open)\n Only the main database is supported"]
+    pub fn turso_connection_blob_open(
+        self_: *const turso_connection_t,
+        table: *const ::std::os::raw::c_char,
+        column: *const ::std::os::raw::c_char,
+        rowid: i64,
+        writable: bool,
+        blob: *mut *mut turso_blob_t,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
+unsafe extern "C" {
+    #[doc = " Get size of the BLOB in bytes (0 if the handle was closed)"]
+    pub fn turso_blob_bytes(self_: *const turso_blob_t) -> i64;
+}
+unsafe extern "C" {
+    #[doc = " Read len bytes of the BLOB starting at offset into ptr\n The range [offset, offset + len) must be within the BLOB size"]
+    pub fn turso_blob_read(
+        self_: *const turso_blob_t,
+        offset: usize,
+        ptr: *mut ::std::os::raw::c_char,
+        len: usize,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
+unsafe extern "C" {
+    #[doc = " Write len bytes from ptr into the BLOB starting at offset\n The BLOB can't be resized: the range [offset, offset + len) must be within the BLOB size"]
+    pub fn turso_blob_write(
+        self_: *const turso_blob_t,
+        offset: usize,
+        ptr: *const ::std::os::raw::c_char,
+        len: usize,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
+unsafe extern "C" {
+    #[doc = " Close the blob handle and commit its writes\n The handle is released even if error is returned; the caller still must deinit the blob"]
+    pub fn turso_blob_close(
+        self_: *const turso_blob_t,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
+unsafe extern "C" {
+    #[doc = " Start backup of the source_name database of source into the destination_name database of destination (mirrors sqlite3_backup_init)\n Only main databases are supported; the destination write transaction is held until the backup completes or is deinited"]
+    pub fn turso_backup_init(
+        destination: *const turso_connection_t,
+        destination_name: *const ::std::os::raw::c_char,
+        source: *const turso_connection_t,
+        source_name: *const ::std::os::raw::c_char,
+        backup: *mut *mut turso_backup_t,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
+unsafe extern "C" {
+    #[doc = " Copy up to pages pages (all pages if pages is negative) from the source to the destination (mirrors sqlite3_backup_step)\n Returns TURSO_DONE once the destination holds the complete copy and TURSO_OK if pages are left to copy\n The copy restarts from the first page if the source changed since the previous step"]
+    pub fn turso_backup_step(
+        self_: *const turso_backup_t,
+        pages: i32,
+        remaining: *mut i64,
+        total: *mut i64,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
 unsafe extern "C" {
     #[doc = " Deallocate C string allocated by Turso"]
     pub fn turso_str_deinit(self_: *const ::std::os::raw::c_char);
 }
+unsafe extern "C" {
+    #[doc = " Deallocate bytes allocated by Turso"]
+    pub fn turso_bytes_deinit(ptr: *const ::std::os::raw::c_char, len: usize);
+}
 unsafe extern "C" {
     #[doc = " Deallocate and close a database\n SAFETY: caller must ensure that no other code can concurrently or later call methods over deinited database"]
     pub fn turso_database_deinit(self_: *const turso_database_t);
@@ -664,3 +937,11 @@ unsafe extern "C" {
     #[doc = " Deallocate and close a statement\n SAFETY: caller must ensure that no other code can concurrently or later call methods over deinited statement"]
     pub fn turso_statement_deinit(self_: *const turso_statement_t);
 }
+unsafe extern "C" {
+    #[doc = " Deallocate and close a blob handle\n SAFETY: caller must ensure that no other code can concurrently or later call methods over deinited blob"]
+    pub fn turso_blob_deinit(self_: *const turso_blob_t);
+}
+unsafe extern "C" {
+    #[doc = " Deallocate a backup handle, rolling back the destination if the backup isn't complete (mirrors sqlite3_backup_finish)\n SAFETY: caller must ensure that no other code can concurrently or later call methods over deinited backup"]
+    pub fn turso_backup_deinit(self_: *const turso_backup_t);
+}
@@ -2,12 +2,12 @@
 
 use std::time::Duration;
 
-use turso_core::{types::AsValueRef, types::Text, IOResult};
+use turso_core::{types::AsValueRef, types::Text, IOResult, OpenFlags};
 use turso_sdk_kit_macros::signature;
 
 use crate::rsapi::{
     self, bytes_from_slice, c_string_to_str, str_from_c_str, str_from_slice, str_to_c_string,
-    TursoConnection, TursoDatabase, TursoStatement,
+    TursoBackup, TursoBlob, TursoConnection, TursoConnectionMetrics, TursoDatabase, TursoStatement,
 };
 
 pub mod c {
@@ -75,6 +75,34 @@ pub extern "C" fn turso_database_new(
     c::turso_status_code_t::TURSO_OK
 }
 
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_database_set_open_flags(
+    database: *const c::turso_database_t,
+    read_only: bool,
+    create: bool,
+    no_lock: bool,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let database = match unsafe { TursoDatabase::ref_from_capi(database) } {
+        Ok(database) => database,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    let mut flags = OpenFlags::None;
+    if read_only {
+        flags |= OpenFlags::ReadOnly;
+    } else if create {
+        flags |= OpenFlags::Create;
+    }
+    if no_lock {
+        flags |= OpenFlags::NoLock;
+    }
+    match database.set_open_flags(flags) {
+        Ok(()) => c::turso_status_code_t::TURSO_OK,
+        Err(err) => unsafe { err.to_capi(error_opt_out) },
+    }
+}
+
 #[no_mangle]
 #[signature(c)]
 pub extern "C" fn turso_database_open(
@@ -148,6 +176,100 @@ pub extern "C" fn turso_connection_last_insert_rowid(
     }
 }
 
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_connection_changes(connection: *const c::turso_connection_t) -> i64 {
+    match unsafe { TursoConnection::ref_from_capi(connection) } {
+        Ok(connection) => connection.changes(),
+        Err(_) => 0,
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_connection_total_changes(connection: *const c::turso_connection_t) -> i64 {
+    match unsafe { TursoConnection::ref_from_capi(connection) } {
+        Ok(connection) => connection.total_changes(),
+        Err(_) => 0,
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_connection_metrics(
+    connection: *const c::turso_connection_t,
+    statements: *mut i64,
+    vm_steps: *mut i64,
+    fullscan_steps: *mut i64,
+    sort_operations: *mut i64,
+    rows_read: *mut i64,
+    rows_written: *mut i64,
+) {
+    let metrics = match unsafe { TursoConnection::ref_from_capi(connection) } {
+        Ok(connection) => connection.metrics(),
+        Err(_) => TursoConnectionMetrics::default(),
+    };
+    let counter = |value: u64| value.min(i64::MAX as u64) as i64;
+    unsafe {
+        *statements = counter(metrics.statements);
+        *vm_steps = counter(metrics.vm_steps);
+        *fullscan_steps = counter(metrics.fullscan_steps);
+        *sort_operations = counter(metrics.sort_operations);
+        *rows_read = counter(metrics.rows_read);
+        *rows_written = counter(metrics.rows_written);
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_connection_wal_position(
+    connection: *const c::turso_connection_t,
+    checkpoint_seq: *mut u32,
+    max_frame: *mut u64,
+) {
+    let (seq, frame) = match unsafe { TursoConnection::ref_from_capi(connection) } {
+        Ok(connection) => connection.wal_position(),
+        Err(_) => (u32::MAX, u64::MAX),
+    };
+    unsafe {
+        *checkpoint_seq = seq;
+        *max_frame = frame;
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_connection_wal_commit_position(
+    connection: *const c::turso_connection_t,
+    checkpoint_seq: *mut u32,
+    max_frame: *mut u64,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let connection = match unsafe { TursoConnection::ref_from_capi(connection) } {
+        Ok(connection) => connection,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+
+    match connection.wal_commit_position() {
+        Ok((seq, frame)) => {
+            unsafe {
+                *checkpoint_seq = seq;
+                *max_frame = frame;
+            }
+            c::turso_status_code_t::TURSO_OK
+        }
+        Err(err) => unsafe { err.to_capi(error_opt_out) },
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_connection_interrupt(connection: *const c::turso_connection_t) {
+    if let Ok(connection) = unsafe { TursoConnection::ref_from_capi(connection) } {
+        connection.interrupt();
+    }
+}
+
 /// # Safety
 /// All pointers must be valid according to `turso.h`; callback function pointers must use the declared C ABI.
 #[no_mangle]
@@ -200,6 +322,92 @@ pub unsafe extern "C" fn turso_connection_register_scalar_function(
     }
 }
 
+/// State of a scalar function registered through `turso_connection_register_scalar_function_out`
+/// which adapts the out-parameter callback to the by-value callback ABI expected by the core
+struct OutScalarFunction {
+    callback: unsafe extern "C" fn(usize, i32, *const c::turso_value_t, *mut c::turso_value_t),
+    context: usize,
+    context_destructor: c::turso_context_destructor_t,
+}
+
+unsafe extern "C" fn out_scalar_function_call(
+    context: usize,
+    argc: i32,
+    argv: *const c::turso_value_t,
+    _context_destructor: c::turso_context_destructor_t,
+    _value_destructor: c::turso_value_destructor_t,
+) -> c::turso_value_t {
+    let function = unsafe { &*(context as *const OutScalarFunction) };
+    let mut result = c::turso_value_t::default();
+    unsafe { (function.callback)(function.context, argc, argv, &mut result) };
+    result
+}
+
+unsafe extern "C" fn out_scalar_function_destroy(context: usize) {
+    let function = unsafe { Box::from_raw(context as *mut OutScalarFunction) };
+    if let Some(destructor) = function.context_destructor {
+        unsafe { destructor(function.context) };
+    }
+}
+
+/// # Safety
+/// All pointers must be valid according to `turso.h`; callback function pointers must use the declared C ABI.
+#[no_mangle]
+#[signature(c)]
+pub unsafe extern "C" fn turso_connection_register_scalar_function_out(
+    connection: *const c::turso_connection_t,
+    name: *const std::ffi::c_char,
+    argc: i32,
+    deterministic: bool,
+    context: usize,
+    callback: c::turso_scalar_function_out_t,
+    context_destructor: c::turso_context_destructor_t,
+    value_destructor: c::turso_value_destructor_t,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let Some(callback) = callback else {
+        return unsafe {
+            rsapi::TursoError::Misuse("expected scalar callback, got null pointer".to_string())
+                .to_capi(error_opt_out)
+        };
+    };
+    let value_destructor = value_destructor.map(|destructor| {
+        std::mem::transmute::<CValueDestructor, turso_ext::ValueDestructor>(destructor)
+    });
+    let name = match unsafe { str_from_c_str(name) } {
+        Ok(name) => name,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    let connection = match unsafe { TursoConnection::ref_from_capi(connection) } {
+        Ok(connection) => connection,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+
+    let function = Box::into_raw(Box::new(OutScalarFunction {
+        callback,
+        context,
+        context_destructor,
+    }));
+    let trampoline =
+        std::mem::transmute::<CScalarFunction, turso_ext::ScalarFunction>(out_scalar_function_call);
+    match connection.register_external_scalar_function(
+        name.to_string(),
+        argc,
+        deterministic,
+        function as usize,
+        trampoline,
+        Some(out_scalar_function_destroy),
+        value_destructor,
+    ) {
+        Ok(()) => c::turso_status_code_t::TURSO_OK,
+        Err(err) => {
+            // registration failed: core didn't take ownership of the state
+            drop(unsafe { Box::from_raw(function) });
+            unsafe { err.to_capi(error_opt_out) }
+        }
+    }
+}
+
 /// # Safety
 /// All pointers must be valid according to `turso.h`; callback function pointers must use the declared C ABI.
 #[no_mangle]
@@ -338,6 +546,164 @@ pub extern "C" fn turso_connection_unregister_collation(
     c::turso_status_code_t::TURSO_OK
 }
 
+/// Context of the hook set through the C API, released with its destructor once the connection drops the hook
+struct HookContext {
+    context: usize,
+    destructor: c::turso_context_destructor_t,
+}
+
+impl HookContext {
+    // closures call the method (instead of reading the field) so they capture and keep alive the whole context
+    fn value(&self) -> usize {
+        self.context
+    }
+}
+
+impl Drop for HookContext {
+    fn drop(&mut self) {
+        if let Some(destructor) = self.destructor {
+            unsafe { destructor(self.context) };
+        }
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_connection_set_update_hook(
+    connection: *const c::turso_connection_t,
+    context: usize,
+    callback: c::turso_update_hook_t,
+    context_destructor: c::turso_context_destructor_t,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let connection = match unsafe { TursoConnection::ref_from_capi(connection) } {
+        Ok(connection) => connection,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    let context = HookContext {
+        context,
+        destructor: context_destructor,
+    };
+    let hook = callback.map(move |callback| -> turso_core::UpdateHookCallback {
+        Box::new(move |op, database, table, rowid| unsafe {
+            callback(
+                context.value(),
+                op as i32,
+                database.as_ptr(),
+                database.len(),
+                table.as_ptr(),
+                table.len(),
+                rowid,
+            )
+        })
+    });
+    connection.set_update_hook(hook);
+    c::turso_status_code_t::TURSO_OK
+}
+
+/// builds hook from the C transaction hook callback; context is released once the hook is dropped
+fn transaction_hook(
+    context: usize,
+    callback: c::turso_transaction_hook_t,
+    context_destructor: c::turso_context_destructor_t,
+) -> Option<turso_core::TransactionHookCallback> {
+    let context = HookContext {
+        context,
+        destructor: context_destructor,
+    };
+    callback.map(move |callback| -> turso_core::TransactionHookCallback {
+        Box::new(move || unsafe { callback(context.value()) })
+    })
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_connection_set_commit_hook(
+    connection: *const c::turso_connection_t,
+    context: usize,
+    callback: c::turso_transaction_hook_t,
+    context_destructor: c::turso_context_destructor_t,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let connection = match unsafe { TursoConnection::ref_from_capi(connection) } {
+        Ok(connection) => connection,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    connection.set_commit_hook(transaction_hook(context, callback, context_destructor));
+    c::turso_status_code_t::TURSO_OK
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_connection_set_rollback_hook(
+    connection: *const c::turso_connection_t,
+    context: usize,
+    callback: c::turso_transaction_hook_t,
+    context_destructor: c::turso_context_destructor_t,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let connection = match unsafe { TursoConnection::ref_from_capi(connection) } {
+        Ok(connection) => connection,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    connection.set_rollback_hook(transaction_hook(context, callback, context_destructor));
+    c::turso_status_code_t::TURSO_OK
+}
+
+/// splits optional string into the pointer and length passed to C callbacks (null pointer for None)
+fn optional_str_to_capi(value: Option<&str>) -> (*const u8, usize) {
+    value.map_or((std::ptr::null(), 0), |value| (value.as_ptr(), value.len()))
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_connection_set_authorizer(
+    connection: *const c::turso_connection_t,
+    context: usize,
+    callback: c::turso_authorizer_t,
+    context_destructor: c::turso_context_destructor_t,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let connection = match unsafe { TursoConnection::ref_from_capi(connection) } {
+        Ok(connection) => connection,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    let context = HookContext {
+        context,
+        destructor: context_destructor,
+    };
+    let authorizer = callback.map(move |callback| -> turso_core::AuthorizerCallback {
+        Box::new(move |action, arg1, arg2, database, trigger| {
+            let (arg1_ptr, arg1_len) = optional_str_to_capi(arg1);
+            let (arg2_ptr, arg2_len) = optional_str_to_capi(arg2);
+            let (database_ptr, database_len) = optional_str_to_capi(database);
+            let (trigger_ptr, trigger_len) = optional_str_to_capi(trigger);
+            let result = unsafe {
+                callback(
+                    context.value(),
+                    action as i32,
+                    arg1_ptr,
+                    arg1_len,
+                    arg2_ptr,
+                    arg2_len,
+                    database_ptr,
+                    database_len,
+                    trigger_ptr,
+                    trigger_len,
+                )
+            };
+            match result {
+                0 => turso_core::AuthorizerResult::Allow,
+                2 => turso_core::AuthorizerResult::Ignore,
+                // SQLite treats unknown codes as an error which fails the statement
+                _ => turso_core::AuthorizerResult::Deny,
+            }
+        })
+    });
+    connection.set_authorizer(authorizer);
+    c::turso_status_code_t::TURSO_OK
+}
+
 #[no_mangle]
 #[signature(c)]
 pub extern "C" fn turso_connection_enable_load_extension(
@@ -376,6 +742,92 @@ pub extern "C" fn turso_connection_load_extension(
     }
 }
 
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_connection_rekey(
+    connection: *const c::turso_connection_t,
+    hexkey: *const std::ffi::c_char,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let hexkey = match unsafe { str_from_c_str(hexkey) } {
+        Ok(hexkey) => hexkey,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    let connection = match unsafe { TursoConnection::ref_from_capi(connection) } {
+        Ok(connection) => connection,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+
+    match connection.rekey(hexkey) {
+        Ok(()) => c::turso_status_code_t::TURSO_OK,
+        Err(err) => unsafe { err.to_capi(error_opt_out) },
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_connection_deserialize(
+    connection: *const c::turso_connection_t,
+    schema: *const std::ffi::c_char,
+    ptr: *const std::ffi::c_char,
+    len: usize,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let schema = match unsafe { str_from_c_str(schema) } {
+        Ok(schema) => schema,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    let image = match unsafe { bytes_from_slice(ptr, len) } {
+        Ok(image) => image,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    let connection = match unsafe { TursoConnection::ref_from_capi(connection) } {
+        Ok(connection) => connection,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+
+    match connection.deserialize(schema, image) {
+        Ok(()) => c::turso_status_code_t::TURSO_OK,
+        Err(err) => unsafe { err.to_capi(error_opt_out) },
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_connection_serialize(
+    connection: *const c::turso_connection_t,
+    schema: *const std::ffi::c_char,
+    ptr: *mut *const std::ffi::c_char,
+    len: *mut usize,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let schema = match unsafe { str_from_c_str(schema) } {
+        Ok(schema) => schema,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    let connection = match unsafe { TursoConnection::ref_from_capi(connection) } {
+        Ok(connection) => connection,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+
+    match connection.serialize(schema) {
+        Ok(image) => {
+            let image_len = image.len();
+            let image_ptr = if image.is_empty() {
+                std::ptr::null()
+            } else {
+                Box::into_raw(image.into_boxed_slice()) as *mut u8 as *const std::ffi::c_char
+            };
+            unsafe {
+                *ptr = image_ptr;
+                *len = image_len;
+            }
+            c::turso_status_code_t::TURSO_OK
+        }
+        Err(err) => unsafe { err.to_capi(error_opt_out) },
+    }
+}
+
 #[no_mangle]
 #[signature(c)]
 pub extern "C" fn turso_connection_prepare_single(
@@ -549,6 +1001,48 @@ pub extern "C" fn turso_statement_n_change(statement: *const c::turso_statement_
     statement.n_change()
 }
 
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_statement_inserted_rowid(statement: *const c::turso_statement_t) -> bool {
+    let statement = match unsafe { TursoStatement::ref_from_capi(statement) } {
+        Ok(statement) => statement,
+        Err(_) => return false,
+    };
+    statement.inserted_rowid()
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_statement_vm_steps(statement: *const c::turso_statement_t) -> i64 {
+    let statement = match unsafe { TursoStatement::ref_from_capi(statement) } {
+        Ok(statement) => statement,
+        Err(_) => return 0,
+    };
+    statement.vm_steps()
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_statement_readonly(statement: *const c::turso_statement_t) -> bool {
+    let statement = match unsafe { TursoStatement::ref_from_capi(statement) } {
+        Ok(statement) => statement,
+        Err(_) => return true,
+    };
+    statement.readonly()
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_statement_extended_error_code(
+    statement: *const c::turso_statement_t,
+) -> i32 {
+    let statement = match unsafe { TursoStatement::ref_from_capi(statement) } {
+        Ok(statement) => statement,
+        Err(_) => return 0,
+    };
+    statement.extended_error_code()
+}
+
 #[no_mangle]
 #[signature(c)]
 pub extern "C" fn turso_statement_column_name(
@@ -592,6 +1086,26 @@ pub extern "C" fn turso_statement_column_decltype(
     }
 }
 
+/// Returns 1 if the column at `index` can hold NULL, 0 if it is declared NOT NULL
+/// (or is a rowid alias) and -1 if nullability is unknown (statement finalized,
+/// index out of bounds, or the result column is not a direct table-column ref).
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_statement_column_nullable(
+    statement: *const c::turso_statement_t,
+    index: usize,
+) -> i32 {
+    let statement = match unsafe { TursoStatement::ref_from_capi(statement) } {
+        Ok(statement) => statement,
+        Err(_) => return -1,
+    };
+    match statement.column_nullable(index) {
+        Some(true) => 1,
+        Some(false) => 0,
+        None => -1,
+    }
+}
+
 /// Sentinel value returned by `turso_statement_column_kind` when no type
 /// information is available (statement finalized, index out of bounds, or
 /// the result column is not a direct table-column reference).
@@ -977,6 +1491,184 @@ pub extern "C" fn turso_statement_bind_positional_blob(
     }
 }
 
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_connection_blob_open(
+    connection: *const c::turso_connection_t,
+    table: *const std::ffi::c_char,
+    column: *const std::ffi::c_char,
+    rowid: i64,
+    writable: bool,
+    blob: *mut *mut c::turso_blob_t,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let table = match unsafe { str_from_c_str(table) } {
+        Ok(table) => table,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    let column = match unsafe { str_from_c_str(column) } {
+        Ok(column) => column,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    let connection = match unsafe { TursoConnection::ref_from_capi(connection) } {
+        Ok(connection) => connection,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+
+    match connection.blob_open(table, column, rowid, writable) {
+        Ok(handle) => {
+            unsafe { *blob = handle.to_capi() };
+            c::turso_status_code_t::TURSO_OK
+        }
+        Err(err) => unsafe { err.to_capi(error_opt_out) },
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_blob_bytes(blob: *const c::turso_blob_t) -> i64 {
+    match unsafe { TursoBlob::ref_from_capi(blob) } {
+        Ok(blob) => blob.bytes() as i64,
+        Err(_) => 0,
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_blob_read(
+    blob: *const c::turso_blob_t,
+    offset: usize,
+    ptr: *mut std::ffi::c_char,
+    len: usize,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let blob = match unsafe { TursoBlob::ref_from_capi(blob) } {
+        Ok(blob) => blob,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    if len > 0 && ptr.is_null() {
+        return unsafe {
+            rsapi::TursoError::Misuse("expected slice, got null pointer".to_string())
+                .to_capi(error_opt_out)
+        };
+    }
+    let buf: &mut [u8] = if len == 0 {
+        &mut []
+    } else {
+        unsafe { std::slice::from_raw_parts_mut(ptr as *mut u8, len) }
+    };
+    match blob.read(offset, buf) {
+        Ok(()) => c::turso_status_code_t::TURSO_OK,
+        Err(err) => unsafe { err.to_capi(error_opt_out) },
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_blob_write(
+    blob: *const c::turso_blob_t,
+    offset: usize,
+    ptr: *const std::ffi::c_char,
+    len: usize,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let blob = match unsafe { TursoBlob::ref_from_capi(blob) } {
+        Ok(blob) => blob,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    let data = match unsafe { bytes_from_slice(ptr, len) } {
+        Ok(data) => data,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    match blob.write(offset, data) {
+        Ok(()) => c::turso_status_code_t::TURSO_OK,
+        Err(err) => unsafe { err.to_capi(error_opt_out) },
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_blob_close(
+    blob: *const c::turso_blob_t,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let blob = match unsafe { TursoBlob::ref_from_capi(blob) } {
+        Ok(blob) => blob,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    match blob.close() {
+        Ok(()) => c::turso_status_code_t::TURSO_OK,
+        Err(err) => unsafe { err.to_capi(error_opt_out) },
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_backup_init(
+    destination: *const c::turso_connection_t,
+    destination_name: *const std::ffi::c_char,
+    source: *const c::turso_connection_t,
+    source_name: *const std::ffi::c_char,
+    backup: *mut *mut c::turso_backup_t,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let destination_name = match unsafe { str_from_c_str(destination_name) } {
+        Ok(name) => name,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    let source_name = match unsafe { str_from_c_str(source_name) } {
+        Ok(name) => name,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    let destination = match unsafe { TursoConnection::ref_from_capi(destination) } {
+        Ok(connection) => connection,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    let source = match unsafe { TursoConnection::ref_from_capi(source) } {
+        Ok(connection) => connection,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+
+    match destination.backup_init(destination_name, source, source_name) {
+        Ok(handle) => {
+            unsafe { *backup = handle.to_capi() };
+            c::turso_status_code_t::TURSO_OK
+        }
+        Err(err) => unsafe { err.to_capi(error_opt_out) },
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_backup_step(
+    backup: *const c::turso_backup_t,
+    pages: i32,
+    remaining: *mut i64,
+    total: *mut i64,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> c::turso_status_code_t {
+    let backup = match unsafe { TursoBackup::ref_from_capi(backup) } {
+        Ok(backup) => backup,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    match backup.step(pages) {
+        Ok(progress) => {
+            if !remaining.is_null() {
+                unsafe { *remaining = progress.remaining as i64 };
+            }
+            if !total.is_null() {
+                unsafe { *total = progress.total as i64 };
+            }
+            if progress.done {
+                c::turso_status_code_t::TURSO_DONE
+            } else {
+                c::turso_status_code_t::TURSO_OK
+            }
+        }
+        Err(err) => unsafe { err.to_capi(error_opt_out) },
+    }
+}
+
 #[no_mangle]
 #[signature(c)]
 pub extern "C" fn turso_str_deinit(s: *const std::ffi::c_char) {
@@ -985,6 +1677,14 @@ pub extern "C" fn turso_str_deinit(s: *const std::ffi::c_char) {
     }
 }
 
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_bytes_deinit(ptr: *const std::ffi::c_char, len: usize) {
+    if !ptr.is_null() {
+        drop(unsafe { Box::from_raw(std::ptr::slice_from_raw_parts_mut(ptr as *mut u8, len)) });
+    }
+}
+
 #[no_mangle]
 #[signature(c)]
 pub extern "C" fn turso_database_deinit(database: *const c::turso_database_t) {
@@ -1009,6 +1709,22 @@ pub extern "C" fn turso_statement_deinit(statement: *const c::turso_statement_t)
     }
 }
 
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_blob_deinit(blob: *const c::turso_blob_t) {
+    if !blob.is_null() {
+        drop(unsafe { TursoBlob::box_from_capi(blob) })
+    }
+}
+
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_backup_deinit(backup: *const c::turso_backup_t) {
+    if !backup.is_null() {
+        drop(unsafe { TursoBackup::box_from_capi(backup) })
+    }
+}
+
 // used in tests for sdk-kit (db and sync)
 pub fn value_from_c_value(stmt: *mut c::turso_statement_t, index: usize) -> turso_core::Value {
     unsafe {
@@ -22,7 +22,7 @@ use tracing_subscriber::{
 use turso_core::{
     storage::database::DatabaseFile, types::AsValueRef, Connection, Database, DatabaseOpts,
     DatabaseStorage, EncryptionKey, IOResult, LimboError, OpenDbAsyncState, OpenFlags, OpenOptions,
-    QueryMode, Statement, StepResult, IO,
+    QueryMode, Statement, StatementStatusCounter, StepResult, IO,
 };
 
 use crate::{
@@ -380,6 +380,8 @@ pub struct TursoDatabaseOpenState {
     io: Option<Arc<dyn IO>>,
     db_file: Option<Arc<dyn DatabaseStorage>>,
     opts: Option<DatabaseOpts>,
+    /// flags requested with [TursoDatabase::set_open_flags] before the database is opened
+    requested_flags: OpenFlags,
     open_flags: OpenFlags,
     open_db_state: OpenDbAsyncState,
 }
@@ -397,6 +399,7 @@ impl TursoDatabaseOpenState {
             io: None,
             db_file: None,
             opts: None,
+            requested_flags: OpenFlags::default(),
             open_flags: OpenFlags::default(),
             open_db_state: OpenDbAsyncState::new(),
         }
@@ -520,9 +523,19 @@ impl From<LimboError> for TursoError {
             LimboError::BusySnapshot => TursoError::BusySnapshot(
                 "database snapshot is stale, rollback and retry the transaction".to_string(),
             ),
+            // conflicts of concurrent (MVCC) transactions are reported like BEGIN CONCURRENT of SQLite does
+            err @ (LimboError::WriteWriteConflict | LimboError::CommitDependencyAborted) => {
+                TursoError::BusySnapshot(err.to_string())
+            }
             LimboError::CompletionError(turso_core::CompletionError::IOError(kind, op)) => {
                 TursoError::IoError(kind, op)
             }
+            // page which fails to decrypt is read with a wrong key (SQLCipher reports SQLITE_NOTADB as well)
+            LimboError::CompletionError(turso_core::CompletionError::DecryptionError {
+                ..
+            }) => {
+                TursoError::NotAdb("file is not a database or encryption key is wrong".to_string())
+            }
             _ => TursoError::Error(value.to_string()),
         }
     }
@@ -669,6 +682,22 @@ impl TursoDatabase {
         })
     }
 
+    /// set flags used to open the database file (read-write with [OpenFlags::Create] by default)
+    /// flags must be set before the database is opened
+    ///
+    /// the process shares one database per file: a read-only open of the file opened read-write
+    /// gets read-only connections, while a read-write open of the file opened read-only fails
+    pub fn set_open_flags(&self, flags: OpenFlags) -> Result<(), TursoError> {
+        let mut state = self.open_state.lock().unwrap();
+        if !matches!(state.phase, TursoDatabaseOpenPhase::Init) {
+            return Err(TursoError::Misuse(
+                "open flags must be set before the database is opened".to_string(),
+            ));
+        }
+        state.requested_flags = flags;
+        Ok(())
+    }
+
     /// Get the config IO or open a new vfs IO from the config
     fn open_vfs_io(&self) -> Result<Arc<dyn turso_core::IO>, TursoError> {
         let io: Arc<dyn turso_core::IO + 'static> = if let Some(io) = &self.config.io {
@@ -759,7 +788,7 @@ impl TursoDatabase {
                         ));
                     }
 
-                    let mut open_flags = OpenFlags::default();
+                    let mut open_flags = state.requested_flags;
                     if opts.enable_multiprocess_wal {
                         open_flags |= OpenFlags::NoLock;
                     }
@@ -803,6 +832,13 @@ impl TursoDatabase {
                         &options,
                     )? {
                         IOResult::Done(db) => {
+                            // the process shares one database per file, so its mode is fixed by the first open
+                            if db.is_readonly() && !open_flags.contains(OpenFlags::ReadOnly) {
+                                return Err(TursoError::Readonly(format!(
+                                    "database {} is already opened read-only by this process",
+                                    self.config.path
+                                )));
+                            }
                             let mut inner_db = self.db.lock().unwrap();
                             *inner_db = Some(db);
                             state.phase = TursoDatabaseOpenPhase::Done;
@@ -846,6 +882,16 @@ impl TursoDatabase {
         // Use connect_with_encryption to properly set up encryption context
         // before the pager reads page 1. This is required for encrypted databases.
         let connection = db.connect_with_encryption(encryption_key)?;
+        // the database may be shared with read-write opens of the same file, so read-only mode is kept per connection
+        if self
+            .open_state
+            .lock()
+            .unwrap()
+            .requested_flags
+            .contains(OpenFlags::ReadOnly)
+        {
+            connection.set_read_only(true);
+        }
 
         Ok(TursoConnection::new(&self.config, connection))
     }
@@ -886,6 +932,17 @@ struct CachedStatement {
     query_mode: QueryMode,
 }
 
+/// cumulative metrics of the statements completed by a connection
+#[derive(Debug, Default, Clone, Copy)]
+pub struct TursoConnectionMetrics {
+    pub statements: u64,
+    pub vm_steps: u64,
+    pub fullscan_steps: u64,
+    pub sort_operations: u64,
+    pub rows_read: u64,
+    pub rows_written: u64,
+}
+
 #[derive(Clone)]
 pub struct TursoConnection {
     async_io: bool,
@@ -955,6 +1012,49 @@ impl TursoConnection {
     pub fn last_insert_rowid(&self) -> i64 {
         self.connection.last_insert_rowid()
     }
+    /// returns number of rows changed by the most recently completed statement of the connection
+    pub fn changes(&self) -> i64 {
+        self.connection.changes()
+    }
+    /// returns number of rows changed by the connection since it was opened
+    pub fn total_changes(&self) -> i64 {
+        self.connection.total_changes()
+    }
+    /// returns cumulative metrics of the statements completed by the connection since it was opened
+    pub fn metrics(&self) -> TursoConnectionMetrics {
+        let metrics = self.connection.metrics.read();
+        TursoConnectionMetrics {
+            statements: metrics.total_statements,
+            vm_steps: metrics.aggregate.insn_executed,
+            fullscan_steps: metrics.aggregate.fullscan_steps,
+            sort_operations: metrics.aggregate.sort_operations,
+            rows_read: metrics.aggregate.rows_read,
+            rows_written: metrics.aggregate.rows_written,
+        }
+    }
+    /// returns WAL position `(checkpoint_seq, max_frame)` of the connection: the read mark of its last read transaction
+    /// or the position after its last commit; positions are ordered lexicographically
+    pub fn wal_position(&self) -> (u32, u64) {
+        self.connection.wal_pos()
+    }
+
+    /// returns WAL position `(checkpoint_seq, max_frame)` of the latest transaction committed to the main database
+    /// by any connection; it's read within a short read transaction, so it can't be called inside a transaction
+    pub fn wal_commit_position(&self) -> Result<(u32, u64), TursoError> {
+        if self.sync_operation_active() {
+            return Err(sync_busy_error());
+        }
+        if !self.connection.get_auto_commit() {
+            return Err(TursoError::Misuse(
+                "cannot read committed WAL position within a transaction".to_string(),
+            ));
+        }
+        // the read mark installed by the read transaction is the position of the latest commit
+        self.connection.wal_read_begin()?;
+        let position = self.connection.wal_pos();
+        self.connection.wal_read_end();
+        Ok(position)
+    }
 
     #[allow(clippy::too_many_arguments)]
     pub fn register_external_scalar_function(
@@ -1053,6 +1153,26 @@ impl TursoConnection {
         self.connection.unregister_external_collation(name);
     }
 
+    /// set hook invoked after a row of a rowid table is inserted, updated or deleted (None clears it)
+    pub fn set_update_hook(&self, hook: Option<turso_core::UpdateHookCallback>) {
+        self.connection.set_update_hook(hook);
+    }
+
+    /// set hook invoked after a write transaction is committed (None clears it)
+    pub fn set_commit_hook(&self, hook: Option<turso_core::TransactionHookCallback>) {
+        self.connection.set_commit_hook(hook);
+    }
+
+    /// set hook invoked after a write transaction is rolled back (None clears it)
+    pub fn set_rollback_hook(&self, hook: Option<turso_core::TransactionHookCallback>) {
+        self.connection.set_rollback_hook(hook);
+    }
+
+    /// set authorizer consulted for every access a statement makes when it is prepared (None clears it)
+    pub fn set_authorizer(&self, authorizer: Option<turso_core::AuthorizerCallback>) {
+        self.connection.set_authorizer(authorizer);
+    }
+
     pub fn set_load_extension_enabled(&self, enabled: bool) {
         self.connection.set_load_extension_enabled(enabled);
     }
@@ -1063,6 +1183,161 @@ impl TursoConnection {
             .map_err(TursoError::from)
     }
 
+    /// Re-encrypt the main database with the hex-encoded key in place (see [turso_core::Connection::rekey])
+    pub fn rekey(&self, hexkey: &str) -> Result<(), TursoError> {
+        if self.sync_operation_active() {
+            return Err(sync_busy_error());
+        }
+        let key = EncryptionKey::from_hex_string(hexkey)?;
+        self.connection.rekey(key).map_err(TursoError::from)
+    }
+
+    /// Open an incremental I/O handle to the BLOB stored in `column` of the row with `rowid` in `table`
+    /// The handle keeps the connection alive until it is closed or dropped
+    pub fn blob_open(
+        &self,
+        table: &str,
+        column: &str,
+        rowid: i64,
+        writable: bool,
+    ) -> Result<Box<TursoBlob>, TursoError> {
+        if self.sync_operation_active() {
+            return Err(sync_busy_error());
+        }
+        let blob = self
+            .connection
+            .blob_open(table, column, rowid, writable)
+            .map_err(TursoError::from)
+            .map_err(|error| self.map_sync_transient_error(error))?;
+        Ok(Box::new(TursoBlob { blob: Some(blob) }))
+    }
+
+    /// Start a backup of the `source_name` database of `source` into the `destination_name` database of this connection
+    /// (mirrors `sqlite3_backup_init`), only the main databases are supported
+    /// The handle keeps both connections alive until it is dropped
+    pub fn backup_init(
+        &self,
+        destination_name: &str,
+        source: &TursoConnection,
+        source_name: &str,
+    ) -> Result<Box<TursoBackup>, TursoError> {
+        for name in [destination_name, source_name] {
+            if !name.eq_ignore_ascii_case("main") {
+                return Err(TursoError::Misuse(format!(
+                    "backup of the {name} database is not supported: only main database can be copied"
+                )));
+            }
+        }
+        if Arc::ptr_eq(&self.connection, &source.connection) {
+            return Err(TursoError::Misuse(
+                "source and destination must be distinct connections".to_string(),
+            ));
+        }
+        if self.sync_operation_active() || source.sync_operation_active() {
+            return Err(sync_busy_error());
+        }
+        Ok(Box::new(TursoBackup {
+            source: source.connection.clone(),
+            destination: self.connection.clone(),
+            snapshot: None,
+            total: 0,
+            next_page: 1,
+            next_frame: 0,
+            in_session: false,
+            finished: false,
+        }))
+    }
+
+    /// Replace the content of the `schema` database (only main is supported) with the database file `image`,
+    /// e.g. produced by `VACUUM INTO` (mirrors `sqlite3_deserialize`)
+    ///
+    /// The image is checked with `PRAGMA quick_check` in a scratch in-memory database first and then its pages
+    /// are appended to the WAL within a single write transaction, so readers see either the old content or the image
+    /// and a failure leaves the database as it was
+    pub fn deserialize(&self, schema: &str, image: &[u8]) -> Result<(), TursoError> {
+        if !schema.eq_ignore_ascii_case("main") {
+            return Err(TursoError::Misuse(format!(
+                "deserialize into the {schema} database is not supported: only main database can be replaced"
+            )));
+        }
+        if self.sync_operation_active() {
+            return Err(sync_busy_error());
+        }
+        if !self.connection.get_auto_commit() {
+            return Err(TursoError::Misuse(
+                "cannot deserialize within a transaction".to_string(),
+            ));
+        }
+        let page_size = database_image_page_size(image)?;
+        let scratch = Database::open(
+            Arc::new(turso_core::MemoryIO::new()),
+            ":memory:",
+            OpenOptions::new(Arc::new(SqliteDialect)),
+        )?
+        .connect()?;
+        scratch.reset_page_size(page_size as u32)?;
+        write_database_image(&scratch, image, page_size, 1)?;
+        let check = scratch.pragma_query("quick_check")?;
+        if check.len() != 1 || check[0].first().and_then(|value| value.to_text()) != Some("ok") {
+            return Err(TursoError::NotAdb(format!(
+                "database image is malformed: {}",
+                check
+                    .first()
+                    .and_then(|row| row.first())
+                    .map(|value| value.to_string())
+                    .unwrap_or_default()
+            )));
+        }
+
+        self.connection.reset_page_size(page_size as u32)?;
+        let destination_page_size = self.connection.get_page_size().get() as usize;
+        if destination_page_size != page_size {
+            return Err(TursoError::Misuse(format!(
+                "database page size {destination_page_size} differs from the image page size {page_size}"
+            )));
+        }
+        // the schema cookie must change, so every connection of the database reparses the schema of the image
+        let schema_cookie = self
+            .connection
+            .pragma_query("schema_version")?
+            .first()
+            .and_then(|row| row.first())
+            .and_then(|value| value.as_int())
+            .unwrap_or(0) as u32;
+        write_database_image(
+            &self.connection,
+            image,
+            page_size,
+            schema_cookie.wrapping_add(1),
+        )
+        .map_err(|error| self.map_sync_transient_error(error))
+    }
+
+    /// Return the content of the `schema` database (only main is supported) as a database file image
+    /// (mirrors `sqlite3_serialize`)
+    ///
+    /// Pages are read in memory from a single read snapshot, so the image is consistent and nothing is written to disk;
+    /// the image is empty if the database has no pages yet
+    pub fn serialize(&self, schema: &str) -> Result<Vec<u8>, TursoError> {
+        if !schema.eq_ignore_ascii_case("main") {
+            return Err(TursoError::Misuse(format!(
+                "serialize of the {schema} database is not supported: only main database can be serialized"
+            )));
+        }
+        if self.sync_operation_active() {
+            return Err(sync_busy_error());
+        }
+        if !self.connection.get_auto_commit() {
+            return Err(TursoError::Misuse(
+                "cannot serialize within a transaction".to_string(),
+            ));
+        }
+        self.connection.wal_read_begin()?;
+        let image = read_database_image(&self.connection);
+        self.connection.wal_read_end();
+        image.map_err(|error| self.map_sync_transient_error(error))
+    }
+
     /// prepares single SQL statement
     pub fn prepare_single(&self, sql: impl AsRef<str>) -> Result<Box<TursoStatement>, TursoError> {
         if self.sync_operation_active() {
@@ -1317,6 +1592,41 @@ impl TursoStatement {
             None => 0,
         }
     }
+    /// returns true if the most recent execution of the statement inserted a row and set the last insert rowid of the connection
+    pub fn inserted_rowid(&self) -> bool {
+        let handle = self.handle.lock().unwrap();
+        match handle.as_ref() {
+            Some(stmt) => stmt.inserted_rowid(),
+            None => false,
+        }
+    }
+    /// returns number of VM instructions executed by the statement; the counter is not cleared by reset
+    pub fn vm_steps(&self) -> i64 {
+        let handle = self.handle.lock().unwrap();
+        match handle.as_ref() {
+            Some(stmt) => {
+                let steps = stmt.stmt_status(StatementStatusCounter::VmStep);
+                steps.min(i64::MAX as u64) as i64
+            }
+            None => 0,
+        }
+    }
+    /// returns true if the statement makes no direct changes to the database (mirrors sqlite3_stmt_readonly)
+    pub fn readonly(&self) -> bool {
+        let handle = self.handle.lock().unwrap();
+        match handle.as_ref() {
+            Some(stmt) => stmt.get_program().is_readonly(),
+            None => true,
+        }
+    }
+    /// returns SQLite extended result code of the constraint which failed the most recent step/execute or 0
+    pub fn extended_error_code(&self) -> i32 {
+        let handle = self.handle.lock().unwrap();
+        match handle.as_ref() {
+            Some(stmt) => stmt.halt_error_code() as i32,
+            None => 0,
+        }
+    }
     /// returns parameters count for the statement
     pub fn parameters_count(&self) -> usize {
         let handle = self.handle.lock().unwrap();
@@ -1502,6 +1812,17 @@ impl TursoStatement {
         stmt.get_column_decltype(index)
     }
 
+    /// Returns whether the column at `index` can hold NULL, or `None` if unknown
+    /// (statement finalized, index out of bounds, or the column is not a table column).
+    pub fn column_nullable(&self, index: usize) -> Option<bool> {
+        let handle = self.handle.lock().unwrap();
+        let stmt = handle.as_ref()?;
+        if index >= stmt.num_columns() {
+            return None;
+        }
+        stmt.get_column_nullable(index)
+    }
+
     /// Returns rich type information for the column at `index`.
     ///
     /// Wraps [`turso_core::Statement::get_column_type_info`]. Returns `None`
@@ -1526,10 +1847,16 @@ impl TursoStatement {
         let _guard = guard.try_use()?;
         let mut handle = self.handle.lock().unwrap();
         if let Some(stmt) = handle.as_mut() {
-            while stmt.execution_state().is_running() {
-                let status = step_inner(stmt, self.async_io, waker)?;
-                if status == TursoStatusCode::Io {
-                    return Ok(status);
+            if stmt.get_program().is_readonly() {
+                // read-only statement has no changes to apply: abandon the remaining rows
+                // instead of stepping through them, so the read transaction ends right away
+                stmt.reset()?;
+            } else {
+                while stmt.execution_state().is_running() {
+                    let status = step_inner(stmt, self.async_io, waker)?;
+                    if status == TursoStatusCode::Io {
+                        return Ok(status);
+                    }
                 }
             }
         }
@@ -1579,10 +1906,340 @@ impl TursoStatement {
     }
 }
 
+const BLOB_CLOSED_ERR: &str = "blob handle has been closed";
+
+/// Incremental I/O handle to a single BLOB value (mirrors `sqlite3_blob_*` family of methods)
+/// The size of the value is fixed: reads and writes must stay within [0, bytes())
+pub struct TursoBlob {
+    blob: Option<turso_core::Blob>,
+}
+
+impl TursoBlob {
+    /// Size of the BLOB in bytes or 0 if the handle is closed
+    pub fn bytes(&self) -> usize {
+        self.blob.as_ref().map(|blob| blob.bytes()).unwrap_or(0)
+    }
+
+    /// Fill `buf` with bytes of the BLOB starting at `offset`
+    pub fn read(&mut self, offset: usize, buf: &mut [u8]) -> Result<(), TursoError> {
+        let blob = self
+            .blob
+            .as_mut()
+            .ok_or_else(|| TursoError::Misuse(BLOB_CLOSED_ERR.to_string()))?;
+        Ok(blob.read(offset, buf)?)
+    }
+
+    /// Overwrite bytes of the BLOB starting at `offset` with `data`
+    pub fn write(&mut self, offset: usize, data: &[u8]) -> Result<(), TursoError> {
+        let blob = self
+            .blob
+            .as_mut()
+            .ok_or_else(|| TursoError::Misuse(BLOB_CLOSED_ERR.to_string()))?;
+        Ok(blob.write(offset, data)?)
+    }
+
+    /// Close the handle and commit its writes; the handle is released even if error is returned
+    /// Closing already closed handle is no-op
+    pub fn close(&mut self) -> Result<(), TursoError> {
+        match self.blob.take() {
+            Some(blob) => Ok(blob.close()?),
+            None => Ok(()),
+        }
+    }
+
+    /// helper method to get C raw container to the TursoBlob instance
+    /// this method is used in the capi wrappers
+    pub fn to_capi(self: Box<Self>) -> *mut capi::c::turso_blob_t {
+        Box::into_raw(self) as *mut capi::c::turso_blob_t
+    }
+
+    /// helper method to restore TursoBlob ref from C raw container
+    /// this method is used in the capi wrappers
+    ///
+    /// # Safety
+    /// value must be a pointer returned from [Self::to_capi] method
+    pub unsafe fn ref_from_capi<'a>(
+        value: *const capi::c::turso_blob_t,
+    ) -> Result<&'a mut Self, TursoError> {
+        if value.is_null() {
+            Err(TursoError::Misuse("got null pointer".to_string()))
+        } else {
+            Ok(&mut *(value as *mut Self))
+        }
+    }
+
+    /// helper method to restore TursoBlob instance from C raw container
+    /// this method is used in the capi wrappers
+    ///
+    /// # Safety
+    /// value must be a pointer returned from [Self::to_capi] method
+    pub unsafe fn box_from_capi(value: *const capi::c::turso_blob_t) -> Box<Self> {
+        Box::from_raw(value as *mut Self)
+    }
+}
+
+const WAL_FRAME_HEADER: usize = 24;
+
+/// validate the header of the database file `image` and return its page size
+fn database_image_page_size(image: &[u8]) -> Result<usize, TursoError> {
+    if image.len() < 100 || &image[..16] != b"SQLite format 3\0" {
+        return Err(TursoError::NotAdb(
+            "database image has no database header".to_string(),
+        ));
+    }
+    // page size is stored at offset 16 of the database header, 1 stands for 65536
+    let page_size = match u16::from_be_bytes([image[16], image[17]]) {
+        1 => 65536,
+        size => size as usize,
+    };
+    if page_size < 512 || !page_size.is_power_of_two() || image.len() % page_size != 0 {
+        return Err(TursoError::NotAdb(
+            "database image is not a whole number of pages".to_string(),
+        ));
+    }
+    Ok(page_size)
+}
+
+/// read all pages of the database of `connection` within its active read session into a database file image
+fn read_database_image(connection: &Arc<Connection>) -> Result<Vec<u8>, TursoError> {
+    let page_size = connection.get_page_size().get() as usize;
+    let mut image = vec![0u8; page_size];
+    if !connection.try_wal_watermark_read_page(1, &mut image, None)? {
+        return Ok(Vec::new());
+    }
+    // database size in pages is stored at offset 28 of the database header
+    let total = u32::from_be_bytes(image[28..32].try_into().unwrap()) as usize;
+    image.resize(total.max(1) * page_size, 0);
+    for (page, content) in image.chunks_exact_mut(page_size).enumerate().skip(1) {
+        // an absent page (never written since the file was extended) is left as zeroes
+        connection.try_wal_watermark_read_page(page as u32 + 1, content, None)?;
+    }
+    Ok(image)
+}
+
+/// append the pages of the database file `image` to the WAL of `connection` and commit them at once,
+/// so the pages replace the database content; `schema_cookie` is stored into the header of the image
+fn write_database_image(
+    connection: &Arc<Connection>,
+    image: &[u8],
+    page_size: usize,
+    schema_cookie: u32,
+) -> Result<(), TursoError> {
+    let total = (image.len() / page_size) as u32;
+    connection.wal_insert_begin()?;
+    let write = || -> Result<(), TursoError> {
+        let mut next_frame = connection.wal_state()?.max_frame + 1;
+        let mut frame = vec![0u8; WAL_FRAME_HEADER + page_size];
+        for (page, content) in image.chunks_exact(page_size).enumerate() {
+            let page_no = page as u32 + 1;
+            frame[WAL_FRAME_HEADER..].copy_from_slice(content);
+            if page_no == 1 {
+                // database size in pages and schema cookie are stored at offsets 28 and 40 of the database header
+                let header = &mut frame[WAL_FRAME_HEADER..];
+                header[28..32].copy_from_slice(&total.to_be_bytes());
+                header[40..44].copy_from_slice(&schema_cookie.to_be_bytes());
+            }
+            let db_size = if page_no == total { total } else { 0 };
+            turso_core::types::WalFrameInfo { page_no, db_size }.put_to_frame_header(&mut frame);
+            connection.wal_insert_frame(next_frame, &frame)?;
+            next_frame += 1;
+        }
+        Ok(())
+    };
+    if let Err(error) = write() {
+        let _ = connection
+            .wal_insert_end(false)
+            .inspect_err(|e| tracing::error!("failed to roll back deserialize: {}", e));
+        return Err(error);
+    }
+    // the last frame carries the database size, so this commits the image
+    connection.wal_insert_end(true)?;
+    Ok(())
+}
+
+/// Progress of the backup after [TursoBackup::step]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct TursoBackupProgress {
+    /// pages which are left to copy
+    pub remaining: u32,
+    /// number of pages in the source snapshot being copied
+    pub total: u32,
+    /// true if the destination holds the complete copy of the source
+    pub done: bool,
+}
+
+/// Page-by-page copy of the database (mirrors `sqlite3_backup_*` family of methods)
+///
+/// Every step reads pages of the source within a read transaction, so it sees one consistent snapshot and
+/// doesn't block writers; pages are appended to the destination WAL within a single write transaction
+/// which is held until the copy completes and committed at once, so readers of the destination never see partial copy.
+/// If the source changed since the previous step (by any connection), the copy restarts from the first page.
+pub struct TursoBackup {
+    source: Arc<Connection>,
+    destination: Arc<Connection>,
+    /// source WAL snapshot the pages copied so far belong to
+    snapshot: Option<turso_core::types::WalState>,
+    total: u32,
+    next_page: u32,
+    next_frame: u64,
+    /// true if the write session on the destination is active
+    in_session: bool,
+    finished: bool,
+}
+
+impl TursoBackup {
+    /// Copy up to `pages` pages (all remaining pages if `pages` is negative)
+    pub fn step(&mut self, pages: i32) -> Result<TursoBackupProgress, TursoError> {
+        if self.finished {
+            return Ok(self.progress());
+        }
+        let snapshot = self.source.wal_read_begin()?;
+        let result = self.copy_pages(snapshot, pages);
+        self.source.wal_read_end();
+        if result.is_err() {
+            self.abort();
+        }
+        result
+    }
+
+    fn copy_pages(
+        &mut self,
+        snapshot: turso_core::types::WalState,
+        pages: i32,
+    ) -> Result<TursoBackupProgress, TursoError> {
+        let page_size = self.source.get_page_size().get() as usize;
+        if self.snapshot.as_ref() != Some(&snapshot) {
+            self.restart(snapshot, page_size)?;
+        }
+        let mut frame = vec![0u8; WAL_FRAME_HEADER + page_size];
+        let mut left = if pages < 0 { u32::MAX } else { pages as u32 };
+        while left > 0 && self.next_page <= self.total {
+            frame.fill(0);
+            // an absent page (never written since the file was extended) is copied as zeroes
+            self.source.try_wal_watermark_read_page(
+                self.next_page,
+                &mut frame[WAL_FRAME_HEADER..],
+                None,
+            )?;
+            let db_size = if self.next_page == self.total {
+                self.total
+            } else {
+                0
+            };
+            turso_core::types::WalFrameInfo {
+                page_no: self.next_page,
+                db_size,
+            }
+            .put_to_frame_header(&mut frame);
+            self.destination.wal_insert_frame(self.next_frame, &frame)?;
+            self.next_frame += 1;
+            self.next_page += 1;
+            left -= 1;
+        }
+        if self.next_page > self.total {
+            self.in_session = false;
+            // the last frame carries the database size, so this commits the copy
+            self.destination.wal_insert_end(self.total > 0)?;
+            self.finished = true;
+        }
+        Ok(self.progress())
+    }
+
+    /// start the copy of the source `snapshot` from the first page, dropping pages copied from the previous snapshot
+    fn restart(
+        &mut self,
+        snapshot: turso_core::types::WalState,
+        page_size: usize,
+    ) -> Result<(), TursoError> {
+        self.abort();
+        let destination_page_size = self.destination.get_page_size().get() as usize;
+        if destination_page_size != page_size {
+            return Err(TursoError::Readonly(format!(
+                "destination page size {destination_page_size} differs from the source page size {page_size}"
+            )));
+        }
+        let mut header = vec![0u8; page_size];
+        let has_header = self
+            .source
+            .try_wal_watermark_read_page(1, &mut header, None)?;
+        // database size in pages is stored at offset 28 of the database header
+        self.total = if has_header {
+            u32::from_be_bytes(header[28..32].try_into().unwrap())
+        } else {
+            0
+        };
+        self.destination.wal_insert_begin()?;
+        self.in_session = true;
+        self.next_frame = self.destination.wal_state()?.max_frame + 1;
+        self.next_page = 1;
+        self.snapshot = Some(snapshot);
+        Ok(())
+    }
+
+    /// roll back pages written to the destination so far
+    fn abort(&mut self) {
+        self.snapshot = None;
+        if self.in_session {
+            self.in_session = false;
+            let _ = self
+                .destination
+                .wal_insert_end(false)
+                .inspect_err(|e| tracing::error!("failed to roll back backup: {}", e));
+        }
+    }
+
+    fn progress(&self) -> TursoBackupProgress {
+        TursoBackupProgress {
+            remaining: (self.total + 1).saturating_sub(self.next_page),
+            total: self.total,
+            done: self.finished,
+        }
+    }
+
+    /// helper method to get C raw container to the TursoBackup instance
+    /// this method is used in the capi wrappers
+    pub fn to_capi(self: Box<Self>) -> *mut capi::c::turso_backup_t {
+        Box::into_raw(self) as *mut capi::c::turso_backup_t
+    }
+
+    /// helper method to restore TursoBackup ref from C raw container
+    /// this method is used in the capi wrappers
+    ///
+    /// # Safety
+    /// value must be a pointer returned from [Self::to_capi] method
+    pub unsafe fn ref_from_capi<'a>(
+        value: *const capi::c::turso_backup_t,
+    ) -> Result<&'a mut Self, TursoError> {
+        if value.is_null() {
+            Err(TursoError::Misuse("got null pointer".to_string()))
+        } else {
+            Ok(&mut *(value as *mut Self))
+        }
+    }
+
+    /// helper method to restore TursoBackup instance from C raw container
+    /// this method is used in the capi wrappers
+    ///
+    /// # Safety
+    /// value must be a pointer returned from [Self::to_capi] method
+    pub unsafe fn box_from_capi(value: *const capi::c::turso_backup_t) -> Box<Self> {
+        Box::from_raw(value as *mut Self)
+    }
+}
+
+impl Drop for TursoBackup {
+    fn drop(&mut self) {
+        self.abort();
+    }
+}
+
 #[cfg(test)]
 mod tests {
     use crate::{
-        rsapi::{TursoDatabase, TursoDatabaseConfig, TursoError, TursoStatusCode, FINALIZED_ERR},
+        rsapi::{
+            TursoConnection, TursoDatabase, TursoDatabaseConfig, TursoError, TursoStatusCode,
+            FINALIZED_ERR,
+        },
         IoBackend,
     };
     use turso_core::Value;
@@ -2379,4 +3036,125 @@ mod tests {
         assert_eq!(stmt.column_count(), 0);
         assert_eq!(stmt.parameters_count(), 0);
     }
+
+    #[test]
+    pub fn test_statement_inserted_rowid() {
+        let db = TursoDatabase::new(TursoDatabaseConfig {
+            path: ":memory:".to_string(),
+            experimental_features: None,
+            async_io: false,
+            encryption: None,
+            vfs: IoBackend::Default,
+            io: None,
+            db_file: None,
+        });
+        let result = db.open().unwrap();
+        assert!(!result.is_io());
+
+        let conn = db.connect().unwrap();
+        let inserted = |sql: &str| {
+            let mut stmt = conn.prepare_single(sql).unwrap();
+            assert_eq!(stmt.execute(None).unwrap().status, TursoStatusCode::Done);
+            let inserted = stmt.inserted_rowid();
+            stmt.finalize(None).unwrap();
+            inserted
+        };
+        assert!(!inserted(
+            "CREATE TABLE t (id INTEGER PRIMARY KEY, x TEXT UNIQUE)"
+        ));
+        assert!(inserted("INSERT INTO t VALUES (1, 'a')"));
+        assert!(!inserted("INSERT OR IGNORE INTO t VALUES (2, 'a')"));
+        assert!(!inserted("UPDATE t SET x = 'b' WHERE id = 1"));
+        assert_eq!(conn.last_insert_rowid(), 1);
+    }
+
+    #[test]
+    pub fn test_connection_deserialize() {
+        let open_memory = || {
+            let db = TursoDatabase::new(TursoDatabaseConfig {
+                path: ":memory:".to_string(),
+                experimental_features: None,
+                async_io: false,
+                encryption: None,
+                vfs: IoBackend::Default,
+                io: None,
+                db_file: None,
+            });
+            assert!(!db.open().unwrap().is_io());
+            db
+        };
+        let execute = |conn: &TursoConnection, sql: &str| {
+            let mut stmt = conn.prepare_single(sql).unwrap();
+            assert_eq!(stmt.execute(None).unwrap().status, TursoStatusCode::Done);
+            stmt.finalize(None).unwrap();
+        };
+        let count = |conn: &TursoConnection, sql: &str| {
+            let mut stmt = conn.prepare_single(sql).unwrap();
+            assert_eq!(stmt.step(None).unwrap(), TursoStatusCode::Row);
+            let count = stmt.row_value(0).unwrap().as_int();
+            stmt.finalize(None).unwrap();
+            count
+        };
+
+        let tmp_dir = tempfile::TempDir::new().unwrap();
+        let path = tmp_dir.path().join("image.db");
+        let source_db = open_memory();
+        let source = source_db.connect().unwrap();
+        execute(
+            &source,
+            "CREATE TABLE t (id INTEGER PRIMARY KEY, x TEXT UNIQUE)",
+        );
+        execute(&source, "INSERT INTO t VALUES (1, 'a'), (2, 'b'), (3, 'c')");
+        execute(&source, &format!("VACUUM INTO '{}'", path.display()));
+        let image = std::fs::read(&path).unwrap();
+
+        let db = open_memory();
+        let conn = db.connect().unwrap();
+        let other = db.connect().unwrap();
+        execute(&conn, "CREATE TABLE stale (x)");
+        execute(&conn, "INSERT INTO stale VALUES (1)");
+        conn.deserialize("main", &image).unwrap();
+        for conn in [&conn, &other] {
+            assert_eq!(
+                count(conn, "SELECT count(*) FROM t WHERE x IS NOT NULL"),
+                Some(3)
+            );
+            assert_eq!(
+                count(
+                    conn,
+                    "SELECT count(*) FROM sqlite_schema WHERE name = 'stale'"
+                ),
+                Some(0)
+            );
+        }
+
+        // malformed images are rejected and the database is left as it was
+        assert!(matches!(
+            conn.deserialize("main", b"definitely not a database"),
+            Err(TursoError::NotAdb(_))
+        ));
+        let mut corrupt = image.clone();
+        corrupt[100..].fill(0xff);
+        assert!(conn.deserialize("main", &corrupt).is_err());
+        assert!(matches!(
+            conn.deserialize("aux", &image),
+            Err(TursoError::Misuse(_))
+        ));
+        assert_eq!(count(&conn, "SELECT count(*) FROM t"), Some(3));
+
+        // serialized image of the database restores the same content
+        let serialized = conn.serialize("main").unwrap();
+        assert_eq!(
+            serialized.len() % conn.connection.get_page_size().get() as usize,
+            0
+        );
+        let copy_db = open_memory();
+        let copy = copy_db.connect().unwrap();
+        copy.deserialize("main", &serialized).unwrap();
+        assert_eq!(
+            count(&copy, "SELECT count(*) FROM t WHERE x IS NOT NULL"),
+            Some(3)
+        );
+        assert!(matches!(conn.serialize("aux"), Err(TursoError::Misuse(_))));
+    }
 }
@@ -137,6 +137,11 @@ typedef void (*turso_value_destructor_t)(turso_value_t *result);
 /** Scalar callback. argv points to argc immutable turso_value_t entries valid only for the call. */
 typedef turso_value_t (*turso_scalar_function_t)(uintptr_t context, int32_t argc, const turso_value_t *argv, turso_context_destructor_t context_destructor, turso_value_destructor_t value_destructor);
 
+/** Scalar callback which writes its result into result (initialized to NULL) instead of returning it by value.
+ * Useful for FFI runtimes which can't return structs from callbacks. argv points to argc immutable turso_value_t entries valid only for the call.
+ */
+typedef void (*turso_scalar_function_out_t)(uintptr_t context, int32_t argc, const turso_value_t *argv, turso_value_t *result);
+
 /** Aggregate initializer. Return value is passed unchanged to step/final/destructor callbacks. */
 typedef turso_agg_ctx_t *(*turso_aggregate_init_function_t)(uintptr_t context);
 
@@ -149,6 +154,19 @@ typedef turso_value_t (*turso_aggregate_final_function_t)(uintptr_t context, tur
 /** Collation callback. Byte ranges are UTF-8 text valid only for the call. Return follows strcmp ordering. */
 typedef int32_t (*turso_collation_function_t)(uintptr_t context, const uint8_t *left_ptr, size_t left_len, const uint8_t *right_ptr, size_t right_len);
 
+/** Update hook callback. op is the SQLite action code (SQLITE_INSERT = 18, SQLITE_UPDATE = 23, SQLITE_DELETE = 9).
+ * Byte ranges are UTF-8 database and table names valid only for the call. The callback must not use the connection. */
+typedef void (*turso_update_hook_t)(uintptr_t context, int32_t op, const uint8_t *database_ptr, size_t database_len, const uint8_t *table_ptr, size_t table_len, int64_t rowid);
+
+/** Commit or rollback hook callback. The callback must not use the connection. */
+typedef void (*turso_transaction_hook_t)(uintptr_t context);
+
+/** Authorizer callback. action is the SQLite action code (SQLITE_CREATE_INDEX = 1 ... SQLITE_SAVEPOINT = 32).
+ * Byte ranges are UTF-8 action arguments, database name and trigger name valid only for the call; null pointer stands for a missing value.
+ * Returns SQLITE_OK (0) to allow, SQLITE_DENY (1) to fail the statement preparation or SQLITE_IGNORE (2) to prepare it as a no-op.
+ * The callback must not use the connection. */
+typedef int32_t (*turso_authorizer_t)(uintptr_t context, int32_t action, const uint8_t *arg1_ptr, size_t arg1_len, const uint8_t *arg2_ptr, size_t arg2_len, const uint8_t *database_ptr, size_t database_len, const uint8_t *trigger_ptr, size_t trigger_len);
+
 typedef enum
 {
     TURSO_TRACING_LEVEL_ERROR = 1,
@@ -170,6 +188,14 @@ typedef struct turso_connection turso_connection_t;
 /// SAFETY: the statement must be used exclusive and can't be accessed concurrently
 typedef struct turso_statement turso_statement_t;
 
+/// opaque pointer to the TursoBlob instance (incremental BLOB I/O handle)
+/// SAFETY: the blob must be used exclusive and can't be accessed concurrently
+typedef struct turso_blob turso_blob_t;
+
+/// opaque pointer to the TursoBackup instance (online backup handle)
+/// SAFETY: the backup must be used exclusive and can't be accessed concurrently
+typedef struct turso_backup turso_backup_t;
+
 // return STATIC zero-terminated C-string with turso version (sem-ver string e.g. x.y.z-...)
 // (this string DO NOT need to be deallocated as it static)
 const char *turso_version();
@@ -243,6 +269,22 @@ turso_status_code_t turso_database_new(
     /** Optional return error parameter (can be null) */
     const char **error_opt_out);
 
+/** Set flags used by turso_database_open to open the database file
+ * - read_only: open the file read-only (missing file is never created)
+ * - create: create the file if it doesn't exist (ignored with read_only)
+ * - no_lock: don't lock the file (for immutable databases which nobody modifies)
+ * Must be called before turso_database_open; by default the file is opened read-write and created if missing
+ * The process shares one database per file: connections of a read-only open are read-only even if the file
+ * is already open read-write, while a read-write open of a file already open read-only fails with TURSO_READONLY
+ */
+turso_status_code_t turso_database_set_open_flags(
+    const turso_database_t *database,
+    bool read_only,
+    bool create,
+    bool no_lock,
+    /** Optional return error parameter (can be null) */
+    const char **error_opt_out);
+
 /** Open database
  *  Can return TURSO_IO result if async_io=true is set
  */
@@ -268,6 +310,49 @@ bool turso_connection_get_autocommit(const turso_connection_t *self);
 /** Get last insert rowid for the connection or 0 if no inserts happened before */
 int64_t turso_connection_last_insert_rowid(const turso_connection_t *self);
 
+/** Get number of rows modified, inserted or deleted by the most recently completed statement of the connection (mirrors sqlite3_changes64) */
+int64_t turso_connection_changes(const turso_connection_t *self);
+
+/** Get total number of rows modified, inserted or deleted by statements of the connection since it was opened (mirrors sqlite3_total_changes64) */
+int64_t turso_connection_total_changes(const turso_connection_t *self);
+
+/** Get cumulative metrics of the statements completed by the connection since it was opened:
+ * number of statements, VM instructions (as turso_statement_vm_steps), full scan steps, sort operations, rows read and rows written
+ */
+void turso_connection_metrics(
+    const turso_connection_t *self,
+    int64_t *statements,
+    int64_t *vm_steps,
+    int64_t *fullscan_steps,
+    int64_t *sort_operations,
+    int64_t *rows_read,
+    int64_t *rows_written);
+
+/** Get WAL position of the connection: the read mark of its last read transaction or the position after its last commit
+ * Positions are ordered lexicographically by (checkpoint_seq, max_frame); both values are set to their max if database has no WAL
+ */
+void turso_connection_wal_position(
+    const turso_connection_t *self,
+    uint32_t *checkpoint_seq,
+    uint64_t *max_frame);
+
+/** Get WAL position of the latest transaction committed to the main database by any connection
+ * The position is read within a short read transaction, so it must not be called inside a transaction;
+ * positions are ordered lexicographically by (checkpoint_seq, max_frame) as in turso_connection_wal_position
+ */
+turso_status_code_t turso_connection_wal_commit_position(
+    const turso_connection_t *self,
+    uint32_t *checkpoint_seq,
+    uint64_t *max_frame,
+    /** Optional return error parameter (can be null) */
+    const char **error_opt_out);
+
+/** Interrupt the statement currently running on the connection (mirrors sqlite3_interrupt)
+ * The in-flight step/execute call returns TURSO_INTERRUPT; if no statement is active the request is ignored
+ * SAFETY: unlike other connection methods, this one can be called concurrently from another thread
+ */
+void turso_connection_interrupt(const turso_connection_t *self);
+
 
 /** Register or replace a per-connection managed scalar function. */
 turso_status_code_t turso_connection_register_scalar_function(
@@ -281,6 +366,20 @@ turso_status_code_t turso_connection_register_scalar_function(
     turso_value_destructor_t value_destructor,
     const char **error_opt_out);
 
+/** Register or replace a per-connection managed scalar function which reports its result through an out-parameter.
+ * Semantic is identical to turso_connection_register_scalar_function; if registration fails context_destructor is not invoked
+ */
+turso_status_code_t turso_connection_register_scalar_function_out(
+    const turso_connection_t *self,
+    const char *name,
+    int32_t argc,
+    bool deterministic,
+    uintptr_t context,
+    turso_scalar_function_out_t callback,
+    turso_context_destructor_t context_destructor,
+    turso_value_destructor_t value_destructor,
+    const char **error_opt_out);
+
 /** Register or replace a per-connection managed aggregate function. */
 turso_status_code_t turso_connection_register_aggregate_function(
     const turso_connection_t *self,
@@ -316,6 +415,42 @@ turso_status_code_t turso_connection_unregister_collation(
     const char *name,
     const char **error_opt_out);
 
+/** Set the hook invoked after a row of a rowid table is inserted, updated or deleted.
+ * Null callback clears the hook. context_destructor is called once the hook is replaced, cleared or the connection is closed. */
+turso_status_code_t turso_connection_set_update_hook(
+    const turso_connection_t *self,
+    uintptr_t context,
+    turso_update_hook_t callback,
+    turso_context_destructor_t context_destructor,
+    const char **error_opt_out);
+
+/** Set the hook invoked after a write transaction is committed (the hook can't turn the commit into a rollback).
+ * Null callback clears the hook. context_destructor is called once the hook is replaced, cleared or the connection is closed. */
+turso_status_code_t turso_connection_set_commit_hook(
+    const turso_connection_t *self,
+    uintptr_t context,
+    turso_transaction_hook_t callback,
+    turso_context_destructor_t context_destructor,
+    const char **error_opt_out);
+
+/** Set the hook invoked after a write transaction is rolled back.
+ * Null callback clears the hook. context_destructor is called once the hook is replaced, cleared or the connection is closed. */
+turso_status_code_t turso_connection_set_rollback_hook(
+    const turso_connection_t *self,
+    uintptr_t context,
+    turso_transaction_hook_t callback,
+    turso_context_destructor_t context_destructor,
+    const char **error_opt_out);
+
+/** Set the authorizer consulted for every access a statement makes when it is prepared on the connection.
+ * Null callback clears the authorizer. context_destructor is called once the authorizer is replaced, cleared or the connection is closed. */
+turso_status_code_t turso_connection_set_authorizer(
+    const turso_connection_t *self,
+    uintptr_t context,
+    turso_authorizer_t callback,
+    turso_context_destructor_t context_destructor,
+    const char **error_opt_out);
+
 /** Enable or disable SQL load_extension() for this connection. */
 turso_status_code_t turso_connection_enable_load_extension(
     const turso_connection_t *self,
@@ -328,6 +463,42 @@ turso_status_code_t turso_connection_load_extension(
     const char *path,
     const char **error_opt_out);
 
+/** Re-encrypt the main database of the encrypted connection with the hex-encoded key in place
+ * The database is rewritten with the new key in a single transaction (as in-place VACUUM, which must be enabled with the experimental vacuum feature);
+ * other connections to the database must reopen with the new key
+ */
+turso_status_code_t turso_connection_rekey(
+    const turso_connection_t *self,
+    /* zero-terminated C string */
+    const char *hexkey,
+    const char **error_opt_out);
+
+/** Replace the content of the schema database (only main is supported) with the database file image (mirrors sqlite3_deserialize)
+ * The image is validated first and its pages are committed in a single transaction, so a failure leaves the database as it was
+ */
+turso_status_code_t turso_connection_deserialize(
+    const turso_connection_t *self,
+    /* zero-terminated C string */
+    const char *schema,
+    const char *ptr,
+    size_t len,
+    /** Optional return error parameter (can be null) */
+    const char **error_opt_out);
+
+/** Return the content of the schema database (only main is supported) as a database file image (mirrors sqlite3_serialize)
+ * Pages are read in memory from a single read snapshot and nothing is written to disk; the image is empty (ptr is NULL) if the database has no pages
+ * The image allocated by Turso must be freed with turso_bytes_deinit
+ */
+turso_status_code_t turso_connection_serialize(
+    const turso_connection_t *self,
+    /* zero-terminated C string */
+    const char *schema,
+    /** image of the database */
+    const char **ptr,
+    size_t *len,
+    /** Optional return error parameter (can be null) */
+    const char **error_opt_out);
+
 /** Prepare single statement in a connection */
 turso_status_code_t
 turso_connection_prepare_single(
@@ -392,12 +563,32 @@ turso_status_code_t turso_statement_reset(const turso_statement_t *self, const c
 /** Finalize a statement
  * finalize returns TURSO_DONE if finalization completed
  * This method must be called in the end of statement execution (either successfull or not)
+ * Pending changes of a write statement are applied by stepping it to completion,
+ * while a read-only statement is reset without producing its remaining rows
  */
 turso_status_code_t turso_statement_finalize(const turso_statement_t *self, const char **error_opt_out);
 
 /** return amount of row modifications (insert/delete operations) made by the most recent executed statement */
 int64_t turso_statement_n_change(const turso_statement_t *self);
 
+/** return true if the most recent execution of the statement inserted a row and set the last insert rowid of the connection
+ * Rows inserted by triggers are not counted; the value is cleared by turso_statement_reset
+ */
+bool turso_statement_inserted_rowid(const turso_statement_t *self);
+
+/** return number of VM instructions executed by the statement (mirrors sqlite3_stmt_status with SQLITE_STMTSTATUS_VM_STEP)
+ * The counter accumulates over executions: it is not cleared by turso_statement_reset
+ */
+int64_t turso_statement_vm_steps(const turso_statement_t *self);
+
+/** return true if the statement makes no direct changes to the database (mirrors sqlite3_stmt_readonly) */
+bool turso_statement_readonly(const turso_statement_t *self);
+
+/** return SQLite extended result code (e.g. SQLITE_CONSTRAINT_UNIQUE = 2067) of the constraint which failed the most recent step/execute
+ * Returns 0 if the statement wasn't halted by a constraint; the value is cleared by turso_statement_reset
+ */
+int32_t turso_statement_extended_error_code(const turso_statement_t *self);
+
 /** Get column count */
 int64_t turso_statement_column_count(const turso_statement_t *self);
 
@@ -412,6 +603,12 @@ const char *turso_statement_column_name(const turso_statement_t *self, size_t in
  */
 const char *turso_statement_column_decltype(const turso_statement_t *self, size_t index);
 
+/** Get the nullability of the column at the index
+ * Returns 1 if the column can hold NULL, 0 if it is declared NOT NULL (or is a rowid alias)
+ * and -1 if nullability is unknown (e.g. the column is an expression and not a table column)
+ */
+int32_t turso_statement_column_nullable(const turso_statement_t *self, size_t index);
+
 /** Classification of a result column's declared type.
  *
  * Returned by turso_statement_column_kind. Values match the
@@ -536,8 +733,88 @@ turso_statement_bind_positional_text(
     /* length of TEXT slice */
     size_t len);
 
+/** Open incremental I/O handle to the BLOB in the column of the row with given rowid (mirrors sqlite3_blob_open)
+ * Only the main database is supported
+ */
+turso_status_code_t turso_connection_blob_open(
+    const turso_connection_t *self,
+    /* zero-terminated C string */
+    const char *table,
+    /* zero-terminated C string */
+    const char *column,
+    int64_t rowid,
+    bool writable,
+    /** reference to pointer which will be set to blob instance in case of TURSO_OK result */
+    turso_blob_t **blob,
+    /** Optional return error parameter (can be null) */
+    const char **error_opt_out);
+
+/** Get size of the BLOB in bytes (0 if the handle was closed) */
+int64_t turso_blob_bytes(const turso_blob_t *self);
+
+/** Read len bytes of the BLOB starting at offset into ptr
+ * The range [offset, offset + len) must be within the BLOB size
+ */
+turso_status_code_t turso_blob_read(
+    const turso_blob_t *self,
+    size_t offset,
+    char *ptr,
+    size_t len,
+    /** Optional return error parameter (can be null) */
+    const char **error_opt_out);
+
+/** Write len bytes from ptr into the BLOB starting at offset
+ * The BLOB can't be resized: the range [offset, offset + len) must be within the BLOB size
+ */
+turso_status_code_t turso_blob_write(
+    const turso_blob_t *self,
+    size_t offset,
+    const char *ptr,
+    size_t len,
+    /** Optional return error parameter (can be null) */
+    const char **error_opt_out);
+
+/** Close the blob handle and commit its writes
+ * The handle is released even if error is returned; the caller still must deinit the blob
+ */
+turso_status_code_t turso_blob_close(
+    const turso_blob_t *self,
+    /** Optional return error parameter (can be null) */
+    const char **error_opt_out);
+
+/** Start backup of the source_name database of source into the destination_name database of destination (mirrors sqlite3_backup_init)
+ * Only main databases are supported; the destination write transaction is held until the backup completes or is deinited
+ */
+turso_status_code_t turso_backup_init(
+    const turso_connection_t *destination,
+    /* zero-terminated C string */
+    const char *destination_name,
+    const turso_connection_t *source,
+    /* zero-terminated C string */
+    const char *source_name,
+    /** reference to pointer which will be set to backup instance in case of TURSO_OK result */
+    turso_backup_t **backup,
+    /** Optional return error parameter (can be null) */
+    const char **error_opt_out);
+
+/** Copy up to pages pages (all pages if pages is negative) from the source to the destination (mirrors sqlite3_backup_step)
+ * Returns TURSO_DONE once the destination holds the complete copy and TURSO_OK if pages are left to copy
+ * The copy restarts from the first page if the source changed since the previous step
+ */
+turso_status_code_t turso_backup_step(
+    const turso_backup_t *self,
+    int32_t pages,
+    /** number of pages left to copy */
+    int64_t *remaining,
+    /** number of pages in the source */
+    int64_t *total,
+    /** Optional return error parameter (can be null) */
+    const char **error_opt_out);
+
 /** Deallocate C string allocated by Turso */
 void turso_str_deinit(const char *self);
+/** Deallocate bytes allocated by Turso */
+void turso_bytes_deinit(const char *ptr, size_t len);
 /** Deallocate and close a database
  * SAFETY: caller must ensure that no other code can concurrently or later call methods over deinited database
  */
@@ -550,5 +827,13 @@ void turso_connection_deinit(const turso_connection_t *self);
  * SAFETY: caller must ensure that no other code can concurrently or later call methods over deinited statement
  */
 void turso_statement_deinit(const turso_statement_t *self);
+/** Deallocate and close a blob handle
+ * SAFETY: caller must ensure that no other code can concurrently or later call methods over deinited blob
+ */
+void turso_blob_deinit(const turso_blob_t *self);
+/** Deallocate a backup handle, rolling back the destination if the backup isn't complete (mirrors sqlite3_backup_finish)
+ * SAFETY: caller must ensure that no other code can concurrently or later call methods over deinited backup
+ */
+void turso_backup_deinit(const turso_backup_t *self);
 
 #endif /* TURSO_H */
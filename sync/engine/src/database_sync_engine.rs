@@ -1,7 +1,7 @@
 use std::{
     collections::{BTreeMap, HashMap, HashSet},
     sync::{
-        atomic::{AtomicUsize, Ordering},
+        atomic::{AtomicU64, AtomicUsize, Ordering},
         Arc, Mutex,
     },
 };
@@ -96,6 +96,23 @@ impl Default for DataStats {
     }
 }
 
+/// Progress counters of sync operations, cumulative over the lifetime of the sync engine IO.
+/// Progress of a single operation is the difference of the counters before and after it.
+pub struct SyncProgressStats {
+    /// pages received from the remote by pull and bootstrap page streams
+    pub pulled_pages: AtomicU64,
+    /// size of the remote database in pages announced by the latest page stream
+    pub remote_db_pages: AtomicU64,
+    /// local changes sent to the remote by push
+    pub pushed_changes: AtomicU64,
+}
+
+impl Default for SyncProgressStats {
+    fn default() -> Self {
+        Self::new()
+    }
+}
+
 #[cfg(test)]
 mod tests {
     use super::{
@@ -1467,6 +1484,25 @@ impl DataStats {
     }
 }
 
+impl SyncProgressStats {
+    pub fn new() -> Self {
+        Self {
+            pulled_pages: AtomicU64::new(0),
+            remote_db_pages: AtomicU64::new(0),
+            pushed_changes: AtomicU64::new(0),
+        }
+    }
+    pub fn page_stream(&self, remote_db_pages: u64) {
+        self.remote_db_pages.store(remote_db_pages, Ordering::SeqCst);
+    }
+    pub fn page_pulled(&self) {
+        self.pulled_pages.fetch_add(1, Ordering::SeqCst);
+    }
+    pub fn changes_pushed(&self, changes: usize) {
+        self.pushed_changes.fetch_add(changes as u64, Ordering::SeqCst);
+    }
+}
+
 pub struct DatabaseSyncEngine<IO: SyncEngineIo> {
     io: Arc<dyn turso_core::IO>,
     sync_engine_io: SyncEngineIoStats<IO>,
@@ -2718,7 +2754,8 @@ impl<IO: SyncEngineIo> DatabaseSyncEngine<IO> {
         let main_conn = connect_untracked(&self.main_tape)?;
         let change_id = self.meta().last_pushed_change_id_hint;
         let last_pull_unix_time = self.meta().last_pull_unix_time;
-        let revision = self.meta().synced_revision.clone().map(|x| match x {
+        let synced_revision = self.meta().synced_revision.clone();
+        let revision = synced_revision.clone().map(|x| match x {
             DatabasePullRevision::Legacy {
                 generation,
                 synced_frame_no,
@@ -2742,6 +2779,7 @@ impl<IO: SyncEngineIo> DatabaseSyncEngine<IO> {
             last_pull_unix_time,
             last_push_unix_time,
             revision,
+            synced_revision,
             network_sent_bytes: self
                 .sync_engine_io
                 .network_stats
@@ -19,7 +19,7 @@ use crate::{
         LogicalOp, LogicalOpType, LogicalSchemaAction, LogicalSchemaKind, LogicalTxnData,
     },
     database_replay_generator::DatabaseReplayGenerator,
-    database_sync_engine::{DataStats, DatabaseSyncEngineOpts},
+    database_sync_engine::{DataStats, DatabaseSyncEngineOpts, SyncProgressStats},
     database_sync_engine_io::{DataCompletion, DataPollResult, SyncEngineIo},
     database_tape::{
         run_stmt_expect_one_row, run_stmt_ignore_rows, run_stmt_once, DatabaseChangesIteratorMode,
@@ -710,6 +710,7 @@ pub(crate) fn acquire_slot<T: Clone>(slot: &Arc<Mutex<Option<T>>>) -> Result<Mut
 pub struct SyncEngineIoStats<IO: SyncEngineIo> {
     pub io: Arc<IO>,
     pub network_stats: Arc<DataStats>,
+    pub progress: Arc<SyncProgressStats>,
 }
 
 impl<IO: SyncEngineIo> SyncEngineIoStats<IO> {
@@ -717,6 +718,7 @@ impl<IO: SyncEngineIo> SyncEngineIoStats<IO> {
         Self {
             io,
             network_stats: Arc::new(DataStats::new()),
+            progress: Arc::new(SyncProgressStats::new()),
         }
     }
 }
@@ -726,6 +728,7 @@ impl<IO: SyncEngineIo> Clone for SyncEngineIoStats<IO> {
         Self {
             io: self.io.clone(),
             network_stats: self.network_stats.clone(),
+            progress: self.progress.clone(),
         }
     }
 }
@@ -1889,6 +1892,7 @@ pub async fn pull_updates_v1<IO: SyncEngineIo, Ctx>(
         PullUpdatesStreamKind::Pages => {
             let replace_base = matches!(apply_mode, PullUpdatesApplyMode::ReplaceBase);
             truncate_file(ctx.coro, frames_file).await?;
+            ctx.io.progress.page_stream(header.db_size);
 
             let mut offset = 0;
             #[allow(clippy::arc_with_non_send_sync)]
@@ -1912,6 +1916,7 @@ pub async fn pull_updates_v1<IO: SyncEngineIo, Ctx>(
                         PAGE_SIZE
                     )));
                 }
+                ctx.io.progress.page_pulled();
                 buffer.as_mut_slice()[WAL_FRAME_HEADER..].copy_from_slice(&page);
                 page_data_opt =
                     wait_proto_message(ctx.coro, &completion, &ctx.io.network_stats, &mut bytes)
@@ -2026,6 +2031,7 @@ pub async fn wal_pull_to_file_v1<IO: SyncEngineIo, Ctx>(
     };
     tracing::info!("wal_pull_to_file: got header={:?}", header);
     ensure_incremental_page_stream(&header, "wal_pull_to_file_v1")?;
+    ctx.io.progress.page_stream(header.db_size);
 
     let mut offset = 0;
     #[allow(clippy::arc_with_non_send_sync)]
@@ -2049,6 +2055,7 @@ pub async fn wal_pull_to_file_v1<IO: SyncEngineIo, Ctx>(
                 PAGE_SIZE
             )));
         }
+        ctx.io.progress.page_pulled();
         buffer.as_mut_slice()[WAL_FRAME_HEADER..].copy_from_slice(&page);
         page_data_opt =
             wait_proto_message(ctx.coro, &completion, &ctx.io.network_stats, &mut bytes).await?;
@@ -2841,6 +2848,7 @@ pub async fn push_logical_changes<IO: SyncEngineIo, Ctx>(
                 .await?;
                 total_rows_changed += rows_changed;
                 last_change_id = Some(next_change_id);
+                ctx.io.progress.changes_pushed(batch.len());
                 batch.clear();
             }
         }
@@ -3233,6 +3241,7 @@ async fn pull_chunk_into_file<IO: SyncEngineIo, Ctx>(
     };
     tracing::info!("bootstrap_db_file: got header={:?}", header);
     ensure_page_stream(&header, "pull_chunk_into_file")?;
+    ctx.io.progress.page_stream(header.db_size);
     if truncate_on_first_response {
         let c = Completion::new_trunc(move |result| {
             let Ok(rc) = result else {
@@ -3268,6 +3277,7 @@ async fn pull_chunk_into_file<IO: SyncEngineIo, Ctx>(
                 PAGE_SIZE
             )));
         }
+        ctx.io.progress.page_pulled();
         buffer.as_mut_slice().copy_from_slice(&page);
         let c = Completion::new_write(move |result| {
             // todo(sivukhin): we need to error out in case of partial read
@@ -3518,6 +3528,7 @@ async fn sql_execute_http<IO: SyncEngineIo, Ctx>(
                 server_proto::StreamResponse::Execute(execute) => {
                     results.push(execute.result);
                 }
+                server_proto::StreamResponse::Close(_) => {}
                 server_proto::StreamResponse::Batch(batch) => {
                     for (i, error) in batch.result.step_errors.iter().enumerate() {
                         if let Some(error) = error {
@@ -149,6 +149,8 @@ pub enum StreamRequest {
     Execute(ExecuteStreamReq),
     /// See [`BatchStreamReq`]
     Batch(BatchStreamReq),
+    /// See [`CloseStreamReq`]
+    Close(CloseStreamReq),
 }
 
 #[derive(Serialize, Deserialize, Default, Debug, PartialEq)]
@@ -169,6 +171,7 @@ pub enum StreamResult {
 pub enum StreamResponse {
     Execute(ExecuteStreamResp),
     Batch(BatchStreamResp),
+    Close(CloseStreamResp),
 }
 
 #[derive(Serialize, Deserialize, Debug)]
@@ -270,6 +273,14 @@ pub struct ExecuteStreamReq {
     pub stmt: Stmt,
 }
 
+#[derive(Serialize, Deserialize, Debug)]
+/// A request to close the stream: the transaction left open on the stream is rolled back.
+pub struct CloseStreamReq {}
+
+#[derive(Serialize, Deserialize, Debug, PartialEq)]
+/// A response to a [`CloseStreamReq`].
+pub struct CloseStreamResp {}
+
 #[derive(Clone, Deserialize, Serialize, Debug, PartialEq)]
 pub struct Error {
     pub message: String,
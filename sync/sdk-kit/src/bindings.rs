@@ -10,6 +10,7 @@ pub enum turso_sync_io_request_type_t {
     TURSO_SYNC_IO_HTTP = 1,
     TURSO_SYNC_IO_FULL_READ = 2,
     TURSO_SYNC_IO_FULL_WRITE = 3,
+    TURSO_SYNC_IO_TRANSFORM = 4,
 }
 #[repr(C)]
 pub struct turso_sync_io_http_request_t {
@@ -69,6 +70,19 @@ impl Default for turso_sync_io_full_write_request_t {
         }
     }
 }
+#[repr(C)]
+pub struct turso_sync_io_transform_request_t {
+    pub mutations: turso_slice_ref_t,
+}
+impl Default for turso_sync_io_transform_request_t {
+    fn default() -> Self {
+        let mut s = ::std::mem::MaybeUninit::<Self>::uninit();
+        unsafe {
+            ::std::ptr::write_bytes(s.as_mut_ptr(), 0, 1);
+            s.assume_init()
+        }
+    }
+}
 #[repr(u32)]
 #[doc = " TURSO_ASYNC_OPERATION_RESULT"]
 #[derive(Debug, Copy, Clone, Hash, PartialEq, Eq)]
@@ -85,6 +99,14 @@ pub struct turso_sync_changes {
 }
 #[doc = " opaque pointer to the TursoDatabaseSyncChanges instance\n SAFETY: turso_sync_changes_t have independent lifetime and must be explicitly deallocated with turso_sync_changes_deinit method OR passed to the turso_sync_database_apply_changes method which gather ownership to this object"]
 pub type turso_sync_changes_t = turso_sync_changes;
+#[repr(u32)]
+#[doc = " kind of the revision pulled from the remote"]
+#[derive(Debug, Copy, Clone, Hash, PartialEq, Eq)]
+pub enum turso_sync_revision_type_t {
+    TURSO_SYNC_REVISION_NONE = 0,
+    TURSO_SYNC_REVISION_LEGACY = 1,
+    TURSO_SYNC_REVISION_V1 = 2,
+}
 #[doc = " structure holding opaque pointer to the SyncEngineStats instance\n SAFETY: revision string will be valid only during async operation lifetime (until turso_sync_operation_deinit)\n Most likely, caller will need to copy revision slice to its internal buffer for longer lifetime"]
 #[repr(C)]
 pub struct turso_sync_stats_t {
@@ -96,6 +118,10 @@ pub struct turso_sync_stats_t {
     pub network_sent_bytes: i64,
     pub network_received_bytes: i64,
     pub revision: turso_slice_ref_t,
+    #[doc = " kind of the revision pulled last from the remote (synced_revision of the sync engine metadata)\n NONE: nothing was pulled yet\n LEGACY: synced_generation and synced_frame_no hold the revision (synced_frame_no is -1 if only the generation is known)\n V1: revision slice holds the opaque revision"]
+    pub synced_revision_type: turso_sync_revision_type_t,
+    pub synced_generation: i64,
+    pub synced_frame_no: i64,
 }
 impl Default for turso_sync_stats_t {
     fn default() -> Self {
@@ -106,6 +132,17 @@ impl Default for turso_sync_stats_t {
         }
     }
 }
+#[doc = " progress counters of the sync operations, cumulative over the lifetime of the synced database\n progress of a single operation is the difference of the counters before and after it"]
+#[repr(C)]
+#[derive(Debug, Default, Copy, Clone)]
+pub struct turso_sync_progress_t {
+    #[doc = " pages received from the remote by pull and bootstrap page streams"]
+    pub pulled_pages: i64,
+    #[doc = " size of the remote database in pages announced by the latest page stream"]
+    pub remote_db_pages: i64,
+    #[doc = " local changes sent to the remote by push"]
+    pub pushed_changes: i64,
+}
 #[doc = " Database sync description."]
 #[repr(C)]
 #[derive(Debug, Copy, Clone)]
@@ -125,6 +162,7 @@ pub struct turso_sync_database_config_t {
     pub push_operations_threshold: usize,
     pub pull_bytes_threshold: usize,
     pub logical_mvcc_pull: bool,
+    pub use_transform: bool,
 }
 impl Default for turso_sync_database_config_t {
     fn default() -> Self {
@@ -279,6 +317,14 @@ unsafe extern "C" {
         error_opt_out: *mut *const ::std::os::raw::c_char,
     ) -> turso_status_code_t;
 }
+unsafe extern "C" {
+    #[doc = " Read progress counters of the synced database\n Counters are updated while async operations run, so caller can poll them between IO items"]
+    pub fn turso_sync_database_progress(
+        self_: *const turso_sync_database_t,
+        progress: *mut turso_sync_progress_t,
+        error_opt_out: *mut *const ::std::os::raw::c_char,
+    ) -> turso_status_code_t;
+}
 unsafe extern "C" {
     #[doc = " Get request IO kind"]
     pub fn turso_sync_database_io_request_kind(
@@ -314,6 +360,13 @@ unsafe extern "C" {
         request: *mut turso_sync_io_full_write_request_t,
     ) -> turso_status_code_t;
 }
+unsafe extern "C" {
+    #[doc = " Get transform request fields"]
+    pub fn turso_sync_database_io_request_transform(
+        self_: *const turso_sync_io_item_t,
+        request: *mut turso_sync_io_transform_request_t,
+    ) -> turso_status_code_t;
+}
 unsafe extern "C" {
     #[doc = " Poison IO request completion with error"]
     pub fn turso_sync_database_io_poison(
@@ -296,6 +296,21 @@ pub extern "C" fn turso_sync_database_io_step_callbacks(
     turso_status_code_t::TURSO_OK
 }
 
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_sync_database_progress(
+    db: *const c::turso_sync_database_t,
+    progress_ref: *mut c::turso_sync_progress_t,
+    error_opt_out: *mut *const std::ffi::c_char,
+) -> turso_status_code_t {
+    let db = match unsafe { TursoDatabaseSync::ref_from_capi(db) } {
+        Ok(db) => db,
+        Err(err) => return unsafe { err.to_capi(error_opt_out) },
+    };
+    unsafe { *progress_ref = db.progress() };
+    turso_status_code_t::TURSO_OK
+}
+
 #[no_mangle]
 #[signature(c)]
 pub extern "C" fn turso_sync_database_io_request_kind(
@@ -381,6 +396,24 @@ pub extern "C" fn turso_sync_database_io_request_full_write(
     turso_status_code_t::TURSO_OK
 }
 
+#[no_mangle]
+#[signature(c)]
+pub extern "C" fn turso_sync_database_io_request_transform(
+    request: *const c::turso_sync_io_item_t,
+    transform_ref: *mut c::turso_sync_io_transform_request_t,
+) -> turso_status_code_t {
+    let request = match unsafe { SyncEngineIoQueueItem::<Vec<u8>>::ref_from_capi(request) } {
+        Ok(request) => request,
+        Err(err) => return unsafe { err.to_capi(std::ptr::null_mut()) },
+    };
+    let transform = match request.get_request().transform_to_capi() {
+        Ok(transform) => transform,
+        Err(err) => return unsafe { err.to_capi(std::ptr::null_mut()) },
+    };
+    unsafe { *transform_ref = transform };
+    turso_status_code_t::TURSO_OK
+}
+
 #[no_mangle]
 #[signature(c)]
 pub extern "C" fn turso_sync_database_io_poison(
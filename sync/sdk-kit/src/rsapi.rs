@@ -1,4 +1,4 @@
-use std::sync::Arc;
+use std::sync::{atomic::Ordering, Arc};
 
 use parking_lot::Mutex;
 use turso_core::{MemoryIO, IO};
@@ -41,6 +41,9 @@ pub struct TursoDatabaseSyncConfig {
     /// required when syncing against an MVCC-mode remote; legacy page/WAL sync
     /// keeps the default `false` value.
     pub logical_mvcc_pull: bool,
+    /// Pass local row changes replayed on top of pulled remote changes and sent by push
+    /// to the caller through transform IO requests.
+    pub use_transform: bool,
 }
 
 pub type PartialSyncOpts = turso_sync_engine::types::PartialSyncOpts;
@@ -123,6 +126,7 @@ impl TursoDatabaseSyncConfig {
                 Some(config.pull_bytes_threshold)
             },
             logical_mvcc_pull: config.logical_mvcc_pull,
+            use_transform: config.use_transform,
         })
     }
 }
@@ -244,7 +248,7 @@ impl<TBytes: AsRef<[u8]> + Send + Sync + 'static> TursoDatabaseSync<TBytes> {
             remote_url: sync_config.remote_url.clone(),
             client_name: sync_config.client_name.clone(),
             tables_ignore: vec![],
-            use_transform: false,
+            use_transform: sync_config.use_transform,
             wal_pull_batch_size: 0,
             long_poll_timeout: sync_config
                 .long_poll_timeout_ms
@@ -526,6 +530,17 @@ impl<TBytes: AsRef<[u8]> + Send + Sync + 'static> TursoDatabaseSync<TBytes> {
         self.sync_engine_io_queue.step_io_callbacks();
     }
 
+    /// read progress counters of sync operations
+    /// counters are cumulative and updated while async operations run, so caller can poll them between IO items
+    pub fn progress(&self) -> capi::c::turso_sync_progress_t {
+        let progress = &self.sync_engine_io_queue.progress;
+        capi::c::turso_sync_progress_t {
+            pulled_pages: progress.pulled_pages.load(Ordering::SeqCst) as i64,
+            remote_db_pages: progress.remote_db_pages.load(Ordering::SeqCst) as i64,
+            pushed_changes: progress.pushed_changes.load(Ordering::SeqCst) as i64,
+        }
+    }
+
     /// helper method to get C raw container to the TursoDatabaseSync instance
     /// this method is used in the capi wrappers
     pub fn to_capi(self: Arc<Self>) -> *mut capi::c::turso_sync_database_t {
@@ -1,9 +1,16 @@
 use std::{
-    collections::VecDeque,
+    collections::{HashMap, VecDeque},
     sync::{Arc, Mutex, MutexGuard},
 };
 
 use turso_sdk_kit::rsapi::{turso_slice_from_bytes, turso_slice_null, TursoError};
+use turso_sync_engine::{
+    server_proto,
+    types::{
+        DatabaseChangeType, DatabaseRowMutation, DatabaseRowTransformResult,
+        DatabaseStatementReplay,
+    },
+};
 
 use crate::capi::c::{self};
 
@@ -20,6 +27,124 @@ pub enum SyncEngineIoRequest {
     FullRead { path: String },
     /// atomic write of the file content (on most FS, this will be temp file write followed by rename and fsync)
     FullWrite { path: String, content: Vec<u8> },
+    /// transformation of the local row changes replayed by the sync engine
+    /// mutations are encoded as JSON array of [RowMutationJson] objects
+    Transform { mutations: Vec<u8> },
+}
+
+/// JSON representation of the [DatabaseRowMutation] passed to the transform IO request
+/// values are encoded in the same way as values of the remote SQL over HTTP protocol
+#[derive(serde::Serialize)]
+struct RowMutationJson {
+    change_time: u64,
+    table_name: String,
+    id: i64,
+    change_type: &'static str,
+    before: Option<HashMap<String, server_proto::Value>>,
+    after: Option<HashMap<String, server_proto::Value>>,
+    updates: Option<HashMap<String, server_proto::Value>>,
+}
+
+/// JSON representation of the [DatabaseRowTransformResult] returned by the caller for every mutation
+#[derive(serde::Deserialize)]
+#[serde(tag = "type", rename_all = "snake_case")]
+enum RowTransformResultJson {
+    Keep,
+    Skip,
+    Rewrite {
+        sql: String,
+        #[serde(default)]
+        args: Vec<server_proto::Value>,
+    },
+}
+
+fn core_value_to_proto(value: turso_core::Value) -> server_proto::Value {
+    match value {
+        turso_core::Value::Null => server_proto::Value::Null,
+        turso_core::Value::Numeric(turso_core::Numeric::Integer(value)) => {
+            server_proto::Value::Integer { value }
+        }
+        turso_core::Value::Numeric(turso_core::Numeric::Float(value)) => {
+            server_proto::Value::Float {
+                value: f64::from(value),
+            }
+        }
+        turso_core::Value::Text(value) => server_proto::Value::Text {
+            value: value.as_str().to_string(),
+        },
+        turso_core::Value::Blob(value) => server_proto::Value::Blob {
+            value: value.into(),
+        },
+    }
+}
+
+fn proto_value_to_core(value: server_proto::Value) -> turso_core::Value {
+    match value {
+        server_proto::Value::None | server_proto::Value::Null => turso_core::Value::Null,
+        server_proto::Value::Integer { value } => turso_core::Value::from_i64(value),
+        server_proto::Value::Float { value } => turso_core::Value::from_f64(value),
+        server_proto::Value::Text { value } => {
+            turso_core::Value::Text(turso_core::types::Text::new(value))
+        }
+        server_proto::Value::Blob { value } => turso_core::Value::Blob(value.to_vec()),
+    }
+}
+
+fn core_values_map_to_proto(
+    values: Option<HashMap<String, turso_core::Value>>,
+) -> Option<HashMap<String, server_proto::Value>> {
+    values.map(|values| {
+        values
+            .into_iter()
+            .map(|(column, value)| (column, core_value_to_proto(value)))
+            .collect()
+    })
+}
+
+/// encode mutations of the transform IO request as JSON array
+fn mutations_to_json(mutations: Vec<DatabaseRowMutation>) -> turso_sync_engine::Result<Vec<u8>> {
+    let mutations = mutations
+        .into_iter()
+        .map(|mutation| RowMutationJson {
+            change_time: mutation.change_time,
+            table_name: mutation.table_name,
+            id: mutation.id,
+            change_type: match mutation.change_type {
+                DatabaseChangeType::Delete => "delete",
+                DatabaseChangeType::Update => "update",
+                DatabaseChangeType::Insert => "insert",
+                DatabaseChangeType::Commit => "commit",
+            },
+            before: core_values_map_to_proto(mutation.before),
+            after: core_values_map_to_proto(mutation.after),
+            updates: core_values_map_to_proto(mutation.updates),
+        })
+        .collect::<Vec<_>>();
+    Ok(serde_json::to_vec(&mutations)?)
+}
+
+/// decode JSON array of transform results pushed by the caller to the transform IO completion
+fn transform_results_from_json(
+    data: &[u8],
+) -> turso_sync_engine::Result<Vec<DatabaseRowTransformResult>> {
+    let results: Vec<RowTransformResultJson> = serde_json::from_slice(data).map_err(|e| {
+        turso_sync_engine::errors::Error::DatabaseSyncEngineError(format!(
+            "malformed transform result: {e}"
+        ))
+    })?;
+    Ok(results
+        .into_iter()
+        .map(|result| match result {
+            RowTransformResultJson::Keep => DatabaseRowTransformResult::Keep,
+            RowTransformResultJson::Skip => DatabaseRowTransformResult::Skip,
+            RowTransformResultJson::Rewrite { sql, args } => {
+                DatabaseRowTransformResult::Rewrite(DatabaseStatementReplay {
+                    sql,
+                    values: args.into_iter().map(proto_value_to_core).collect(),
+                })
+            }
+        })
+        .collect())
 }
 
 impl SyncEngineIoRequest {
@@ -32,6 +157,9 @@ impl SyncEngineIoRequest {
             SyncEngineIoRequest::FullWrite { .. } => {
                 c::turso_sync_io_request_type_t::TURSO_SYNC_IO_FULL_WRITE
             }
+            SyncEngineIoRequest::Transform { .. } => {
+                c::turso_sync_io_request_type_t::TURSO_SYNC_IO_TRANSFORM
+            }
         }
     }
     /// extract header key-value pair from the HTTP IO request
@@ -97,6 +225,16 @@ impl SyncEngineIoRequest {
             _ => Err(TursoError::Misuse("unexpected io request type".to_string())),
         }
     }
+    pub fn transform_to_capi(&self) -> Result<c::turso_sync_io_transform_request_t, TursoError> {
+        match self {
+            SyncEngineIoRequest::Transform { mutations } => {
+                Ok(c::turso_sync_io_transform_request_t {
+                    mutations: turso_slice_from_bytes(mutations.as_ref()),
+                })
+            }
+            _ => Err(TursoError::Misuse("unexpected io request type".to_string())),
+        }
+    }
 }
 
 struct SyncEngineIoCompletionInner<TBytes: AsRef<[u8]>> {
@@ -140,36 +278,46 @@ impl<TBytes: AsRef<[u8]> + Send + Sync + 'static>
     }
 }
 
-// todo(sivukhin): implement mutation callback
-pub struct SyncEngineIoTransformPollResult;
+pub struct SyncEngineIoTransformPollResult(Vec<DatabaseRowTransformResult>);
 
-impl
-    turso_sync_engine::database_sync_engine_io::DataPollResult<
-        turso_sync_engine::types::DatabaseRowTransformResult,
-    > for SyncEngineIoTransformPollResult
+impl turso_sync_engine::database_sync_engine_io::DataPollResult<DatabaseRowTransformResult>
+    for SyncEngineIoTransformPollResult
 {
-    fn data(&self) -> &[turso_sync_engine::types::DatabaseRowTransformResult] {
-        todo!()
+    fn data(&self) -> &[DatabaseRowTransformResult] {
+        &self.0
     }
 }
 
+/// transform completion reuses buffers of the IO completion: caller pushes JSON array of transform
+/// results (possibly in several chunks) and marks completion as done, after which results are decoded at once
 impl<TBytes: AsRef<[u8]> + Send + Sync + 'static>
-    turso_sync_engine::database_sync_engine_io::DataCompletion<
-        turso_sync_engine::types::DatabaseRowTransformResult,
-    > for SyncEngineIoCompletion<TBytes>
+    turso_sync_engine::database_sync_engine_io::DataCompletion<DatabaseRowTransformResult>
+    for SyncEngineIoCompletion<TBytes>
 {
     type DataPollResult = SyncEngineIoTransformPollResult;
 
     fn status(&self) -> turso_sync_engine::Result<Option<u16>> {
-        todo!()
+        let inner = self.inner()?;
+        Ok(inner.status)
     }
 
     fn poll_data(&self) -> turso_sync_engine::Result<Option<Self::DataPollResult>> {
-        todo!()
+        let mut inner = self.inner()?;
+        if !inner.finished || inner.chunks.is_empty() {
+            return Ok(None);
+        }
+        let mut data = Vec::new();
+        for chunk in inner.chunks.drain(..) {
+            data.extend_from_slice(chunk.as_ref());
+        }
+        Ok(Some(SyncEngineIoTransformPollResult(
+            transform_results_from_json(&data)?,
+        )))
     }
 
     fn is_done(&self) -> turso_sync_engine::Result<bool> {
-        todo!()
+        let inner = self.inner()?;
+        Ok(inner.finished)
     }
 }
 
@@ -309,10 +457,11 @@ impl<TBytes: AsRef<[u8]> + Send + Sync + 'static>
 
     fn transform(
         &self,
-        _mutations: Vec<turso_sync_engine::types::DatabaseRowMutation>,
+        mutations: Vec<DatabaseRowMutation>,
     ) -> turso_sync_engine::Result<Self::DataCompletionTransform> {
-        // todo(sivukhin): add mutation callbacks to the sdk-kit
-        todo!()
+        Ok(self.push_back(SyncEngineIoRequest::Transform {
+            mutations: mutations_to_json(mutations)?,
+        }))
     }
 
     fn add_io_callback(&self, callback: Box<dyn FnMut() -> bool + Send>) {
@@ -4,6 +4,7 @@ use turso_sdk_kit::{
     capi::c::turso_slice_ref_t,
     rsapi::{self, turso_slice_from_bytes, TursoError},
 };
+use turso_sync_engine::types::DatabasePullRevision;
 
 use crate::{
     capi::c::{self},
@@ -119,6 +120,26 @@ impl TursoDatabaseAsyncOperation {
                 } else {
                     turso_slice_ref_t::default()
                 },
+                synced_revision_type: match &stats.synced_revision {
+                    None => c::turso_sync_revision_type_t::TURSO_SYNC_REVISION_NONE,
+                    Some(DatabasePullRevision::Legacy { .. }) => {
+                        c::turso_sync_revision_type_t::TURSO_SYNC_REVISION_LEGACY
+                    }
+                    Some(DatabasePullRevision::V1 { .. }) => {
+                        c::turso_sync_revision_type_t::TURSO_SYNC_REVISION_V1
+                    }
+                },
+                synced_generation: match &stats.synced_revision {
+                    Some(DatabasePullRevision::Legacy { generation, .. }) => *generation as i64,
+                    _ => -1,
+                },
+                synced_frame_no: match &stats.synced_revision {
+                    Some(DatabasePullRevision::Legacy {
+                        synced_frame_no: Some(frame_no),
+                        ..
+                    }) => *frame_no as i64,
+                    _ => -1,
+                },
             }),
             _ => Err(TursoError::Misuse(
                 "unexpected async operation result".to_string(),
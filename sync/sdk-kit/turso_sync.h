@@ -20,6 +20,9 @@ typedef enum
     TURSO_SYNC_IO_FULL_READ = 2,
     // atomic write of the file (operation either succeed or no, on most FS this will be write to temp file followed by rename)
     TURSO_SYNC_IO_FULL_WRITE = 3,
+    // transformation of local row changes replayed by the sync engine (issued only if use_transform is set)
+    // caller must push JSON array with one result per mutation and mark the completion as done
+    TURSO_SYNC_IO_TRANSFORM = 4,
 } turso_sync_io_request_type_t;
 
 // sync engine IO HTTP request fields
@@ -60,6 +63,16 @@ typedef struct
     turso_slice_ref_t content;
 } turso_sync_io_full_write_request_t;
 
+// sync engine IO transform request
+typedef struct
+{
+    // JSON array of row mutations: {"change_time", "table_name", "id", "change_type": "insert"|"update"|"delete", "before", "after", "updates"}
+    // where before, after and updates are either null or objects mapping column names to values encoded as in the SQL over HTTP protocol
+    // (e.g. {"type":"integer","value":"1"}, {"type":"text","value":"a"}, {"type":"blob","base64":"AQ=="}, {"type":"null"})
+    // results pushed by the caller must be JSON array of {"type":"keep"}, {"type":"skip"} or {"type":"rewrite","sql":"...","args":[<values>]} objects
+    turso_slice_ref_t mutations;
+} turso_sync_io_transform_request_t;
+
 /******** TURSO_ASYNC_OPERATION_RESULT ********/
 
 // async operation result type
@@ -79,6 +92,14 @@ typedef enum
 /// SAFETY: turso_sync_changes_t have independent lifetime and must be explicitly deallocated with turso_sync_changes_deinit method OR passed to the turso_sync_database_apply_changes method which gather ownership to this object
 typedef struct turso_sync_changes turso_sync_changes_t;
 
+// kind of the revision pulled from the remote
+typedef enum
+{
+    TURSO_SYNC_REVISION_NONE = 0,
+    TURSO_SYNC_REVISION_LEGACY = 1,
+    TURSO_SYNC_REVISION_V1 = 2,
+} turso_sync_revision_type_t;
+
 /// structure holding opaque pointer to the SyncEngineStats instance
 /// SAFETY: revision string will be valid only during async operation lifetime (until turso_sync_operation_deinit)
 /// Most likely, caller will need to copy revision slice to its internal buffer for longer lifetime
@@ -92,8 +113,27 @@ typedef struct
     int64_t network_sent_bytes;
     int64_t network_received_bytes;
     turso_slice_ref_t revision;
+    // kind of the revision pulled last from the remote (synced_revision of the sync engine metadata)
+    // NONE: nothing was pulled yet
+    // LEGACY: synced_generation and synced_frame_no hold the revision (synced_frame_no is -1 if only the generation is known)
+    // V1: revision slice holds the opaque revision
+    turso_sync_revision_type_t synced_revision_type;
+    int64_t synced_generation;
+    int64_t synced_frame_no;
 } turso_sync_stats_t;
 
+/// progress counters of the sync operations, cumulative over the lifetime of the synced database
+/// progress of a single operation is the difference of the counters before and after it
+typedef struct
+{
+    /// pages received from the remote by pull and bootstrap page streams
+    int64_t pulled_pages;
+    /// size of the remote database in pages announced by the latest page stream
+    int64_t remote_db_pages;
+    /// local changes sent to the remote by push
+    int64_t pushed_changes;
+} turso_sync_progress_t;
+
 /******** MAIN TYPES ********/
 
 /**
@@ -141,6 +181,9 @@ typedef struct
     // when true, V1 incremental pulls use the MVCC logical-log stream instead of
     // the page stream. required for MVCC-mode remotes; leave false for legacy sync.
     bool logical_mvcc_pull;
+    // when true, local row changes replayed on top of pulled remote changes and sent by push
+    // are passed to the caller through TURSO_SYNC_IO_TRANSFORM requests which decide what to do with every row
+    bool use_transform;
 } turso_sync_database_config_t;
 
 /// opaque pointer to the TursoDatabaseSync instance
@@ -301,6 +344,17 @@ turso_sync_database_io_step_callbacks(
     /** Optional return error parameter (can be null) */
     const char **error_opt_out);
 
+/** Read progress counters of the synced database
+ * Counters are updated while async operations run, so caller can poll them between IO items
+ */
+turso_status_code_t
+turso_sync_database_progress(
+    const turso_sync_database_t *self,
+    /** reference to the progress counters which will be filled in case of TURSO_OK result */
+    turso_sync_progress_t *progress,
+    /** Optional return error parameter (can be null) */
+    const char **error_opt_out);
+
 /** Get request IO kind */
 turso_sync_io_request_type_t
 turso_sync_database_io_request_kind(const turso_sync_io_item_t *self);
@@ -321,6 +375,10 @@ turso_sync_database_io_request_full_read(const turso_sync_io_item_t *self, turso
 turso_status_code_t
 turso_sync_database_io_request_full_write(const turso_sync_io_item_t *self, turso_sync_io_full_write_request_t *request);
 
+/** Get transform request fields */
+turso_status_code_t
+turso_sync_database_io_request_transform(const turso_sync_io_item_t *self, turso_sync_io_transform_request_t *request);
+
 /** Poison IO request completion with error */
 turso_status_code_t turso_sync_database_io_poison(const turso_sync_io_item_t *self, turso_slice_ref_t *error);
 
@@ -2,7 +2,7 @@ use std::sync::Arc;
 use turso::IoBackend;
 use turso_core::SqliteDialect;
 use turso_core::{Database, OpenFlags};
-use turso_sdk_kit::rsapi::{TursoDatabase, TursoDatabaseConfig, TursoStatusCode};
+use turso_sdk_kit::rsapi::{TursoDatabase, TursoDatabaseConfig, TursoError, TursoStatusCode};
 
 /// Regression test: DATABASE_MANAGER registry returns stale Database after
 /// the underlying file is renamed.
@@ -331,3 +331,48 @@ fn test_do_open_skips_registry() {
         "open() must still return the registered instance; do_open must not replace it"
     );
 }
+
+/// The registry shares one Database per file, so read-only mode is kept per connection:
+/// read-only opens of a file open read-write get read-only connections, while read-write
+/// opens of a file open read-only fail instead of silently getting a read-only database.
+#[test]
+fn test_sdk_read_only_open_of_shared_database() {
+    let tmp_dir = tempfile::TempDir::new().unwrap();
+    let path = tmp_dir.path().join("shared.db");
+    let open = |flags: OpenFlags| {
+        let db = TursoDatabase::new(TursoDatabaseConfig {
+            path: path.to_str().unwrap().to_string(),
+            experimental_features: None,
+            async_io: false,
+            encryption: None,
+            vfs: IoBackend::Default,
+            io: None,
+            db_file: None,
+        });
+        db.set_open_flags(flags).unwrap();
+        db.open().map(|_| db)
+    };
+    let execute = |db: &Arc<TursoDatabase>, sql: &str| {
+        let conn = db.connect().unwrap();
+        let mut stmt = conn.prepare_single(sql)?;
+        stmt.execute(None).map(|_| ())
+    };
+
+    let rw = open(OpenFlags::Create).unwrap();
+    execute(&rw, "CREATE TABLE t(x INTEGER)").unwrap();
+    let ro = open(OpenFlags::ReadOnly).unwrap();
+    execute(&ro, "SELECT x FROM t").unwrap();
+    assert!(matches!(
+        execute(&ro, "INSERT INTO t VALUES (1)"),
+        Err(TursoError::Readonly(_))
+    ));
+    execute(&rw, "INSERT INTO t VALUES (1)").unwrap();
+    drop(rw);
+    drop(ro);
+
+    let ro = open(OpenFlags::ReadOnly).unwrap();
+    assert!(matches!(open(OpenFlags::Create), Err(TursoError::Readonly(_))));
+    drop(ro);
+    let rw = open(OpenFlags::Create).unwrap();
+    execute(&rw, "INSERT INTO t VALUES (2)").unwrap();
+}
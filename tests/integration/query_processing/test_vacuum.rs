@@ -7,7 +7,9 @@ use rusqlite::Connection as SqliteConnection;
 use std::{path::Path, sync::Arc};
 use tempfile::TempDir;
 use turso_core::SqliteDialect;
-use turso_core::{Connection, Database, DatabaseOpts, LimboError, StepResult, Value};
+use turso_core::{
+    Connection, Database, DatabaseOpts, EncryptionKey, LimboError, StepResult, Value,
+};
 use turso_parser::{ast::Cmd, parser::Parser};
 
 #[derive(Debug, Clone, PartialEq, Eq)]
@@ -5498,6 +5500,54 @@ fn test_plain_vacuum_encrypted_existing() -> anyhow::Result<()> {
     Ok(())
 }
 
+/// Rekey rewrites the encrypted database through plain VACUUM with the
+/// temp database and the source WAL frames encrypted with the new key.
+#[test]
+fn test_rekey_encrypted() -> anyhow::Result<()> {
+    const HEXKEY: &str = "b1bbfda4f589dc9daaf004fe21111e00dc00c98237102f5c7002a5669fc76327";
+    const NEW_HEXKEY: &str = "c2ccfda4f589dc9daaf004fe21111e00dc00c98237102f5c7002a5669fc76327";
+
+    let tmp_db = TempDatabase::new_empty();
+    {
+        let conn = tmp_db.connect_limbo();
+        conn.execute(format!("PRAGMA hexkey = '{HEXKEY}'"))?;
+        conn.execute("PRAGMA cipher = 'aegis256'")?;
+
+        conn.execute("CREATE TABLE t(id INTEGER PRIMARY KEY, v TEXT)")?;
+        for i in 0..150 {
+            conn.execute(format!("INSERT INTO t VALUES({i}, '{}')", "z".repeat(80)))?;
+        }
+        conn.rekey(EncryptionKey::from_hex_string(NEW_HEXKEY)?)?;
+        assert_eq!(run_integrity_check(&conn), "ok");
+        assert_eq!(scalar_i64(&conn, "SELECT COUNT(*) FROM t"), 150);
+        conn.execute("INSERT INTO t VALUES(1000, 'after-rekey')")?;
+        do_flush(&conn, &tmp_db)?;
+    }
+
+    let old_key = TempDatabase::new_with_existent_with_opts(&tmp_db.path, tmp_db.db_opts);
+    let old_key_conn = old_key.connect_limbo();
+    old_key_conn.execute(format!("PRAGMA hexkey = '{HEXKEY}'"))?;
+    old_key_conn.execute("PRAGMA cipher = 'aegis256'")?;
+    assert!(old_key_conn.execute("SELECT COUNT(*) FROM t").is_err());
+    drop(old_key_conn);
+    drop(old_key);
+
+    let reopened = TempDatabase::new_with_existent_with_opts(&tmp_db.path, tmp_db.db_opts);
+    let reopened_conn = reopened.connect_limbo();
+    reopened_conn.execute(format!("PRAGMA hexkey = '{NEW_HEXKEY}'"))?;
+    reopened_conn.execute("PRAGMA cipher = 'aegis256'")?;
+    assert_eq!(run_integrity_check(&reopened_conn), "ok");
+    assert_eq!(scalar_i64(&reopened_conn, "SELECT COUNT(*) FROM t"), 151);
+
+    // plain database can't be rekeyed
+    let plain = TempDatabase::new_empty();
+    let plain_conn = plain.connect_limbo();
+    assert!(plain_conn
+        .rekey(EncryptionKey::from_hex_string(NEW_HEXKEY)?)
+        .is_err());
+    Ok(())
+}
+
 #[test]
 fn test_plain_vacuum_preserves_full_autovacuum() -> anyhow::Result<()> {
     assert_plain_vacuum_preserves_autovacuum_mode("full", 1)